@@ -0,0 +1,371 @@
+// Package backup reads and writes archives of data that is deleted or modified by CLI operations,
+// so that the data may later be restored.
+//
+// An archive is a local directory or S3 prefix containing a manifest.json file that describes
+// the archive contents. DynamoDB items are stored as DynamoDB JSON, one item per line, in files
+// under items/, and S3 objects are stored under objects/ at their original keys. The metadata of
+// S3 objects is stored as JSON, one object per line, in files under object-index/.
+//
+// The manifest is rewritten each time that buffered data is flushed to the archive, so that data
+// which was flushed remains restorable even if the archive is never closed (e.g. because the
+// process was killed). Data should therefore only be deleted once it has been flushed.
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/usdigitalresponse/grants-ingest/internal/itemMapper"
+)
+
+const (
+	ManifestName    = "manifest.json"
+	ManifestVersion = 2
+
+	// maxItemSize is large enough for a DynamoDB item of the maximum size (400 KB) in DynamoDB JSON.
+	maxItemSize = 4 * 1024 * 1024
+)
+
+var (
+	ErrArchiveExists    = errors.New("an archive already exists at this location")
+	ErrArchiveMismatch  = errors.New("the archive was not created from the same source")
+	ErrLocationMismatch = errors.New("the backup location differs from that of the interrupted run")
+)
+
+// Kind identifies the type of data source from which an archive was created.
+type Kind string
+
+const (
+	KindDynamoDBTable Kind = "dynamodb-table"
+	KindS3Bucket      Kind = "s3-bucket"
+)
+
+// Manifest describes the contents of an archive.
+type Manifest struct {
+	Version int  `json:"version"`
+	Kind    Kind `json:"kind"`
+	// Name of the DynamoDB table or S3 bucket from which data was archived
+	Source string `json:"source"`
+	// Description of the operation for which data was archived
+	Operation string    `json:"operation"`
+	CreatedAt time.Time `json:"created_at"`
+	// Unset until the archive is closed, so an archive without it was interrupted while
+	// being written
+	ClosedAt    *time.Time `json:"closed_at,omitempty"`
+	ItemCount   int64      `json:"item_count"`
+	ItemFiles   []string   `json:"item_files"`
+	ObjectFiles []string   `json:"object_files"`
+	// Objects are listed in ObjectFiles, which are read into Objects by Open
+	// (version 1 manifests list objects here instead)
+	Objects []Object `json:"objects,omitempty"`
+}
+
+// Object describes an archived S3 object.
+type Object struct {
+	Key             string            `json:"key"`
+	File            string            `json:"file"`
+	Size            int64             `json:"size"`
+	ETag            string            `json:"etag,omitempty"`
+	LastModified    *time.Time        `json:"last_modified,omitempty"`
+	ContentType     string            `json:"content_type,omitempty"`
+	ContentEncoding string            `json:"content_encoding,omitempty"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
+}
+
+// Writer writes an archive. It is safe for concurrent use.
+type Writer struct {
+	ctx   context.Context
+	store store
+
+	mu             sync.Mutex
+	manifest       Manifest
+	pending        bytes.Buffer
+	pendingCount   int64
+	pendingObjects bytes.Buffer
+}
+
+// Create returns a Writer for a new archive of data from the given source at location,
+// which is either a local directory or an S3 prefix in the form s3://bucket/prefix.
+// The s3svc client is only required for S3 locations. The archive manifest is written
+// immediately, so ErrArchiveExists is returned if any archive (even an interrupted one)
+// already exists at location.
+func Create(ctx context.Context, location string, s3svc *s3.Client, kind Kind, source, operation string) (*Writer, error) {
+	st, err := newStore(location, s3svc)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := st.get(ctx, ManifestName)
+	if err == nil {
+		existing.Close()
+		return nil, ErrArchiveExists
+	} else if !errors.Is(err, ErrNotExist) {
+		return nil, err
+	}
+
+	w := &Writer{ctx: ctx, store: st, manifest: Manifest{
+		Version:     ManifestVersion,
+		Kind:        kind,
+		Source:      source,
+		Operation:   operation,
+		CreatedAt:   time.Now().UTC(),
+		ItemFiles:   []string{},
+		ObjectFiles: []string{},
+	}}
+	if err := w.writeManifest(ctx); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Reopen returns a Writer that adds to the archive at location, which was created from the
+// given source by an operation that was interrupted, so that the archive of a resumed operation
+// includes the data archived before the interruption. The archive is marked as closed again
+// when the Writer is closed.
+func Reopen(ctx context.Context, location string, s3svc *s3.Client, kind Kind, source string) (*Writer, error) {
+	st, err := newStore(location, s3svc)
+	if err != nil {
+		return nil, err
+	}
+	m, err := readManifest(ctx, st)
+	if err != nil {
+		return nil, err
+	}
+	if m.Version != ManifestVersion {
+		return nil, fmt.Errorf("cannot add to an archive with manifest version %d", m.Version)
+	}
+	if m.Kind != kind || m.Source != source {
+		return nil, fmt.Errorf("%w (archived from %s %q)", ErrArchiveMismatch, m.Kind, m.Source)
+	}
+	m.ClosedAt = nil
+	w := &Writer{ctx: ctx, store: st, manifest: m}
+	if err := w.writeManifest(ctx); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Location returns the location of the archive.
+func (w *Writer) Location() string {
+	return w.store.String()
+}
+
+// AddItem adds a DynamoDB item to the archive. Items are buffered until Flush or Close
+// is called.
+func (w *Writer) AddItem(item map[string]types.AttributeValue) error {
+	converted, err := itemMapper.FromAttributeValueMap(item)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(converted)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending.Write(b)
+	w.pending.WriteByte('\n')
+	w.pendingCount++
+	return nil
+}
+
+// AddObject writes the body of an S3 object to the archive. The object's metadata is buffered
+// until Flush or Close is called, and the object cannot be restored from the archive until then.
+func (w *Writer) AddObject(obj Object, body io.Reader) error {
+	if !fs.ValidPath(obj.Key) {
+		return fmt.Errorf("cannot archive S3 object with key %q", obj.Key)
+	}
+	obj.File = "objects/" + obj.Key
+	if err := w.store.put(w.ctx, obj.File, body); err != nil {
+		return err
+	}
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pendingObjects.Write(b)
+	w.pendingObjects.WriteByte('\n')
+	return nil
+}
+
+// Flush writes all buffered items and object metadata to new files in the archive, and
+// updates the archive manifest to include them. Once Flush returns, all data that was added
+// to the archive is restorable.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flush(w.ctx)
+}
+
+func (w *Writer) flush(ctx context.Context) error {
+	if w.pendingCount == 0 && w.pendingObjects.Len() == 0 {
+		return nil
+	}
+	if w.pendingCount > 0 {
+		name := fmt.Sprintf("items/%06d.jsonl", len(w.manifest.ItemFiles)+1)
+		if err := w.store.put(ctx, name, bytes.NewReader(w.pending.Bytes())); err != nil {
+			return err
+		}
+		w.manifest.ItemFiles = append(w.manifest.ItemFiles, name)
+		w.manifest.ItemCount += w.pendingCount
+		w.pending.Reset()
+		w.pendingCount = 0
+	}
+	if w.pendingObjects.Len() > 0 {
+		name := fmt.Sprintf("object-index/%06d.jsonl", len(w.manifest.ObjectFiles)+1)
+		if err := w.store.put(ctx, name, bytes.NewReader(w.pendingObjects.Bytes())); err != nil {
+			return err
+		}
+		w.manifest.ObjectFiles = append(w.manifest.ObjectFiles, name)
+		w.pendingObjects.Reset()
+	}
+	return w.writeManifest(ctx)
+}
+
+func (w *Writer) writeManifest(ctx context.Context) error {
+	b, err := json.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return w.store.put(ctx, ManifestName, bytes.NewReader(b))
+}
+
+// Close flushes any buffered data and marks the archive manifest as closed.
+// Close completes even if the Writer's context is canceled, so that data which was archived
+// before an operation was interrupted remains restorable.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ctx := context.WithoutCancel(w.ctx)
+	if err := w.flush(ctx); err != nil {
+		return err
+	}
+	closedAt := time.Now().UTC()
+	w.manifest.ClosedAt = &closedAt
+	return w.writeManifest(ctx)
+}
+
+// Archive reads an archive.
+type Archive struct {
+	Manifest
+	store store
+}
+
+// Open returns the archive at location, which is either a local directory or an S3 prefix
+// in the form s3://bucket/prefix. The s3svc client is only required for S3 locations.
+func Open(ctx context.Context, location string, s3svc *s3.Client) (*Archive, error) {
+	st, err := newStore(location, s3svc)
+	if err != nil {
+		return nil, err
+	}
+	m, err := readManifest(ctx, st)
+	if err != nil {
+		return nil, err
+	}
+	a := &Archive{Manifest: m, store: st}
+	for _, name := range a.ObjectFiles {
+		if err := a.readObjectFile(ctx, name); err != nil {
+			return nil, fmt.Errorf("error reading archived object metadata from %s: %w", name, err)
+		}
+	}
+	return a, nil
+}
+
+func readManifest(ctx context.Context, st store) (Manifest, error) {
+	var m Manifest
+	r, err := st.get(ctx, ManifestName)
+	if err != nil {
+		return m, fmt.Errorf("error reading archive manifest: %w", err)
+	}
+	defer r.Close()
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return m, fmt.Errorf("error decoding archive manifest: %w", err)
+	}
+	if m.Version < 1 || m.Version > ManifestVersion {
+		return m, fmt.Errorf("unsupported archive manifest version %d", m.Version)
+	}
+	return m, nil
+}
+
+func (a *Archive) readObjectFile(ctx context.Context, name string) error {
+	r, err := a.store.get(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	decoder := json.NewDecoder(r)
+	for decoder.More() {
+		var obj Object
+		if err := decoder.Decode(&obj); err != nil {
+			return err
+		}
+		a.Objects = append(a.Objects, obj)
+	}
+	return nil
+}
+
+// Complete reports whether the archive was closed. An incomplete archive contains the data
+// that was flushed before the operation which wrote it was interrupted.
+func (a *Archive) Complete() bool {
+	return a.ClosedAt != nil
+}
+
+// Location returns the location of the archive.
+func (a *Archive) Location() string {
+	return a.store.String()
+}
+
+// Items calls fn with each DynamoDB item in the archive, stopping at the first error.
+func (a *Archive) Items(ctx context.Context, fn func(map[string]types.AttributeValue) error) error {
+	for _, name := range a.ItemFiles {
+		if err := a.readItemFile(ctx, name, fn); err != nil {
+			return fmt.Errorf("error reading archived items from %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (a *Archive) readItemFile(ctx context.Context, name string, fn func(map[string]types.AttributeValue) error) error {
+	r, err := a.store.get(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxItemSize)
+	for scanner.Scan() {
+		var decoded map[string]events.DynamoDBAttributeValue
+		if err := json.Unmarshal(scanner.Bytes(), &decoded); err != nil {
+			return err
+		}
+		item, err := itemMapper.ToAttributeValueMap(decoded)
+		if err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// OpenObject returns the archived body of an S3 object.
+func (a *Archive) OpenObject(ctx context.Context, obj Object) (io.ReadCloser, error) {
+	return a.store.get(ctx, obj.File)
+}
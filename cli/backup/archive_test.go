@@ -0,0 +1,132 @@
+package backup
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testItem(grantID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"grant_id":    &types.AttributeValueMemberS{Value: grantID},
+		"CFDANumbers": &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "10.001"}}},
+		"is_forecast": &types.AttributeValueMemberBOOL{Value: false},
+	}
+}
+
+// readArchive returns the grant IDs of the items and the contents of the objects in an archive,
+// keyed by object key.
+func readArchive(t *testing.T, a *Archive) ([]string, map[string]string) {
+	t.Helper()
+	grantIDs := []string{}
+	require.NoError(t, a.Items(context.Background(), func(item map[string]types.AttributeValue) error {
+		grantIDs = append(grantIDs, item["grant_id"].(*types.AttributeValueMemberS).Value)
+		return nil
+	}))
+	objects := map[string]string{}
+	for _, obj := range a.Objects {
+		r, err := a.OpenObject(context.Background(), obj)
+		require.NoError(t, err)
+		b, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+		objects[obj.Key] = string(b)
+	}
+	return grantIDs, objects
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	location := filepath.Join(t.TempDir(), "archive")
+	w, err := Create(context.Background(), location, nil, KindDynamoDBTable, "grants-table", "purge")
+	require.NoError(t, err)
+	assert.Equal(t, location, w.Location())
+
+	require.NoError(t, w.AddItem(testItem("1")))
+	require.NoError(t, w.AddItem(testItem("2")))
+	require.NoError(t, w.Flush())
+	require.NoError(t, w.AddItem(testItem("3")))
+	require.NoError(t, w.AddObject(Object{Key: "2023/1/grants.gov/v2.xml", Size: 5, Metadata: map[string]string{"k": "v"}},
+		strings.NewReader("hello")))
+	require.NoError(t, w.Close())
+
+	a, err := Open(context.Background(), location, nil)
+	require.NoError(t, err)
+	assert.True(t, a.Complete())
+	assert.Equal(t, KindDynamoDBTable, a.Kind)
+	assert.Equal(t, "grants-table", a.Source)
+	assert.Equal(t, "purge", a.Operation)
+	assert.EqualValues(t, 3, a.ItemCount)
+	assert.Equal(t, []string{"items/000001.jsonl", "items/000002.jsonl"}, a.ItemFiles)
+	require.Len(t, a.Objects, 1)
+	assert.Equal(t, map[string]string{"k": "v"}, a.Objects[0].Metadata)
+
+	grantIDs, objects := readArchive(t, a)
+	assert.Equal(t, []string{"1", "2", "3"}, grantIDs)
+	assert.Equal(t, map[string]string{"2023/1/grants.gov/v2.xml": "hello"}, objects)
+
+	var restored map[string]types.AttributeValue
+	require.NoError(t, a.Items(context.Background(), func(item map[string]types.AttributeValue) error {
+		restored = item
+		return nil
+	}))
+	assert.Equal(t, testItem("3"), restored)
+}
+
+func TestArchiveInterrupted(t *testing.T) {
+	location := filepath.Join(t.TempDir(), "archive")
+	w, err := Create(context.Background(), location, nil, KindS3Bucket, "grants-bucket", "purge")
+	require.NoError(t, err)
+
+	a, err := Open(context.Background(), location, nil)
+	require.NoError(t, err, "A new archive should be readable before anything is flushed")
+	assert.False(t, a.Complete())
+	assert.Empty(t, a.Objects)
+
+	require.NoError(t, w.AddObject(Object{Key: "a.json"}, strings.NewReader("a")))
+	require.NoError(t, w.AddItem(testItem("1")))
+	require.NoError(t, w.Flush())
+	// Added after the last flush, so not restorable when the writer is never closed
+	require.NoError(t, w.AddObject(Object{Key: "b.json"}, strings.NewReader("b")))
+	require.NoError(t, w.AddItem(testItem("2")))
+
+	a, err = Open(context.Background(), location, nil)
+	require.NoError(t, err)
+	assert.False(t, a.Complete())
+	grantIDs, objects := readArchive(t, a)
+	assert.Equal(t, []string{"1"}, grantIDs)
+	assert.Equal(t, map[string]string{"a.json": "a"}, objects)
+}
+
+func TestArchiveExists(t *testing.T) {
+	location := filepath.Join(t.TempDir(), "archive")
+	w, err := Create(context.Background(), location, nil, KindDynamoDBTable, "grants-table", "purge")
+	require.NoError(t, err)
+
+	_, err = Create(context.Background(), location, nil, KindDynamoDBTable, "grants-table", "purge")
+	assert.ErrorIs(t, err, ErrArchiveExists, "An interrupted archive should not be overwritten")
+
+	require.NoError(t, w.Close())
+	_, err = Create(context.Background(), location, nil, KindDynamoDBTable, "grants-table", "purge")
+	assert.ErrorIs(t, err, ErrArchiveExists)
+}
+
+func TestAddObjectInvalidKey(t *testing.T) {
+	w, err := Create(context.Background(), t.TempDir(), nil, KindS3Bucket, "grants-bucket", "purge")
+	require.NoError(t, err)
+	assert.Error(t, w.AddObject(Object{Key: "../escape"}, strings.NewReader("")))
+}
+
+func TestOpenUnsupportedVersion(t *testing.T) {
+	location := t.TempDir()
+	st, err := newStore(location, nil)
+	require.NoError(t, err)
+	require.NoError(t, st.put(context.Background(), ManifestName, strings.NewReader(`{"version": 99}`)))
+	_, err = Open(context.Background(), location, nil)
+	assert.ErrorContains(t, err, "unsupported archive manifest version 99")
+}
@@ -0,0 +1,72 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Flags configures the archiving of data before it is deleted or modified by a CLI command.
+// It is intended to be embedded in a command's flags.
+type Flags struct {
+	BackupTo string `name:"backup-to" placeholder:"DIR|s3://bucket/prefix" help:"Local directory or S3 prefix to which data is archived before it is purged (default: ./grants-ingest-backup-<source>-<timestamp>). A resumed purge adds to the archive of the interrupted run."`
+	NoBackup bool   `name:"no-backup" help:"Do not archive data before it is purged. Not recommended."`
+}
+
+// Create returns a Writer for a new archive of data from source, or nil if backups are disabled.
+// The archive is created at the configured location, or at DefaultLocation(source) if no
+// location is configured. Local locations are made absolute, so that the Writer's Location
+// may be used to reopen the archive from another working directory.
+func (f Flags) Create(ctx context.Context, s3svc *s3.Client, kind Kind, source string) (*Writer, error) {
+	if f.NoBackup {
+		return nil, nil
+	}
+	location := f.BackupTo
+	if location == "" {
+		location = DefaultLocation(source)
+	}
+	if !strings.HasPrefix(location, "s3://") {
+		var err error
+		if location, err = filepath.Abs(location); err != nil {
+			return nil, err
+		}
+	}
+	return Create(ctx, location, s3svc, kind, source, strings.Join(os.Args, " "))
+}
+
+// Resume returns a Writer for the archive of an interrupted operation on data from source,
+// which is at location, so that a resumed operation adds to the same archive. When location is
+// empty because the interrupted operation was not backed up, Resume behaves like Create.
+// It is an error if backups are disabled or configured with a different location, since the
+// archived data would otherwise be split across archives.
+func (f Flags) Resume(ctx context.Context, s3svc *s3.Client, kind Kind, source, location string) (*Writer, error) {
+	if location == "" {
+		return f.Create(ctx, s3svc, kind, source)
+	}
+	if f.NoBackup {
+		return nil, fmt.Errorf("%w: the interrupted run was backed up to %s", ErrLocationMismatch, location)
+	}
+	if f.BackupTo != "" {
+		configured := f.BackupTo
+		if !strings.HasPrefix(configured, "s3://") {
+			var err error
+			if configured, err = filepath.Abs(configured); err != nil {
+				return nil, err
+			}
+		}
+		if strings.TrimSuffix(configured, "/") != strings.TrimSuffix(location, "/") {
+			return nil, fmt.Errorf("%w: the interrupted run was backed up to %s", ErrLocationMismatch, location)
+		}
+	}
+	return Reopen(ctx, location, s3svc, kind, source)
+}
+
+// DefaultLocation returns a local directory name for a new archive of data from source.
+func DefaultLocation(source string) string {
+	return fmt.Sprintf("grants-ingest-backup-%s-%s", source, time.Now().UTC().Format("20060102T150405Z"))
+}
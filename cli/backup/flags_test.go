@@ -0,0 +1,99 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/usdigitalresponse/grants-ingest/cli/checkpoint"
+)
+
+// chdir changes the working directory for the duration of the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestFlagsCreate(t *testing.T) {
+	chdir(t, t.TempDir())
+
+	w, err := Flags{NoBackup: true}.Create(context.Background(), nil, KindDynamoDBTable, "table")
+	require.NoError(t, err)
+	assert.Nil(t, w)
+
+	w, err = Flags{BackupTo: "relative"}.Create(context.Background(), nil, KindDynamoDBTable, "table")
+	require.NoError(t, err)
+	assert.True(t, filepath.IsAbs(w.Location()), "Local locations should be made absolute")
+}
+
+func TestFlagsResume(t *testing.T) {
+	dir := t.TempDir()
+	location := filepath.Join(dir, "archive")
+	checkpointFlags := checkpoint.Flags{CheckpointFile: filepath.Join(dir, "checkpoint.json")}
+	type state struct {
+		BackupLocation string `json:"backup_location"`
+	}
+
+	// An interrupted run flushes some items and saves its checkpoint, but is never closed
+	f, err := checkpointFlags.Open("table", nil, &state{})
+	require.NoError(t, err)
+	w, err := Flags{BackupTo: location}.Create(context.Background(), nil, KindDynamoDBTable, "table")
+	require.NoError(t, err)
+	require.NoError(t, f.Save(state{BackupLocation: w.Location()}))
+	require.NoError(t, w.AddItem(testItem("1")))
+	require.NoError(t, w.Flush())
+
+	// Resuming uses the archive location that was saved with the checkpoint
+	checkpointFlags.Resume = true
+	var resumed state
+	_, err = checkpointFlags.Open("table", nil, &resumed)
+	require.NoError(t, err)
+	assert.Equal(t, location, resumed.BackupLocation)
+
+	t.Run("backup disabled", func(t *testing.T) {
+		_, err := Flags{NoBackup: true}.Resume(context.Background(), nil, KindDynamoDBTable, "table", resumed.BackupLocation)
+		assert.ErrorIs(t, err, ErrLocationMismatch)
+	})
+	t.Run("different location", func(t *testing.T) {
+		_, err := Flags{BackupTo: filepath.Join(dir, "other")}.Resume(context.Background(), nil,
+			KindDynamoDBTable, "table", resumed.BackupLocation)
+		assert.ErrorIs(t, err, ErrLocationMismatch)
+	})
+	t.Run("different source", func(t *testing.T) {
+		_, err := Flags{}.Resume(context.Background(), nil, KindDynamoDBTable, "other-table", resumed.BackupLocation)
+		assert.ErrorIs(t, err, ErrArchiveMismatch)
+	})
+	t.Run("interrupted run was not backed up", func(t *testing.T) {
+		chdir(t, t.TempDir())
+		w, err := Flags{}.Resume(context.Background(), nil, KindDynamoDBTable, "table", "")
+		require.NoError(t, err)
+		assert.NotEqual(t, location, w.Location(), "A new archive should be created")
+	})
+
+	for _, flags := range []Flags{{}, {BackupTo: location}} {
+		_, err := Create(context.Background(), location, nil, KindDynamoDBTable, "table", "purge")
+		require.ErrorIs(t, err, ErrArchiveExists)
+
+		w, err := flags.Resume(context.Background(), nil, KindDynamoDBTable, "table", resumed.BackupLocation)
+		require.NoError(t, err)
+		assert.Equal(t, location, w.Location())
+		a, err := Open(context.Background(), location, nil)
+		require.NoError(t, err)
+		assert.False(t, a.Complete(), "A reopened archive is incomplete until it is closed")
+		require.NoError(t, w.AddItem(testItem("2")))
+		require.NoError(t, w.Close())
+	}
+
+	a, err := Open(context.Background(), location, nil)
+	require.NoError(t, err)
+	assert.True(t, a.Complete())
+	grantIDs, _ := readArchive(t, a)
+	assert.Equal(t, []string{"1", "2", "2"}, grantIDs, "Items from each run should be in one archive")
+	assert.Equal(t, []string{"items/000001.jsonl", "items/000002.jsonl", "items/000003.jsonl"}, a.ItemFiles)
+}
@@ -0,0 +1,132 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsTransport "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// ErrNotExist is returned when a file does not exist in an archive location.
+var ErrNotExist = errors.New("file does not exist in archive")
+
+// store reads and writes named files in an archive location.
+type store interface {
+	put(ctx context.Context, name string, body io.Reader) error
+	get(ctx context.Context, name string) (io.ReadCloser, error)
+	String() string
+}
+
+// newStore returns the store for an archive location, which is either a local directory
+// or an S3 prefix in the form s3://bucket/prefix.
+func newStore(location string, s3svc *s3.Client) (store, error) {
+	if !strings.HasPrefix(location, "s3://") {
+		if location == "" {
+			return nil, errors.New("archive location cannot be empty")
+		}
+		return &localStore{location}, nil
+	}
+	bucket, prefix, _ := strings.Cut(strings.TrimPrefix(location, "s3://"), "/")
+	if bucket == "" {
+		return nil, fmt.Errorf("invalid S3 archive location %q: must be of the form s3://bucket/prefix", location)
+	}
+	if s3svc == nil {
+		return nil, fmt.Errorf("an S3 client is required for archive location %q", location)
+	}
+	return &s3Store{s3svc, bucket, strings.TrimSuffix(prefix, "/")}, nil
+}
+
+type localStore struct {
+	dir string
+}
+
+// put writes the named file atomically, so that an interruption while rewriting a file
+// (such as the manifest) does not corrupt its previous content.
+func (s *localStore) put(ctx context.Context, name string, body io.Reader) error {
+	p := filepath.Join(s.dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(p), filepath.Base(p)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, body); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), p)
+}
+
+func (s *localStore) get(ctx context.Context, name string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(s.dir, filepath.FromSlash(name)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotExist
+	}
+	return f, err
+}
+
+func (s *localStore) String() string {
+	return s.dir
+}
+
+type s3Store struct {
+	s3svc  *s3.Client
+	bucket string
+	prefix string
+}
+
+func (s *s3Store) key(name string) string {
+	if s.prefix == "" {
+		return name
+	}
+	return path.Join(s.prefix, name)
+}
+
+func (s *s3Store) put(ctx context.Context, name string, body io.Reader) error {
+	_, err := manager.NewUploader(s.s3svc).Upload(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(name)),
+		Body:   body,
+	})
+	return err
+}
+
+func (s *s3Store) get(ctx context.Context, name string) (io.ReadCloser, error) {
+	resp, err := s.s3svc.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(name)),
+	})
+	if err != nil {
+		var respError *awsTransport.ResponseError
+		if errors.As(err, &respError) && respError.ResponseError.HTTPStatusCode() == 404 {
+			return nil, ErrNotExist
+		}
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *s3Store) String() string {
+	return fmt.Sprintf("s3://%s/%s", s.bucket, s.prefix)
+}
@@ -0,0 +1,154 @@
+// Package checkpoint saves the progress of long-running CLI commands so that an interrupted run
+// can be resumed.
+package checkpoint
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SaveInterval is how often commands should save their progress while running.
+const SaveInterval = 10 * time.Second
+
+var (
+	ErrExists          = errors.New("a checkpoint file from an interrupted run already exists")
+	ErrNotFound        = errors.New("no checkpoint file exists to resume from")
+	ErrOptionsMismatch = errors.New("options differ from those of the run that saved the checkpoint")
+)
+
+// Flags configures the saving of progress by a CLI command. It is intended to be embedded in a
+// command's flags.
+type Flags struct {
+	CheckpointFile string `name:"checkpoint-file" type:"path" placeholder:"PATH" help:"File to which progress is saved so that an interrupted run can be resumed (default: ./grants-ingest-checkpoint-<source>.json)."`
+	Resume         bool   `name:"resume" help:"Resume an interrupted run from its checkpoint file."`
+}
+
+// DefaultPath returns a local file name for the checkpoint file of a run against source.
+func DefaultPath(source string) string {
+	return fmt.Sprintf("grants-ingest-checkpoint-%s.json", source)
+}
+
+// Path returns the configured checkpoint file path, or DefaultPath(source) if no path is configured.
+func (f Flags) Path(source string) string {
+	if f.CheckpointFile != "" {
+		return f.CheckpointFile
+	}
+	return DefaultPath(source)
+}
+
+// document is the JSON-encoded content of a checkpoint file.
+type document struct {
+	Source  string          `json:"source"`
+	Command string          `json:"command"`
+	Options json.RawMessage `json:"options"`
+	SavedAt time.Time       `json:"saved_at"`
+	State   json.RawMessage `json:"state"`
+}
+
+// File is the checkpoint file of a run.
+type File struct {
+	path    string
+	source  string
+	options json.RawMessage
+}
+
+// Open returns the checkpoint file for a run against source with the given options, which must
+// be JSON-serializable. When resuming, the state saved by the interrupted run is unmarshaled into
+// state, and it is an error if the saved options differ from the given options. Otherwise, it is
+// an error if a checkpoint file already exists, so that progress from an interrupted run is never
+// overwritten by accident.
+func (f Flags) Open(source string, options, state any) (*File, error) {
+	opts, err := json.Marshal(options)
+	if err != nil {
+		return nil, err
+	}
+	c := &File{path: f.Path(source), source: source, options: opts}
+
+	b, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		if f.Resume {
+			return nil, fmt.Errorf("%w at %s", ErrNotFound, c.path)
+		}
+		return c, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if !f.Resume {
+		return nil, fmt.Errorf("%w at %s: use --resume to continue it or remove the file to start over",
+			ErrExists, c.path)
+	}
+
+	var doc document
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("error reading checkpoint file %s: %w", c.path, err)
+	}
+	if doc.Source != source {
+		return nil, fmt.Errorf("checkpoint file %s was saved by a run against %q", c.path, doc.Source)
+	}
+	var saved, given bytes.Buffer
+	if err := errors.Join(json.Compact(&saved, doc.Options), json.Compact(&given, opts)); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(saved.Bytes(), given.Bytes()) {
+		return nil, fmt.Errorf("%w (saved: %s)", ErrOptionsMismatch, saved.String())
+	}
+	if err := json.Unmarshal(doc.State, state); err != nil {
+		return nil, fmt.Errorf("error reading checkpoint file %s: %w", c.path, err)
+	}
+	return c, nil
+}
+
+// Path returns the path of the checkpoint file.
+func (c *File) Path() string {
+	return c.path
+}
+
+// Save replaces the content of the checkpoint file with the given state, which must be
+// JSON-serializable. The file is replaced atomically, so that an interruption while saving
+// does not corrupt previously-saved progress.
+func (c *File) Save(state any) error {
+	s, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(document{
+		Source:  c.source,
+		Command: strings.Join(os.Args, " "),
+		Options: c.options,
+		SavedAt: time.Now().UTC(),
+		State:   s,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
+
+// Remove deletes the checkpoint file, if it exists. It should be called once a run completes
+// successfully.
+func (c *File) Remove() error {
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
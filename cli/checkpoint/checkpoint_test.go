@@ -0,0 +1,75 @@
+package checkpoint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testOptions struct {
+	Prefix string `json:"prefix"`
+}
+
+type testState struct {
+	StartAfter string `json:"start_after"`
+	Deleted    int64  `json:"deleted"`
+}
+
+func TestPath(t *testing.T) {
+	assert.Equal(t, "grants-ingest-checkpoint-my-bucket.json", Flags{}.Path("my-bucket"))
+	assert.Equal(t, "custom.json", Flags{CheckpointFile: "custom.json"}.Path("my-bucket"))
+}
+
+func TestCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	opts := testOptions{Prefix: "sources/"}
+
+	t.Run("resume without checkpoint", func(t *testing.T) {
+		_, err := Flags{CheckpointFile: path, Resume: true}.Open("bucket", opts, &testState{})
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	var state testState
+	f, err := Flags{CheckpointFile: path}.Open("bucket", opts, &state)
+	require.NoError(t, err)
+	assert.Equal(t, path, f.Path())
+	assert.Zero(t, state)
+	_, err = os.Stat(path)
+	assert.ErrorIs(t, err, os.ErrNotExist, "Opening should not create the file")
+
+	saved := testState{StartAfter: "sources/2023/05/01", Deleted: 42}
+	require.NoError(t, f.Save(saved))
+	tmpFiles, err := filepath.Glob(path + ".*.tmp")
+	require.NoError(t, err)
+	assert.Empty(t, tmpFiles, "Temporary files should be renamed")
+
+	t.Run("existing checkpoint without resume", func(t *testing.T) {
+		_, err := Flags{CheckpointFile: path}.Open("bucket", opts, &testState{})
+		assert.ErrorIs(t, err, ErrExists)
+	})
+
+	t.Run("resume with different options", func(t *testing.T) {
+		_, err := Flags{CheckpointFile: path, Resume: true}.Open("bucket", testOptions{Prefix: "other/"}, &testState{})
+		assert.ErrorIs(t, err, ErrOptionsMismatch)
+	})
+
+	t.Run("resume against different source", func(t *testing.T) {
+		_, err := Flags{CheckpointFile: path, Resume: true}.Open("other-bucket", opts, &testState{})
+		assert.ErrorContains(t, err, `saved by a run against "bucket"`)
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		var resumed testState
+		f, err := Flags{CheckpointFile: path, Resume: true}.Open("bucket", opts, &resumed)
+		require.NoError(t, err)
+		assert.Equal(t, saved, resumed)
+
+		require.NoError(t, f.Remove())
+		_, err = os.Stat(path)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		assert.NoError(t, f.Remove(), "Removing a missing file should not fail")
+	})
+}
@@ -0,0 +1,140 @@
+package agencies
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/alecthomas/kong"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/usdr"
+)
+
+// DefaultRegistryFile is the path, relative to the repository root, of the registry that is
+// embedded in the usdr package.
+const DefaultRegistryFile = "pkg/grantsSchemas/usdr/agencies.json"
+
+var ErrNotFound = errors.New("agency not found in registry")
+
+type Cmd struct {
+	// Sub-commands
+	List     ListCmd     `cmd:"list" help:"List the agencies in the registry."`
+	Lookup   LookupCmd   `cmd:"lookup" help:"Look up agencies by code or name."`
+	Set      SetCmd      `cmd:"set" help:"Add or update an agency in a registry file."`
+	Remove   RemoveCmd   `cmd:"remove" help:"Remove an agency from a registry file."`
+	Discover DiscoverCmd `cmd:"discover" help:"Add agencies found in the prepared-data table to a registry file."`
+}
+
+func (cmd *Cmd) Help() string {
+	return `
+This command serves as the entrypoint for subcommands that read or update the agency registry.
+The registry lists known departments and sub-agencies by Grants.gov agency code (e.g. "HHS" and
+"HHS-NIH11"), along with their canonical names and aliases. It is used to set the department
+and sub-agency of published grant data, to normalize agency names, and to reconcile the
+free-text agency names of FFIS spreadsheets with agency codes.
+
+The registry is embedded from ` + DefaultRegistryFile + ` when the Lambda functions and this
+CLI are compiled. Subcommands that update the registry modify that file by default (relative to
+the current directory), so changes take effect once they are committed and deployed.`
+}
+
+// readRegistry reads the registry file at path, or returns the embedded registry if path is empty.
+func readRegistry(path string) (*usdr.AgencyRegistry, error) {
+	if path == "" {
+		return usdr.DefaultAgencyRegistry(), nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return usdr.ReadAgencyRegistry(f)
+}
+
+// writeRegistry replaces the registry file at path, so that it is never partially written.
+func writeRegistry(path string, r *usdr.AgencyRegistry) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if err := r.WriteJSON(f); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+type ListCmd struct {
+	Registry string `type:"existingfile" placeholder:"PATH" help:"Registry file to read instead of the embedded registry."`
+	JSON     bool   `name:"json" help:"Print the registry as JSON instead of a table."`
+}
+
+func (cmd *ListCmd) Run(app *kong.Kong, logger *log.Logger) error {
+	r, err := readRegistry(cmd.Registry)
+	if err != nil {
+		return log.Errorf(*logger, "Error reading agency registry", err)
+	}
+	if cmd.JSON {
+		return r.WriteJSON(app.Stdout)
+	}
+	w := tabwriter.NewWriter(app.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CODE\tDEPARTMENT\tNAME\tALIASES")
+	for _, e := range r.Entries() {
+		department, _, _ := usdr.ParseAgencyCode(e.Code)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Code, department, e.Name, strings.Join(e.Aliases, "; "))
+	}
+	return w.Flush()
+}
+
+type LookupCmd struct {
+	// Positional arguments
+	Queries []string `arg:"" name:"code-or-name" help:"Agency codes (e.g. HHS-NIH11) or names (e.g. \"Forest Service\") to look up."`
+
+	// Flags
+	Registry string `type:"existingfile" placeholder:"PATH" help:"Registry file to read instead of the embedded registry."`
+}
+
+func (cmd *LookupCmd) Help() string {
+	return `
+Prints the normalized agency data (as JSON lines) that would be published for each agency code,
+including codes of sub-agencies that are not in the registry. Queries that are not valid
+agency codes, or are not in the registry, are reconciled by name and alias in the same manner
+as FFIS agency names.`
+}
+
+func (cmd *LookupCmd) Run(app *kong.Kong, logger *log.Logger) error {
+	r, err := readRegistry(cmd.Registry)
+	if err != nil {
+		return log.Errorf(*logger, "Error reading agency registry", err)
+	}
+	enc := json.NewEncoder(app.Stdout)
+	var notFound []string
+	for _, q := range cmd.Queries {
+		agency := usdr.Agency{Code: q}
+		if _, ok := r.Lookup(q); !ok {
+			if e, ok := r.Reconcile(q); ok {
+				agency = usdr.Agency{Code: e.Code}
+			} else if _, _, err := usdr.ParseAgencyCode(q); err != nil {
+				notFound = append(notFound, q)
+				continue
+			}
+		}
+		if err := enc.Encode(r.Normalize(agency)); err != nil {
+			return err
+		}
+	}
+	if len(notFound) > 0 {
+		return log.Errorf(*logger, "Error looking up agencies",
+			fmt.Errorf("%w: %s", ErrNotFound, strings.Join(notFound, ", ")))
+	}
+	return nil
+}
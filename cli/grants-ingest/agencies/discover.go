@@ -0,0 +1,181 @@
+package agencies
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+
+	"github.com/alecthomas/kong"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/usdigitalresponse/grants-ingest/cli/tableScan"
+	ct "github.com/usdigitalresponse/grants-ingest/cli/types"
+	"github.com/usdigitalresponse/grants-ingest/internal/awsHelpers"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+)
+
+type DiscoverCmd struct {
+	// Flags
+	PreparedDataTable string              `required:"" env:"GRANTS_PREPARED_DYNAMODB_NAME" help:"Name of the DynamoDB table containing grants prepared data."`
+	Registry          string              `type:"existingfile" default:"pkg/grantsSchemas/usdr/agencies.json" placeholder:"PATH" help:"Registry file to update."`
+	DryRun            bool                `help:"Log the agencies that would be added without updating the registry file."`
+	ReadConcurrency   ct.ConcurrencyLimit `default:"1" help:"Max DynamoDB parallel scan workers."`
+
+	// Internal
+	ctx  context.Context
+	stop context.CancelFunc
+	ddb  *dynamodb.Client
+}
+
+func (cmd *DiscoverCmd) Help() string {
+	return `
+Scans the AgencyCode and AgencyName attributes of every item in the prepared-data table, and adds
+an entry to the registry for each agency code that is not already in the registry, named with
+the most common Grants.gov agency name for the code. Existing entries are never modified, and
+codes whose name is already used by another entry are skipped (and logged).
+
+The table name may be provided with the GRANTS_PREPARED_DYNAMODB_NAME environment variable.`
+}
+
+func (cmd *DiscoverCmd) BeforeApply() error {
+	cmd.ctx, cmd.stop = signal.NotifyContext(context.Background(),
+		syscall.SIGHUP, syscall.SIGINT, os.Interrupt)
+	return nil
+}
+
+func (cmd *DiscoverCmd) AfterApply() error {
+	cfg, err := awsHelpers.GetConfig(cmd.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to configure AWS SDK: %w", err)
+	}
+	cmd.ddb = dynamodb.NewFromConfig(cfg)
+	return nil
+}
+
+func (cmd *DiscoverCmd) Run(app *kong.Kong, baseLogger *log.Logger) error {
+	defer cmd.stop()
+	logger := log.With(*baseLogger, "table", cmd.PreparedDataTable, "registry", cmd.Registry,
+		"dry_run", cmd.DryRun)
+	r, err := readRegistry(cmd.Registry)
+	if err != nil {
+		return log.Errorf(logger, "Error reading agency registry", err)
+	}
+
+	names, err := cmd.scanAgencyNames(logger)
+	if err != nil {
+		return err
+	}
+	codes := make([]string, 0, len(names))
+	for code := range names {
+		if _, ok := r.Lookup(code); !ok {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+
+	added := 0
+	for _, code := range codes {
+		name := mostCommon(names[code])
+		logger := log.With(logger, "code", code, "name", name)
+		entry, ok := newEntryFor(code, name)
+		if !ok {
+			log.Warn(logger, "Skipped agency with an invalid code or empty name")
+			continue
+		}
+		if err := r.Set(entry); err != nil {
+			log.Warn(logger, "Skipped agency that conflicts with the registry", "error", err)
+			continue
+		}
+		log.Info(logger, "Discovered agency")
+		added++
+	}
+
+	log.Info(logger, "Discovered agencies", "added", added)
+	if cmd.DryRun || added == 0 {
+		return nil
+	}
+	if err := writeRegistry(cmd.Registry, r); err != nil {
+		return log.Errorf(logger, "Error writing agency registry", err)
+	}
+	return nil
+}
+
+// scanAgencyNames returns the number of items with each agency name, by agency code.
+func (cmd *DiscoverCmd) scanAgencyNames(logger log.Logger) (map[string]map[string]int, error) {
+	expr, err := expression.NewBuilder().WithProjection(
+		expression.NamesList(expression.Name("AgencyCode"), expression.Name("AgencyName")),
+	).Build()
+	if err != nil {
+		return nil, err
+	}
+	input := dynamodb.ScanInput{
+		TableName:                aws.String(cmd.PreparedDataTable),
+		ProjectionExpression:     expr.Projection(),
+		ExpressionAttributeNames: expr.Names(),
+	}
+
+	scannedItems := make(chan map[string]types.AttributeValue)
+	var scanTableErr error
+	scanWg := sync.WaitGroup{}
+	for i := 0; i < int(cmd.ReadConcurrency); i++ {
+		scanWg.Add(1)
+		segmentId := i
+		go func() {
+			defer scanWg.Done()
+			err := tableScan.Segment(cmd.ctx, cmd.ddb, logger, input,
+				segmentId, int(cmd.ReadConcurrency), scannedItems)
+			if err != nil && err != context.Canceled {
+				log.Error(logger,
+					"Stopping application due to fatal error encountered while scanning DynamoDB items",
+					err)
+				scanTableErr = err
+				cmd.stop()
+			}
+		}()
+	}
+	go func() {
+		scanWg.Wait()
+		close(scannedItems)
+	}()
+
+	names := make(map[string]map[string]int)
+	for item := range scannedItems {
+		code := stringAttr(item, "AgencyCode")
+		if code == "" {
+			continue
+		}
+		if names[code] == nil {
+			names[code] = make(map[string]int)
+		}
+		names[code][stringAttr(item, "AgencyName")]++
+	}
+	if scanTableErr != nil || cmd.ctx.Err() != nil {
+		// Don't update the registry from a partial scan
+		return nil, fmt.Errorf("the operation completed with errors")
+	}
+	return names, nil
+}
+
+func stringAttr(item map[string]types.AttributeValue, name string) string {
+	if v, ok := item[name].(*types.AttributeValueMemberS); ok {
+		return v.Value
+	}
+	return ""
+}
+
+// mostCommon returns the name with the highest count, preferring the first in sorted order.
+func mostCommon(counts map[string]int) string {
+	best, bestCount := "", 0
+	for name, count := range counts {
+		if count > bestCount || count == bestCount && name < best {
+			best, bestCount = name, count
+		}
+	}
+	return best
+}
@@ -0,0 +1,75 @@
+package agencies
+
+import (
+	"github.com/alecthomas/kong"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/usdr"
+)
+
+type SetCmd struct {
+	// Positional arguments
+	Code string `arg:"" name:"code" help:"Agency code (e.g. HHS-NIH11)."`
+
+	// Flags
+	Name     string   `help:"Canonical name of the agency (required for new agencies)."`
+	Alias    []string `name:"alias" help:"Alias of the agency to add, such as an acronym or FFIS agency name (repeatable)."`
+	Registry string   `type:"existingfile" default:"pkg/grantsSchemas/usdr/agencies.json" placeholder:"PATH" help:"Registry file to update."`
+}
+
+func (cmd *SetCmd) Run(app *kong.Kong, baseLogger *log.Logger) error {
+	logger := log.With(*baseLogger, "registry", cmd.Registry, "code", cmd.Code)
+	r, err := readRegistry(cmd.Registry)
+	if err != nil {
+		return log.Errorf(logger, "Error reading agency registry", err)
+	}
+	entry, exists := r.Lookup(cmd.Code)
+	if !exists {
+		entry.Code = cmd.Code
+	}
+	if cmd.Name != "" {
+		entry.Name = cmd.Name
+	}
+	entry.Aliases = append(entry.Aliases, cmd.Alias...)
+	if err := r.Set(entry); err != nil {
+		return log.Errorf(logger, "Error setting agency", err)
+	}
+	if err := writeRegistry(cmd.Registry, r); err != nil {
+		return log.Errorf(logger, "Error writing agency registry", err)
+	}
+	entry, _ = r.Lookup(cmd.Code)
+	log.Info(logger, "Saved agency to registry", "name", entry.Name, "created", !exists)
+	return nil
+}
+
+type RemoveCmd struct {
+	// Positional arguments
+	Code string `arg:"" name:"code" help:"Agency code (e.g. HHS-NIH11)."`
+
+	// Flags
+	Registry string `type:"existingfile" default:"pkg/grantsSchemas/usdr/agencies.json" placeholder:"PATH" help:"Registry file to update."`
+}
+
+func (cmd *RemoveCmd) Run(app *kong.Kong, baseLogger *log.Logger) error {
+	logger := log.With(*baseLogger, "registry", cmd.Registry, "code", cmd.Code)
+	r, err := readRegistry(cmd.Registry)
+	if err != nil {
+		return log.Errorf(logger, "Error reading agency registry", err)
+	}
+	if !r.Remove(cmd.Code) {
+		return log.Errorf(logger, "Error removing agency", ErrNotFound)
+	}
+	if err := writeRegistry(cmd.Registry, r); err != nil {
+		return log.Errorf(logger, "Error writing agency registry", err)
+	}
+	log.Info(logger, "Removed agency from registry")
+	return nil
+}
+
+// newEntryFor returns a registry entry for an agency code and name that were found in source
+// data, or false if the code is invalid or the name is empty.
+func newEntryFor(code, name string) (usdr.AgencyRegistryEntry, bool) {
+	if _, _, err := usdr.ParseAgencyCode(code); err != nil || usdr.NormalizeAgencyName(name) == "" {
+		return usdr.AgencyRegistryEntry{}, false
+	}
+	return usdr.AgencyRegistryEntry{Code: code, Name: name}, true
+}
@@ -0,0 +1,110 @@
+package assistanceListingsImport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/usdigitalresponse/grants-ingest/internal/assistanceListings"
+	"github.com/usdigitalresponse/grants-ingest/internal/awsHelpers"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+	"github.com/usdigitalresponse/grants-ingest/internal/preparedData"
+)
+
+type Cmd struct {
+	// Positional arguments
+	Extract string `arg:"" name:"extract" type:"existingfile" predictor:"file" help:"Path to a SAM.gov Assistance Listings extract (CSV or JSON)."`
+
+	// Flags
+	PreparedDataBucket string `required:"" env:"GRANTS_PREPARED_DATA_BUCKET_NAME" help:"Name of the S3 bucket containing grants prepared data."`
+	Format             string `enum:"auto,csv,json" default:"auto" help:"Format of the extract (auto|csv|json). By default, determined by the file extension."`
+	S3Key              string `name:"s3-key" help:"S3 key of the imported catalog (default: the key read by PublishGrantEvents)."`
+	S3UsePathStyle     bool   `name:"s3-use-path-style" help:"Use path-style addressing for S3 bucket"`
+	DryRun             bool   `help:"Dry run only - the catalog is not uploaded to S3"`
+}
+
+func (cmd *Cmd) Help() string {
+	return `
+Imports an Assistance Listings extract, which may be downloaded from SAM.gov
+(e.g. "AssistanceListings_DataGov_PUBLIC_CURRENT.csv"), as the catalog that PublishGrantEvents
+uses to enrich the CFDA numbers of published grants with their program title, objectives, and
+administering agency. CFDA numbers that are not in the catalog are flagged in published events.
+
+The extract's "Program Number", "Program Title", "Objectives", and "Federal Agency" columns are
+imported, and any other columns are ignored. JSON extracts must be an array of objects with
+the same keys as the CSV columns. The import fails without uploading anything if any program
+number is invalid or duplicated.
+
+The catalog replaces any catalog previously imported to the prepared data bucket, and is used
+by PublishGrantEvents within the refresh interval of its ASSISTANCE_LISTINGS_REFRESH_INTERVAL
+environment variable (one hour by default).`
+}
+
+func (cmd *Cmd) AfterApply() error {
+	if cmd.S3Key == "" {
+		cmd.S3Key = preparedData.AssistanceListingsKey
+	}
+	return nil
+}
+
+// Destructive reports whether the command uploads the catalog, which replaces any existing catalog.
+func (cmd *Cmd) Destructive() bool {
+	return !cmd.DryRun
+}
+
+func (cmd *Cmd) Run(app *kong.Kong, baseLogger *log.Logger) error {
+	ctx := context.Background()
+	logger := log.With(*baseLogger, "extract", cmd.Extract)
+
+	format := cmd.Format
+	if format == "auto" {
+		var err error
+		if format, err = assistanceListings.FormatOf(cmd.Extract); err != nil {
+			return log.Errorf(logger, "Error determining extract format", err)
+		}
+	}
+	f, err := os.Open(cmd.Extract)
+	if err != nil {
+		return log.Errorf(logger, "Error opening extract", err)
+	}
+	defer f.Close()
+	catalog, err := assistanceListings.FromExtract(f, format, filepath.Base(cmd.Extract), time.Now().UTC())
+	if err != nil {
+		return log.Errorf(logger, "Error reading extract", err)
+	}
+	log.Info(logger, "Read Assistance Listings extract", "format", format, "listings", catalog.Len())
+
+	var b bytes.Buffer
+	if err := catalog.WriteJSON(&b); err != nil {
+		return log.Errorf(logger, "Error encoding catalog", err)
+	}
+	logger = log.With(logger, "destination", fmt.Sprintf("s3://%s/%s", cmd.PreparedDataBucket, cmd.S3Key))
+	if cmd.DryRun {
+		log.Info(logger, "Dry run - catalog not uploaded to S3", "size_bytes", b.Len())
+		return nil
+	}
+
+	cfg, err := awsHelpers.GetConfig(ctx)
+	if err != nil {
+		return log.Errorf(logger, "Failed to configure AWS SDK", err)
+	}
+	s3svc := s3.NewFromConfig(cfg, func(o *s3.Options) { o.UsePathStyle = cmd.S3UsePathStyle })
+	if _, err := s3svc.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:               aws.String(cmd.PreparedDataBucket),
+		Key:                  aws.String(cmd.S3Key),
+		Body:                 bytes.NewReader(b.Bytes()),
+		ContentType:          aws.String("application/json"),
+		ServerSideEncryption: types.ServerSideEncryptionAes256,
+	}); err != nil {
+		return log.Errorf(logger, "Error uploading catalog to S3", err)
+	}
+	log.Info(logger, "Uploaded Assistance Listings catalog to S3", "size_bytes", b.Len())
+	return nil
+}
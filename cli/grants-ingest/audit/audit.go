@@ -0,0 +1,215 @@
+package audit
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/usdigitalresponse/grants-ingest/internal/preparedData"
+	grantsgov "github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/grants.gov"
+)
+
+// Category identifies a kind of discrepancy between the data stores.
+type Category string
+
+const (
+	// CategoryMissingGrant is a grant in the extract for which there is neither a
+	// prepared-data S3 object nor a DynamoDB item.
+	CategoryMissingGrant Category = "missing-grant"
+	// CategoryStale is a grant whose newest prepared data is older than the extract.
+	CategoryStale Category = "stale"
+	// CategoryMissingItem is a prepared-data S3 object without a DynamoDB item.
+	CategoryMissingItem Category = "missing-item"
+	// CategoryMissingObject is a DynamoDB item without a prepared-data S3 object.
+	CategoryMissingObject Category = "missing-object"
+	// CategoryLastUpdatedMismatch is a DynamoDB item whose LastUpdatedDate differs from
+	// that of its prepared-data S3 object.
+	CategoryLastUpdatedMismatch Category = "last-updated-mismatch"
+)
+
+// Categories lists all discrepancy categories in the order in which they are reported.
+var Categories = []Category{
+	CategoryMissingGrant,
+	CategoryStale,
+	CategoryMissingItem,
+	CategoryMissingObject,
+	CategoryLastUpdatedMismatch,
+}
+
+const (
+	// RepairRetouchObject copies a prepared-data S3 object onto itself, which re-triggers
+	// PersistGrantsGovXMLDB for the object.
+	RepairRetouchObject = "retouch-object"
+	// RepairWriteObject writes the extract record for a grant to its prepared-data S3 object,
+	// which triggers PersistGrantsGovXMLDB for the object.
+	RepairWriteObject = "write-object"
+)
+
+// Repair is an action that resolves a discrepancy.
+type Repair struct {
+	Action string `json:"action"`
+	Key    string `json:"key"`
+}
+
+// Discrepancy is an inconsistency found for a single grant.
+type Discrepancy struct {
+	GrantID  string   `json:"grant_id"`
+	Category Category `json:"category"`
+	Detail   string   `json:"detail"`
+	Repair   *Repair  `json:"repair,omitempty"`
+}
+
+// Report is the result of an audit.
+type Report struct {
+	Extract         string             `json:"extract"`
+	ExtractRecords  int                `json:"extract_records"`
+	PreparedObjects int                `json:"prepared_objects"`
+	Items           int                `json:"items"`
+	Counts          map[Category]int   `json:"counts"`
+	Discrepancies   []Discrepancy      `json:"discrepancies"`
+	repairs         map[string]*Repair // Keyed by grant ID
+}
+
+// record summarizes a Grants.gov opportunity or forecast record.
+type record struct {
+	layout          preparedData.Layout
+	lastUpdatedDate grantsgov.MMDDYYYYType
+}
+
+// object summarizes a Grants.gov prepared-data S3 object.
+type object struct {
+	record
+	key string
+}
+
+// grantState collects everything known about a grant across the data stores.
+type grantState struct {
+	extract *record
+	objects []object
+	// LastUpdatedDate of the DynamoDB item, if the item has Grants.gov data
+	item *grantsgov.MMDDYYYYType
+}
+
+// latestObject returns the prepared-data object with the most recent LastUpdatedDate, if any.
+func (g *grantState) latestObject() *object {
+	var latest *object
+	for i, obj := range g.objects {
+		if latest == nil || isAfter(obj.lastUpdatedDate, latest.lastUpdatedDate) {
+			latest = &g.objects[i]
+		}
+	}
+	return latest
+}
+
+// audit returns the discrepancies found for the grant.
+func (g *grantState) audit(grantID string) []Discrepancy {
+	ds := []Discrepancy{}
+	add := func(c Category, repair *Repair, format string, a ...any) {
+		ds = append(ds, Discrepancy{grantID, c, fmt.Sprintf(format, a...), repair})
+	}
+	var writeObject *Repair
+	if g.extract != nil {
+		writeObject = &Repair{RepairWriteObject, g.extract.layout.Key(grantID)}
+	}
+
+	obj := g.latestObject()
+	switch {
+	case obj == nil && g.item == nil:
+		if g.extract != nil {
+			add(CategoryMissingGrant, writeObject, "%s last updated %s has no prepared data",
+				g.extract.layout.Name, g.extract.lastUpdatedDate)
+			return ds
+		}
+	case g.item == nil:
+		add(CategoryMissingItem, &Repair{RepairRetouchObject, obj.key},
+			"%s has no DynamoDB item", obj.key)
+	case obj == nil:
+		var repair *Repair
+		if g.extract != nil && !isAfter(*g.item, g.extract.lastUpdatedDate) {
+			repair = writeObject
+		}
+		add(CategoryMissingObject, repair, "DynamoDB item last updated %s has no prepared-data object",
+			*g.item)
+	case obj.lastUpdatedDate != *g.item:
+		// Re-triggering persistence of an object that is older than the item would revert the item
+		var repair *Repair
+		if isAfter(obj.lastUpdatedDate, *g.item) {
+			repair = &Repair{RepairRetouchObject, obj.key}
+		}
+		add(CategoryLastUpdatedMismatch, repair, "%s last updated %s but DynamoDB item last updated %s",
+			obj.key, obj.lastUpdatedDate, *g.item)
+	}
+
+	if g.extract != nil {
+		var latest grantsgov.MMDDYYYYType
+		if obj != nil {
+			latest = obj.lastUpdatedDate
+		}
+		if g.item != nil && isAfter(*g.item, latest) {
+			latest = *g.item
+		}
+		if isAfter(g.extract.lastUpdatedDate, latest) {
+			add(CategoryStale, writeObject, "%s last updated %s but prepared data last updated %s",
+				g.extract.layout.Name, g.extract.lastUpdatedDate, latest)
+		}
+	}
+	return ds
+}
+
+// newReport audits every grant and collects the discrepancies in a report.
+func newReport(extract string, grants map[string]*grantState) *Report {
+	report := &Report{
+		Extract:       extract,
+		Counts:        make(map[Category]int),
+		Discrepancies: []Discrepancy{},
+		repairs:       make(map[string]*Repair),
+	}
+	for _, c := range Categories {
+		report.Counts[c] = 0
+	}
+
+	for grantID, g := range grants {
+		if g.extract != nil {
+			report.ExtractRecords++
+		}
+		report.PreparedObjects += len(g.objects)
+		if g.item != nil {
+			report.Items++
+		}
+
+		for _, d := range g.audit(grantID) {
+			report.Counts[d.Category]++
+			report.Discrepancies = append(report.Discrepancies, d)
+			// Writing an object from the extract supersedes re-touching an object
+			if d.Repair != nil && (report.repairs[grantID] == nil || d.Repair.Action == RepairWriteObject) {
+				report.repairs[grantID] = d.Repair
+			}
+		}
+	}
+
+	order := make(map[Category]int, len(Categories))
+	for i, c := range Categories {
+		order[c] = i
+	}
+	sort.Slice(report.Discrepancies, func(i, j int) bool {
+		a, b := report.Discrepancies[i], report.Discrepancies[j]
+		if a.Category != b.Category {
+			return order[a.Category] < order[b.Category]
+		}
+		return a.GrantID < b.GrantID
+	})
+	return report
+}
+
+// isAfter reports whether the date a is after the date b. A date that cannot be parsed
+// (including an empty date) is considered to be before any valid date.
+func isAfter(a, b grantsgov.MMDDYYYYType) bool {
+	ta, err := a.Time()
+	if err != nil {
+		return false
+	}
+	tb, err := b.Time()
+	if err != nil {
+		return true
+	}
+	return ta.After(tb)
+}
@@ -0,0 +1,203 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/alecthomas/kong"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/hashicorp/go-multierror"
+	ct "github.com/usdigitalresponse/grants-ingest/cli/types"
+	"github.com/usdigitalresponse/grants-ingest/internal/awsHelpers"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+	grantsgov "github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/grants.gov"
+)
+
+var (
+	ErrCompletion    = errors.New("the operation completed with errors")
+	ErrDiscrepancies = errors.New("discrepancies were found")
+)
+
+type Cmd struct {
+	// Flags
+	SourceDataBucket   string              `required:"" env:"GRANTS_SOURCE_DATA_BUCKET_NAME" help:"Name of the S3 bucket containing grants source data."`
+	PreparedDataBucket string              `required:"" env:"GRANTS_PREPARED_DATA_BUCKET_NAME" help:"Name of the S3 bucket containing grants prepared data."`
+	PreparedDataTable  string              `required:"" env:"GRANTS_PREPARED_DYNAMODB_NAME" help:"Name of the DynamoDB table containing grants prepared data."`
+	ExtractKey         string              `placeholder:"KEY" help:"Key of the Grants.gov extract XML object in the source data bucket (default: the most recent extract)."`
+	IgnoreForecasts    bool                `help:"Ignore forecasts in the extract (e.g. when forecasted grants are not ingested)."`
+	ReadConcurrency    ct.ConcurrencyLimit `default:"10" help:"Max DynamoDB parallel scan workers and concurrent S3 object reads."`
+	TotalsAfter        ct.TotalsAfter      `default:"10000" help:"Log totals after this many records, objects, or items are read (silent if 0)."`
+	JSON               bool                `name:"json" help:"Print the report as JSON instead of human-readable text."`
+	ExitCode           bool                `help:"Exit with a non-zero status when discrepancies are found."`
+	Repair             bool                `help:"Perform the repair action for each discrepancy that has one."`
+	DryRun             bool                `help:"Dry run only - repair actions are logged, but no S3 objects will be written."`
+	S3UsePathStyle     bool                `name:"s3-use-path-style" help:"Use path-style addressing for S3 buckets."`
+
+	// Internal
+	ctx  context.Context
+	stop context.CancelFunc
+	s3   *s3.Client
+	ddb  *dynamodb.Client
+}
+
+func (cmd *Cmd) Help() string {
+	return `
+Cross-checks the most recent Grants.gov extract in the source data bucket against the
+prepared-data S3 bucket and DynamoDB table, and reports any discrepancies by category:
+
+  missing-grant          A grant in the extract has neither a prepared-data object nor an item.
+  stale                  A grant in the extract is newer than its newest prepared data.
+  missing-item           A prepared-data object has no item with Grants.gov data.
+  missing-object         An item with Grants.gov data has no prepared-data object.
+  last-updated-mismatch  An item's LastUpdatedDate differs from that of its prepared-data object.
+
+Each discrepancy is reported with a repair action, if one is available, which is performed
+when --repair is given:
+
+  write-object    Writes the grant's extract record to its prepared-data object, as done by
+                  SplitGrantsGovXMLDB.
+  retouch-object  Copies the prepared-data object onto itself, which re-triggers
+                  PersistGrantsGovXMLDB for the object.
+
+Repair actions are not available for items that are newer than their prepared-data object,
+since re-triggering PersistGrantsGovXMLDB would revert the item to older data.
+
+The bucket and table names may be provided with the GRANTS_SOURCE_DATA_BUCKET_NAME,
+GRANTS_PREPARED_DATA_BUCKET_NAME, and GRANTS_PREPARED_DYNAMODB_NAME environment variables,
+respectively.`
+}
+
+func (cmd *Cmd) BeforeApply() error {
+	cmd.ctx, cmd.stop = signal.NotifyContext(context.Background(),
+		syscall.SIGHUP, syscall.SIGINT, os.Interrupt)
+	return nil
+}
+
+func (cmd *Cmd) AfterApply() error {
+	cfg, err := awsHelpers.GetConfig(cmd.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to configure AWS SDK: %w", err)
+	}
+	cmd.s3 = s3.NewFromConfig(cfg, func(o *s3.Options) { o.UsePathStyle = cmd.S3UsePathStyle })
+	cmd.ddb = dynamodb.NewFromConfig(cfg)
+	return nil
+}
+
+// Destructive reports whether the command performs repair actions.
+func (cmd *Cmd) Destructive() bool {
+	return cmd.Repair && !cmd.DryRun
+}
+
+func (cmd *Cmd) Run(app *kong.Kong, baseLogger *log.Logger) error {
+	defer cmd.stop()
+	logger := *baseLogger
+
+	if cmd.ExtractKey == "" {
+		key, err := cmd.latestExtractKey()
+		if err != nil {
+			return log.Errorf(logger, "Error finding the most recent Grants.gov extract", err,
+				"bucket", cmd.SourceDataBucket)
+		}
+		cmd.ExtractKey = key
+	}
+	log.Info(logger, "Auditing prepared data against Grants.gov extract",
+		"source_bucket", cmd.SourceDataBucket, "extract_key", cmd.ExtractKey)
+
+	var records map[string]*record
+	var objects map[string][]object
+	var items map[string]grantsgov.MMDDYYYYType
+	wg := multierror.Group{}
+	wg.Go(func() (err error) {
+		records, err = cmd.readExtract(log.With(logger, "source_bucket", cmd.SourceDataBucket))
+		return err
+	})
+	wg.Go(func() (err error) {
+		objects, err = cmd.readObjects(log.With(logger, "bucket", cmd.PreparedDataBucket))
+		return err
+	})
+	wg.Go(func() (err error) {
+		items, err = cmd.readItems(log.With(logger, "table", cmd.PreparedDataTable))
+		return err
+	})
+	if err := wg.Wait().ErrorOrNil(); err != nil {
+		if cmd.ctx.Err() != nil {
+			log.Warn(logger, "Audit canceled before all data was read")
+			return ErrCompletion
+		}
+		return log.Errorf(logger, "Error reading data to audit", err)
+	}
+
+	grants := make(map[string]*grantState)
+	state := func(grantID string) *grantState {
+		if grants[grantID] == nil {
+			grants[grantID] = &grantState{}
+		}
+		return grants[grantID]
+	}
+	for grantID, r := range records {
+		state(grantID).extract = r
+	}
+	for grantID, objs := range objects {
+		state(grantID).objects = objs
+	}
+	for grantID, lastUpdatedDate := range items {
+		state(grantID).item = &lastUpdatedDate
+	}
+	report := newReport(cmd.ExtractKey, grants)
+
+	if err := cmd.printReport(app.Stdout, report); err != nil {
+		return log.Errorf(logger, "Error printing audit report", err)
+	}
+	log.Info(logger, "Audit complete", "discrepancies", len(report.Discrepancies),
+		"repairable", len(report.repairs))
+
+	if cmd.Repair && len(report.repairs) > 0 {
+		logger := log.With(logger, "bucket", cmd.PreparedDataBucket, "dry_run", cmd.DryRun)
+		if failures := cmd.repair(logger, report); failures > 0 || cmd.ctx.Err() != nil {
+			return ErrCompletion
+		}
+	}
+
+	if cmd.ExitCode && len(report.Discrepancies) > 0 {
+		return ErrDiscrepancies
+	}
+	return nil
+}
+
+func (cmd *Cmd) printReport(w io.Writer, report *Report) error {
+	if cmd.JSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	fmt.Fprintf(w, "Extract: %s\n", report.Extract)
+	fmt.Fprintf(w, "Grants in extract: %d\nPrepared-data objects: %d\nItems with Grants.gov data: %d\n\n",
+		report.ExtractRecords, report.PreparedObjects, report.Items)
+
+	fmt.Fprintln(w, "Discrepancies:")
+	for _, c := range Categories {
+		fmt.Fprintf(w, "  %-22s %d\n", c, report.Counts[c])
+	}
+
+	var category Category
+	for _, d := range report.Discrepancies {
+		if d.Category != category {
+			category = d.Category
+			fmt.Fprintf(w, "\n%s:\n", category)
+		}
+		fmt.Fprintf(w, "  %s: %s", d.GrantID, d.Detail)
+		if d.Repair != nil {
+			fmt.Fprintf(w, " (repair: %s %s)", d.Repair.Action, d.Repair.Key)
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
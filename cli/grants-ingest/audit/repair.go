@@ -0,0 +1,164 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/xml"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/usdigitalresponse/grants-ingest/internal/lambdas/splitGrantsGovXMLDB"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+	"github.com/usdigitalresponse/grants-ingest/internal/preparedData"
+	grantsgov "github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/grants.gov"
+)
+
+// repair performs the repair actions of the report, returning the number of failed actions.
+func (cmd *Cmd) repair(logger log.Logger, report *Report) int {
+	var retouchKeys []string
+	writeKeys := make(map[string]bool)
+	for _, r := range report.repairs {
+		switch r.Action {
+		case RepairRetouchObject:
+			retouchKeys = append(retouchKeys, r.Key)
+		case RepairWriteObject:
+			writeKeys[r.Key] = true
+		}
+	}
+	sort.Strings(retouchKeys)
+	log.Info(logger, "Repairing discrepancies",
+		"retouch_objects", len(retouchKeys), "write_objects", len(writeKeys))
+
+	failures := cmd.retouchObjects(logger, retouchKeys)
+	if len(writeKeys) > 0 {
+		failures += cmd.writeObjects(logger, writeKeys)
+	}
+	return failures
+}
+
+// retouchObjects copies each object onto itself so that S3 notifies PersistGrantsGovXMLDB of
+// a newly-created object.
+func (cmd *Cmd) retouchObjects(logger log.Logger, keys []string) int {
+	work := make(chan string)
+	go func() {
+		defer close(work)
+		for _, k := range keys {
+			select {
+			case work <- k:
+			case <-cmd.ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var failures, retouched int
+	var mu sync.Mutex
+	retouchedAt := time.Now().UTC().Format(time.RFC3339)
+	wg := sync.WaitGroup{}
+	for i := 0; i < int(cmd.ReadConcurrency); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range work {
+				err := cmd.retouchObject(key, retouchedAt)
+				mu.Lock()
+				if err != nil {
+					log.Warn(logger, "Failed to retouch S3 object", "key", key, "error", err)
+					failures++
+				} else {
+					log.Debug(logger, "Retouched S3 object", "key", key, "dry_run", cmd.DryRun)
+					retouched++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	log.Info(logger, "Final count of retouched objects", "count", retouched, "failures", failures)
+	return failures
+}
+
+func (cmd *Cmd) retouchObject(key, retouchedAt string) error {
+	if cmd.DryRun {
+		return nil
+	}
+	// Copying an object onto itself requires a change, so the metadata is replaced
+	_, err := cmd.s3.CopyObject(cmd.ctx, &s3.CopyObjectInput{
+		Bucket:               aws.String(cmd.PreparedDataBucket),
+		Key:                  aws.String(key),
+		CopySource:           aws.String(cmd.PreparedDataBucket + "/" + key),
+		MetadataDirective:    s3types.MetadataDirectiveReplace,
+		Metadata:             map[string]string{"audit-retouched-at": retouchedAt},
+		ServerSideEncryption: s3types.ServerSideEncryptionAes256,
+	})
+	return err
+}
+
+// writeObjects re-reads the extract and writes each record whose prepared-data object key
+// is in keys to the prepared-data bucket, in the same manner as SplitGrantsGovXMLDB.
+func (cmd *Cmd) writeObjects(logger log.Logger, keys map[string]bool) int {
+	resp, err := cmd.s3.GetObject(cmd.ctx, &s3.GetObjectInput{
+		Bucket: aws.String(cmd.SourceDataBucket),
+		Key:    aws.String(cmd.ExtractKey),
+	})
+	if err != nil {
+		log.Error(logger, "Error re-reading Grants.gov extract", err)
+		return len(keys)
+	}
+	defer resp.Body.Close()
+
+	var failures, written int
+	err = decodeRecords(cmd.ctx, resp.Body, func(d *xml.Decoder, se xml.StartElement, layout preparedData.Layout) error {
+		var rec any
+		var grantID grantsgov.Number20DigitsType
+		switch layout {
+		case preparedData.GrantsGovOpportunity:
+			var o grantsgov.OpportunitySynopsisDetail_1_0
+			if err := d.DecodeElement(&o, &se); err != nil {
+				return err
+			}
+			rec, grantID = o, o.OpportunityID
+		case preparedData.GrantsGovForecast:
+			var f grantsgov.OpportunityForecastDetail_1_0
+			if err := d.DecodeElement(&f, &se); err != nil {
+				return err
+			}
+			rec, grantID = f, f.OpportunityID
+		}
+
+		key := layout.Key(string(grantID))
+		if !keys[key] {
+			return nil
+		}
+		// Only the first record for each key is written
+		delete(keys, key)
+
+		b, err := xml.Marshal(rec)
+		if err == nil && !cmd.DryRun {
+			err = splitGrantsGovXMLDB.UploadS3Object(cmd.ctx, cmd.s3, cmd.PreparedDataBucket, key, bytes.NewReader(b))
+		}
+		if err != nil {
+			log.Warn(logger, "Failed to write S3 object", "key", key, "error", err)
+			failures++
+		} else {
+			log.Debug(logger, "Wrote S3 object", "key", key, "dry_run", cmd.DryRun)
+			written++
+		}
+		if len(keys) == 0 {
+			return errStopDecoding
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error(logger, "Error re-reading Grants.gov extract", err)
+	}
+	if len(keys) > 0 {
+		log.Warn(logger, "Some records to write were not found in the extract", "count", len(keys))
+	}
+	failures += len(keys)
+	log.Info(logger, "Final count of written objects", "count", written, "failures", failures)
+	return failures
+}
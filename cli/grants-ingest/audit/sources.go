@@ -0,0 +1,295 @@
+package audit
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/usdigitalresponse/grants-ingest/cli/tableScan"
+	"github.com/usdigitalresponse/grants-ingest/internal/lambdas/splitGrantsGovXMLDB"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+	"github.com/usdigitalresponse/grants-ingest/internal/preparedData"
+	grantsgov "github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/grants.gov"
+)
+
+const extractKeySuffix = "/grants.gov/extract.xml"
+
+var errStopDecoding = errors.New("stop decoding")
+
+// recordSummary is decoded from Grants.gov opportunity and forecast XML elements.
+type recordSummary struct {
+	OpportunityID   string                 `xml:"OpportunityID"`
+	LastUpdatedDate grantsgov.MMDDYYYYType `xml:"LastUpdatedDate"`
+}
+
+// decodeRecords reads Grants.gov opportunity and forecast XML elements from r, calling fn with
+// the start element of each record and the layout of the record's prepared-data object.
+// fn is responsible for decoding (or skipping) the element; decoding stops without error
+// when fn returns errStopDecoding.
+func decodeRecords(ctx context.Context, r io.Reader,
+	fn func(d *xml.Decoder, se xml.StartElement, layout preparedData.Layout) error) error {
+	d := xml.NewDecoder(r)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		token, err := d.Token()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		se, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		var layout preparedData.Layout
+		switch se.Name.Local {
+		case splitGrantsGovXMLDB.GRANT_OPPORTUNITY_XML_NAME:
+			layout = preparedData.GrantsGovOpportunity
+		case splitGrantsGovXMLDB.GRANT_FORECAST_XML_NAME:
+			layout = preparedData.GrantsGovForecast
+		default:
+			continue
+		}
+		if err := fn(d, se, layout); err == errStopDecoding {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// latestExtractKey returns the key of the most recent Grants.gov extract in the source data bucket.
+func (cmd *Cmd) latestExtractKey() (string, error) {
+	var latest string
+	paginator := s3.NewListObjectsV2Paginator(cmd.s3, &s3.ListObjectsV2Input{
+		Bucket: aws.String(cmd.SourceDataBucket),
+		Prefix: aws.String("sources/"),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(cmd.ctx)
+		if err != nil {
+			return "", err
+		}
+		for _, obj := range page.Contents {
+			// Keys are dated (sources/YYYY/MM/DD/...), so the most recent sorts last
+			if key := aws.ToString(obj.Key); strings.HasSuffix(key, extractKeySuffix) && key > latest {
+				latest = key
+			}
+		}
+	}
+	if latest == "" {
+		return "", errors.New("no Grants.gov extract found in source data bucket")
+	}
+	return latest, nil
+}
+
+// readExtract returns a summary of the newest record for each grant in the extract.
+func (cmd *Cmd) readExtract(logger log.Logger) (map[string]*record, error) {
+	resp, err := cmd.s3.GetObject(cmd.ctx, &s3.GetObjectInput{
+		Bucket: aws.String(cmd.SourceDataBucket),
+		Key:    aws.String(cmd.ExtractKey),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	records := make(map[string]*record)
+	var count int64
+	err = decodeRecords(cmd.ctx, resp.Body, func(d *xml.Decoder, se xml.StartElement, layout preparedData.Layout) error {
+		if cmd.IgnoreForecasts && layout == preparedData.GrantsGovForecast {
+			return d.Skip()
+		}
+		var s recordSummary
+		if err := d.DecodeElement(&s, &se); err != nil {
+			return err
+		}
+		count++
+		if cmd.TotalsAfter.Check(count) {
+			log.Info(logger, "Updated extract records total", "count", count)
+		}
+
+		// SplitGrantsGovXMLDB only uploads a record that is newer than the extant data, so an
+		// opportunity and forecast for the same grant are resolved in favor of the newer record.
+		existing, ok := records[s.OpportunityID]
+		if !ok || isAfter(s.LastUpdatedDate, existing.lastUpdatedDate) {
+			records[s.OpportunityID] = &record{layout, s.LastUpdatedDate}
+		}
+		return nil
+	})
+	log.Info(logger, "Final count of extract records", "count", count, "grants", len(records))
+	return records, err
+}
+
+// readObjects returns a summary of each Grants.gov prepared-data S3 object, keyed by grant ID.
+func (cmd *Cmd) readObjects(logger log.Logger) (map[string][]object, error) {
+	type listedObject struct {
+		grantID string
+		key     string
+		layout  preparedData.Layout
+	}
+	listed := make(chan listedObject)
+
+	var listErr error
+	go func() {
+		defer close(listed)
+		paginator := s3.NewListObjectsV2Paginator(cmd.s3, &s3.ListObjectsV2Input{
+			Bucket: aws.String(cmd.PreparedDataBucket),
+		})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(cmd.ctx)
+			if err != nil {
+				listErr = err
+				return
+			}
+			for _, obj := range page.Contents {
+				key := aws.ToString(obj.Key)
+				for _, layout := range preparedData.GrantsGovLayouts {
+					grantID, ok := layout.GrantID(key)
+					if !ok {
+						continue
+					}
+					select {
+					case listed <- listedObject{grantID, key, layout}:
+					case <-cmd.ctx.Done():
+						listErr = cmd.ctx.Err()
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	objects := make(map[string][]object)
+	var count int64
+	var mu sync.Mutex
+	var readErr error
+	wg := sync.WaitGroup{}
+	for i := 0; i < int(cmd.ReadConcurrency); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for o := range listed {
+				lastUpdatedDate, err := cmd.readObjectLastUpdatedDate(o.key)
+				mu.Lock()
+				if err != nil {
+					if readErr == nil && cmd.ctx.Err() == nil {
+						log.Error(logger, "Error reading prepared-data S3 object", err, "key", o.key)
+						readErr = err
+						cmd.stop()
+					}
+					mu.Unlock()
+					continue
+				}
+				objects[o.grantID] = append(objects[o.grantID],
+					object{record{o.layout, lastUpdatedDate}, o.key})
+				count++
+				if cmd.TotalsAfter.Check(count) {
+					log.Info(logger, "Updated prepared-data objects total", "count", count)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	log.Info(logger, "Final count of prepared-data objects", "count", count)
+	if readErr != nil {
+		return objects, readErr
+	}
+	return objects, listErr
+}
+
+func (cmd *Cmd) readObjectLastUpdatedDate(key string) (grantsgov.MMDDYYYYType, error) {
+	resp, err := cmd.s3.GetObject(cmd.ctx, &s3.GetObjectInput{
+		Bucket: aws.String(cmd.PreparedDataBucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var s recordSummary
+	err = decodeRecords(cmd.ctx, resp.Body, func(d *xml.Decoder, se xml.StartElement, _ preparedData.Layout) error {
+		if err := d.DecodeElement(&s, &se); err != nil {
+			return err
+		}
+		return errStopDecoding
+	})
+	return s.LastUpdatedDate, err
+}
+
+// readItems returns the LastUpdatedDate of each DynamoDB item with Grants.gov data,
+// keyed by grant ID.
+func (cmd *Cmd) readItems(logger log.Logger) (map[string]grantsgov.MMDDYYYYType, error) {
+	expr, err := expression.NewBuilder().WithProjection(
+		expression.NamesList(expression.Name("grant_id"), expression.Name("LastUpdatedDate")),
+	).Build()
+	if err != nil {
+		return nil, err
+	}
+	input := dynamodb.ScanInput{
+		TableName:                aws.String(cmd.PreparedDataTable),
+		ProjectionExpression:     expr.Projection(),
+		ExpressionAttributeNames: expr.Names(),
+	}
+
+	scannedItems := make(chan map[string]types.AttributeValue)
+	var scanErr error
+	scanWg := sync.WaitGroup{}
+	for i := 0; i < int(cmd.ReadConcurrency); i++ {
+		scanWg.Add(1)
+		segmentId := i
+		go func() {
+			defer scanWg.Done()
+			err := tableScan.Segment(cmd.ctx, cmd.ddb, logger, input,
+				segmentId, int(cmd.ReadConcurrency), scannedItems)
+			if err != nil && err != context.Canceled {
+				log.Error(logger, "Error scanning DynamoDB items", err)
+				scanErr = err
+				cmd.stop()
+			}
+		}()
+	}
+	go func() {
+		scanWg.Wait()
+		close(scannedItems)
+	}()
+
+	items := make(map[string]grantsgov.MMDDYYYYType)
+	var count int64
+	for item := range scannedItems {
+		count++
+		if cmd.TotalsAfter.Check(count) {
+			log.Info(logger, "Updated scanned items total", "count", count)
+		}
+		var s struct {
+			GrantID         string                 `dynamodbav:"grant_id"`
+			LastUpdatedDate grantsgov.MMDDYYYYType `dynamodbav:"LastUpdatedDate"`
+		}
+		if err := attributevalue.UnmarshalMap(item, &s); err != nil {
+			log.Warn(logger, "Skipping DynamoDB item that could not be unmarshaled", "error", err)
+			continue
+		}
+		// Items without a LastUpdatedDate have no Grants.gov data (e.g. FFIS.org data only)
+		if s.LastUpdatedDate != "" {
+			items[s.GrantID] = s.LastUpdatedDate
+		}
+	}
+
+	log.Info(logger, "Final count of scanned items", "count", count, "with_grants_gov_data", len(items))
+	return items, scanErr
+}
@@ -0,0 +1,321 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/hashicorp/go-multierror"
+	"github.com/usdigitalresponse/grants-ingest/cli/tableScan"
+	ct "github.com/usdigitalresponse/grants-ingest/cli/types"
+	"github.com/usdigitalresponse/grants-ingest/internal/awsHelpers"
+	"github.com/usdigitalresponse/grants-ingest/internal/itemMapper"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/usdr"
+)
+
+var contentTypes = map[string]string{
+	"jsonl":   "application/x-ndjson",
+	"csv":     "text/csv",
+	"parquet": "application/vnd.apache.parquet",
+}
+
+type Cmd struct {
+	// Flags
+	PreparedDataTable string              `required:"" env:"GRANTS_PREPARED_DYNAMODB_NAME" help:"Name of the DynamoDB table containing grants prepared data."`
+	Format            string              `enum:"jsonl,csv,parquet" default:"jsonl" help:"Output format (jsonl|csv|parquet)."`
+	Output            string              `short:"o" default:"-" help:"Local file path or S3 URI (s3://bucket/key) to which the export is written, or - for stdout."`
+	Agency            []string            `name:"agency" placeholder:"CODE" help:"Only export grants from the given agency code, including its sub-agencies (repeatable)."`
+	Stage             string              `enum:"all,forecast,posted" default:"all" help:"Only export forecasted or posted grants (all|forecast|posted)."`
+	OpenOn            time.Time           `name:"open-on" format:"2006-01-02" placeholder:"YYYY-MM-DD" help:"Only export posted grants that are open for applications on the given date."`
+	ReadConcurrency   ct.ConcurrencyLimit `default:"1" help:"Max DynamoDB parallel scan workers."`
+	TotalsAfter       ct.TotalsAfter      `default:"1000" help:"Log item totals after this many items are scanned (silent if 0)."`
+	S3UsePathStyle    bool                `name:"s3-use-path-style" help:"Use path-style addressing for S3 bucket."`
+
+	// Internal
+	ctx      context.Context
+	stop     context.CancelFunc
+	ddb      *dynamodb.Client
+	s3       *s3.Client
+	s3Bucket string
+	s3Key    string
+}
+
+func (cmd *Cmd) Help() string {
+	return `
+Scans the prepared-data DynamoDB table and exports each grant, in the form published by
+PublishGrantEvents, as JSON lines, flattened CSV, or Parquet. Items that cannot be mapped
+to valid grant data are skipped.
+
+Agency filters match agency codes case-insensitively, either exactly or as the parent of a
+sub-agency code (e.g. HHS matches HHS-NIH11). When --open-on is given, only posted grants
+with a post date on or before the given date and a close date (if any) on or after it are
+exported.
+
+The table name may be provided with the GRANTS_PREPARED_DYNAMODB_NAME environment variable.`
+}
+
+func (cmd *Cmd) Validate() error {
+	if !cmd.OpenOn.IsZero() && cmd.Stage == "forecast" {
+		return errors.New("--open-on cannot be used with --stage=forecast")
+	}
+	if strings.HasPrefix(cmd.Output, "s3://") {
+		bucket, key, _ := strings.Cut(strings.TrimPrefix(cmd.Output, "s3://"), "/")
+		if bucket == "" || key == "" {
+			return fmt.Errorf("invalid S3 output URI %q: must be of the form s3://bucket/key", cmd.Output)
+		}
+		cmd.s3Bucket, cmd.s3Key = bucket, key
+	}
+	return nil
+}
+
+func (cmd *Cmd) BeforeApply() error {
+	cmd.ctx, cmd.stop = signal.NotifyContext(context.Background(),
+		syscall.SIGHUP, syscall.SIGINT, os.Interrupt)
+	return nil
+}
+
+func (cmd *Cmd) AfterApply() error {
+	cfg, err := awsHelpers.GetConfig(cmd.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to configure AWS SDK: %w", err)
+	}
+	cmd.ddb = dynamodb.NewFromConfig(cfg)
+	cmd.s3 = s3.NewFromConfig(cfg, func(o *s3.Options) { o.UsePathStyle = cmd.S3UsePathStyle })
+	return nil
+}
+
+func (cmd *Cmd) Run(app *kong.Kong, baseLogger *log.Logger) error {
+	defer cmd.stop()
+	logger := log.With(*baseLogger, "table", cmd.PreparedDataTable,
+		"format", cmd.Format, "output", cmd.Output)
+
+	out, err := cmd.openOutput(app.Stdout)
+	if err != nil {
+		return log.Errorf(logger, "Error opening export output", err)
+	}
+	gw, err := newGrantWriter(cmd.Format, out)
+	if err != nil {
+		out.CloseWithError(err)
+		return log.Errorf(logger, "Error initializing export writer", err)
+	}
+
+	scannedItems := make(chan map[string]types.AttributeValue)
+	scanGroup := multierror.Group{}
+	for i := 0; i < int(cmd.ReadConcurrency); i++ {
+		segmentId := i
+		scanGroup.Go(func() error {
+			err := tableScan.Segment(cmd.ctx, cmd.ddb, logger,
+				dynamodb.ScanInput{TableName: aws.String(cmd.PreparedDataTable)},
+				segmentId, int(cmd.ReadConcurrency), scannedItems)
+			if err != nil && err != context.Canceled {
+				log.Error(logger,
+					"Stopping application due to fatal error encountered while scanning DynamoDB items",
+					err)
+				cmd.stop()
+				return err
+			}
+			return nil
+		})
+	}
+	// scanTableErr is set before scannedItems is closed, so it can be read after every item is received
+	var scanTableErr error
+	go func() {
+		scanTableErr = scanGroup.Wait().ErrorOrNil()
+		close(scannedItems)
+	}()
+
+	var totalScanned, totalExported, totalFiltered, totalInvalid int64
+	var writeErr error
+	for item := range scannedItems {
+		totalScanned++
+		if cmd.TotalsAfter.Check(totalScanned) {
+			log.Info(logger, "Updated scanned items total", "count", totalScanned)
+		}
+		if writeErr != nil {
+			// Drain remaining items so that scan workers can shut down
+			continue
+		}
+
+		grantLogger := log.With(logger, "grant_id", itemGrantID(item))
+		grant, err := grantFromItem(grantLogger, item)
+		if err != nil {
+			log.Warn(grantLogger, "Skipping item that could not be mapped to valid grant data",
+				"error", err)
+			totalInvalid++
+			continue
+		}
+		isForecast := itemIsForecast(item)
+		if !cmd.matches(grant, isForecast) {
+			totalFiltered++
+			continue
+		}
+		if err := gw.Write(grant, isForecast); err != nil {
+			writeErr = log.Errorf(grantLogger, "Error writing exported grant", err)
+			cmd.stop()
+			continue
+		}
+		totalExported++
+	}
+
+	if writeErr == nil {
+		writeErr = gw.Close()
+	}
+	if writeErr != nil || scanTableErr != nil || cmd.ctx.Err() != nil {
+		out.CloseWithError(errors.Join(writeErr, scanTableErr, cmd.ctx.Err()))
+	} else if err := out.Close(); err != nil {
+		writeErr = log.Errorf(logger, "Error finalizing export output", err)
+	}
+
+	log.Info(logger, "Final export totals", "scanned", totalScanned, "exported", totalExported,
+		"filtered", totalFiltered, "invalid", totalInvalid)
+	if cmd.ctx.Err() != nil || writeErr != nil || scanTableErr != nil {
+		return fmt.Errorf("the operation completed with errors")
+	}
+	return nil
+}
+
+// matches reports whether a grant satisfies the configured export filters.
+func (cmd *Cmd) matches(grant usdr.Grant, isForecast bool) bool {
+	switch {
+	case cmd.Stage == "forecast" && !isForecast, cmd.Stage == "posted" && isForecast:
+		return false
+	}
+
+	if len(cmd.Agency) > 0 {
+		found := false
+		for _, code := range cmd.Agency {
+			if agencyCodeMatches(grant.Agency.Code, code) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if !cmd.OpenOn.IsZero() {
+		milestones := grant.Opportunity.Milestones
+		if isForecast || milestones.PostDate == nil || time.Time(*milestones.PostDate).After(cmd.OpenOn) {
+			return false
+		}
+		if milestones.Close.Date != nil && time.Time(*milestones.Close.Date).Before(cmd.OpenOn) {
+			return false
+		}
+	}
+	return true
+}
+
+// agencyCodeMatches reports whether code is the same as, or a sub-agency of, filterCode.
+func agencyCodeMatches(code, filterCode string) bool {
+	code, filterCode = strings.ToUpper(code), strings.ToUpper(filterCode)
+	return code == filterCode || strings.HasPrefix(code, filterCode+"-")
+}
+
+// grantFromItem maps a prepared-data table item to grant data in the same manner
+// as PublishGrantEvents.
+func grantFromItem(logger log.Logger, item map[string]types.AttributeValue) (usdr.Grant, error) {
+	image, err := itemMapper.FromAttributeValueMap(item)
+	if err != nil {
+		return usdr.Grant{}, err
+	}
+	mapper := itemMapper.NewItemMapper(image, func(name string, err error) {
+		log.Debug(logger, "Malformed item attribute", "field", name, "error", err)
+	})
+	grant, err := itemMapper.GuardPanic(mapper.Grant)
+	if err != nil {
+		return grant, err
+	}
+	return grant, grant.Validate()
+}
+
+func itemGrantID(item map[string]types.AttributeValue) string {
+	if v, ok := item["grant_id"].(*types.AttributeValueMemberS); ok {
+		return v.Value
+	}
+	return ""
+}
+
+func itemIsForecast(item map[string]types.AttributeValue) bool {
+	v, ok := item["is_forecast"].(*types.AttributeValueMemberBOOL)
+	return ok && v.Value
+}
+
+// openOutput returns a writer for the configured export destination. When the destination is
+// an S3 key, data written is streamed to S3 by a multipart upload that completes when the
+// writer is closed, or is aborted if the writer is closed with an error.
+func (cmd *Cmd) openOutput(stdout io.Writer) (output, error) {
+	switch {
+	case cmd.Output == "-":
+		return nopOutput{stdout}, nil
+	case cmd.s3Bucket != "":
+		pr, pw := io.Pipe()
+		uploaded := make(chan error, 1)
+		go func() {
+			_, err := manager.NewUploader(cmd.s3).Upload(cmd.ctx, &s3.PutObjectInput{
+				Bucket:      aws.String(cmd.s3Bucket),
+				Key:         aws.String(cmd.s3Key),
+				Body:        pr,
+				ContentType: aws.String(contentTypes[cmd.Format]),
+			})
+			pr.CloseWithError(err)
+			uploaded <- err
+		}()
+		return &s3Output{pw, uploaded}, nil
+	default:
+		f, err := os.Create(cmd.Output)
+		if err != nil {
+			return nil, err
+		}
+		return &fileOutput{f}, nil
+	}
+}
+
+// output is an export destination.
+type output interface {
+	io.Writer
+	// Close completes the export.
+	Close() error
+	// CloseWithError abandons the export, discarding written data where possible.
+	CloseWithError(error)
+}
+
+type nopOutput struct{ io.Writer }
+
+func (nopOutput) Close() error         { return nil }
+func (nopOutput) CloseWithError(error) {}
+
+type fileOutput struct{ *os.File }
+
+func (o *fileOutput) CloseWithError(error) {
+	o.File.Close()
+	os.Remove(o.File.Name())
+}
+
+type s3Output struct {
+	*io.PipeWriter
+	uploaded <-chan error
+}
+
+func (o *s3Output) Close() error {
+	o.PipeWriter.Close()
+	return <-o.uploaded
+}
+
+func (o *s3Output) CloseWithError(err error) {
+	o.PipeWriter.CloseWithError(err)
+	<-o.uploaded
+}
@@ -0,0 +1,220 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/usdr"
+)
+
+// grantWriter writes exported grants in a particular output format.
+// Close must be called to flush any buffered output; it does not close the underlying writer.
+type grantWriter interface {
+	Write(grant usdr.Grant, isForecast bool) error
+	Close() error
+}
+
+func newGrantWriter(format string, w io.Writer) (grantWriter, error) {
+	switch format {
+	case "jsonl":
+		return &jsonlWriter{enc: json.NewEncoder(w)}, nil
+	case "csv":
+		return newCSVWriter(w)
+	case "parquet":
+		return &parquetWriter{w: parquet.NewGenericWriter[flatGrant](w)}, nil
+	}
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+// exportedGrant is the JSON representation of an exported grant.
+type exportedGrant struct {
+	usdr.Grant
+	IsForecast bool `json:"is_forecast"`
+}
+
+type jsonlWriter struct {
+	enc *json.Encoder
+}
+
+func (jw *jsonlWriter) Write(grant usdr.Grant, isForecast bool) error {
+	return jw.enc.Encode(exportedGrant{grant, isForecast})
+}
+
+func (jw *jsonlWriter) Close() error { return nil }
+
+type parquetWriter struct {
+	w *parquet.GenericWriter[flatGrant]
+}
+
+func (pw *parquetWriter) Write(grant usdr.Grant, isForecast bool) error {
+	_, err := pw.w.Write([]flatGrant{flattenGrant(grant, isForecast)})
+	return err
+}
+
+func (pw *parquetWriter) Close() error { return pw.w.Close() }
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+// newCSVWriter returns a grantWriter that writes a header row followed by one row per grant.
+// Column names are the same as those used for Parquet output.
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w)}
+	t := reflect.TypeOf(flatGrant{})
+	header := make([]string, t.NumField())
+	for i := range header {
+		header[i] = columnName(t.Field(i))
+	}
+	return cw, cw.w.Write(header)
+}
+
+func (cw *csvWriter) Write(grant usdr.Grant, isForecast bool) error {
+	v := reflect.ValueOf(flattenGrant(grant, isForecast))
+	row := make([]string, v.NumField())
+	for i := range row {
+		row[i] = csvValue(v.Field(i))
+	}
+	return cw.w.Write(row)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func columnName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("parquet"), ",")
+	return name
+}
+
+// csvValue formats a flatGrant field value as a CSV cell. Nil values are empty.
+func csvValue(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	}
+	panic(fmt.Sprintf("unsupported flatGrant field kind: %s", v.Kind()))
+}
+
+// listSeparator joins multi-valued grant fields into a single column value.
+const listSeparator = ";"
+
+// flatGrant is the tabular (CSV and Parquet) representation of an exported grant.
+// Multi-valued fields are joined with listSeparator, and dates are formatted as YYYY-MM-DD.
+type flatGrant struct {
+	GrantID                           string  `parquet:"grant_id"`
+	OpportunityNumber                 string  `parquet:"opportunity_number"`
+	Title                             string  `parquet:"title"`
+	IsForecast                        bool    `parquet:"is_forecast"`
+	CategoryCode                      string  `parquet:"category_code"`
+	CategoryName                      string  `parquet:"category_name"`
+	AgencyCode                        string  `parquet:"agency_code"`
+	AgencyName                        string  `parquet:"agency_name"`
+	AgencyDepartmentCode              string  `parquet:"agency_department_code"`
+	AgencyDepartmentName              string  `parquet:"agency_department_name"`
+	PostDate                          *string `parquet:"post_date,optional"`
+	CloseDate                         *string `parquet:"close_date,optional"`
+	CloseDateExplanation              string  `parquet:"close_date_explanation"`
+	ArchiveDate                       *string `parquet:"archive_date,optional"`
+	LastUpdated                       *string `parquet:"last_updated,optional"`
+	AwardCeiling                      string  `parquet:"award_ceiling"`
+	AwardFloor                        string  `parquet:"award_floor"`
+	EstimatedTotalProgramFunding      string  `parquet:"estimated_total_program_funding"`
+	AwardCeilingCents                 *int64  `parquet:"award_ceiling_cents,optional"`
+	AwardFloorCents                   *int64  `parquet:"award_floor_cents,optional"`
+	EstimatedTotalProgramFundingCents *int64  `parquet:"estimated_total_program_funding_cents,optional"`
+	ExpectedNumberOfAwards            int64   `parquet:"expected_number_of_awards"`
+	CostSharingOrMatchingRequirement  *bool   `parquet:"cost_sharing_or_matching_requirement,optional"`
+	CFDANumbers                       string  `parquet:"cfda_numbers"`
+	EligibleApplicantCodes            string  `parquet:"eligible_applicant_codes"`
+	EligibilityCategories             string  `parquet:"eligibility_categories"`
+	FundingInstrumentCodes            string  `parquet:"funding_instrument_codes"`
+	FundingActivityCategoryCodes      string  `parquet:"funding_activity_category_codes"`
+	Bill                              string  `parquet:"bill"`
+	GrantorEmail                      string  `parquet:"grantor_email"`
+	RevisionID                        string  `parquet:"revision_id"`
+	Description                       string  `parquet:"description"`
+}
+
+func flattenGrant(g usdr.Grant, isForecast bool) flatGrant {
+	return flatGrant{
+		GrantID:                           g.Opportunity.Id,
+		OpportunityNumber:                 g.Opportunity.Number,
+		Title:                             g.Opportunity.Title,
+		IsForecast:                        isForecast,
+		CategoryCode:                      string(g.Opportunity.Category.Code),
+		CategoryName:                      string(g.Opportunity.Category.Name),
+		AgencyCode:                        g.Agency.Code,
+		AgencyName:                        g.Agency.Name,
+		AgencyDepartmentCode:              g.Agency.DepartmentCode,
+		AgencyDepartmentName:              g.Agency.DepartmentName,
+		PostDate:                          formatDate(g.Opportunity.Milestones.PostDate),
+		CloseDate:                         formatDate(g.Opportunity.Milestones.Close.Date),
+		CloseDateExplanation:              g.Opportunity.Milestones.Close.Explanation,
+		ArchiveDate:                       formatDate(g.Opportunity.Milestones.ArchiveDate),
+		LastUpdated:                       formatDate(g.Opportunity.LastUpdated),
+		AwardCeiling:                      g.Award.Ceiling,
+		AwardFloor:                        g.Award.Floor,
+		EstimatedTotalProgramFunding:      g.Award.EstimatedTotalProgramFunding,
+		AwardCeilingCents:                 amountCents(g.Award.CeilingAmount),
+		AwardFloorCents:                   amountCents(g.Award.FloorAmount),
+		EstimatedTotalProgramFundingCents: amountCents(g.Award.EstimatedTotalProgramFundingAmount),
+		ExpectedNumberOfAwards:            int64(g.Award.ExpectedNumberOfAwards),
+		CostSharingOrMatchingRequirement:  g.CostSharingOrMatchingRequirement,
+		CFDANumbers:                       joinStrings(g.CFDANumbers),
+		EligibleApplicantCodes:            joinList(g.EligibleApplicants, func(a usdr.Applicant) string { return string(a.Code) }),
+		EligibilityCategories:             joinList(g.Eligibility, func(e usdr.Eligibility) string { return string(e.Category) }),
+		FundingInstrumentCodes:            joinList(g.FundingInstrumentTypes, func(f usdr.FundingInstrument) string { return string(f.Code) }),
+		FundingActivityCategoryCodes:      joinList(g.FundingActivity.Categories, func(c usdr.FundingActivityCategory) string { return string(c.Code) }),
+		Bill:                              g.Bill,
+		GrantorEmail:                      g.Grantor.Email.Address,
+		RevisionID:                        g.Revision.Id.String(),
+		Description:                       g.Opportunity.Description,
+	}
+}
+
+func formatDate(d *usdr.Date) *string {
+	if d == nil {
+		return nil
+	}
+	s := time.Time(*d).Format(usdr.DateLayout)
+	return &s
+}
+
+// amountCents returns the amount in cents, or nil if the amount is missing or unspecified.
+func amountCents(a *usdr.Amount) *int64 {
+	if a == nil || a.Unspecified {
+		return nil
+	}
+	return &a.Cents
+}
+
+func joinList[S ~[]E, E any](values S, format func(E) string) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = format(v)
+	}
+	return strings.Join(parts, listSeparator)
+}
+
+func joinStrings[S ~[]E, E ~string](values S) string {
+	return joinList(values, func(v E) string { return string(v) })
+}
@@ -0,0 +1,207 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/usdr"
+)
+
+func testDate(year int, month time.Month, day int) *usdr.Date {
+	d := usdr.Date(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+	return &d
+}
+
+// testGrant returns a grant with every exported field set.
+func testGrant(t *testing.T) usdr.Grant {
+	t.Helper()
+	states, err := usdr.ApplicantFromCode("00")
+	require.NoError(t, err)
+	counties, err := usdr.ApplicantFromCode("01")
+	require.NoError(t, err)
+	grant, err := usdr.FundingInstrumentFromCode("G")
+	require.NoError(t, err)
+	energy, err := usdr.FundingActivityCategoryFromCode("EN")
+	require.NoError(t, err)
+	costSharing := true
+
+	g := usdr.Grant{
+		FundingInstrumentTypes:           []usdr.FundingInstrument{grant},
+		CostSharingOrMatchingRequirement: &costSharing,
+		Bill:                             "Infrastructure Investment and Jobs Act",
+		EligibleApplicants:               []usdr.Applicant{states, counties},
+		Eligibility: []usdr.Eligibility{
+			{Category: usdr.EligibilityStateGovernments},
+			{Category: usdr.EligibilityLocalGovernments},
+		},
+		Agency: usdr.Agency{
+			Code:           "DOE-GFO",
+			Name:           "Golden Field Office",
+			DepartmentCode: "DOE",
+			DepartmentName: "Department of Energy",
+		},
+		Award: usdr.Award{
+			Ceiling:                            "1000000",
+			Floor:                              "none",
+			EstimatedTotalProgramFunding:       "5000000",
+			CeilingAmount:                      &usdr.Amount{Cents: 100000000},
+			FloorAmount:                        &usdr.Amount{Unspecified: true},
+			EstimatedTotalProgramFundingAmount: &usdr.Amount{Cents: 500000000},
+			ExpectedNumberOfAwards:             5,
+		},
+		FundingActivity: usdr.FundingActivity{Categories: []usdr.FundingActivityCategory{energy}},
+		Grantor:         usdr.GrantorContact{Email: usdr.Email{Address: "grants@example.gov"}},
+		Opportunity: usdr.Opportunity{
+			Id:          "123456",
+			Number:      "DE-FOA-0001234",
+			Title:       "Clean energy, \"efficiency\"; and more",
+			Description: "Line one\nline two",
+			Category:    usdr.OpportunityCategory{Code: "D", Name: "Discretionary"},
+			Milestones: usdr.OpportunityMilestones{
+				PostDate:    testDate(2023, 5, 1),
+				Close:       usdr.CloseDate{Date: testDate(2023, 7, 31), Explanation: "Electronic only"},
+				ArchiveDate: testDate(2023, 8, 30),
+			},
+			LastUpdated: testDate(2023, 5, 15),
+		},
+		Revision: usdr.Revision{Id: ulid.MustParse("01H1X3QK8NMJ6G0V5AXN9V3EZZ")},
+	}
+	g.CFDANumbers = append(g.CFDANumbers, "81.086", "81.087")
+	return g
+}
+
+func TestFlattenGrant(t *testing.T) {
+	str := func(s string) *string { return &s }
+	cents := func(c int64) *int64 { return &c }
+	costSharing := true
+
+	assert.Equal(t, flatGrant{
+		GrantID:                           "123456",
+		OpportunityNumber:                 "DE-FOA-0001234",
+		Title:                             "Clean energy, \"efficiency\"; and more",
+		IsForecast:                        true,
+		CategoryCode:                      "D",
+		CategoryName:                      "Discretionary",
+		AgencyCode:                        "DOE-GFO",
+		AgencyName:                        "Golden Field Office",
+		AgencyDepartmentCode:              "DOE",
+		AgencyDepartmentName:              "Department of Energy",
+		PostDate:                          str("2023-05-01"),
+		CloseDate:                         str("2023-07-31"),
+		CloseDateExplanation:              "Electronic only",
+		ArchiveDate:                       str("2023-08-30"),
+		LastUpdated:                       str("2023-05-15"),
+		AwardCeiling:                      "1000000",
+		AwardFloor:                        "none",
+		EstimatedTotalProgramFunding:      "5000000",
+		AwardCeilingCents:                 cents(100000000),
+		AwardFloorCents:                   nil,
+		EstimatedTotalProgramFundingCents: cents(500000000),
+		ExpectedNumberOfAwards:            5,
+		CostSharingOrMatchingRequirement:  &costSharing,
+		CFDANumbers:                       "81.086;81.087",
+		EligibleApplicantCodes:            "00;01",
+		EligibilityCategories:             "state_governments;local_governments",
+		FundingInstrumentCodes:            "G",
+		FundingActivityCategoryCodes:      "EN",
+		Bill:                              "Infrastructure Investment and Jobs Act",
+		GrantorEmail:                      "grants@example.gov",
+		RevisionID:                        "01H1X3QK8NMJ6G0V5AXN9V3EZZ",
+		Description:                       "Line one\nline two",
+	}, flattenGrant(testGrant(t), true))
+
+	// Missing dates, amounts, and lists are empty rather than zero values
+	flat := flattenGrant(usdr.Grant{Opportunity: usdr.Opportunity{Id: "234567"}}, false)
+	assert.Nil(t, flat.PostDate)
+	assert.Nil(t, flat.CloseDate)
+	assert.Nil(t, flat.AwardCeilingCents)
+	assert.Nil(t, flat.CostSharingOrMatchingRequirement)
+	assert.Empty(t, flat.CFDANumbers)
+	assert.Empty(t, flat.EligibleApplicantCodes)
+}
+
+// writeGrants writes the grants in the given format, with every other grant written as a forecast.
+func writeGrants(t *testing.T, format string, grants []usdr.Grant) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw, err := newGrantWriter(format, &buf)
+	require.NoError(t, err)
+	for i, g := range grants {
+		require.NoError(t, gw.Write(g, i%2 == 1))
+	}
+	require.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+func TestGrantWriter(t *testing.T) {
+	grants := []usdr.Grant{
+		testGrant(t),
+		{Opportunity: usdr.Opportunity{Id: "234567", Title: "Sparse grant"}},
+	}
+	expected := []flatGrant{flattenGrant(grants[0], false), flattenGrant(grants[1], true)}
+
+	t.Run("jsonl", func(t *testing.T) {
+		dec := json.NewDecoder(bytes.NewReader(writeGrants(t, "jsonl", grants)))
+		for i, g := range grants {
+			var exported exportedGrant
+			require.NoError(t, dec.Decode(&exported))
+			assert.Equal(t, g, exported.Grant)
+			assert.Equal(t, i%2 == 1, exported.IsForecast)
+		}
+		assert.False(t, dec.More())
+	})
+
+	t.Run("parquet", func(t *testing.T) {
+		b := writeGrants(t, "parquet", grants)
+		rows, err := parquet.Read[flatGrant](bytes.NewReader(b), int64(len(b)))
+		require.NoError(t, err)
+		assert.Equal(t, expected, rows)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		records, err := csv.NewReader(bytes.NewReader(writeGrants(t, "csv", grants))).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 1+len(grants))
+
+		typ := reflect.TypeOf(flatGrant{})
+		for i, name := range records[0] {
+			assert.Equal(t, columnName(typ.Field(i)), name)
+		}
+		for i, row := range records[1:] {
+			v := reflect.ValueOf(expected[i])
+			for j, cell := range row {
+				assert.Equal(t, csvValue(v.Field(j)), cell, "%s of grant %d", records[0][j], i)
+			}
+		}
+		// Spot-check the formatting of values rather than only comparing them with csvValue
+		row := records[1]
+		column := func(name string) string {
+			for i, n := range records[0] {
+				if n == name {
+					return row[i]
+				}
+			}
+			t.Fatalf("missing column %s", name)
+			return ""
+		}
+		assert.Equal(t, "Clean energy, \"efficiency\"; and more", column("title"))
+		assert.Equal(t, "false", column("is_forecast"))
+		assert.Equal(t, "2023-07-31", column("close_date"))
+		assert.Equal(t, "100000000", column("award_ceiling_cents"))
+		assert.Equal(t, "", column("award_floor_cents"))
+		assert.Equal(t, "true", column("cost_sharing_or_matching_requirement"))
+		assert.Equal(t, "81.086;81.087", column("cfda_numbers"))
+		assert.Equal(t, "", records[2][1], "opportunity_number of the sparse grant")
+	})
+
+	_, err := newGrantWriter("xml", &bytes.Buffer{})
+	assert.ErrorContains(t, err, `unsupported export format "xml"`)
+}
@@ -0,0 +1,23 @@
+package ffis
+
+import (
+	"github.com/usdigitalresponse/grants-ingest/cli/grants-ingest/ffis/releaseEmail"
+)
+
+type Cmd struct {
+	// Sub-commands
+	Release releaseEmail.Cmd `cmd:"release" help:"Review a quarantined FFIS email and release it into the pipeline."`
+}
+
+func (cmd *Cmd) Help() string {
+	return `
+This command serves as the entrypoint for subcommands that operate on FFIS.org data in an
+environment, typically in order to recover from scenarios where the normal ingestion of FFIS.org
+data was interrupted.
+
+FFIS digest emails that cannot be parsed or fail any verification check (unrecognized sender,
+SPF, spam, or virus) are not processed further by the pipeline. Instead, they are copied to the
+"quarantine/" prefix of the source data bucket, where the object metadata records every check
+that failed. Use the release subcommand to review a quarantined email and, if it is determined
+to be legitimate, release it into the pipeline as though it had passed verification.`
+}
@@ -0,0 +1,199 @@
+package releaseEmail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsTransport "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/usdigitalresponse/grants-ingest/cli/profile"
+	"github.com/usdigitalresponse/grants-ingest/internal/awsHelpers"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+)
+
+type Cmd struct {
+	// Positional arguments
+	S3Bucket string `arg:"" optional:"" name:"bucket" help:"S3 bucket containing the quarantined email (default: the source data bucket of the selected profile)."`
+	S3Key    string `arg:"" optional:"" name:"key" help:"S3 key of the quarantined email."`
+
+	// Flags
+	DestinationBucket string    `help:"S3 bucket to which the email is released (defaults to <bucket>)."`
+	EmailDate         time.Time `format:"2006-01-02" placeholder:"YYYY-MM-DD" help:"Date used to determine the released S3 key, instead of the email's Date header."`
+	Overwrite         bool      `help:"Release the email even when an email was already received for the same date."`
+	KeepQuarantined   bool      `help:"Do not delete the quarantined email after it is released."`
+	S3UsePathStyle    bool      `name:"s3-use-path-style" help:"Use path-style addressing for S3 bucket."`
+	DryRun            bool      `help:"Dry run only - the quarantined email is reviewed but not released."`
+}
+
+var (
+	ErrMissingEmailDate = errors.New("could not determine email date; use --email-date to provide one")
+	ErrAlreadyReceived  = errors.New("an email was already received for this date; use --overwrite to replace it")
+)
+
+// reviewHeaders are the email headers displayed when reviewing a quarantined email.
+var reviewHeaders = []string{
+	"From", "To", "Subject", "Date",
+	"Received-SPF", "X-SES-Spam-Verdict", "X-SES-Virus-Verdict",
+}
+
+func (cmd *Cmd) Help() string {
+	return `
+Displays the metadata recorded when the email was quarantined (including the checks that it failed),
+along with the email headers that are relevant to its verification. Unless --dry-run is given,
+the email is then copied to the S3 key that would have been used had the email passed verification,
+i.e. "sources/YYYY/MM/DD/ffis.org/raw.eml", where the date is determined by the email's Date header
+(or --email-date, when given). This triggers the remainder of the FFIS.org ingestion pipeline.
+Finally, the quarantined email is deleted unless --keep-quarantined is given.
+
+When only one argument is given, it is the <key>, and <bucket> is the source data bucket of the
+selected profile.`
+}
+
+func (cmd *Cmd) AfterApply(selected *profile.Profile) error {
+	if cmd.S3Key == "" {
+		cmd.S3Bucket, cmd.S3Key = "", cmd.S3Bucket
+	}
+	if cmd.S3Key == "" {
+		return errors.New("missing <key>")
+	}
+	bucket, err := profile.Default(cmd.S3Bucket, selected.SourceDataBucket,
+		"<bucket>", "source_data_bucket")
+	if err != nil {
+		return err
+	}
+	cmd.S3Bucket = bucket
+	return nil
+}
+
+// Destructive reports whether the command releases the email, which may replace an email that was
+// already received and deletes the quarantined email.
+func (cmd *Cmd) Destructive() bool {
+	return !cmd.DryRun
+}
+
+func (cmd *Cmd) Run(app *kong.Kong, baseLogger *log.Logger) error {
+	ctx := context.Background()
+	cfg, err := awsHelpers.GetConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to configure AWS SDK: %w", err)
+	}
+	s3svc := s3.NewFromConfig(cfg, func(o *s3.Options) { o.UsePathStyle = cmd.S3UsePathStyle })
+	if cmd.DestinationBucket == "" {
+		cmd.DestinationBucket = cmd.S3Bucket
+	}
+	logger := log.With(*baseLogger, "source", fmt.Sprintf("s3://%s/%s", cmd.S3Bucket, cmd.S3Key))
+
+	resp, err := s3svc.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(cmd.S3Bucket),
+		Key:    aws.String(cmd.S3Key),
+	})
+	if err != nil {
+		return log.Errorf(logger, "Error retrieving quarantined email from S3", err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return log.Errorf(logger, "Error reading quarantined email from S3", err)
+	}
+
+	msg, parseErr := mail.ReadMessage(bytes.NewReader(raw))
+	cmd.printReview(app.Stdout, resp.Metadata, msg, parseErr)
+
+	emailDate := cmd.EmailDate
+	if emailDate.IsZero() {
+		if parseErr != nil {
+			return ErrMissingEmailDate
+		}
+		if emailDate, err = msg.Header.Date(); err != nil {
+			return ErrMissingEmailDate
+		}
+	}
+	destKey := fmt.Sprintf("sources/%s/ffis.org/raw.eml", emailDate.Format("2006/01/02"))
+	logger = log.With(logger, "destination", fmt.Sprintf("s3://%s/%s", cmd.DestinationBucket, destKey))
+
+	if !cmd.Overwrite {
+		exists, err := objectExists(ctx, s3svc, cmd.DestinationBucket, destKey)
+		if err != nil {
+			return log.Errorf(logger, "Error checking for previously-received email", err)
+		}
+		if exists {
+			return ErrAlreadyReceived
+		}
+	}
+
+	if cmd.DryRun {
+		log.Info(logger, "Dry run - quarantined email would be released")
+		return nil
+	}
+
+	if _, err := s3svc.CopyObject(ctx, &s3.CopyObjectInput{
+		CopySource:           aws.String(filepath.Join(cmd.S3Bucket, cmd.S3Key)),
+		Bucket:               aws.String(cmd.DestinationBucket),
+		Key:                  aws.String(destKey),
+		MetadataDirective:    types.MetadataDirectiveReplace,
+		Metadata:             map[string]string{"released-from": cmd.S3Key},
+		ServerSideEncryption: types.ServerSideEncryptionAes256,
+	}); err != nil {
+		return log.Errorf(logger, "Error releasing quarantined email", err)
+	}
+	log.Info(logger, "Released quarantined email")
+
+	if !cmd.KeepQuarantined {
+		if _, err := s3svc.DeleteObject(ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String(cmd.S3Bucket),
+			Key:    aws.String(cmd.S3Key),
+		}); err != nil {
+			return log.Errorf(logger, "Error deleting released email from quarantine", err)
+		}
+		log.Info(logger, "Deleted released email from quarantine")
+	}
+
+	return nil
+}
+
+func (cmd *Cmd) printReview(w io.Writer, metadata map[string]string, msg *mail.Message, parseErr error) {
+	fmt.Fprintf(w, "Quarantined email: s3://%s/%s\n\nQuarantine metadata:\n", cmd.S3Bucket, cmd.S3Key)
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "  %s: %s\n", k, metadata[k])
+	}
+
+	fmt.Fprintln(w, "\nEmail headers:")
+	if parseErr != nil {
+		fmt.Fprintf(w, "  (email could not be parsed: %s)\n", parseErr)
+	} else {
+		for _, h := range reviewHeaders {
+			fmt.Fprintf(w, "  %s: %s\n", h, msg.Header.Get(h))
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+func objectExists(ctx context.Context, c s3.HeadObjectAPIClient, bucket, key string) (bool, error) {
+	_, err := c.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var respError *awsTransport.ResponseError
+		if errors.As(err, &respError) && respError.ResponseError.HTTPStatusCode() == 404 {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/alecthomas/kong"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/usdigitalresponse/grants-ingest/cli/profile"
 	"github.com/usdigitalresponse/grants-ingest/internal/awsHelpers"
 	"github.com/usdigitalresponse/grants-ingest/internal/log"
 )
@@ -14,7 +15,7 @@ import (
 type Cmd struct {
 	// Positional arguments
 	SourceDirectory string `arg:"" name:"directory" type:"existingdir" predictor:"dir" help:"Source directory containing FFIS spreadsheets"`
-	S3Bucket        string `arg:"" name:"bucket" help:"Destination S3 bucket name"`
+	S3Bucket        string `arg:"" optional:"" name:"bucket" help:"Destination S3 bucket name (default: the source data bucket of the selected profile)"`
 
 	// Flags
 	S3Prefix       string        `name:"s3-prefix" help:"Path prefix for mapped S3 keys" default:"sources"`
@@ -36,6 +37,21 @@ the S3 key are determined by the publishing date.`,
 		publishDateSheet, publishDateCell)
 }
 
+func (cmd *Cmd) AfterApply(selected *profile.Profile) error {
+	bucket, err := profile.Default(cmd.S3Bucket, selected.SourceDataBucket,
+		"<bucket>", "source_data_bucket")
+	if err != nil {
+		return err
+	}
+	cmd.S3Bucket = bucket
+	return nil
+}
+
+// Destructive reports whether the command uploads files, which replace any existing objects.
+func (cmd *Cmd) Destructive() bool {
+	return !cmd.DryRun
+}
+
 func (cmd *Cmd) Run(app *kong.Kong, logger *log.Logger) error {
 	ctx := context.Background()
 	cfg, err := awsHelpers.GetConfig(ctx)
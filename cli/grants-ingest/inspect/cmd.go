@@ -0,0 +1,278 @@
+package inspect
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"regexp"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsTransport "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/hashicorp/go-multierror"
+	"github.com/usdigitalresponse/grants-ingest/internal/awsHelpers"
+	"github.com/usdigitalresponse/grants-ingest/internal/itemMapper"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+	"github.com/usdigitalresponse/grants-ingest/internal/preparedData"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/usdr"
+)
+
+var grantIDPattern = regexp.MustCompile(`^\d{3,}$`)
+
+type Cmd struct {
+	// Positional arguments
+	GrantID string `arg:"" name:"grant-id" help:"ID of the grant to inspect."`
+
+	// Flags
+	PreparedDataBucket string `required:"" env:"GRANTS_PREPARED_DATA_BUCKET_NAME" help:"Name of the S3 bucket containing grants prepared data."`
+	PreparedDataTable  string `required:"" env:"GRANTS_PREPARED_DYNAMODB_NAME" help:"Name of the DynamoDB table containing grants prepared data."`
+	JSON               bool   `name:"json" help:"Print the report as JSON instead of human-readable text."`
+	S3UsePathStyle     bool   `name:"s3-use-path-style" help:"Use path-style addressing for S3 bucket."`
+
+	// Internal
+	ctx  context.Context
+	stop context.CancelFunc
+	s3   *s3.Client
+	ddb  *dynamodb.Client
+}
+
+// Report describes the data stored for a grant across the prepared-data stores.
+type Report struct {
+	GrantID      string         `json:"grant_id"`
+	S3Objects    []S3Object     `json:"s3_objects"`
+	DynamoDBItem map[string]any `json:"dynamodb_item"`
+	// The grant that would be published by PublishGrantEvents for the DynamoDB item
+	Grant            *usdr.Grant      `json:"grant"`
+	MappingError     string           `json:"mapping_error,omitempty"`
+	MalformedFields  []MalformedField `json:"malformed_fields"`
+	ValidationErrors []string         `json:"validation_errors"`
+}
+
+// S3Object describes a prepared-data S3 object for the grant.
+type S3Object struct {
+	Key          string     `json:"key"`
+	Exists       bool       `json:"exists"`
+	LastModified *time.Time `json:"last_modified,omitempty"`
+	ETag         string     `json:"etag,omitempty"`
+	Size         int64      `json:"size,omitempty"`
+	Content      string     `json:"content,omitempty"`
+}
+
+// MalformedField is an item attribute that could not be mapped as expected.
+type MalformedField struct {
+	Field string `json:"field"`
+	Error string `json:"error,omitempty"`
+}
+
+func (cmd *Cmd) Help() string {
+	return `
+Fetches everything stored for a single grant in the prepared-data S3 bucket and DynamoDB table.
+The DynamoDB item is mapped to the grant data that would be published by PublishGrantEvents,
+and any malformed item attributes and grant data validation errors are reported.
+
+The bucket and table names may be provided with the GRANTS_PREPARED_DATA_BUCKET_NAME
+and GRANTS_PREPARED_DYNAMODB_NAME environment variables, respectively.`
+}
+
+func (cmd *Cmd) Validate() error {
+	if !grantIDPattern.MatchString(cmd.GrantID) {
+		return fmt.Errorf("invalid grant ID %q: must be numeric with at least 3 digits", cmd.GrantID)
+	}
+	return nil
+}
+
+func (cmd *Cmd) BeforeApply() error {
+	cmd.ctx, cmd.stop = signal.NotifyContext(context.Background(),
+		syscall.SIGHUP, syscall.SIGINT, os.Interrupt)
+	return nil
+}
+
+func (cmd *Cmd) AfterApply() error {
+	cfg, err := awsHelpers.GetConfig(cmd.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to configure AWS SDK: %w", err)
+	}
+	cmd.s3 = s3.NewFromConfig(cfg, func(o *s3.Options) { o.UsePathStyle = cmd.S3UsePathStyle })
+	cmd.ddb = dynamodb.NewFromConfig(cfg)
+	return nil
+}
+
+func (cmd *Cmd) Run(app *kong.Kong, baseLogger *log.Logger) error {
+	defer cmd.stop()
+	logger := log.With(*baseLogger, "grant_id", cmd.GrantID)
+
+	report := Report{
+		GrantID:          cmd.GrantID,
+		S3Objects:        []S3Object{},
+		MalformedFields:  []MalformedField{},
+		ValidationErrors: []string{},
+	}
+
+	for _, layout := range preparedData.Layouts {
+		key := layout.Key(cmd.GrantID)
+		obj, err := cmd.getS3Object(key)
+		if err != nil {
+			return log.Errorf(logger, "Error retrieving prepared data from S3", err,
+				"bucket", cmd.PreparedDataBucket, "key", key)
+		}
+		report.S3Objects = append(report.S3Objects, obj)
+	}
+
+	resp, err := cmd.ddb.GetItem(cmd.ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(cmd.PreparedDataTable),
+		Key:            map[string]types.AttributeValue{"grant_id": &types.AttributeValueMemberS{Value: cmd.GrantID}},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return log.Errorf(logger, "Error retrieving item from DynamoDB", err,
+			"table", cmd.PreparedDataTable)
+	}
+	if resp.Item != nil {
+		if err := attributevalue.UnmarshalMap(resp.Item, &report.DynamoDBItem); err != nil {
+			return log.Errorf(logger, "Error unmarshaling DynamoDB item", err)
+		}
+		inspectItem(resp.Item, &report)
+	}
+
+	if cmd.JSON {
+		enc := json.NewEncoder(app.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	return printReport(app.Stdout, report)
+}
+
+func (cmd *Cmd) getS3Object(key string) (S3Object, error) {
+	obj := S3Object{Key: key}
+	resp, err := cmd.s3.GetObject(cmd.ctx, &s3.GetObjectInput{
+		Bucket: aws.String(cmd.PreparedDataBucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var respError *awsTransport.ResponseError
+		if errors.As(err, &respError) && respError.ResponseError.HTTPStatusCode() == 404 {
+			return obj, nil
+		}
+		return obj, err
+	}
+	defer resp.Body.Close()
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return obj, err
+	}
+	obj.Exists = true
+	obj.LastModified = resp.LastModified
+	obj.ETag = strings.Trim(aws.ToString(resp.ETag), `"`)
+	obj.Size = int64(len(content))
+	obj.Content = string(content)
+	return obj, nil
+}
+
+// inspectItem maps the DynamoDB item to a grant in the same manner as PublishGrantEvents,
+// recording the mapped grant and any problems encountered in the report.
+func inspectItem(item map[string]types.AttributeValue, report *Report) {
+	image, err := itemMapper.FromAttributeValueMap(item)
+	if err != nil {
+		report.MappingError = err.Error()
+		return
+	}
+	mapper := itemMapper.NewItemMapper(image, func(name string, err error) {
+		field := MalformedField{Field: name}
+		if err != nil {
+			field.Error = err.Error()
+		}
+		report.MalformedFields = append(report.MalformedFields, field)
+	})
+	grant, err := itemMapper.GuardPanic(mapper.Grant)
+	if err != nil {
+		report.MappingError = err.Error()
+		return
+	}
+	report.Grant = &grant
+
+	if err := grant.Validate(); err != nil {
+		var merr *multierror.Error
+		if errors.As(err, &merr) {
+			for _, e := range merr.Errors {
+				report.ValidationErrors = append(report.ValidationErrors, e.Error())
+			}
+		} else {
+			report.ValidationErrors = append(report.ValidationErrors, err.Error())
+		}
+	}
+}
+
+func printReport(w io.Writer, report Report) error {
+	fmt.Fprintf(w, "Grant %s\n\n", report.GrantID)
+
+	fmt.Fprintln(w, "S3 objects:")
+	for _, obj := range report.S3Objects {
+		if !obj.Exists {
+			fmt.Fprintf(w, "  %s: (not found)\n", obj.Key)
+			continue
+		}
+		fmt.Fprintf(w, "  %s: %d bytes, last modified %s, ETag %s\n",
+			obj.Key, obj.Size, obj.LastModified.Format(time.RFC3339), obj.ETag)
+		for _, line := range strings.Split(strings.TrimSpace(obj.Content), "\n") {
+			fmt.Fprintf(w, "    %s\n", line)
+		}
+	}
+
+	fmt.Fprintln(w, "\nDynamoDB item:")
+	if report.DynamoDBItem == nil {
+		fmt.Fprintln(w, "  (not found)")
+		return nil
+	}
+	keys := make([]string, 0, len(report.DynamoDBItem))
+	for k := range report.DynamoDBItem {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "  %s: %v\n", k, report.DynamoDBItem[k])
+	}
+
+	fmt.Fprintln(w, "\nMapped grant:")
+	if report.MappingError != "" {
+		fmt.Fprintf(w, "  (could not be mapped: %s)\n", report.MappingError)
+	} else {
+		b, err := json.MarshalIndent(report.Grant, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "  %s\n", b)
+	}
+
+	fmt.Fprintln(w, "\nMalformed fields:")
+	if len(report.MalformedFields) == 0 {
+		fmt.Fprintln(w, "  (none)")
+	}
+	for _, f := range report.MalformedFields {
+		if f.Error != "" {
+			fmt.Fprintf(w, "  %s: %s\n", f.Field, f.Error)
+		} else {
+			fmt.Fprintf(w, "  %s\n", f.Field)
+		}
+	}
+
+	fmt.Fprintln(w, "\nValidation errors:")
+	if len(report.ValidationErrors) == 0 {
+		fmt.Fprintln(w, "  (none)")
+	}
+	for _, e := range report.ValidationErrors {
+		fmt.Fprintf(w, "  %s\n", e)
+	}
+	return nil
+}
@@ -0,0 +1,332 @@
+package localRun
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+
+	goenv "github.com/Netflix/go-env"
+	"github.com/alecthomas/kong"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/usdigitalresponse/grants-ingest/internal/assistanceListings"
+	"github.com/usdigitalresponse/grants-ingest/internal/lambdas/extractGrantsGovDBToXML"
+	"github.com/usdigitalresponse/grants-ingest/internal/lambdas/persistFFISData"
+	"github.com/usdigitalresponse/grants-ingest/internal/lambdas/persistGrantsGovXMLDB"
+	"github.com/usdigitalresponse/grants-ingest/internal/lambdas/publishGrantEvents"
+	"github.com/usdigitalresponse/grants-ingest/internal/lambdas/splitFFISSpreadsheet"
+	"github.com/usdigitalresponse/grants-ingest/internal/lambdas/splitGrantsGovXMLDB"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+	"github.com/usdigitalresponse/grants-ingest/internal/preparedData"
+)
+
+// Names of the in-memory resources that stand in for those provisioned by Terraform
+const (
+	sourceDataBucket   = "local-grants-source-data"
+	preparedDataBucket = "local-grants-prepared-data"
+	preparedDataTable  = "local-grants-prepared-data"
+	eventBusName       = "local-grants"
+)
+
+type Cmd struct {
+	// Flags
+	GrantsGovExtract   string    `name:"grants-gov-extract" type:"existingfile" predictor:"file" help:"Path to a GrantsDBExtract zip archive from Grants.gov."`
+	FFISSpreadsheet    string    `name:"ffis-spreadsheet" type:"existingfile" predictor:"file" help:"Path to an FFIS xlsx spreadsheet."`
+	AssistanceListings string    `name:"assistance-listings" type:"existingfile" predictor:"file" help:"Path to a SAM.gov Assistance Listings extract (CSV or JSON) used to enrich published grants."`
+	Output             string    `short:"o" type:"path" predictor:"file" help:"Write events to this JSONL file instead of stdout."`
+	SourceDate         time.Time `name:"source-date" format:"2006-01-02" help:"Date (YYYY-MM-DD) used in source data S3 keys. Defaults to today."`
+	ForecastedGrants   bool      `name:"forecasted-grants" negatable:"" default:"true" help:"Process forecasted grants from the Grants.gov extract."`
+	StrictValidation   bool      `name:"strict-validation" help:"Skip Grants.gov records that violate the constraints of the Grants.gov XML schema."`
+	StreamBatchSize    int       `name:"stream-batch-size" default:"100" help:"Maximum number of DynamoDB stream records per PublishGrantEvents invocation."`
+
+	// Internal
+	ctx  context.Context
+	stop context.CancelFunc
+}
+
+func (cmd *Cmd) Help() string {
+	return `
+Runs the ingestion pipeline on this machine, without AWS or LocalStack, and outputs the resulting
+GrantModificationEvents as JSON lines. The Lambda handlers of ExtractGrantsGovDBToXML,
+SplitGrantsGovXMLDB, PersistGrantsGovXMLDB, SplitFFISSpreadsheet, PersistFFISData, and
+PublishGrantEvents are invoked in-process against an in-memory S3 service and an in-memory
+DynamoDB table with a simulated stream.
+
+Each source file is added to the in-memory source data bucket at the same key used by the
+deployed pipeline (e.g. "sources/YYYY/MM/DD/grants.gov/archive.zip"). Handlers are invoked as
+their S3 bucket notifications would invoke them, one object per invocation. The Grants.gov
+extract is processed before the FFIS spreadsheet. Stream records are published after all
+sources are processed. When an Assistance Listings extract is given, it is imported to the
+in-memory prepared data bucket before any source is processed, and published grants are
+enriched with it.
+
+Failed invocations are logged and not retried. The command exits with an error if any
+S3-triggered invocation failed. Stream records that fail to publish (e.g. FFIS data for grants
+absent from the Grants.gov extract) are logged and discarded.`
+}
+
+func (cmd *Cmd) Validate() error {
+	if cmd.GrantsGovExtract == "" && cmd.FFISSpreadsheet == "" {
+		return fmt.Errorf("at least one of --grants-gov-extract or --ffis-spreadsheet is required")
+	}
+	if cmd.StreamBatchSize < 1 {
+		return fmt.Errorf("--stream-batch-size must be at least 1")
+	}
+	return nil
+}
+
+func (cmd *Cmd) BeforeApply() error {
+	cmd.ctx, cmd.stop = signal.NotifyContext(context.Background(),
+		syscall.SIGHUP, syscall.SIGINT, os.Interrupt)
+	return nil
+}
+
+func (cmd *Cmd) Run(app *kong.Kong, baseLogger *log.Logger) error {
+	defer cmd.stop()
+	logger := *baseLogger
+
+	var out io.Writer = app.Stdout
+	if cmd.Output != "" {
+		f, err := os.Create(cmd.Output)
+		if err != nil {
+			return log.Errorf(logger, "Error creating output file", err, "path", cmd.Output)
+		}
+		defer f.Close()
+		out = f
+	}
+	bw := bufio.NewWriter(out)
+	defer bw.Flush()
+
+	backend := newNotifyingBackend()
+	s3svc, stopS3 := startS3Server(backend)
+	defer stopS3()
+	for _, bucket := range []string{sourceDataBucket, preparedDataBucket} {
+		if _, err := s3svc.CreateBucket(cmd.ctx, &s3.CreateBucketInput{Bucket: aws.String(bucket)}); err != nil {
+			return log.Errorf(logger, "Error creating in-memory S3 bucket", err, "bucket", bucket)
+		}
+	}
+	table := newMemoryTable(preparedDataTable, "grant_id")
+	if cmd.AssistanceListings != "" {
+		if err := cmd.importAssistanceListings(s3svc); err != nil {
+			return log.Errorf(logger, "Error importing Assistance Listings extract", err,
+				"path", cmd.AssistanceListings)
+		}
+	}
+
+	p := &pipeline{
+		logger:          logger,
+		s3:              backend,
+		s3Client:        s3svc,
+		table:           table,
+		publisher:       &eventWriter{w: bw},
+		streamBatchSize: cmd.StreamBatchSize,
+		stats:           map[string]*invocationStats{},
+	}
+	triggers, err := cmd.configureLambdas(logger, s3svc, table)
+	if err != nil {
+		return log.Errorf(logger, "Error configuring Lambda handlers", err)
+	}
+	p.triggers = triggers
+
+	sourceDate := cmd.SourceDate
+	if sourceDate.IsZero() {
+		sourceDate = time.Now()
+	}
+	sourcePrefix := path.Join("sources", sourceDate.Format("2006/01/02"))
+	for _, src := range []struct{ file, key string }{
+		{cmd.GrantsGovExtract, path.Join(sourcePrefix, "grants.gov/archive.zip")},
+		{cmd.FFISSpreadsheet, path.Join(sourcePrefix, "ffis.org/download.xlsx")},
+	} {
+		if src.file == "" {
+			continue
+		}
+		logger := log.With(logger, "source_file", src.file, "key", src.key)
+		if err := cmd.uploadSource(s3svc, src.file, src.key); err != nil {
+			return log.Errorf(logger, "Error adding source file to in-memory S3 bucket", err)
+		}
+		log.Info(logger, "Processing source file")
+		if err := p.processNotifications(cmd.ctx); err != nil {
+			return log.Errorf(logger, "Processing interrupted", err)
+		}
+	}
+
+	log.Info(logger, "Publishing DynamoDB stream records")
+	if err := p.publishStream(cmd.ctx); err != nil {
+		return log.Errorf(logger, "Publishing interrupted", err)
+	}
+	if err := bw.Flush(); err != nil {
+		return log.Errorf(logger, "Error writing events", err)
+	}
+
+	return cmd.summarize(logger, p)
+}
+
+// configureLambdas configures each Lambda handler package for the in-memory resources and
+// returns the triggers that invoke the S3-triggered handlers.
+func (cmd *Cmd) configureLambdas(logger log.Logger, s3svc *s3.Client, table *memoryTable) ([]trigger, error) {
+	lambdaLogger := func(name string) log.Logger {
+		return log.With(logger, "lambda", name)
+	}
+
+	var extractEnv extractGrantsGovDBToXML.Environment
+	if err := goenv.Unmarshal(goenv.EnvSet{"S3_USE_PATH_STYLE": "true"}, &extractEnv); err != nil {
+		return nil, err
+	}
+	extractGrantsGovDBToXML.Configure(extractEnv, lambdaLogger("ExtractGrantsGovDBToXML"))
+
+	var splitGovEnv splitGrantsGovXMLDB.Environment
+	if err := goenv.Unmarshal(goenv.EnvSet{
+		"GRANTS_PREPARED_DATA_BUCKET_NAME": preparedDataBucket,
+		"GRANTS_PREPARED_DATA_TABLE_NAME":  preparedDataTable,
+		"S3_USE_PATH_STYLE":                "true",
+		"IS_FORECASTED_GRANTS_ENABLED":     fmt.Sprint(cmd.ForecastedGrants),
+		"IS_STRICT_VALIDATION_ENABLED":     fmt.Sprint(cmd.StrictValidation),
+	}, &splitGovEnv); err != nil {
+		return nil, err
+	}
+	splitGrantsGovXMLDB.Configure(splitGovEnv, lambdaLogger("SplitGrantsGovXMLDB"))
+
+	var persistGovEnv persistGrantsGovXMLDB.Environment
+	if err := goenv.Unmarshal(goenv.EnvSet{
+		"GRANTS_PREPARED_DYNAMODB_NAME": preparedDataTable,
+		"S3_USE_PATH_STYLE":             "true",
+	}, &persistGovEnv); err != nil {
+		return nil, err
+	}
+	persistGrantsGovXMLDB.Configure(persistGovEnv, lambdaLogger("PersistGrantsGovXMLDB"))
+
+	var splitFFISEnv splitFFISSpreadsheet.Environment
+	if err := goenv.Unmarshal(goenv.EnvSet{
+		"GRANTS_PREPARED_DATA_BUCKET_NAME": preparedDataBucket,
+		"GRANTS_PREPARED_DYNAMODB_NAME":    preparedDataTable,
+		"S3_USE_PATH_STYLE":                "true",
+	}, &splitFFISEnv); err != nil {
+		return nil, err
+	}
+	splitFFISSpreadsheet.Configure(splitFFISEnv, lambdaLogger("SplitFFISSpreadsheet"))
+
+	var persistFFISEnv persistFFISData.Environment
+	if err := goenv.Unmarshal(goenv.EnvSet{
+		"GRANTS_PREPARED_DYNAMODB_NAME": preparedDataTable,
+		"S3_USE_PATH_STYLE":             "true",
+	}, &persistFFISEnv); err != nil {
+		return nil, err
+	}
+	persistFFISData.Configure(persistFFISEnv, lambdaLogger("PersistFFISData"))
+
+	publishEnvSet := goenv.EnvSet{"EVENT_BUS_NAME": eventBusName}
+	if cmd.AssistanceListings != "" {
+		publishEnvSet["GRANTS_PREPARED_DATA_BUCKET_NAME"] = preparedDataBucket
+		publishEnvSet["S3_USE_PATH_STYLE"] = "true"
+	}
+	var publishEnv publishGrantEvents.Environment
+	if err := goenv.Unmarshal(publishEnvSet, &publishEnv); err != nil {
+		return nil, err
+	}
+	publishGrantEvents.Configure(publishEnv, lambdaLogger("PublishGrantEvents"))
+
+	persistGov := func(ctx context.Context, e events.S3Event) error {
+		return persistGrantsGovXMLDB.HandleS3EventWithConfig(s3svc, table, ctx, e)
+	}
+	return []trigger{
+		{"ExtractGrantsGovDBToXML", sourceDataBucket, "sources/", "/grants.gov/archive.zip",
+			func(ctx context.Context, e events.S3Event) error {
+				return extractGrantsGovDBToXML.HandleS3Event(ctx, s3svc, e)
+			}},
+		{"SplitGrantsGovXMLDB", sourceDataBucket, "sources/", "/grants.gov/extract.xml",
+			func(ctx context.Context, e events.S3Event) error {
+				return splitGrantsGovXMLDB.HandleS3Event(ctx, s3svc, table, e)
+			}},
+		{"SplitFFISSpreadsheet", sourceDataBucket, "sources/", "/ffis.org/download.xlsx",
+			func(ctx context.Context, e events.S3Event) error {
+				return splitFFISSpreadsheet.HandleS3Event(ctx, s3svc, table, e)
+			}},
+		{"PersistGrantsGovXMLDB", preparedDataBucket, "", preparedData.GrantsGovLegacy.Suffix(), persistGov},
+		{"PersistGrantsGovXMLDB", preparedDataBucket, "", preparedData.GrantsGovOpportunity.Suffix(), persistGov},
+		{"PersistGrantsGovXMLDB", preparedDataBucket, "", preparedData.GrantsGovForecast.Suffix(), persistGov},
+		{"PersistFFISData", preparedDataBucket, "", preparedData.FFIS.Suffix(),
+			func(ctx context.Context, e events.S3Event) error {
+				return persistFFISData.HandleS3Event(ctx, e, s3svc, table)
+			}},
+	}, nil
+}
+
+func (cmd *Cmd) uploadSource(s3svc *s3.Client, file, key string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = s3svc.PutObject(cmd.ctx, &s3.PutObjectInput{
+		Bucket: aws.String(sourceDataBucket),
+		Key:    aws.String(key),
+		Body:   f,
+	})
+	return err
+}
+
+// importAssistanceListings adds the catalog of the Assistance Listings extract to the
+// in-memory prepared data bucket, as the assistance-listings-import command would.
+func (cmd *Cmd) importAssistanceListings(s3svc *s3.Client) error {
+	format, err := assistanceListings.FormatOf(cmd.AssistanceListings)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(cmd.AssistanceListings)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	catalog, err := assistanceListings.FromExtract(f, format, filepath.Base(cmd.AssistanceListings), time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	if err := catalog.WriteJSON(&b); err != nil {
+		return err
+	}
+	_, err = s3svc.PutObject(cmd.ctx, &s3.PutObjectInput{
+		Bucket: aws.String(preparedDataBucket),
+		Key:    aws.String(preparedData.AssistanceListingsKey),
+		Body:   bytes.NewReader(b.Bytes()),
+	})
+	return err
+}
+
+// summarize logs the outcome of the run and returns an error if any S3-triggered invocation failed.
+func (cmd *Cmd) summarize(logger log.Logger, p *pipeline) error {
+	lambdas := make([]string, 0, len(p.stats))
+	for name := range p.stats {
+		lambdas = append(lambdas, name)
+	}
+	sort.Strings(lambdas)
+
+	var failures int
+	for _, name := range lambdas {
+		stats := p.stats[name]
+		failures += stats.Failures
+		log.Info(logger, "Lambda handler invocations", "lambda", name,
+			"count_invocations", stats.Invocations, "count_failures", stats.Failures)
+	}
+	if p.failedStreamRecords > 0 {
+		// e.g. FFIS data for grants that are absent from the Grants.gov extract
+		log.Warn(logger, "Some DynamoDB stream records could not be published; check logs for details",
+			"count_failed_stream_records", p.failedStreamRecords)
+	}
+	log.Info(logger, "Local run complete", "count_events", p.publisher.count)
+
+	if failures > 0 {
+		return fmt.Errorf("%d Lambda handler invocations failed; check logs for details", failures)
+	}
+	return nil
+}
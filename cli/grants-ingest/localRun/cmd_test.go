@@ -0,0 +1,94 @@
+package localRun
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alecthomas/kong"
+	kitLog "github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/usdr"
+)
+
+// writeExtract writes a Grants.gov extract archive containing fixtures/extract.xml to dir.
+func writeExtract(t *testing.T, dir string) string {
+	t.Helper()
+	xml, err := os.ReadFile("fixtures/extract.xml")
+	require.NoError(t, err)
+	path := filepath.Join(dir, "GrantsDBExtract20230515v2.zip")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+	z := zip.NewWriter(f)
+	w, err := z.Create("GrantsDBExtract20230515v2.xml")
+	require.NoError(t, err)
+	_, err = w.Write(xml)
+	require.NoError(t, err)
+	require.NoError(t, z.Close())
+	return path
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "events.jsonl")
+
+	var cli struct {
+		LocalRun Cmd `cmd:""`
+	}
+	var logger log.Logger = kitLog.NewNopLogger()
+	parser, err := kong.New(&cli, kong.Bind(&logger))
+	require.NoError(t, err)
+	ctx, err := parser.Parse([]string{"local-run",
+		"--grants-gov-extract", writeExtract(t, dir),
+		"--ffis-spreadsheet", "fixtures/ffis.xlsx",
+		"--source-date", "2023-05-15",
+		"-o", output,
+	})
+	require.NoError(t, err)
+	// FFIS data for grants that are absent from the extract fail to publish without failing the run
+	require.NoError(t, ctx.Run())
+
+	f, err := os.Open(output)
+	require.NoError(t, err)
+	defer f.Close()
+	var received []usdr.GrantModificationEvent
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		var event usdr.GrantModificationEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		require.NoError(t, event.Validate())
+		received = append(received, event)
+	}
+	require.NoError(t, scanner.Err())
+
+	type summary struct {
+		Type     string
+		GrantID  string
+		Previous bool
+		Bill     string
+	}
+	summaries := make([]summary, 0, len(received))
+	for _, event := range received {
+		summaries = append(summaries, summary{
+			Type:     string(event.Type),
+			GrantID:  event.Versions.New.Opportunity.Id,
+			Previous: event.Versions.Previous != nil,
+			Bill:     event.Versions.New.Bill,
+		})
+	}
+	// Grants from the extract are created, and grant 123456 is then updated with its FFIS data
+	// (grants from the extract are split concurrently, so their order may vary)
+	assert.ElementsMatch(t, []summary{
+		{"create", "123456", false, ""},
+		{"create", "234567", false, ""},
+		{"update", "123456", true, "Infrastructure Investment and Jobs Act"},
+	}, summaries)
+	assert.Equal(t, "update", summaries[len(summaries)-1].Type, "The update should follow the creates")
+}
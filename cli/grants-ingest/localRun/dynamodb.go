@@ -0,0 +1,236 @@
+package localRun
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/usdigitalresponse/grants-ingest/internal/itemMapper"
+)
+
+// memoryTable is an in-memory stand-in for the prepared-data DynamoDB table. It supports the
+// subset of the GetItem and UpdateItem APIs used by the pipeline Lambda handlers, and records
+// each change to an item as a stream record with both new and old item images.
+type memoryTable struct {
+	name    string
+	hashKey string
+
+	mu       sync.Mutex
+	items    map[string]map[string]types.AttributeValue
+	stream   []events.DynamoDBEventRecord
+	sequence int
+}
+
+func newMemoryTable(name, hashKey string) *memoryTable {
+	return &memoryTable{
+		name:    name,
+		hashKey: hashKey,
+		items:   map[string]map[string]types.AttributeValue{},
+	}
+}
+
+func (t *memoryTable) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	key, err := t.keyValue(params.TableName, params.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	item, exists := t.items[key]
+	if !exists {
+		return &dynamodb.GetItemOutput{}, nil
+	}
+	if params.ProjectionExpression == nil {
+		return &dynamodb.GetItemOutput{Item: copyItem(item)}, nil
+	}
+
+	paths, err := parseProjection(*params.ProjectionExpression, params.ExpressionAttributeNames)
+	if err != nil {
+		return nil, err
+	}
+	projected := map[string]types.AttributeValue{}
+	for _, path := range paths {
+		if v, ok := item[path]; ok {
+			projected[path] = v
+		}
+	}
+	return &dynamodb.GetItemOutput{Item: projected}, nil
+}
+
+func (t *memoryTable) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	key, err := t.keyValue(params.TableName, params.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	oldItem, exists := t.items[key]
+	if params.ConditionExpression != nil {
+		ok, err := evaluateCondition(*params.ConditionExpression, oldItem,
+			params.ExpressionAttributeNames, params.ExpressionAttributeValues)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, &types.ConditionalCheckFailedException{
+				Message: aws.String("The conditional request failed"),
+			}
+		}
+	}
+
+	newItem := copyItem(oldItem)
+	for k, v := range params.Key {
+		newItem[k] = v
+	}
+	if params.UpdateExpression != nil {
+		if err := applyUpdate(*params.UpdateExpression, newItem,
+			params.ExpressionAttributeNames, params.ExpressionAttributeValues); err != nil {
+			return nil, err
+		}
+	}
+	t.items[key] = newItem
+
+	// As with DynamoDB Streams, no stream record is written when an item is not modified.
+	if exists && reflect.DeepEqual(oldItem, newItem) {
+		return &dynamodb.UpdateItemOutput{}, nil
+	}
+	eventName := events.DynamoDBOperationTypeInsert
+	if exists {
+		eventName = events.DynamoDBOperationTypeModify
+	}
+	if err := t.recordChange(string(eventName), params.Key, oldItem, newItem); err != nil {
+		return nil, err
+	}
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+// recordChange appends a stream record for the change to an item. Callers must hold t.mu.
+func (t *memoryTable) recordChange(eventName string, key, oldItem, newItem map[string]types.AttributeValue) error {
+	keys, err := itemMapper.FromAttributeValueMap(key)
+	if err != nil {
+		return err
+	}
+	newImage, err := itemMapper.FromAttributeValueMap(newItem)
+	if err != nil {
+		return err
+	}
+	var oldImage map[string]events.DynamoDBAttributeValue
+	if oldItem != nil {
+		if oldImage, err = itemMapper.FromAttributeValueMap(oldItem); err != nil {
+			return err
+		}
+	}
+
+	t.sequence++
+	t.stream = append(t.stream, events.DynamoDBEventRecord{
+		AWSRegion:    "local",
+		EventID:      strconv.Itoa(t.sequence),
+		EventName:    eventName,
+		EventSource:  "aws:dynamodb",
+		EventVersion: "1.1",
+		Change: events.DynamoDBStreamRecord{
+			ApproximateCreationDateTime: events.SecondsEpochTime{Time: time.Now()},
+			Keys:                        keys,
+			NewImage:                    newImage,
+			OldImage:                    oldImage,
+			SequenceNumber:              fmt.Sprintf("%021d", t.sequence),
+			SizeBytes:                   itemSize(key) + itemSize(oldItem) + itemSize(newItem),
+			StreamViewType:              string(types.StreamViewTypeNewAndOldImages),
+		},
+	})
+	return nil
+}
+
+// takeStream removes and returns all stream records written since it was last called.
+func (t *memoryTable) takeStream() []events.DynamoDBEventRecord {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	records := t.stream
+	t.stream = nil
+	return records
+}
+
+// keyValue validates the table name and primary key of a request and returns the hash key value.
+func (t *memoryTable) keyValue(tableName *string, key map[string]types.AttributeValue) (string, error) {
+	if aws.ToString(tableName) != t.name {
+		return "", &types.ResourceNotFoundException{
+			Message: aws.String(fmt.Sprintf("Requested resource not found: Table: %s not found",
+				aws.ToString(tableName))),
+		}
+	}
+	v, ok := key[t.hashKey].(*types.AttributeValueMemberS)
+	if !ok || len(key) != 1 {
+		return "", fmt.Errorf("key must consist of the string attribute %q", t.hashKey)
+	}
+	return v.Value, nil
+}
+
+// itemSize approximates the size of an item as DynamoDB calculates it, i.e. the sum of the lengths
+// of its attribute names and values. The size of a stream record is approximated as the sum of
+// the sizes of its keys and item images, without the overhead of the record's own metadata.
+func itemSize(item map[string]types.AttributeValue) int64 {
+	var size int64
+	for name, v := range item {
+		size += int64(len(name)) + attributeValueSize(v)
+	}
+	return size
+}
+
+func attributeValueSize(v types.AttributeValue) int64 {
+	switch v := v.(type) {
+	case *types.AttributeValueMemberS:
+		return int64(len(v.Value))
+	case *types.AttributeValueMemberN:
+		return int64(len(v.Value)/2 + 1)
+	case *types.AttributeValueMemberB:
+		return int64(len(v.Value))
+	case *types.AttributeValueMemberBOOL, *types.AttributeValueMemberNULL:
+		return 1
+	case *types.AttributeValueMemberSS:
+		var size int64
+		for _, s := range v.Value {
+			size += int64(len(s))
+		}
+		return size
+	case *types.AttributeValueMemberNS:
+		var size int64
+		for _, n := range v.Value {
+			size += int64(len(n)/2 + 1)
+		}
+		return size
+	case *types.AttributeValueMemberBS:
+		var size int64
+		for _, b := range v.Value {
+			size += int64(len(b))
+		}
+		return size
+	case *types.AttributeValueMemberL:
+		// Lists and maps have 3 bytes of overhead, plus 1 byte per element
+		size := int64(3 + len(v.Value))
+		for _, e := range v.Value {
+			size += attributeValueSize(e)
+		}
+		return size
+	case *types.AttributeValueMemberM:
+		return 3 + int64(len(v.Value)) + itemSize(v.Value)
+	default:
+		return 0
+	}
+}
+
+func copyItem(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	c := make(map[string]types.AttributeValue, len(item))
+	for k, v := range item {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,94 @@
+package localRun
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryTable(t *testing.T) {
+	ctx := context.Background()
+	table := newMemoryTable("grants", "grant_id")
+	key := map[string]types.AttributeValue{"grant_id": &types.AttributeValueMemberS{Value: "123"}}
+	update := func(expr string, values map[string]types.AttributeValue) error {
+		_, err := table.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName:                 aws.String("grants"),
+			Key:                       key,
+			ConditionExpression:       aws.String("attribute_not_exists(grant_id) OR LastUpdatedDate <> :date"),
+			UpdateExpression:          aws.String(expr),
+			ExpressionAttributeValues: values,
+		})
+		return err
+	}
+	date := func(v string) map[string]types.AttributeValue {
+		return map[string]types.AttributeValue{":date": &types.AttributeValueMemberS{Value: v}}
+	}
+
+	require.NoError(t, update("SET LastUpdatedDate = :date", date("05012023")))
+	require.NoError(t, update("SET LastUpdatedDate = :date", date("05022023")))
+	var conditionErr *types.ConditionalCheckFailedException
+	assert.ErrorAs(t, update("SET LastUpdatedDate = :date", date("05022023")), &conditionErr)
+	// Updates that do not modify the item are not recorded in the stream
+	require.NoError(t, update("SET LastUpdatedDate = LastUpdatedDate", date("05032023")))
+
+	stream := table.takeStream()
+	require.Len(t, stream, 2)
+	assert.Empty(t, table.takeStream(), "Stream records should only be taken once")
+
+	assert.Equal(t, string(events.DynamoDBOperationTypeInsert), stream[0].EventName)
+	assert.Nil(t, stream[0].Change.OldImage)
+	assert.Equal(t, "05012023", stream[0].Change.NewImage["LastUpdatedDate"].String())
+	// "grant_id" and "123" for the key, plus "grant_id", "123", "LastUpdatedDate", and "05012023" for the new image
+	assert.EqualValues(t, 11+11+15+8, stream[0].Change.SizeBytes)
+
+	assert.Equal(t, string(events.DynamoDBOperationTypeModify), stream[1].EventName)
+	assert.Equal(t, "05012023", stream[1].Change.OldImage["LastUpdatedDate"].String())
+	assert.Equal(t, "05022023", stream[1].Change.NewImage["LastUpdatedDate"].String())
+	assert.EqualValues(t, 11+34+34, stream[1].Change.SizeBytes)
+	assert.Less(t, stream[0].Change.SequenceNumber, stream[1].Change.SequenceNumber)
+
+	resp, err := table.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:                aws.String("grants"),
+		Key:                      key,
+		ProjectionExpression:     aws.String("#0"),
+		ExpressionAttributeNames: map[string]string{"#0": "LastUpdatedDate"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]types.AttributeValue{
+		"LastUpdatedDate": &types.AttributeValueMemberS{Value: "05022023"},
+	}, resp.Item)
+
+	_, err = table.GetItem(ctx, &dynamodb.GetItemInput{TableName: aws.String("other"), Key: key})
+	var notFoundErr *types.ResourceNotFoundException
+	assert.ErrorAs(t, err, &notFoundErr)
+}
+
+func TestItemSize(t *testing.T) {
+	for _, tt := range []struct {
+		value    types.AttributeValue
+		expected int64
+	}{
+		{&types.AttributeValueMemberS{Value: "abc"}, 3},
+		{&types.AttributeValueMemberN{Value: "12345"}, 3},
+		{&types.AttributeValueMemberBOOL{Value: true}, 1},
+		{&types.AttributeValueMemberNULL{Value: true}, 1},
+		{&types.AttributeValueMemberSS{Value: []string{"a", "bc"}}, 3},
+		{&types.AttributeValueMemberL{Value: []types.AttributeValue{
+			&types.AttributeValueMemberS{Value: "a"},
+			&types.AttributeValueMemberBOOL{Value: true},
+		}}, 3 + 2 + 1 + 1},
+		{&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"key": &types.AttributeValueMemberS{Value: "abc"},
+		}}, 3 + 1 + 3 + 3},
+	} {
+		// The attribute name "v" adds 1 byte
+		assert.Equal(t, 1+tt.expected, itemSize(map[string]types.AttributeValue{"v": tt.value}), "%#v", tt.value)
+	}
+	assert.Zero(t, itemSize(nil))
+}
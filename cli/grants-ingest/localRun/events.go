@@ -0,0 +1,32 @@
+package localRun
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+)
+
+// eventWriter implements the EventBridge PutEvents API by writing the detail of each event,
+// i.e. a GrantModificationEvent, to w as a single line of JSON.
+type eventWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (ew *eventWriter) PutEvents(ctx context.Context, params *eventbridge.PutEventsInput, optFns ...func(*eventbridge.Options)) (*eventbridge.PutEventsOutput, error) {
+	entries := make([]types.PutEventsResultEntry, 0, len(params.Entries))
+	for _, entry := range params.Entries {
+		if _, err := fmt.Fprintln(ew.w, aws.ToString(entry.Detail)); err != nil {
+			return nil, err
+		}
+		ew.count++
+		entries = append(entries, types.PutEventsResultEntry{
+			EventId: aws.String(fmt.Sprintf("local-%d", ew.count)),
+		})
+	}
+	return &eventbridge.PutEventsOutput{Entries: entries}, nil
+}
@@ -0,0 +1,260 @@
+package localRun
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// This file implements just enough of the DynamoDB expression syntax to evaluate the
+// expressions that the pipeline Lambda handlers build with the SDK's expression package:
+//   - Projections of top-level attributes
+//   - SET (to a value or attribute) and REMOVE update actions on top-level attributes
+//   - Conditions composed of comparisons, attribute_exists, attribute_not_exists, AND, OR, and NOT
+
+var expressionTokenPattern = regexp.MustCompile(`\s*(<>|<=|>=|[()=<>,]|[#:]?[A-Za-z0-9_.-]+)`)
+
+func tokenizeExpression(expr string) ([]string, error) {
+	tokens := []string{}
+	rest := strings.TrimSpace(expr)
+	for rest != "" {
+		loc := expressionTokenPattern.FindStringSubmatchIndex(rest)
+		if loc == nil || loc[0] != 0 {
+			return nil, fmt.Errorf("unsupported expression syntax at %q", rest)
+		}
+		tokens = append(tokens, rest[loc[2]:loc[3]])
+		rest = strings.TrimSpace(rest[loc[1]:])
+	}
+	return tokens, nil
+}
+
+// expressionParser consumes tokens of an expression, resolving attribute name and value placeholders.
+type expressionParser struct {
+	tokens []string
+	pos    int
+	names  map[string]string
+	values map[string]types.AttributeValue
+}
+
+func newExpressionParser(expr string, names map[string]string, values map[string]types.AttributeValue) (*expressionParser, error) {
+	tokens, err := tokenizeExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &expressionParser{tokens: tokens, names: names, values: values}, nil
+}
+
+func (p *expressionParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *expressionParser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *expressionParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *expressionParser) expect(token string) error {
+	if t := p.next(); t != token {
+		return fmt.Errorf("expected %q in expression but found %q", token, t)
+	}
+	return nil
+}
+
+// path consumes a top-level attribute name, which may be an expression attribute name placeholder.
+func (p *expressionParser) path() (string, error) {
+	t := p.next()
+	switch {
+	case strings.HasPrefix(t, "#"):
+		name, ok := p.names[t]
+		if !ok {
+			return "", fmt.Errorf("expression attribute name %s is not defined", t)
+		}
+		return name, nil
+	case t == "" || strings.HasPrefix(t, ":") || strings.Contains(t, "."):
+		return "", fmt.Errorf("unsupported attribute path %q", t)
+	default:
+		return t, nil
+	}
+}
+
+// operand consumes a value placeholder or attribute path and returns its value in item,
+// which is nil if the operand refers to an attribute that does not exist.
+func (p *expressionParser) operand(item map[string]types.AttributeValue) (types.AttributeValue, error) {
+	if t := p.peek(); strings.HasPrefix(t, ":") {
+		p.next()
+		v, ok := p.values[t]
+		if !ok {
+			return nil, fmt.Errorf("expression attribute value %s is not defined", t)
+		}
+		return v, nil
+	}
+	path, err := p.path()
+	if err != nil {
+		return nil, err
+	}
+	return item[path], nil
+}
+
+func parseProjection(expr string, names map[string]string) ([]string, error) {
+	p, err := newExpressionParser(expr, names, nil)
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{}
+	for {
+		path, err := p.path()
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+		if p.done() {
+			return paths, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// applyUpdate modifies item according to the update expression.
+func applyUpdate(expr string, item map[string]types.AttributeValue, names map[string]string, values map[string]types.AttributeValue) error {
+	p, err := newExpressionParser(expr, names, values)
+	if err != nil {
+		return err
+	}
+	// Operands refer to the item as it was before any update actions are applied
+	original := copyItem(item)
+	for !p.done() {
+		action := strings.ToUpper(p.next())
+		if action != "SET" && action != "REMOVE" {
+			return fmt.Errorf("unsupported update action %q", action)
+		}
+		for {
+			path, err := p.path()
+			if err != nil {
+				return err
+			}
+			if action == "REMOVE" {
+				delete(item, path)
+			} else {
+				if err := p.expect("="); err != nil {
+					return err
+				}
+				v, err := p.operand(original)
+				if err != nil {
+					return err
+				}
+				if v == nil {
+					return fmt.Errorf("the SET operand for %s refers to a missing attribute", path)
+				}
+				item[path] = v
+			}
+			if p.peek() != "," {
+				break
+			}
+			p.next()
+		}
+	}
+	return nil
+}
+
+// evaluateCondition reports whether item satisfies the condition expression.
+// A nil item is evaluated as an item with no attributes.
+func evaluateCondition(expr string, item map[string]types.AttributeValue, names map[string]string, values map[string]types.AttributeValue) (bool, error) {
+	p, err := newExpressionParser(expr, names, values)
+	if err != nil {
+		return false, err
+	}
+	result, err := p.orCondition(item)
+	if err != nil {
+		return false, err
+	}
+	if !p.done() {
+		return false, fmt.Errorf("unexpected %q in condition expression", p.peek())
+	}
+	return result, nil
+}
+
+func (p *expressionParser) orCondition(item map[string]types.AttributeValue) (bool, error) {
+	result, err := p.andCondition(item)
+	for err == nil && strings.ToUpper(p.peek()) == "OR" {
+		p.next()
+		var next bool
+		next, err = p.andCondition(item)
+		result = result || next
+	}
+	return result, err
+}
+
+func (p *expressionParser) andCondition(item map[string]types.AttributeValue) (bool, error) {
+	result, err := p.unaryCondition(item)
+	for err == nil && strings.ToUpper(p.peek()) == "AND" {
+		p.next()
+		var next bool
+		next, err = p.unaryCondition(item)
+		result = result && next
+	}
+	return result, err
+}
+
+func (p *expressionParser) unaryCondition(item map[string]types.AttributeValue) (bool, error) {
+	switch t := p.peek(); strings.ToLower(t) {
+	case "not":
+		p.next()
+		result, err := p.unaryCondition(item)
+		return !result, err
+
+	case "(":
+		p.next()
+		result, err := p.orCondition(item)
+		if err != nil {
+			return false, err
+		}
+		return result, p.expect(")")
+
+	case "attribute_exists", "attribute_not_exists":
+		p.next()
+		if err := p.expect("("); err != nil {
+			return false, err
+		}
+		path, err := p.path()
+		if err != nil {
+			return false, err
+		}
+		if err := p.expect(")"); err != nil {
+			return false, err
+		}
+		_, exists := item[path]
+		return exists == (strings.ToLower(t) == "attribute_exists"), nil
+	}
+
+	left, err := p.operand(item)
+	if err != nil {
+		return false, err
+	}
+	operator := p.next()
+	right, err := p.operand(item)
+	if err != nil {
+		return false, err
+	}
+	switch operator {
+	case "=":
+		return left != nil && right != nil && reflect.DeepEqual(left, right), nil
+	case "<>":
+		// Consistent with DynamoDB, a missing attribute is not equal to any value
+		return left == nil || right == nil || !reflect.DeepEqual(left, right), nil
+	default:
+		return false, fmt.Errorf("unsupported comparison operator %q", operator)
+	}
+}
@@ -0,0 +1,144 @@
+package localRun
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenizeExpression(t *testing.T) {
+	tokens, err := tokenizeExpression("(attribute_not_exists (#0)) OR (#1 <> :0)")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"(", "attribute_not_exists", "(", "#0", ")", ")", "OR", "(", "#1", "<>", ":0", ")"}, tokens)
+
+	_, err = tokenizeExpression("#0 = :0 + :1")
+	assert.ErrorContains(t, err, "unsupported expression syntax")
+}
+
+func TestEvaluateCondition(t *testing.T) {
+	item := map[string]types.AttributeValue{
+		"grant_id":        &types.AttributeValueMemberS{Value: "123"},
+		"LastUpdatedDate": &types.AttributeValueMemberS{Value: "05012023"},
+		"is_forecast":     &types.AttributeValueMemberBOOL{Value: false},
+	}
+	names := map[string]string{"#id": "grant_id", "#updated": "LastUpdatedDate", "#missing": "missing"}
+	values := map[string]types.AttributeValue{
+		":id":      &types.AttributeValueMemberS{Value: "123"},
+		":updated": &types.AttributeValueMemberS{Value: "05022023"},
+		":false":   &types.AttributeValueMemberBOOL{Value: false},
+	}
+
+	for _, tt := range []struct {
+		expr     string
+		item     map[string]types.AttributeValue
+		expected bool
+	}{
+		{"attribute_exists(#id)", item, true},
+		{"attribute_not_exists(#id)", item, false},
+		{"attribute_not_exists(#id)", nil, true},
+		{"attribute_not_exists(#missing)", item, true},
+		{"#id = :id", item, true},
+		{"#id <> :id", item, false},
+		{"#updated <> :updated", item, true},
+		{"is_forecast = :false", item, true},
+		{"#missing = :id", item, false},
+		{"#missing <> :id", item, true},
+		{"#id = #id", item, true},
+		{"attribute_not_exists(#id) OR #updated <> :updated", item, true},
+		{"attribute_exists(#id) AND #updated = :updated", item, false},
+		{"attribute_exists(#id) AND (#id = :updated OR #id = :id)", item, true},
+		{"NOT #id = :id", item, false},
+		{"NOT (attribute_exists(#id) AND #missing = :id)", item, true},
+	} {
+		t.Run(tt.expr, func(t *testing.T) {
+			result, err := evaluateCondition(tt.expr, tt.item, names, values)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	t.Run("expression builder", func(t *testing.T) {
+		cond := expression.Or(
+			expression.AttributeNotExists(expression.Name("grant_id")),
+			expression.Name("LastUpdatedDate").NotEqual(expression.Value("05022023")),
+		)
+		expr, err := expression.NewBuilder().WithCondition(cond).Build()
+		require.NoError(t, err)
+		result, err := evaluateCondition(aws.ToString(expr.Condition()), item, expr.Names(), expr.Values())
+		require.NoError(t, err)
+		assert.True(t, result)
+	})
+
+	for _, tt := range []struct {
+		expr string
+		err  string
+	}{
+		{"#undefined = :id", "expression attribute name #undefined is not defined"},
+		{"#id = :undefined", "expression attribute value :undefined is not defined"},
+		{"#id < :id", `unsupported comparison operator "<"`},
+		{"#id = :id :id", `unexpected ":id" in condition expression`},
+		{"(#id = :id", `expected ")"`},
+		{"info.nested = :id", "unsupported attribute path"},
+	} {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := evaluateCondition(tt.expr, item, names, values)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestApplyUpdate(t *testing.T) {
+	item := map[string]types.AttributeValue{
+		"grant_id": &types.AttributeValueMemberS{Value: "123"},
+		"a":        &types.AttributeValueMemberS{Value: "a"},
+		"b":        &types.AttributeValueMemberS{Value: "b"},
+		"stale":    &types.AttributeValueMemberS{Value: "stale"},
+	}
+	names := map[string]string{"#a": "a", "#b": "b", "#new": "new"}
+	values := map[string]types.AttributeValue{":new": &types.AttributeValueMemberN{Value: "1"}}
+
+	// Operands refer to the item as it was before the update, so #a and #b are swapped
+	require.NoError(t, applyUpdate("SET #a = #b, #b = #a, #new = :new REMOVE stale", item, names, values))
+	assert.Equal(t, map[string]types.AttributeValue{
+		"grant_id": &types.AttributeValueMemberS{Value: "123"},
+		"a":        &types.AttributeValueMemberS{Value: "b"},
+		"b":        &types.AttributeValueMemberS{Value: "a"},
+		"new":      &types.AttributeValueMemberN{Value: "1"},
+	}, item)
+
+	t.Run("expression builder", func(t *testing.T) {
+		update := expression.Set(expression.Name("a"), expression.Value("updated")).
+			Remove(expression.Name("new"))
+		expr, err := expression.NewBuilder().WithUpdate(update).Build()
+		require.NoError(t, err)
+		require.NoError(t, applyUpdate(aws.ToString(expr.Update()), item, expr.Names(), expr.Values()))
+		assert.Equal(t, &types.AttributeValueMemberS{Value: "updated"}, item["a"])
+		assert.NotContains(t, item, "new")
+	})
+
+	for _, tt := range []struct {
+		expr string
+		err  string
+	}{
+		{"ADD #a :new", `unsupported update action "ADD"`},
+		{"SET #a = missing", "the SET operand for a refers to a missing attribute"},
+		{"SET #a :new", `expected "="`},
+	} {
+		t.Run(tt.expr, func(t *testing.T) {
+			assert.ErrorContains(t, applyUpdate(tt.expr, copyItem(item), names, values), tt.err)
+		})
+	}
+}
+
+func TestParseProjection(t *testing.T) {
+	paths, err := parseProjection("#0, LastUpdatedDate, #1", map[string]string{"#0": "grant_id", "#1": "is_forecast"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"grant_id", "LastUpdatedDate", "is_forecast"}, paths)
+
+	_, err = parseProjection("#0 #1", map[string]string{"#0": "grant_id", "#1": "is_forecast"})
+	assert.ErrorContains(t, err, `expected ","`)
+}
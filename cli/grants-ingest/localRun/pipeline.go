@@ -0,0 +1,126 @@
+package localRun
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/usdigitalresponse/grants-ingest/internal/lambdas/publishGrantEvents"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+)
+
+// trigger invokes a Lambda handler for objects created in a bucket with keys that match
+// the prefix and suffix filters, mirroring the aws_s3_bucket_notification resources
+// that are configured by terraform/main.tf.
+type trigger struct {
+	lambda string
+	bucket string
+	prefix string
+	suffix string
+	handle func(context.Context, events.S3Event) error
+}
+
+func (t trigger) matches(record events.S3EventRecord) bool {
+	key := record.S3.Object.Key
+	return record.S3.Bucket.Name == t.bucket &&
+		strings.HasPrefix(key, t.prefix) && strings.HasSuffix(key, t.suffix)
+}
+
+// invocationStats counts the invocations of a Lambda handler.
+type invocationStats struct {
+	Invocations int
+	Failures    int
+}
+
+// pipeline drives Lambda handlers in response to changes in the in-memory S3 buckets and
+// DynamoDB table, in the same way that the deployed handlers are invoked by AWS.
+type pipeline struct {
+	logger          log.Logger
+	s3              *notifyingBackend
+	s3Client        *s3.Client
+	table           *memoryTable
+	publisher       *eventWriter
+	triggers        []trigger
+	streamBatchSize int
+
+	stats               map[string]*invocationStats
+	failedStreamRecords int
+}
+
+// processNotifications invokes the triggered Lambda handler for each created S3 object,
+// one object per invocation, until no more objects are created.
+// Handler failures are logged and counted, and are not retried.
+func (p *pipeline) processNotifications(ctx context.Context) error {
+	for records := p.s3.takeCreated(); len(records) > 0; records = p.s3.takeCreated() {
+		for _, record := range records {
+			for _, t := range p.triggers {
+				if !t.matches(record) {
+					continue
+				}
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				logger := log.With(p.logger, "lambda", t.lambda,
+					"bucket", record.S3.Bucket.Name, "key", record.S3.Object.Key)
+				log.Debug(logger, "Invoking Lambda handler for created S3 object")
+				err := t.handle(ctx, events.S3Event{Records: []events.S3EventRecord{record}})
+				p.count(t.lambda, err)
+				if err != nil {
+					log.Warn(logger, "Lambda handler invocation failed", "error", err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// publishStream invokes the PublishGrantEvents handler with batches of DynamoDB stream records.
+// When the handler reports a failed record, the record is discarded (as if retries were exhausted)
+// and the following records are sent in the next batch.
+func (p *pipeline) publishStream(ctx context.Context) error {
+	records := p.table.takeStream()
+	for len(records) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		batch := records[:min(p.streamBatchSize, len(records))]
+		resp, err := publishGrantEvents.HandleEvent(ctx, p.publisher, p.s3Client, events.DynamoDBEvent{Records: batch})
+		p.count("PublishGrantEvents", err)
+		if err != nil {
+			log.Warn(p.logger, "Lambda handler invocation failed",
+				"lambda", "PublishGrantEvents", "error", err)
+			p.failedStreamRecords += len(batch)
+			records = records[len(batch):]
+			continue
+		}
+
+		processed := len(batch)
+		if len(resp.BatchItemFailures) > 0 {
+			failedSeq := resp.BatchItemFailures[0].ItemIdentifier
+			for i, record := range batch {
+				if record.Change.SequenceNumber == failedSeq {
+					processed = i + 1
+					break
+				}
+			}
+			p.failedStreamRecords++
+			log.Warn(p.logger, "Discarding DynamoDB stream record that failed to publish",
+				"sequence_number", failedSeq)
+		}
+		records = records[processed:]
+	}
+	return nil
+}
+
+func (p *pipeline) count(lambda string, err error) {
+	stats, ok := p.stats[lambda]
+	if !ok {
+		stats = &invocationStats{}
+		p.stats[lambda] = stats
+	}
+	stats.Invocations++
+	if err != nil {
+		stats.Failures++
+	}
+}
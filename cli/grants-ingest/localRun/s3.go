@@ -0,0 +1,72 @@
+package localRun
+
+import (
+	"io"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+)
+
+// notifyingBackend is an in-memory gofakes3 backend that records the creation of each object,
+// in the manner of "s3:ObjectCreated:*" bucket notifications.
+type notifyingBackend struct {
+	gofakes3.Backend
+
+	mu      sync.Mutex
+	created []events.S3EventRecord
+}
+
+func newNotifyingBackend() *notifyingBackend {
+	return &notifyingBackend{Backend: s3mem.New()}
+}
+
+func (b *notifyingBackend) PutObject(bucketName, key string, meta map[string]string, input io.Reader, size int64) (gofakes3.PutObjectResult, error) {
+	result, err := b.Backend.PutObject(bucketName, key, meta, input, size)
+	if err != nil {
+		return result, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.created = append(b.created, events.S3EventRecord{
+		AWSRegion:   "local",
+		EventName:   "ObjectCreated:Put",
+		EventSource: "aws:s3",
+		EventTime:   time.Now(),
+		S3: events.S3Entity{
+			Bucket: events.S3Bucket{Name: bucketName},
+			Object: events.S3Object{Key: key, Size: size},
+		},
+	})
+	return result, nil
+}
+
+// takeCreated removes and returns records of all objects created since it was last called.
+func (b *notifyingBackend) takeCreated() []events.S3EventRecord {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	records := b.created
+	b.created = nil
+	return records
+}
+
+// startS3Server serves the S3 API for the backend on a local port and returns an S3 client
+// for the server, along with a function that stops the server.
+func startS3Server(backend gofakes3.Backend) (*s3.Client, func()) {
+	faker := gofakes3.New(backend, gofakes3.WithLogger(gofakes3.DiscardLog()))
+	ts := httptest.NewServer(faker.Server())
+	client := s3.New(s3.Options{
+		Region:       "us-west-2",
+		Credentials:  credentials.NewStaticCredentialsProvider("local", "local", ""),
+		BaseEndpoint: aws.String(ts.URL),
+		UsePathStyle: true,
+	})
+	return client, ts.Close
+}
@@ -7,8 +7,18 @@ import (
 	kitLog "github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/posener/complete"
+	"github.com/usdigitalresponse/grants-ingest/cli/grants-ingest/agencies"
+	"github.com/usdigitalresponse/grants-ingest/cli/grants-ingest/assistanceListingsImport"
+	"github.com/usdigitalresponse/grants-ingest/cli/grants-ingest/audit"
+	"github.com/usdigitalresponse/grants-ingest/cli/grants-ingest/export"
+	"github.com/usdigitalresponse/grants-ingest/cli/grants-ingest/ffis"
 	"github.com/usdigitalresponse/grants-ingest/cli/grants-ingest/ffisImport"
+	"github.com/usdigitalresponse/grants-ingest/cli/grants-ingest/inspect"
+	"github.com/usdigitalresponse/grants-ingest/cli/grants-ingest/localRun"
 	"github.com/usdigitalresponse/grants-ingest/cli/grants-ingest/purgeData"
+	"github.com/usdigitalresponse/grants-ingest/cli/grants-ingest/quality"
+	"github.com/usdigitalresponse/grants-ingest/cli/grants-ingest/restore"
+	"github.com/usdigitalresponse/grants-ingest/cli/profile"
 	"github.com/usdigitalresponse/grants-ingest/internal/log"
 	"github.com/willabides/kongplete"
 )
@@ -18,6 +28,11 @@ type Globals struct {
 		Level string `enum:"debug,info,warn,error" help:"Log level (debug|info|warn|error)" default:"info"`
 		JSON  bool   `help:"Outputs JSON-formatted logs"`
 	} `embed:"" prefix:"log-"`
+	profile.Flags
+}
+
+func (g Globals) BeforeResolve(ctx *kong.Context, selected *profile.Profile) error {
+	return profile.Select(ctx, selected)
 }
 
 func (g Globals) AfterApply(app *kong.Kong, logger *log.Logger) error {
@@ -36,20 +51,31 @@ func (g Globals) AfterApply(app *kong.Kong, logger *log.Logger) error {
 type CLI struct {
 	Globals
 
-	FFISImport ffisImport.Cmd `cmd:"ffis-import" help:"Import FFIS spreadsheets to S3."`
-	Purge      purgeData.Cmd  `cmd:"purge" help:"Purge data from various locations."`
+	Agencies                 agencies.Cmd                 `cmd:"agencies" help:"Read or update the agency registry."`
+	AssistanceListingsImport assistanceListingsImport.Cmd `cmd:"assistance-listings-import" help:"Import a SAM.gov Assistance Listings extract to S3."`
+	Audit                    audit.Cmd                    `cmd:"audit" help:"Audit the consistency of prepared data with the latest Grants.gov extract."`
+	Export                   export.Cmd                   `cmd:"export" help:"Export grants from the prepared-data table."`
+	FFIS                     ffis.Cmd                     `cmd:"ffis" help:"Manage FFIS.org data."`
+	FFISImport               ffisImport.Cmd               `cmd:"ffis-import" help:"Import FFIS spreadsheets to S3."`
+	Inspect                  inspect.Cmd                  `cmd:"inspect" help:"Inspect the stored data for a single grant."`
+	LocalRun                 localRun.Cmd                 `cmd:"local-run" help:"Run the ingestion pipeline locally against in-memory AWS services."`
+	Purge                    purgeData.Cmd                `cmd:"purge" help:"Purge data from various locations."`
+	Quality                  quality.Cmd                  `cmd:"quality" help:"Report the data quality of items in the prepared-data table."`
+	Restore                  restore.Cmd                  `cmd:"restore" help:"Restore data from a purge backup archive."`
 
 	Completion kongplete.InstallCompletions `cmd:"" help:"Install shell completions"`
 }
 
 func main() {
 	var logger log.Logger
+	var selectedProfile profile.Profile
 	parser := kong.Must(&CLI{Globals: Globals{}},
 		kong.Name("grants-ingest"),
 		kong.Description("CLI utility for the grants-ingest service."),
 		kong.UsageOnError(),
 		kong.ConfigureHelp(kong.HelpOptions{Compact: true}),
-		kong.Bind(&logger),
+		kong.Bind(&logger, &selectedProfile),
+		kong.Resolvers(profile.Resolver(&selectedProfile)),
 	)
 
 	kongplete.Complete(parser,
@@ -59,6 +85,7 @@ func main() {
 
 	cli, err := parser.Parse(os.Args[1:])
 	parser.FatalIfErrorf(err)
+	parser.FatalIfErrorf(profile.ConfirmDestructive(cli, selectedProfile, os.Stdin, parser.Stderr))
 	if err := cli.Run(); err != nil {
 		cli.Exit(1)
 	}
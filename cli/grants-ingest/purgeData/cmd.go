@@ -43,7 +43,13 @@ Observe the following recommended best practices:
 - Always test workflows involving these commands against lower environments.
 - Make use of --dry-run and --log-level=debug options when running against sensitive environments.
 - Consider making bash scripts that can be peer-reviewed to guard against human error.
-- Make backups of important data before performing destructive actions.
+- Keep the backup archive that is written before data is purged (see --backup-to) until the
+	purge is known to be successful. Purged data can be recovered from the archive with the
+	restore command. Avoid --no-backup unless another backup of the data exists.
+- If a purge is interrupted (e.g. by Ctrl+C or a fatal error), rerun the same command with
+	--resume to continue from its checkpoint file (see --checkpoint-file) rather than starting
+	over. Totals logged by a resumed purge include the progress of the interrupted run. Each
+	run writes its own backup archive, so keep the archives of every run.
 - Always communicate explicitly before running these commands against sensitive and/or shared
 	environments.
 
@@ -0,0 +1,137 @@
+package preparedDataBucket
+
+import (
+	"sync"
+)
+
+// checkpointOptions are the options of a purge that cannot change when it is resumed.
+type checkpointOptions struct {
+	Patterns    []string `json:"patterns"`
+	Prefix      string   `json:"prefix"`
+	AllVersions bool     `json:"all_versions"`
+}
+
+func (cmd *Cmd) checkpointOptions() checkpointOptions {
+	opts := checkpointOptions{Prefix: cmd.FilterPrefix, AllVersions: cmd.AllVersions}
+	for _, m := range cmd.matchers {
+		opts.Patterns = append(opts.Patterns, m.Pattern())
+	}
+	return opts
+}
+
+// checkpointState is the saved progress of a purge.
+type checkpointState struct {
+	// Key after which listing resumes, or empty to list from the beginning
+	StartAfter string `json:"start_after,omitempty"`
+	Complete   bool   `json:"complete,omitempty"`
+	Matched    int64  `json:"matched"`
+	Deleted    int64  `json:"deleted"`
+	// Objects before StartAfter that could not be deleted
+	FailedObjects []string `json:"failed_objects,omitempty"`
+	// Location of the backup archive, which a resumed purge adds to
+	BackupLocation string `json:"backup_location,omitempty"`
+}
+
+// progress tracks the deletion of listed objects, so that the listing position only advances
+// past objects once they are deleted or have failed to be deleted. Deleted objects are never
+// listed again, so the total of deleted objects includes those after the listing position.
+// Objects after the listing position that failed to be deleted are retried when the purge is
+// resumed.
+type progress struct {
+	mu         sync.Mutex
+	startAfter string
+	complete   bool
+	deleted    int64
+	failed     []string
+	// Failures of settled pages that are after startAfter
+	pendingFailures []objectVersion
+	// Listed pages after startAfter, in listing order
+	pages []*listedPage
+	// Set once the backup archive is opened, before the purge begins
+	backupLocation string
+}
+
+// listedPage is a page of listed objects that are matched for deletion.
+type listedPage struct {
+	objects []objectVersion
+	// Key after which listing resumes once this page and all previous pages are settled
+	startAfter string
+	// Whether this is the last page of the listing
+	last    bool
+	settled bool
+}
+
+// newProgress returns progress that begins at the given checkpoint state, which is empty unless
+// an interrupted purge is resumed.
+func newProgress(state checkpointState) *progress {
+	return &progress{
+		startAfter: state.StartAfter,
+		complete:   state.Complete,
+		deleted:    state.Deleted,
+		failed:     append([]string{}, state.FailedObjects...),
+		// Retained when the resumed purge is not backed up, so that it may be resumed again
+		backupLocation: state.BackupLocation,
+	}
+}
+
+// start returns the key after which listing should begin, and whether listing already completed.
+func (p *progress) start() (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.startAfter, p.complete
+}
+
+// addPage begins tracking the objects of a listed page. Pages must be added in listing order.
+// An empty page is settled immediately.
+func (p *progress) addPage(page *listedPage) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pages = append(p.pages, page)
+	if len(page.objects) == 0 {
+		page.settled = true
+	}
+	p.advance()
+}
+
+// settle records that every object of a page was either deleted or failed to be deleted.
+func (p *progress) settle(page *listedPage, deleted int, failures []objectVersion) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	page.settled = true
+	p.deleted += int64(deleted)
+	p.pendingFailures = append(p.pendingFailures, failures...)
+	p.advance()
+}
+
+// advance moves the listing position past the leading pages that are settled.
+func (p *progress) advance() {
+	for len(p.pages) > 0 && p.pages[0].settled {
+		page := p.pages[0]
+		p.pages = p.pages[1:]
+		p.startAfter = page.startAfter
+		p.complete = page.last
+	}
+	remaining := p.pendingFailures[:0]
+	for _, o := range p.pendingFailures {
+		if p.complete || o.key <= p.startAfter {
+			p.failed = append(p.failed, o.String())
+		} else {
+			remaining = append(remaining, o)
+		}
+	}
+	p.pendingFailures = remaining
+}
+
+// checkpoint returns the current progress as checkpoint state.
+func (p *progress) checkpoint() checkpointState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return checkpointState{
+		StartAfter:     p.startAfter,
+		Complete:       p.complete,
+		Matched:        p.deleted + int64(len(p.failed)),
+		Deleted:        p.deleted,
+		FailedObjects:  append([]string{}, p.failed...),
+		BackupLocation: p.backupLocation,
+	}
+}
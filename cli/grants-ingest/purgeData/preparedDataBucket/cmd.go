@@ -6,13 +6,19 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/hashicorp/go-multierror"
+	"github.com/usdigitalresponse/grants-ingest/cli/backup"
+	"github.com/usdigitalresponse/grants-ingest/cli/checkpoint"
+	"github.com/usdigitalresponse/grants-ingest/cli/profile"
 	ct "github.com/usdigitalresponse/grants-ingest/cli/types"
 	"github.com/usdigitalresponse/grants-ingest/internal/awsHelpers"
 	"github.com/usdigitalresponse/grants-ingest/internal/log"
@@ -20,19 +26,24 @@ import (
 
 type Cmd struct {
 	// Positional arguments
-	S3Bucket string `arg:"" name:"bucket" help:"Prepared data S3 bucket name from which to purge objects."`
+	S3Bucket string `arg:"" optional:"" name:"bucket" help:"Prepared data S3 bucket name from which to purge objects (default: the bucket of the selected profile)."`
 
 	// Flags
 	MatchPaths     []FilePathMatcher   `placeholder:"glob" help:"Shell filename pattern (i.e. glob) for matching S3 keys to delete."`
 	MatchRegex     []RegexMatcher      `placeholder:"expression" help:"Regex pattern for matching S3 keys to delete."`
 	PurgeFFIS      bool                `help:"Delete all FFIS.org S3 objects (after applying --filter-prefix, if given)."`
 	PurgeGov       bool                `help:"Delete all Grants.gov S3 objects (after applying --filter-prefix, if given)."`
+	PurgeOpps      bool                `name:"purge-opportunities" help:"Delete all Grants.gov opportunity (i.e. synopsis) S3 objects (after applying --filter-prefix, if given)."`
+	PurgeForecasts bool                `help:"Delete all Grants.gov forecast S3 objects (after applying --filter-prefix, if given)."`
 	PurgeAll       bool                `help:"Delete all S3 objects (after applying --filter-prefix, if given)."`
 	FilterPrefix   string              `name:"s3-prefix" default:"" help:"Prevent deleting bucket objects outside this prefix."`
 	Concurrency    ct.ConcurrencyLimit `default:"10" help:"Max concurrent batch-delete operations."`
 	TotalsAfter    ct.TotalsAfter      `default:"1000" help:"Log S3 object totals after this many successful/failed deletions (silent if 0)."`
 	S3UsePathStyle bool                `name:"s3-use-path-style" help:"Use path-style addressing for S3 bucket."`
+	AllVersions    bool                `help:"Delete every version of matched objects in a versioned bucket, instead of adding delete markers. Only the current version of each object is backed up."`
 	DryRun         bool                `help:"Dry run only - no files will be uploaded to S3."`
+	backup.Flags   `embed:""`
+	Checkpoint     checkpoint.Flags `embed:""`
 
 	// Internal
 	ctx      context.Context
@@ -40,11 +51,15 @@ type Cmd struct {
 	s3svc    *s3.Client
 	matchers []Matcher
 	logger   *log.Logger
+	archive  *backup.Writer
+	progress *progress
 }
 
 var (
 	ErrCompletion = errors.New("the operation completed with errors")
 	ErrNoMatchers = errors.New("no match options are configured for purging data")
+	// Backups cannot be written to the bucket being purged, where they may be matched for deletion
+	ErrBackupInBucket = errors.New("--backup-to cannot be a location in the bucket being purged")
 )
 
 func (cmd *Cmd) BeforeApply(app *kong.Kong, logger *log.Logger) error {
@@ -55,7 +70,14 @@ func (cmd *Cmd) BeforeApply(app *kong.Kong, logger *log.Logger) error {
 	return nil
 }
 
-func (cmd *Cmd) AfterApply(app *kong.Kong) error {
+func (cmd *Cmd) AfterApply(app *kong.Kong, selected *profile.Profile) error {
+	bucket, err := profile.Default(cmd.S3Bucket, selected.PreparedDataBucket,
+		"<bucket>", "prepared_data_bucket")
+	if err != nil {
+		return err
+	}
+	cmd.S3Bucket = bucket
+
 	cfg, err := awsHelpers.GetConfig(cmd.ctx)
 	if err != nil {
 		err := fmt.Errorf("failed to configure AWS SDK: %w", err)
@@ -65,6 +87,11 @@ func (cmd *Cmd) AfterApply(app *kong.Kong) error {
 	}
 	cmd.s3svc = s3.NewFromConfig(cfg, func(o *s3.Options) { o.UsePathStyle = cmd.S3UsePathStyle })
 
+	if bucketURI := "s3://" + cmd.S3Bucket; cmd.BackupTo == bucketURI ||
+		strings.HasPrefix(cmd.BackupTo, bucketURI+"/") {
+		return ErrBackupInBucket
+	}
+
 	if cmd.PurgeAll {
 		cmd.matchers = append(cmd.matchers, AllMatcher)
 	} else {
@@ -73,6 +100,13 @@ func (cmd *Cmd) AfterApply(app *kong.Kong) error {
 		}
 		if cmd.PurgeGov {
 			cmd.matchers = append(cmd.matchers, GrantsGovMatcher)
+		} else {
+			if cmd.PurgeOpps {
+				cmd.matchers = append(cmd.matchers, OpportunitiesMatcher)
+			}
+			if cmd.PurgeForecasts {
+				cmd.matchers = append(cmd.matchers, ForecastsMatcher)
+			}
 		}
 		for _, m := range cmd.MatchRegex {
 			cmd.matchers = append(cmd.matchers, &m)
@@ -88,37 +122,103 @@ func (cmd *Cmd) AfterApply(app *kong.Kong) error {
 	return nil
 }
 
+// Destructive reports whether the command deletes objects.
+func (cmd *Cmd) Destructive() bool {
+	return !cmd.DryRun
+}
+
 func (cmd *Cmd) Run(app *kong.Kong) error {
 	defer cmd.stop()
 
-	keysToDelete := make(chan []string)
+	var state checkpointState
+	checkpointFile, err := cmd.Checkpoint.Open(cmd.S3Bucket, cmd.checkpointOptions(), &state)
+	if err != nil {
+		return log.Errorf(*cmd.logger, "Error opening checkpoint file", err)
+	}
+	cmd.progress = newProgress(state)
+	if cmd.Checkpoint.Resume {
+		log.Info(*cmd.logger, "Resuming interrupted purge from checkpoint",
+			"path", checkpointFile.Path(), "start_after", state.StartAfter,
+			"matched", state.Matched, "deleted", state.Deleted, "failed", len(state.FailedObjects))
+	}
+
+	if cmd.DryRun {
+		log.Info(*cmd.logger, "Skipping backup of purged objects for dry run")
+	} else {
+		var archive *backup.Writer
+		if cmd.Checkpoint.Resume {
+			archive, err = cmd.Flags.Resume(cmd.ctx, cmd.s3svc, backup.KindS3Bucket, cmd.S3Bucket,
+				state.BackupLocation)
+		} else {
+			archive, err = cmd.Flags.Create(cmd.ctx, cmd.s3svc, backup.KindS3Bucket, cmd.S3Bucket)
+		}
+		if err != nil {
+			return log.Errorf(*cmd.logger, "Error opening backup archive", err)
+		}
+		if archive == nil {
+			log.Warn(*cmd.logger, "Backup is disabled; purged objects will not be recoverable")
+		} else {
+			cmd.archive = archive
+			cmd.progress.backupLocation = archive.Location()
+			log.Info(*cmd.logger, "Backing up objects before they are purged",
+				"location", archive.Location(), "resumed", cmd.Checkpoint.Resume)
+		}
+		// The backup location is saved immediately, so that a purge which is interrupted
+		// before its first periodic checkpoint can still be resumed with the same archive
+		if err := checkpointFile.Save(cmd.progress.checkpoint()); err != nil {
+			return log.Errorf(*cmd.logger, "Error saving checkpoint file", err, "path", checkpointFile.Path())
+		}
+	}
+
+	objectsToDelete := make(chan *listedPage)
 	failedDeletions := make(chan string)
 	successfulDeletions := make(chan string)
 
-	var deleteObjectsErr error
-	workWg := sync.WaitGroup{}
+	checkpointDone := make(chan struct{})
+	checkpointStop := make(chan struct{})
+	go func() {
+		defer close(checkpointDone)
+		if cmd.DryRun {
+			return
+		}
+		ticker := time.NewTicker(checkpoint.SaveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := checkpointFile.Save(cmd.progress.checkpoint()); err != nil {
+					log.Warn(*cmd.logger, "Error saving checkpoint file", "error", err,
+						"path", checkpointFile.Path())
+				}
+			case <-checkpointStop:
+				return
+			}
+		}
+	}()
+
+	workGroup := multierror.Group{}
 	for i := 0; i < int(cmd.Concurrency); i++ {
 		workLogger := log.WithSuffix(*cmd.logger, "worker_id", i)
-		workWg.Add(1)
-		go func() {
-			defer workWg.Done()
-			err := cmd.deleteObjectsWorker(workLogger, keysToDelete, successfulDeletions, failedDeletions)
+		workGroup.Go(func() error {
+			err := cmd.deleteObjectsWorker(workLogger, objectsToDelete, successfulDeletions, failedDeletions)
 			if err != nil && err != context.Canceled {
 				log.Error(*cmd.logger,
 					"Stopping application due to fatal error encountered while purging S3 objects",
 					err)
-				deleteObjectsErr = err
 				cmd.stop()
+				return err
 			}
-		}()
+			return nil
+		})
 	}
 
 	resultWg := sync.WaitGroup{}
-	failedObjectKeys := make([]string, len(failedDeletions))
+	// Objects that failed to be deleted by an interrupted run are not retried
+	failedObjectKeys := append([]string{}, state.FailedObjects...)
 	resultWg.Add(1)
 	go func() {
 		defer resultWg.Done()
-		var totalFailedDeletions int64
+		totalFailedDeletions := int64(len(state.FailedObjects))
 		for f := range failedDeletions {
 			failedObjectKeys = append(failedObjectKeys, f)
 			totalFailedDeletions++
@@ -132,7 +232,7 @@ func (cmd *Cmd) Run(app *kong.Kong) error {
 	resultWg.Add(1)
 	go func() {
 		defer resultWg.Done()
-		var totalDeletedObjects int64
+		totalDeletedObjects := state.Deleted
 		for range successfulDeletions {
 			totalDeletedObjects++
 			if cmd.TotalsAfter.Check(totalDeletedObjects) {
@@ -142,18 +242,56 @@ func (cmd *Cmd) Run(app *kong.Kong) error {
 		log.Info(*cmd.logger, "Final count of deleted objects", "count", totalDeletedObjects)
 	}()
 
-	var listObjectsErr error
-	go func() {
-		defer close(keysToDelete)
-		listObjectsErr = cmd.listObjects(cmd.logger, keysToDelete)
-	}()
+	listGroup := multierror.Group{}
+	listGroup.Go(func() error {
+		defer close(objectsToDelete)
+		startAfter, complete := cmd.progress.start()
+		switch {
+		case complete:
+			log.Info(*cmd.logger, "Skipping listing of S3 objects completed before the purge was interrupted")
+			return nil
+		case cmd.AllVersions:
+			return cmd.listObjectVersions(objectsToDelete, startAfter, state.Matched)
+		default:
+			return cmd.listObjects(objectsToDelete, startAfter, state.Matched)
+		}
+	})
 
-	workWg.Wait()
+	deleteObjectsErr := workGroup.Wait().ErrorOrNil()
+	// Listing stops once the workers stop, since they only stop early when cmd.ctx is canceled
+	listObjectsErr := listGroup.Wait().ErrorOrNil()
 	close(failedDeletions)
 	close(successfulDeletions)
 	resultWg.Wait()
+	close(checkpointStop)
+	<-checkpointDone
 
-	if cmd.ctx.Err() != nil || listObjectsErr != nil || deleteObjectsErr != nil || len(failedObjectKeys) > 0 {
+	var archiveErr error
+	if cmd.archive != nil {
+		if archiveErr = cmd.archive.Close(); archiveErr != nil {
+			log.Error(*cmd.logger, "Error finalizing backup archive", archiveErr,
+				"location", cmd.archive.Location())
+		} else {
+			log.Info(*cmd.logger, "Backup archive is complete", "location", cmd.archive.Location())
+		}
+	}
+
+	interrupted := cmd.ctx.Err() != nil || listObjectsErr != nil || deleteObjectsErr != nil || archiveErr != nil
+	if interrupted && !cmd.DryRun {
+		if err := checkpointFile.Save(cmd.progress.checkpoint()); err != nil {
+			log.Error(*cmd.logger, "Error saving checkpoint file", err, "path", checkpointFile.Path())
+		} else {
+			log.Info(*cmd.logger, "Saved purge progress; rerun with --resume to continue the purge",
+				"path", checkpointFile.Path())
+		}
+	} else if !cmd.DryRun {
+		if err := checkpointFile.Remove(); err != nil {
+			log.Warn(*cmd.logger, "Error removing checkpoint file of completed purge", "error", err,
+				"path", checkpointFile.Path())
+		}
+	}
+
+	if interrupted || len(failedObjectKeys) > 0 {
 		if len(failedObjectKeys) > 0 {
 			log.Warn(*cmd.logger, "Some objects could not be deleted",
 				"count", len(failedObjectKeys), "keys", failedObjectKeys)
@@ -164,13 +302,63 @@ func (cmd *Cmd) Run(app *kong.Kong) error {
 	return nil
 }
 
-func (cmd *Cmd) listObjects(logger *log.Logger, ch chan<- []string) error {
-	var totalMatchCount int64
+// objectVersion identifies an S3 object (or a version of it) to delete.
+type objectVersion struct {
+	key       string
+	versionID *string
+	// Whether the object should be backed up before it is deleted
+	backup bool
+}
+
+func (o objectVersion) String() string {
+	if o.versionID == nil {
+		return o.key
+	}
+	return fmt.Sprintf("%s (version %s)", o.key, *o.versionID)
+}
+
+func (cmd *Cmd) matchKey(key string) bool {
+	for _, m := range cmd.matchers {
+		if m.Match(key) {
+			log.Debug(*cmd.logger, "Matched S3 object", "key", key, "pattern", m.Pattern())
+			return true
+		}
+	}
+	return false
+}
+
+// sendMatched sends a page of matched objects to ch, unless the page is empty.
+func (cmd *Cmd) sendMatched(ch chan<- *listedPage, page *listedPage, totalMatchCount *int64) error {
+	for range page.objects {
+		*totalMatchCount++
+		if cmd.TotalsAfter.Check(*totalMatchCount) {
+			log.Info(*cmd.logger, "Updated matched objects total", "count", *totalMatchCount)
+		}
+	}
+	cmd.progress.addPage(page)
+	if len(page.objects) == 0 {
+		return nil
+	}
+	select {
+	case ch <- page:
+		return nil
+	case <-cmd.ctx.Done():
+		return cmd.ctx.Err()
+	}
+}
+
+// listObjects lists matching objects after the given key. Pages are tracked by the key of
+// their last object rather than by continuation token, because continuation tokens are not
+// guaranteed to remain valid when an interrupted purge is resumed.
+func (cmd *Cmd) listObjects(ch chan<- *listedPage, startAfter string, totalMatchCount int64) error {
 	params := &s3.ListObjectsV2Input{
 		Bucket:  aws.String(cmd.S3Bucket),
 		Prefix:  aws.String(cmd.FilterPrefix),
 		MaxKeys: aws.Int32(1000),
 	}
+	if startAfter != "" {
+		params.StartAfter = aws.String(startAfter)
+	}
 	for {
 		resp, err := cmd.s3svc.ListObjectsV2(cmd.ctx, params)
 		if err != nil {
@@ -178,34 +366,89 @@ func (cmd *Cmd) listObjects(logger *log.Logger, ch chan<- []string) error {
 			return err
 		}
 
-		keys := make([]string, *resp.KeyCount)
-		matchCount := 0
+		page := &listedPage{
+			objects:    make([]objectVersion, 0, len(resp.Contents)),
+			startAfter: startAfter,
+			last:       resp.NextContinuationToken == nil,
+		}
 		for _, obj := range resp.Contents {
-			k := *obj.Key
-			for _, m := range cmd.matchers {
-				if m.Match(k) {
-					log.Debug(*cmd.logger, "Matched S3 object", "key", k, "pattern", m.Pattern())
-					keys[matchCount] = k
-					matchCount++
-					totalMatchCount++
-					if cmd.TotalsAfter.Check(totalMatchCount) {
-						log.Info(*cmd.logger, "Updated matched objects total", "count", totalMatchCount)
-					}
-				}
+			if cmd.matchKey(*obj.Key) {
+				page.objects = append(page.objects, objectVersion{key: *obj.Key, backup: true})
 			}
+			startAfter = *obj.Key
 		}
-		if matchCount > 0 {
-			ch <- keys[:matchCount]
+		page.startAfter = startAfter
+		if err := cmd.sendMatched(ch, page, &totalMatchCount); err != nil {
+			return err
 		}
 
-		if resp.NextContinuationToken == nil {
+		if page.last {
 			return nil
 		}
 		params.ContinuationToken = resp.NextContinuationToken
 	}
 }
 
-func (cmd *Cmd) deleteObjectsWorker(logger log.Logger, work <-chan []string, deleted, failures chan<- string) (err error) {
+// listObjectVersions lists every version and delete marker of matching objects with keys after
+// the given key. Only the current version of each object is marked for backup.
+func (cmd *Cmd) listObjectVersions(ch chan<- *listedPage, startAfter string, totalMatchCount int64) error {
+	params := &s3.ListObjectVersionsInput{
+		Bucket:  aws.String(cmd.S3Bucket),
+		Prefix:  aws.String(cmd.FilterPrefix),
+		MaxKeys: aws.Int32(1000),
+	}
+	if startAfter != "" {
+		params.KeyMarker = aws.String(startAfter)
+	}
+	for {
+		resp, err := cmd.s3svc.ListObjectVersions(cmd.ctx, params)
+		if err != nil {
+			log.Error(*cmd.logger, "Error listing S3 object versions", err)
+			return err
+		}
+
+		page := &listedPage{
+			objects: make([]objectVersion, 0, len(resp.Versions)+len(resp.DeleteMarkers)),
+			last:    !aws.ToBool(resp.IsTruncated),
+		}
+		// More versions of the last key on a truncated page may be listed on the next page,
+		// so a resumed purge can only skip the keys before it
+		nextKey := aws.ToString(resp.NextKeyMarker)
+		advanceTo := func(key string) {
+			if key > startAfter && (page.last || key < nextKey) {
+				startAfter = key
+			}
+		}
+		for _, v := range resp.Versions {
+			if cmd.matchKey(*v.Key) {
+				page.objects = append(page.objects, objectVersion{
+					key:       *v.Key,
+					versionID: v.VersionId,
+					backup:    aws.ToBool(v.IsLatest),
+				})
+			}
+			advanceTo(*v.Key)
+		}
+		for _, m := range resp.DeleteMarkers {
+			if cmd.matchKey(*m.Key) {
+				page.objects = append(page.objects, objectVersion{key: *m.Key, versionID: m.VersionId})
+			}
+			advanceTo(*m.Key)
+		}
+		page.startAfter = startAfter
+		if err := cmd.sendMatched(ch, page, &totalMatchCount); err != nil {
+			return err
+		}
+
+		if page.last {
+			return nil
+		}
+		params.KeyMarker = resp.NextKeyMarker
+		params.VersionIdMarker = resp.NextVersionIdMarker
+	}
+}
+
+func (cmd *Cmd) deleteObjectsWorker(logger log.Logger, work <-chan *listedPage, deleted, failures chan<- string) (err error) {
 	defer func() {
 		if err == nil {
 			log.Debug(logger, "Worker shutting down", "reason", "no more work")
@@ -222,13 +465,13 @@ func (cmd *Cmd) deleteObjectsWorker(logger log.Logger, work <-chan []string, del
 			return cmd.ctx.Err()
 		default:
 			select {
-			case batchKeys, ok := <-work:
+			case page, ok := <-work:
 				if !ok {
 					return nil
 				}
-				deleteErr := cmd.deleteObjects(logger, batchKeys, deleted, failures)
+				deleteErr := cmd.deleteObjects(logger, page, deleted, failures)
 				if deleteErr != nil {
-					return fmt.Errorf("error requesting S3 object deletion: %w", err)
+					return fmt.Errorf("error requesting S3 object deletion: %w", deleteErr)
 				}
 			case <-cmd.ctx.Done():
 				return cmd.ctx.Err()
@@ -237,19 +480,53 @@ func (cmd *Cmd) deleteObjectsWorker(logger log.Logger, work <-chan []string, del
 	}
 }
 
-func (cmd *Cmd) deleteObjects(logger log.Logger, keys []string, deleted, failures chan<- string) error {
-	identifiers := make([]types.ObjectIdentifier, len(keys))
-	for i, k := range keys {
-		identifiers[i].Key = aws.String(k)
-	}
-
+// deleteObjects deletes the objects of a listed page. The page is settled unless an error is
+// returned.
+func (cmd *Cmd) deleteObjects(logger log.Logger, page *listedPage, deleted, failures chan<- string) error {
+	objects := page.objects
 	if cmd.DryRun {
-		for _, k := range keys {
-			deleted <- k
+		for _, o := range objects {
+			deleted <- o.String()
 		}
+		cmd.progress.settle(page, len(objects), nil)
 		return nil
 	}
 
+	var failed []objectVersion
+	if cmd.archive != nil {
+		archived := make([]objectVersion, 0, len(objects))
+		for _, o := range objects {
+			if o.backup {
+				if err := cmd.backupObject(o); err != nil {
+					if cmd.ctx.Err() != nil {
+						return cmd.ctx.Err()
+					}
+					log.Warn(logger, "Failed to back up S3 object; it will not be deleted",
+						"key", o.key, "version_id", o.versionID, "error", err)
+					failures <- o.String()
+					failed = append(failed, o)
+					continue
+				}
+			}
+			archived = append(archived, o)
+		}
+		objects = archived
+		if len(objects) == 0 {
+			cmd.progress.settle(page, 0, failed)
+			return nil
+		}
+		// Objects are only restorable from the archive once it is flushed
+		if err := cmd.archive.Flush(); err != nil {
+			return fmt.Errorf("error flushing backup archive: %w", err)
+		}
+	}
+
+	identifiers := make([]types.ObjectIdentifier, len(objects))
+	for i, o := range objects {
+		identifiers[i].Key = aws.String(o.key)
+		identifiers[i].VersionId = o.versionID
+	}
+
 	resp, err := cmd.s3svc.DeleteObjects(cmd.ctx, &s3.DeleteObjectsInput{
 		Bucket: aws.String(cmd.S3Bucket),
 		Delete: &types.Delete{Objects: identifiers},
@@ -265,18 +542,43 @@ func (cmd *Cmd) deleteObjects(logger log.Logger, keys []string, deleted, failure
 		defer wg.Done()
 		for _, f := range resp.Errors {
 			log.Debug(logger, "Failed to delete S3 object",
-				"key", f.Key, "message", f.Message, "code", f.Code)
-			failures <- *f.Key
+				"key", f.Key, "version_id", f.VersionId, "message", f.Message, "code", f.Code)
+			o := objectVersion{key: *f.Key, versionID: f.VersionId}
+			failures <- o.String()
+			failed = append(failed, o)
 		}
 	}()
 	go func() {
 		defer wg.Done()
 		for _, d := range resp.Deleted {
-			log.Debug(logger, "Deleted S3 object", "key", d.Key)
-			deleted <- *d.Key
+			log.Debug(logger, "Deleted S3 object", "key", d.Key, "version_id", d.VersionId)
+			deleted <- objectVersion{key: *d.Key, versionID: d.VersionId}.String()
 		}
 	}()
 	wg.Wait()
 
+	cmd.progress.settle(page, len(resp.Deleted), failed)
 	return nil
 }
+
+// backupObject copies an S3 object (version) to the backup archive.
+func (cmd *Cmd) backupObject(o objectVersion) error {
+	resp, err := cmd.s3svc.GetObject(cmd.ctx, &s3.GetObjectInput{
+		Bucket:    aws.String(cmd.S3Bucket),
+		Key:       aws.String(o.key),
+		VersionId: o.versionID,
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return cmd.archive.AddObject(backup.Object{
+		Key:             o.key,
+		Size:            aws.ToInt64(resp.ContentLength),
+		ETag:            strings.Trim(aws.ToString(resp.ETag), `"`),
+		LastModified:    resp.LastModified,
+		ContentType:     aws.ToString(resp.ContentType),
+		ContentEncoding: aws.ToString(resp.ContentEncoding),
+		Metadata:        resp.Metadata,
+	}, resp.Body)
+}
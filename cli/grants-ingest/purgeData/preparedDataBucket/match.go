@@ -3,20 +3,46 @@ package preparedDataBucket
 import (
 	"path/filepath"
 	"regexp"
+	"strings"
+
+	"github.com/usdigitalresponse/grants-ingest/internal/preparedData"
 )
 
 var (
-	FFISMatcher      = &RegexMatcher{regexp.MustCompile(`\d{3}/\d{3,}/ffis.org/v1.json`)}
-	GrantsGovMatcher = &RegexMatcher{regexp.MustCompile(`\d{3}/\d{3,}/grants.gov/v2.xml`)}
-	AllMatcher       = &RegexMatcher{regexp.MustCompile(`.*`)}
+	FFISMatcher          = &LayoutMatcher{[]preparedData.Layout{preparedData.FFIS}}
+	GrantsGovMatcher     = &LayoutMatcher{preparedData.GrantsGovLayouts}
+	OpportunitiesMatcher = &LayoutMatcher{[]preparedData.Layout{preparedData.GrantsGovOpportunity, preparedData.GrantsGovLegacy}}
+	ForecastsMatcher     = &LayoutMatcher{[]preparedData.Layout{preparedData.GrantsGovForecast}}
+	AllMatcher           = &RegexMatcher{regexp.MustCompile(`.*`)}
 )
 
 type Matcher interface {
-	SetPattern(string) error
 	Pattern() string
 	Match(string) bool
 }
 
+// LayoutMatcher matches the keys of prepared-data objects with any of the given layouts.
+type LayoutMatcher struct {
+	layouts []preparedData.Layout
+}
+
+func (m *LayoutMatcher) Match(key string) bool {
+	for _, l := range m.layouts {
+		if l.Match(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *LayoutMatcher) Pattern() string {
+	patterns := make([]string, len(m.layouts))
+	for i, l := range m.layouts {
+		patterns[i] = "NNN/<grant ID>/" + l.File
+	}
+	return strings.Join(patterns, "|")
+}
+
 type RegexMatcher struct {
 	expr *regexp.Regexp
 }
@@ -0,0 +1,219 @@
+package preparedDataTable
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/usdigitalresponse/grants-ingest/cli/tableScan"
+)
+
+// checkpointOptions are the options of a purge that cannot change when it is resumed.
+type checkpointOptions struct {
+	PurgeFFIS         bool      `json:"purge_ffis"`
+	PurgeGov          bool      `json:"purge_gov"`
+	KeepRevisionIDs   bool      `json:"keep_revision_ids"`
+	PurgeAll          bool      `json:"purge_all"`
+	ReadConcurrency   int       `json:"read_concurrency"`
+	GrantIDsFile      string    `json:"grant_ids_file"`
+	Agency            []string  `json:"agency"`
+	Stage             string    `json:"stage"`
+	LastUpdatedSince  time.Time `json:"last_updated_since"`
+	LastUpdatedBefore time.Time `json:"last_updated_before"`
+	RevisedSince      time.Time `json:"revised_since"`
+	RevisedBefore     time.Time `json:"revised_before"`
+}
+
+func (cmd *Cmd) checkpointOptions() checkpointOptions {
+	return checkpointOptions{
+		PurgeFFIS:         cmd.PurgeFFIS,
+		PurgeGov:          cmd.PurgeGov,
+		KeepRevisionIDs:   cmd.KeepRevisionIDs,
+		PurgeAll:          cmd.PurgeAll,
+		ReadConcurrency:   int(cmd.ReadConcurrency),
+		GrantIDsFile:      cmd.GrantIDsFile,
+		Agency:            cmd.Agency,
+		Stage:             cmd.Stage,
+		LastUpdatedSince:  cmd.LastUpdatedSince,
+		LastUpdatedBefore: cmd.LastUpdatedBefore,
+		RevisedSince:      cmd.RevisedSince,
+		RevisedBefore:     cmd.RevisedBefore,
+	}
+}
+
+// checkpointState is the saved progress of a purge.
+type checkpointState struct {
+	Scanned  int64               `json:"scanned"`
+	Purged   int64               `json:"purged"`
+	Segments []segmentCheckpoint `json:"segments"`
+	// Location of the backup archive, which a resumed purge adds to
+	BackupLocation string `json:"backup_location,omitempty"`
+}
+
+type segmentCheckpoint struct {
+	// Key at which the scan of the segment resumes, or nil to scan from the beginning
+	StartKey map[string]any `json:"start_key,omitempty"`
+	Complete bool           `json:"complete,omitempty"`
+	// Grant IDs of items that were purged after StartKey. These items are skipped if they are
+	// scanned again, so that they are not counted twice.
+	PurgedGrantIDs []string `json:"purged_grant_ids,omitempty"`
+}
+
+// progress tracks the purge of scanned items, so that the scan position of each segment only
+// advances past items once they are purged (or are skipped because they do not match the
+// purge filters). Totals include the items before each segment's scan position, along with
+// any items purged after it.
+type progress struct {
+	mu       sync.Mutex
+	scanned  int64
+	purged   int64
+	segments []*segmentProgress
+	// Set once the backup archive is opened, before the purge begins
+	backupLocation string
+}
+
+type segmentProgress struct {
+	startKey map[string]types.AttributeValue
+	complete bool
+	// Pages scanned after startKey, in scan order
+	pages []*scannedPage
+	// Grant IDs of items purged after startKey by an interrupted run, which have not been
+	// scanned again
+	resumedGrantIDs map[string]bool
+}
+
+// scannedPage tracks the items of a scanned page that have not yet been settled.
+type scannedPage struct {
+	segment *segmentProgress
+	lastKey map[string]types.AttributeValue
+	pending int
+	scanned int64
+	// Grant IDs of items on the page that were purged by this run
+	purgedGrantIDs []string
+	// Grant IDs of items on the page that were purged by an interrupted run
+	resumedGrantIDs []string
+}
+
+// scannedItem is a table item, along with the page on which it was scanned.
+type scannedItem struct {
+	item DDBItem
+	page *scannedPage
+}
+
+// newProgress returns progress that begins at the given checkpoint state, which is empty unless
+// an interrupted purge is resumed.
+func newProgress(state checkpointState, totalSegments int) (*progress, error) {
+	// The backup location is retained when the resumed purge is not backed up, so that it may
+	// be resumed again
+	p := &progress{scanned: state.Scanned, purged: state.Purged, backupLocation: state.BackupLocation}
+	for i := 0; i < totalSegments; i++ {
+		seg := &segmentProgress{resumedGrantIDs: make(map[string]bool)}
+		if i < len(state.Segments) {
+			saved := state.Segments[i]
+			if saved.StartKey != nil {
+				key, err := attributevalue.MarshalMap(saved.StartKey)
+				if err != nil {
+					return nil, err
+				}
+				seg.startKey = key
+			}
+			seg.complete = saved.Complete
+			for _, id := range saved.PurgedGrantIDs {
+				seg.resumedGrantIDs[id] = true
+			}
+		}
+		p.segments = append(p.segments, seg)
+	}
+	return p, nil
+}
+
+// start returns the key at which the scan of a segment should begin, and whether the segment
+// has already been scanned completely.
+func (p *progress) start(segment int) (map[string]types.AttributeValue, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	seg := p.segments[segment]
+	return seg.startKey, seg.complete
+}
+
+// addPage begins tracking the items of a scanned page. Pages of each segment must be added in
+// scan order.
+func (p *progress) addPage(segment int, page tableScan.Page) *scannedPage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	seg := p.segments[segment]
+	sp := &scannedPage{segment: seg, lastKey: page.LastEvaluatedKey, pending: len(page.Items)}
+	seg.pages = append(seg.pages, sp)
+	p.advance(seg)
+	return sp
+}
+
+// resumed reports whether the item was already purged by an interrupted run, in which case
+// it is settled without being counted again.
+func (p *progress) resumed(si scannedItem) bool {
+	grantID := stringAttr(si.item, "grant_id")
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	seg := si.page.segment
+	if !seg.resumedGrantIDs[grantID] {
+		return false
+	}
+	delete(seg.resumedGrantIDs, grantID)
+	si.page.resumedGrantIDs = append(si.page.resumedGrantIDs, grantID)
+	si.page.pending--
+	p.advance(seg)
+	return true
+}
+
+// settle records that a scanned item was either purged or skipped.
+func (p *progress) settle(page *scannedPage, grantID string, purged bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	page.pending--
+	page.scanned++
+	if purged {
+		page.purgedGrantIDs = append(page.purgedGrantIDs, grantID)
+	}
+	p.advance(page.segment)
+}
+
+// advance moves the scan position of a segment past its leading pages that are fully settled.
+func (p *progress) advance(seg *segmentProgress) {
+	for len(seg.pages) > 0 && seg.pages[0].pending == 0 {
+		page := seg.pages[0]
+		seg.pages = seg.pages[1:]
+		seg.startKey = page.lastKey
+		seg.complete = page.lastKey == nil
+		p.scanned += page.scanned
+		p.purged += int64(len(page.purgedGrantIDs))
+	}
+}
+
+// checkpoint returns the current progress as checkpoint state.
+func (p *progress) checkpoint() (checkpointState, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	state := checkpointState{Scanned: p.scanned, Purged: p.purged, BackupLocation: p.backupLocation}
+	for _, seg := range p.segments {
+		saved := segmentCheckpoint{Complete: seg.complete}
+		if seg.startKey != nil {
+			if err := attributevalue.UnmarshalMap(seg.startKey, &saved.StartKey); err != nil {
+				return state, err
+			}
+		}
+		for id := range seg.resumedGrantIDs {
+			saved.PurgedGrantIDs = append(saved.PurgedGrantIDs, id)
+		}
+		for _, page := range seg.pages {
+			saved.PurgedGrantIDs = append(saved.PurgedGrantIDs, page.resumedGrantIDs...)
+			saved.PurgedGrantIDs = append(saved.PurgedGrantIDs, page.purgedGrantIDs...)
+			state.Scanned += int64(len(page.purgedGrantIDs))
+			state.Purged += int64(len(page.purgedGrantIDs))
+		}
+		sort.Strings(saved.PurgedGrantIDs)
+		state.Segments = append(state.Segments, saved)
+	}
+	return state, nil
+}
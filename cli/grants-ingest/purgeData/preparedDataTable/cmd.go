@@ -5,16 +5,23 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
-	"sync"
+	"slices"
 	"syscall"
 	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/cenkalti/backoff/v4"
+	"github.com/hashicorp/go-multierror"
+	"github.com/usdigitalresponse/grants-ingest/cli/backup"
+	"github.com/usdigitalresponse/grants-ingest/cli/checkpoint"
+	"github.com/usdigitalresponse/grants-ingest/cli/profile"
+	"github.com/usdigitalresponse/grants-ingest/cli/tableScan"
 	ct "github.com/usdigitalresponse/grants-ingest/cli/types"
 	"github.com/usdigitalresponse/grants-ingest/internal/awsHelpers"
 	"github.com/usdigitalresponse/grants-ingest/internal/log"
@@ -22,30 +29,63 @@ import (
 
 // Aliases
 type (
-	DDBItem map[string]types.AttributeValue
+	DDBItem = map[string]types.AttributeValue
 )
 
 type Cmd struct {
 	// Positional arguments
-	TableName string `arg:"" name:"table" help:"Name of the DynamoDB table from which to purge data."`
+	TableName string `arg:"" optional:"" name:"table" help:"Name of the DynamoDB table from which to purge data (default: the table of the selected profile)."`
 
 	// Flags
 	PurgeFFIS        bool                `help:"Purge all item attributes sourced from FFIS.org data (ignored if --purge-all is given)."`
 	PurgeGov         bool                `help:"Purge all item attributes sourced from Grants.gov data (ignored if --purge-all is given)."`
-	KeepRevisionIDs  bool                `name:"keep-revision-ids" help:"Retain item revision_id attributes when purging data (ignored if --purge-all is given)."`
+	KeepRevisionIDs  bool                `name:"keep-revision-ids" help:"Retain item revision attributes when purging data (ignored if --purge-all is given)."`
 	PurgeAll         bool                `help:"Delete all table items completely."`
 	ReadConcurrency  ct.ConcurrencyLimit `default:"1" help:"Max DynamoDB parallel scan workers."`
 	WriteConcurrency ct.ConcurrencyLimit `default:"10" help:"Max concurrent batch-write operations."`
 	TotalsAfter      ct.TotalsAfter      `default:"1000" help:"Log DynamoDB item totals after this many successful/failed deletions (silent if 0)."`
 	IgnoreStreams    bool                `help:"Purge data even if the DynamoDB table has an active stream. Not recommended."`
 	DryRun           bool                `help:"Dry run only - no DynamoDB table items will be modified or deleted."`
+	S3UsePathStyle   bool                `name:"s3-use-path-style" help:"Use path-style addressing for an S3 backup location."`
+	backup.Flags     `embed:""`
+	Checkpoint       checkpoint.Flags `embed:""`
+	Filters          `embed:""`
 
 	// Internal
 	ctx       context.Context
 	stop      context.CancelFunc
 	ddb       *dynamodb.Client
 	ddbstream *dynamodbstreams.Client
+	s3        *s3.Client
 	logger    *log.Logger
+	archive   *backup.Writer
+	progress  *progress
+}
+
+// pendingWrite is a write request that purges a scanned item.
+type pendingWrite struct {
+	request types.WriteRequest
+	grantID string
+	page    *scannedPage
+}
+
+// archiveFlushSize is the number of items that are archived together before any of them
+// are purged. It is a multiple of the maximum batch-write size.
+const archiveFlushSize = 1000
+
+func (cmd *Cmd) Validate() error {
+	if cmd.PreviewSampleSize < 0 {
+		return fmt.Errorf("--preview-sample-size must be >= 0")
+	}
+	if !cmd.LastUpdatedSince.IsZero() && !cmd.LastUpdatedBefore.IsZero() &&
+		!cmd.LastUpdatedSince.Before(cmd.LastUpdatedBefore) {
+		return fmt.Errorf("--last-updated-since must be before --last-updated-before")
+	}
+	if !cmd.RevisedSince.IsZero() && !cmd.RevisedBefore.IsZero() &&
+		!cmd.RevisedSince.Before(cmd.RevisedBefore) {
+		return fmt.Errorf("--revised-since must be before --revised-before")
+	}
+	return nil
 }
 
 func (cmd *Cmd) BeforeApply(app *kong.Kong, logger *log.Logger) error {
@@ -55,7 +95,14 @@ func (cmd *Cmd) BeforeApply(app *kong.Kong, logger *log.Logger) error {
 	return nil
 }
 
-func (cmd *Cmd) AfterApply(app *kong.Kong) error {
+func (cmd *Cmd) AfterApply(app *kong.Kong, selected *profile.Profile) error {
+	table, err := profile.Default(cmd.TableName, selected.PreparedDataTable,
+		"<table>", "prepared_data_table")
+	if err != nil {
+		return err
+	}
+	cmd.TableName = table
+
 	cfg, err := awsHelpers.GetConfig(cmd.ctx)
 	if err != nil {
 		err := fmt.Errorf("failed to configure AWS SDK: %w", err)
@@ -65,23 +112,110 @@ func (cmd *Cmd) AfterApply(app *kong.Kong) error {
 	}
 	cmd.ddb = dynamodb.NewFromConfig(cfg)
 	cmd.ddbstream = dynamodbstreams.NewFromConfig(cfg)
+	cmd.s3 = s3.NewFromConfig(cfg, func(o *s3.Options) { o.UsePathStyle = cmd.S3UsePathStyle })
+	if err := cmd.Filters.load(); err != nil {
+		return fmt.Errorf("failed to load grant IDs: %w", err)
+	}
 	return nil
 }
 
+// Destructive reports whether the command modifies or deletes items.
+func (cmd *Cmd) Destructive() bool {
+	return !cmd.DryRun
+}
+
 func (cmd *Cmd) Run(app *kong.Kong) error {
 	if !cmd.tableStreamsInactive(app) {
 		return fmt.Errorf("table stream check failed")
 	}
 	defer cmd.stop()
 
-	scannedItems := make(chan DDBItem)
-	batchedRequests := make(chan []types.WriteRequest)
+	var state checkpointState
+	checkpointFile, err := cmd.Checkpoint.Open(cmd.TableName, cmd.checkpointOptions(), &state)
+	if err != nil {
+		return log.Errorf(*cmd.logger, "Error opening checkpoint file", err)
+	}
+	if cmd.progress, err = newProgress(state, int(cmd.ReadConcurrency)); err != nil {
+		return log.Errorf(*cmd.logger, "Error reading checkpoint file", err,
+			"path", checkpointFile.Path())
+	}
+	if cmd.Checkpoint.Resume {
+		log.Info(*cmd.logger, "Resuming interrupted purge from checkpoint",
+			"path", checkpointFile.Path(), "scanned", state.Scanned, "purged", state.Purged)
+	}
+
+	if cmd.Filters.IsSet() {
+		count, err := cmd.preview(app.Stdout)
+		if err != nil {
+			return log.Errorf(*cmd.logger, "Error previewing items matched by purge filters", err)
+		}
+		if count == 0 {
+			log.Info(*cmd.logger, "No items match the purge filters")
+			return nil
+		}
+		if !cmd.DryRun && !cmd.SkipPreviewConfirm && !confirm(os.Stdin, app.Stdout, count) {
+			return fmt.Errorf("purge was not confirmed")
+		}
+	}
+
+	if cmd.DryRun {
+		log.Info(*cmd.logger, "Skipping backup of purged items for dry run")
+	} else {
+		var archive *backup.Writer
+		if cmd.Checkpoint.Resume {
+			archive, err = cmd.Flags.Resume(cmd.ctx, cmd.s3, backup.KindDynamoDBTable, cmd.TableName,
+				state.BackupLocation)
+		} else {
+			archive, err = cmd.Flags.Create(cmd.ctx, cmd.s3, backup.KindDynamoDBTable, cmd.TableName)
+		}
+		if err != nil {
+			return log.Errorf(*cmd.logger, "Error opening backup archive", err)
+		}
+		if archive == nil {
+			log.Warn(*cmd.logger, "Backup is disabled; purged items will not be recoverable")
+		} else {
+			cmd.archive = archive
+			cmd.progress.backupLocation = archive.Location()
+			log.Info(*cmd.logger, "Backing up items before they are purged",
+				"location", archive.Location(), "resumed", cmd.Checkpoint.Resume)
+		}
+		// The backup location is saved immediately, so that a purge which is interrupted
+		// before its first periodic checkpoint can still be resumed with the same archive
+		if err := cmd.saveCheckpoint(checkpointFile); err != nil {
+			return log.Errorf(*cmd.logger, "Error saving checkpoint file", err, "path", checkpointFile.Path())
+		}
+	}
+
+	scannedItems := make(chan scannedItem)
+	batchedRequests := make(chan []pendingWrite)
 	purgeCounts := make(chan int)
 
+	checkpointDone := make(chan struct{})
+	checkpointStop := make(chan struct{})
+	go func() {
+		defer close(checkpointDone)
+		if cmd.DryRun {
+			return
+		}
+		ticker := time.NewTicker(checkpoint.SaveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := cmd.saveCheckpoint(checkpointFile); err != nil {
+					log.Warn(*cmd.logger, "Error saving checkpoint file", "error", err,
+						"path", checkpointFile.Path())
+				}
+			case <-checkpointStop:
+				return
+			}
+		}
+	}()
+
 	reportingDone := make(chan struct{})
 	go func() {
 		defer func() { close(reportingDone) }()
-		var totalPurged int64
+		totalPurged := state.Purged
 		for nextCount := range purgeCounts {
 			for i := 0; i < nextCount; i++ {
 				totalPurged++
@@ -93,123 +227,224 @@ func (cmd *Cmd) Run(app *kong.Kong) error {
 		log.Info(*cmd.logger, "Final count of purged items", "count", totalPurged)
 	}()
 
+	var archiveErr error
 	go func() {
 		defer close(batchedRequests)
-		var totalScanned int64
-		currentBatch := make([]types.WriteRequest, 0, 25)
-		for item := range scannedItems {
+		totalScanned := state.Scanned
+		pending := make([]pendingWrite, 0, 25)
+		// Without a backup, each batch of items is purged as soon as it is scanned.
+		// Otherwise, items are purged only after they are archived.
+		flushSize := 25
+		if cmd.archive != nil {
+			flushSize = archiveFlushSize
+		}
+		flush := func() {
+			if cmd.archive != nil && archiveErr == nil {
+				if archiveErr = cmd.archive.Flush(); archiveErr != nil {
+					log.Error(*cmd.logger,
+						"Stopping application due to fatal error encountered while backing up DynamoDB items",
+						archiveErr)
+					cmd.stop()
+				}
+			}
+			if archiveErr != nil {
+				pending = pending[:0]
+				return
+			}
+			for len(pending) > 0 && cmd.ctx.Err() == nil {
+				n := min(25, len(pending))
+				select {
+				case batchedRequests <- pending[:n:n]:
+					pending = pending[n:]
+				case <-cmd.ctx.Done():
+				}
+			}
+			pending = make([]pendingWrite, 0, 25)
+		}
+		for si := range scannedItems {
+			if cmd.progress.resumed(si) {
+				log.Debug(*cmd.logger, "Skipping item purged before the purge was interrupted",
+					"grant_id", stringAttr(si.item, "grant_id"))
+				continue
+			}
+			item := si.item
+			grantID := stringAttr(item, "grant_id")
 			totalScanned++
 			if cmd.TotalsAfter.Check(totalScanned) {
 				log.Info(*cmd.logger, "Updated scanned items total", "count", totalScanned)
 			}
-			currentBatch = append(currentBatch, cmd.writeRequestForItem(item))
-			if len(currentBatch) == 25 {
-				batchedRequests <- currentBatch
-				currentBatch = make([]types.WriteRequest, 0, 25)
+			match, err := cmd.Filters.matches(item)
+			if err != nil {
+				log.Warn(*cmd.logger, "Skipping item that cannot be checked against the purge filters",
+					"grant_id", grantID, "error", err)
+			}
+			if !match {
+				cmd.progress.settle(si.page, grantID, false)
+				continue
+			}
+			if cmd.archive != nil && archiveErr == nil {
+				if archiveErr = cmd.archive.AddItem(item); archiveErr != nil {
+					log.Error(*cmd.logger,
+						"Stopping application due to fatal error encountered while backing up DynamoDB items",
+						archiveErr)
+					cmd.stop()
+				}
+			}
+			pending = append(pending, pendingWrite{cmd.writeRequestForItem(item), grantID, si.page})
+			if len(pending) >= flushSize {
+				flush()
 			}
 		}
-		if len(currentBatch) > 0 {
-			batchedRequests <- currentBatch
-		}
+		flush()
 	}()
 
-	var purgeItemsErr error
-	purgeWg := sync.WaitGroup{}
+	purgeGroup := multierror.Group{}
 	for i := 0; i < int(cmd.WriteConcurrency); i++ {
 		logger := log.WithSuffix(*cmd.logger, "worker_id", i)
-		purgeWg.Add(1)
-		go func() {
-			defer purgeWg.Done()
+		purgeGroup.Go(func() error {
 			err := cmd.purgeWorker(logger, batchedRequests, purgeCounts)
 			if err != nil && err != context.Canceled {
 				log.Error(*cmd.logger,
 					"Stopping application due to fatal error encountered while purging DynamoDB items",
 					err)
-				purgeItemsErr = err
 				cmd.stop()
+				return err
 			}
-		}()
+			return nil
+		})
 	}
 
-	var scanTableErr error
-	scanWg := sync.WaitGroup{}
+	scanGroup := multierror.Group{}
 	for i := 0; i < int(cmd.ReadConcurrency); i++ {
-		scanWg.Add(1)
 		segmentId := i
-		go func() {
-			defer scanWg.Done()
+		scanGroup.Go(func() error {
 			err := cmd.scanTable(segmentId, scannedItems)
 			if err != nil && err != context.Canceled {
 				log.Error(*cmd.logger,
 					"Stopping application due to fatal error encountered while scanning DynamoDB items",
 					err)
-				scanTableErr = err
 				cmd.stop()
+				return err
 			}
-		}()
+			return nil
+		})
 	}
 
-	scanWg.Wait()
+	scanTableErr := scanGroup.Wait().ErrorOrNil()
 	close(scannedItems)
-	purgeWg.Wait()
+	purgeItemsErr := purgeGroup.Wait().ErrorOrNil()
 	close(purgeCounts)
 	<-reportingDone
+	close(checkpointStop)
+	<-checkpointDone
+
+	if cmd.archive != nil {
+		if err := cmd.archive.Close(); err != nil {
+			log.Error(*cmd.logger, "Error finalizing backup archive", err,
+				"location", cmd.archive.Location())
+			archiveErr = err
+		} else {
+			log.Info(*cmd.logger, "Backup archive is complete", "location", cmd.archive.Location())
+		}
+	}
 
-	if cmd.ctx.Err() != nil || purgeItemsErr != nil || scanTableErr != nil {
+	if cmd.ctx.Err() != nil || purgeItemsErr != nil || scanTableErr != nil || archiveErr != nil {
+		if !cmd.DryRun {
+			if err := cmd.saveCheckpoint(checkpointFile); err != nil {
+				log.Error(*cmd.logger, "Error saving checkpoint file", err, "path", checkpointFile.Path())
+			} else {
+				log.Info(*cmd.logger, "Saved purge progress; rerun with --resume to continue the purge",
+					"path", checkpointFile.Path())
+			}
+		}
 		return fmt.Errorf("the operation completed with errors")
 	}
 
+	if !cmd.DryRun {
+		if err := checkpointFile.Remove(); err != nil {
+			log.Warn(*cmd.logger, "Error removing checkpoint file of completed purge", "error", err,
+				"path", checkpointFile.Path())
+		}
+	}
 	return nil
 }
 
-func (cmd *Cmd) scanTable(segmentId int, ch chan<- DDBItem) (err error) {
-	logger := log.WithSuffix(*cmd.logger, "worker_id", segmentId)
-	defer func() {
-		msg := "Scan worker shutting down"
-		if err == nil {
-			log.Debug(logger, msg, "reason", "no more work")
-		} else if err == context.Canceled {
-			log.Warn(logger, msg, "reason", "shutdown requested")
-		} else {
-			log.Error(logger, msg, err, "reason", "fatal error")
-		}
-	}()
+func (cmd *Cmd) saveCheckpoint(f *checkpoint.File) error {
+	state, err := cmd.progress.checkpoint()
+	if err != nil {
+		return err
+	}
+	return f.Save(state)
+}
 
-	input := &dynamodb.ScanInput{
-		TableName:      aws.String(cmd.TableName),
-		ConsistentRead: aws.Bool(true),
+func (cmd *Cmd) scanTable(segmentId int, ch chan<- scannedItem) error {
+	startKey, complete := cmd.progress.start(segmentId)
+	if complete {
+		log.Debug(*cmd.logger, "Skipping segment scanned before the purge was interrupted",
+			"worker_id", segmentId)
+		return nil
 	}
-	if cmd.ReadConcurrency > 1 {
-		input.Segment = aws.Int32(int32(segmentId))
-		input.TotalSegments = aws.Int32(int32(cmd.ReadConcurrency))
+	var projection []string
+	if cmd.PurgeAll && cmd.archive == nil {
+		projection = append([]string{"grant_id"}, cmd.Filters.projectedAttributes()...)
 	}
-	if cmd.PurgeAll {
-		input.ProjectionExpression = aws.String("grant_id")
+	input, err := cmd.buildScanInput(projection)
+	if err != nil {
+		return err
 	}
+	input.ExclusiveStartKey = startKey
 
-	for {
-		select {
-		case <-cmd.ctx.Done():
-			return cmd.ctx.Err()
-		default:
-			resp, err := cmd.ddb.Scan(cmd.ctx, input)
-			if err != nil {
-				log.Error(logger, "Error scanning DynamoDB table items", err)
-				return err
-			}
-			for _, item := range resp.Items {
-				log.Debug(logger, "Item found in scan", "item", item)
-				ch <- item
-			}
-			if resp.LastEvaluatedKey == nil {
-				return nil
+	pages := make(chan tableScan.Page)
+	scanErr := make(chan error, 1)
+	go func() {
+		defer close(pages)
+		scanErr <- tableScan.SegmentPages(cmd.ctx, cmd.ddb, *cmd.logger, input,
+			segmentId, int(cmd.ReadConcurrency), pages)
+	}()
+	for page := range pages {
+		sp := cmd.progress.addPage(segmentId, page)
+		for _, item := range page.Items {
+			select {
+			case ch <- scannedItem{item, sp}:
+			case <-cmd.ctx.Done():
 			}
-			input.ExclusiveStartKey = resp.LastEvaluatedKey
 		}
 	}
+	return <-scanErr
 }
 
-func (cmd *Cmd) purgeWorker(logger log.Logger, batches <-chan []types.WriteRequest, purgeCounts chan<- int) (err error) {
+// buildScanInput returns input for scanning the table with the configured filters. When projection
+// is non-empty, only the named attributes of scanned items are returned.
+func (cmd *Cmd) buildScanInput(projection []string) (dynamodb.ScanInput, error) {
+	input := dynamodb.ScanInput{TableName: aws.String(cmd.TableName)}
+	builder := expression.NewBuilder()
+	condition, hasCondition := cmd.Filters.condition()
+	if hasCondition {
+		builder = builder.WithFilter(condition)
+	}
+	if len(projection) > 0 {
+		proj := expression.NamesList(expression.Name(projection[0]))
+		for _, name := range projection[1:] {
+			proj = proj.AddNames(expression.Name(name))
+		}
+		builder = builder.WithProjection(proj)
+	}
+	if !hasCondition && len(projection) == 0 {
+		return input, nil
+	}
+
+	expr, err := builder.Build()
+	if err != nil {
+		return input, err
+	}
+	input.FilterExpression = expr.Filter()
+	input.ProjectionExpression = expr.Projection()
+	input.ExpressionAttributeNames = expr.Names()
+	input.ExpressionAttributeValues = expr.Values()
+	return input, nil
+}
+
+func (cmd *Cmd) purgeWorker(logger log.Logger, batches <-chan []pendingWrite, purgeCounts chan<- int) (err error) {
 	defer func() {
 		msg := "Purge worker shutting down"
 		if err == nil {
@@ -241,10 +476,14 @@ func (cmd *Cmd) purgeWorker(logger log.Logger, batches <-chan []types.WriteReque
 	}
 }
 
-func (cmd *Cmd) purgeItems(logger log.Logger, batch []types.WriteRequest, purgeCounts chan<- int) error {
+func (cmd *Cmd) purgeItems(logger log.Logger, batch []pendingWrite, purgeCounts chan<- int) error {
+	requests := make([]types.WriteRequest, len(batch))
+	for i, w := range batch {
+		requests[i] = w.request
+	}
 	input := dynamodb.BatchWriteItemInput{
 		RequestItems: map[string][]types.WriteRequest{
-			cmd.TableName: batch,
+			cmd.TableName: requests,
 		},
 	}
 
@@ -253,18 +492,20 @@ func (cmd *Cmd) purgeItems(logger log.Logger, batch []types.WriteRequest, purgeC
 			thisBatchSize := len(input.RequestItems[cmd.TableName])
 			if cmd.DryRun {
 				purgeCounts <- thisBatchSize
+				batch = cmd.settlePurged(batch, nil)
 				return nil
 			}
 			resp, err := cmd.ddb.BatchWriteItem(cmd.ctx, &input)
 			if err != nil {
 				return backoff.Permanent(err)
 			}
-			countUnprocessed := len(resp.UnprocessedItems)
-			purgeCounts <- (thisBatchSize - countUnprocessed)
-			if countUnprocessed > 0 {
+			unprocessed := resp.UnprocessedItems[cmd.TableName]
+			purgeCounts <- (thisBatchSize - len(unprocessed))
+			batch = cmd.settlePurged(batch, unprocessed)
+			if len(unprocessed) > 0 {
 				input.RequestItems = resp.UnprocessedItems
 				return fmt.Errorf("dynamodb batch write operation returned %d unprocessed items",
-					len(resp.UnprocessedItems))
+					len(unprocessed))
 			}
 			return nil
 		},
@@ -282,29 +523,56 @@ func (cmd *Cmd) purgeItems(logger log.Logger, batch []types.WriteRequest, purgeC
 	return err
 }
 
+// settlePurged records the purge of each write in batch that is not among the unprocessed
+// requests of a batch-write operation, and returns the writes that remain unprocessed.
+func (cmd *Cmd) settlePurged(batch []pendingWrite, unprocessed []types.WriteRequest) []pendingWrite {
+	unprocessedIDs := make(map[string]bool, len(unprocessed))
+	for _, req := range unprocessed {
+		if req.DeleteRequest != nil {
+			unprocessedIDs[stringAttr(req.DeleteRequest.Key, "grant_id")] = true
+		} else if req.PutRequest != nil {
+			unprocessedIDs[stringAttr(req.PutRequest.Item, "grant_id")] = true
+		}
+	}
+	remaining := make([]pendingWrite, 0, len(unprocessed))
+	for _, w := range batch {
+		if unprocessedIDs[w.grantID] {
+			remaining = append(remaining, w)
+		} else {
+			cmd.progress.settle(w.page, w.grantID, true)
+		}
+	}
+	return remaining
+}
+
+// ffisAttributes are the item attributes that are sourced from FFIS.org data.
+var ffisAttributes = []string{"Bill", "BillStaleSince", "FFISEligibility"}
+
 func (cmd *Cmd) writeRequestForItem(item DDBItem) types.WriteRequest {
 	req := types.WriteRequest{}
 
 	if cmd.PurgeAll {
-		req.DeleteRequest = &types.DeleteRequest{Key: item}
+		req.DeleteRequest = &types.DeleteRequest{Key: DDBItem{"grant_id": item["grant_id"]}}
 		return req
 	}
 
 	if !cmd.KeepRevisionIDs {
-		delete(item, "revision_id")
+		delete(item, "revision")
 	}
 	if cmd.PurgeFFIS {
-		delete(item, "Bill")
+		for _, k := range ffisAttributes {
+			delete(item, k)
+		}
 	}
 	if cmd.PurgeGov {
 		for k := range item {
 			if k == "grant_id" {
 				continue
 			}
-			if k == "Bill" {
+			if slices.Contains(ffisAttributes, k) {
 				continue
 			}
-			if k == "revision_id" {
+			if k == "revision" {
 				continue
 			}
 			delete(item, k)
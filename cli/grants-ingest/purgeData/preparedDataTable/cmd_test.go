@@ -0,0 +1,69 @@
+package preparedDataTable
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+)
+
+func TestWriteRequestForItem(t *testing.T) {
+	newItem := func() DDBItem {
+		item := DDBItem{}
+		for _, k := range []string{"grant_id", "revision", "OpportunityTitle", "CloseDate",
+			"Bill", "BillStaleSince", "FFISEligibility"} {
+			item[k] = &types.AttributeValueMemberS{Value: k}
+		}
+		return item
+	}
+	logger := log.Logger(nil)
+	log.ConfigureLogger(&logger, "ERROR")
+
+	for _, tt := range []struct {
+		name    string
+		cmd     Cmd
+		expKeys []string
+	}{
+		{
+			"purge FFIS",
+			Cmd{PurgeFFIS: true, KeepRevisionIDs: true},
+			[]string{"CloseDate", "OpportunityTitle", "grant_id", "revision"},
+		},
+		{
+			"purge Grants.gov",
+			Cmd{PurgeGov: true, KeepRevisionIDs: true},
+			[]string{"Bill", "BillStaleSince", "FFISEligibility", "grant_id", "revision"},
+		},
+		{
+			"purge FFIS without revision IDs",
+			Cmd{PurgeFFIS: true},
+			[]string{"CloseDate", "OpportunityTitle", "grant_id"},
+		},
+		{
+			"purge Grants.gov without revision IDs",
+			Cmd{PurgeGov: true},
+			[]string{"Bill", "BillStaleSince", "FFISEligibility", "grant_id"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cmd.logger = &logger
+			req := tt.cmd.writeRequestForItem(newItem())
+			require.NotNil(t, req.PutRequest)
+			assert.Nil(t, req.DeleteRequest)
+			assert.Equal(t, tt.expKeys, slices.Sorted(maps.Keys(req.PutRequest.Item)))
+		})
+	}
+
+	t.Run("purge all", func(t *testing.T) {
+		cmd := Cmd{PurgeAll: true, PurgeFFIS: true, logger: &logger}
+		req := cmd.writeRequestForItem(newItem())
+		assert.Nil(t, req.PutRequest)
+		require.NotNil(t, req.DeleteRequest)
+		assert.Equal(t, DDBItem{"grant_id": &types.AttributeValueMemberS{Value: "grant_id"}},
+			req.DeleteRequest.Key)
+	})
+}
@@ -0,0 +1,179 @@
+package preparedDataTable
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/oklog/ulid/v2"
+	"github.com/usdigitalresponse/grants-ingest/internal/itemMapper"
+)
+
+// maxFilterGrantIDs is the maximum number of grant IDs for which a scan filter expression is used.
+// DynamoDB allows at most 100 operands for the IN comparator; larger sets of grant IDs are
+// only filtered after items are scanned.
+const maxFilterGrantIDs = 100
+
+// Filters select the items to purge. An item is purged only if it matches every given filter.
+type Filters struct {
+	GrantIDsFile       string    `name:"grant-ids-file" type:"existingfile" predictor:"file" placeholder:"PATH" help:"Only purge items for grant IDs listed in this file (one per line)."`
+	Agency             []string  `name:"agency" placeholder:"CODE" help:"Only purge items for grants from the given agency code, including its sub-agencies (repeatable)."`
+	Stage              string    `enum:"all,forecast,posted" default:"all" help:"Only purge items for forecasted or posted grants (all|forecast|posted)."`
+	LastUpdatedSince   time.Time `name:"last-updated-since" format:"2006-01-02" placeholder:"YYYY-MM-DD" help:"Only purge items for grants last updated on or after this date."`
+	LastUpdatedBefore  time.Time `name:"last-updated-before" format:"2006-01-02" placeholder:"YYYY-MM-DD" help:"Only purge items for grants last updated before this date."`
+	RevisedSince       time.Time `name:"revised-since" placeholder:"RFC3339" help:"Only purge items whose current revision was created at or after this time."`
+	RevisedBefore      time.Time `name:"revised-before" placeholder:"RFC3339" help:"Only purge items whose current revision was created before this time."`
+	PreviewSampleSize  int       `name:"preview-sample-size" default:"10" help:"Number of matching items to show in the preview of a filtered purge."`
+	SkipPreviewConfirm bool      `name:"yes" short:"y" help:"Proceed with a filtered purge after the preview without asking for confirmation."`
+
+	grantIDs map[string]struct{}
+}
+
+// IsSet reports whether any item filters are configured.
+func (f *Filters) IsSet() bool {
+	return f.GrantIDsFile != "" || len(f.Agency) > 0 || f.Stage != "all" ||
+		!f.LastUpdatedSince.IsZero() || !f.LastUpdatedBefore.IsZero() ||
+		!f.RevisedSince.IsZero() || !f.RevisedBefore.IsZero()
+}
+
+// load reads the grant IDs file, if configured.
+func (f *Filters) load() error {
+	if f.GrantIDsFile == "" {
+		return nil
+	}
+	file, err := os.Open(f.GrantIDsFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	f.grantIDs = make(map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		id := strings.TrimSpace(scanner.Text())
+		if id == "" || strings.HasPrefix(id, "#") {
+			continue
+		}
+		f.grantIDs[id] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(f.grantIDs) == 0 {
+		return fmt.Errorf("no grant IDs found in %s", f.GrantIDsFile)
+	}
+	return nil
+}
+
+// condition returns a scan filter condition for the filters that can be evaluated by DynamoDB,
+// or false if there are no such filters.
+func (f *Filters) condition() (expression.ConditionBuilder, bool) {
+	conditions := []expression.ConditionBuilder{}
+
+	if len(f.grantIDs) > 0 && len(f.grantIDs) <= maxFilterGrantIDs {
+		operands := make([]expression.OperandBuilder, 0, len(f.grantIDs))
+		for id := range f.grantIDs {
+			operands = append(operands, expression.Value(id))
+		}
+		conditions = append(conditions,
+			expression.Name("grant_id").In(operands[0], operands[1:]...))
+	}
+
+	if len(f.Agency) > 0 {
+		agencyConditions := []expression.ConditionBuilder{}
+		for _, code := range f.Agency {
+			code = strings.ToUpper(code)
+			agencyConditions = append(agencyConditions,
+				expression.Name("AgencyCode").Equal(expression.Value(code)),
+				expression.Name("AgencyCode").BeginsWith(code+"-"))
+		}
+		conditions = append(conditions, expression.Or(
+			agencyConditions[0], agencyConditions[1], agencyConditions[2:]...))
+	}
+
+	switch f.Stage {
+	case "forecast":
+		conditions = append(conditions, expression.Name("is_forecast").Equal(expression.Value(true)))
+	case "posted":
+		conditions = append(conditions, expression.Or(
+			expression.AttributeNotExists(expression.Name("is_forecast")),
+			expression.Name("is_forecast").Equal(expression.Value(false))))
+	}
+
+	// ULIDs sort lexically by time, so the lowest ULID for a given time is a bound
+	// for all revisions created before or after that time.
+	if !f.RevisedSince.IsZero() {
+		conditions = append(conditions, expression.Name("revision").GreaterThanEqual(
+			expression.Value(minULIDForTime(f.RevisedSince))))
+	}
+	if !f.RevisedBefore.IsZero() {
+		conditions = append(conditions, expression.Name("revision").LessThan(
+			expression.Value(minULIDForTime(f.RevisedBefore))))
+	}
+
+	if len(conditions) == 0 {
+		return expression.ConditionBuilder{}, false
+	}
+	if len(conditions) == 1 {
+		return conditions[0], true
+	}
+	return expression.And(conditions[0], conditions[1], conditions[2:]...), true
+}
+
+// matches reports whether a scanned item matches the filters that cannot be evaluated by DynamoDB.
+// Filters that are evaluated by DynamoDB are not checked again. An item that cannot be checked
+// against a filter (e.g. because its LastUpdatedDate cannot be parsed) does not match, and the
+// reason is returned as an error.
+func (f *Filters) matches(item DDBItem) (bool, error) {
+	if len(f.grantIDs) > maxFilterGrantIDs {
+		if _, ok := f.grantIDs[stringAttr(item, "grant_id")]; !ok {
+			return false, nil
+		}
+	}
+
+	if !f.LastUpdatedSince.IsZero() || !f.LastUpdatedBefore.IsZero() {
+		lastUpdated, err := time.Parse(itemMapper.GrantsGovDateLayout, stringAttr(item, "LastUpdatedDate"))
+		if err != nil {
+			return false, fmt.Errorf("error parsing LastUpdatedDate: %w", err)
+		}
+		if !f.LastUpdatedSince.IsZero() && lastUpdated.Before(f.LastUpdatedSince) {
+			return false, nil
+		}
+		if !f.LastUpdatedBefore.IsZero() && !lastUpdated.Before(f.LastUpdatedBefore) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// projectedAttributes returns the names of item attributes that are needed by matches.
+func (f *Filters) projectedAttributes() []string {
+	if !f.LastUpdatedSince.IsZero() || !f.LastUpdatedBefore.IsZero() {
+		return []string{"LastUpdatedDate"}
+	}
+	return nil
+}
+
+func minULIDForTime(t time.Time) string {
+	var id ulid.ULID
+	if err := id.SetTime(ulid.Timestamp(t)); err != nil {
+		panic(err)
+	}
+	return id.String()
+}
+
+func stringAttr(item DDBItem, name string) string {
+	if v, ok := item[name].(*types.AttributeValueMemberS); ok {
+		return v.Value
+	}
+	return ""
+}
+
+func itemIsForecast(item DDBItem) bool {
+	v, ok := item["is_forecast"].(*types.AttributeValueMemberBOOL)
+	return ok && v.Value
+}
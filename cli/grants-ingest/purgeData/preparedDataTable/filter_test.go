@@ -0,0 +1,158 @@
+package preparedDataTable
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildFilter returns the scan filter expression for the filters, along with its attribute names
+// and values, or an empty expression if no filter is evaluated by DynamoDB.
+func buildFilter(t *testing.T, f Filters) (string, map[string]string, map[string]types.AttributeValue) {
+	t.Helper()
+	condition, ok := f.condition()
+	if !ok {
+		return "", nil, nil
+	}
+	expr, err := expression.NewBuilder().WithFilter(condition).Build()
+	require.NoError(t, err)
+	return *expr.Filter(), expr.Names(), expr.Values()
+}
+
+func stringValue(v string) types.AttributeValue {
+	return &types.AttributeValueMemberS{Value: v}
+}
+
+func grantIDs(n int) map[string]struct{} {
+	ids := make(map[string]struct{}, n)
+	for i := 0; i < n; i++ {
+		ids[fmt.Sprint(i)] = struct{}{}
+	}
+	return ids
+}
+
+func TestFiltersGrantIDs(t *testing.T) {
+	t.Run("filtered by DynamoDB", func(t *testing.T) {
+		f := Filters{Stage: "all", grantIDs: grantIDs(maxFilterGrantIDs)}
+		filter, names, values := buildFilter(t, f)
+		assert.Regexp(t, `^#0 IN \(:0(, :\d+){99}\)$`, filter)
+		assert.Equal(t, map[string]string{"#0": "grant_id"}, names)
+		ids := make([]string, 0, len(values))
+		for _, v := range values {
+			ids = append(ids, v.(*types.AttributeValueMemberS).Value)
+		}
+		assert.ElementsMatch(t, slices.Collect(maps.Keys(f.grantIDs)), ids)
+
+		match, err := f.matches(DDBItem{"grant_id": stringValue("not-listed")})
+		require.NoError(t, err)
+		assert.True(t, match, "Grant IDs filtered by DynamoDB should not be checked again")
+	})
+
+	t.Run("filtered after scanning", func(t *testing.T) {
+		f := Filters{Stage: "all", grantIDs: grantIDs(maxFilterGrantIDs + 1)}
+		filter, _, _ := buildFilter(t, f)
+		assert.Empty(t, filter, "The IN comparator allows at most 100 operands")
+
+		for id, expected := range map[string]bool{"0": true, "100": true, "101": false} {
+			match, err := f.matches(DDBItem{"grant_id": stringValue(id)})
+			require.NoError(t, err)
+			assert.Equal(t, expected, match, "grant ID %s", id)
+		}
+		match, err := f.matches(DDBItem{})
+		require.NoError(t, err)
+		assert.False(t, match, "Items without a grant ID should not match")
+	})
+}
+
+func TestFiltersAgency(t *testing.T) {
+	filter, names, values := buildFilter(t, Filters{Stage: "all", Agency: []string{"hhs", "DOE-GFO"}})
+	assert.Equal(t, "(#0 = :0) OR (begins_with (#0, :1)) OR (#0 = :2) OR (begins_with (#0, :3))", filter)
+	assert.Equal(t, map[string]string{"#0": "AgencyCode"}, names)
+	// Agency codes are matched case-insensitively, along with the codes of sub-agencies
+	// (e.g. HHS-ACF) but not other agencies with the same prefix (e.g. HHSX)
+	assert.Equal(t, map[string]types.AttributeValue{
+		":0": stringValue("HHS"), ":1": stringValue("HHS-"), ":2": stringValue("DOE-GFO"), ":3": stringValue("DOE-GFO-"),
+	}, values)
+}
+
+func TestFiltersStage(t *testing.T) {
+	filter, _, _ := buildFilter(t, Filters{Stage: "all"})
+	assert.Empty(t, filter)
+
+	filter, names, values := buildFilter(t, Filters{Stage: "forecast"})
+	assert.Equal(t, "#0 = :0", filter)
+	assert.Equal(t, map[string]string{"#0": "is_forecast"}, names)
+	assert.Equal(t, map[string]types.AttributeValue{":0": &types.AttributeValueMemberBOOL{Value: true}}, values)
+
+	// Items without is_forecast were written before forecasts were ingested, so are posted grants
+	filter, names, values = buildFilter(t, Filters{Stage: "posted"})
+	assert.Equal(t, "(attribute_not_exists (#0)) OR (#0 = :0)", filter)
+	assert.Equal(t, map[string]string{"#0": "is_forecast"}, names)
+	assert.Equal(t, map[string]types.AttributeValue{":0": &types.AttributeValueMemberBOOL{Value: false}}, values)
+}
+
+func TestFiltersRevised(t *testing.T) {
+	since := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	before := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	filter, names, values := buildFilter(t, Filters{Stage: "all", RevisedSince: since, RevisedBefore: before})
+	assert.Equal(t, "(#0 >= :0) AND (#0 < :1)", filter)
+	assert.Equal(t, map[string]string{"#0": "revision"}, names)
+	lower := values[":0"].(*types.AttributeValueMemberS).Value
+	upper := values[":1"].(*types.AttributeValueMemberS).Value
+
+	revision := func(t time.Time) string {
+		return ulid.MustNew(ulid.Timestamp(t), ulid.DefaultEntropy()).String()
+	}
+	for _, tt := range []struct {
+		created time.Time
+		inRange bool
+	}{
+		{since.Add(-time.Millisecond), false},
+		{since, true},
+		{before.Add(-time.Millisecond), true},
+		{before, false},
+	} {
+		id := revision(tt.created)
+		assert.Equal(t, tt.inRange, id >= lower && id < upper, "revision created at %s", tt.created)
+	}
+}
+
+func TestFiltersLastUpdated(t *testing.T) {
+	f := Filters{
+		Stage:             "all",
+		LastUpdatedSince:  time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC),
+		LastUpdatedBefore: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+	filter, _, _ := buildFilter(t, f)
+	assert.Empty(t, filter, "LastUpdatedDate cannot be compared by DynamoDB")
+	assert.Equal(t, []string{"LastUpdatedDate"}, f.projectedAttributes())
+
+	for lastUpdated, expected := range map[string]bool{
+		"04302023": false,
+		"05012023": true,
+		"05312023": true,
+		"06012023": false,
+	} {
+		match, err := f.matches(DDBItem{"LastUpdatedDate": stringValue(lastUpdated)})
+		require.NoError(t, err)
+		assert.Equal(t, expected, match, "LastUpdatedDate %s", lastUpdated)
+	}
+
+	for _, item := range []DDBItem{
+		{"LastUpdatedDate": stringValue("2023-05-15")},
+		{"LastUpdatedDate": stringValue("")},
+		{},
+	} {
+		match, err := f.matches(item)
+		assert.ErrorContains(t, err, "error parsing LastUpdatedDate")
+		assert.False(t, match, "Items with an invalid LastUpdatedDate should not be purged")
+	}
+}
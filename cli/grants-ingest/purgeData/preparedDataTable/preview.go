@@ -0,0 +1,115 @@
+package preparedDataTable
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/oklog/ulid/v2"
+	"github.com/usdigitalresponse/grants-ingest/cli/tableScan"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+)
+
+// previewAttributes are the item attributes shown for each sample item in a purge preview.
+var previewAttributes = []string{
+	"grant_id", "AgencyCode", "is_forecast", "LastUpdatedDate", "revision", "OpportunityTitle",
+}
+
+// preview scans the table for items that match the purge filters, writing the number of
+// matching items and a sample of them to w. It returns the number of matching items.
+func (cmd *Cmd) preview(w io.Writer) (int64, error) {
+	input, err := cmd.buildScanInput(previewAttributes)
+	if err != nil {
+		return 0, err
+	}
+
+	scannedItems := make(chan DDBItem)
+	scanGroup := multierror.Group{}
+	for i := 0; i < int(cmd.ReadConcurrency); i++ {
+		segmentId := i
+		scanGroup.Go(func() error {
+			err := tableScan.Segment(cmd.ctx, cmd.ddb, *cmd.logger, input,
+				segmentId, int(cmd.ReadConcurrency), scannedItems)
+			if err != nil && err != context.Canceled {
+				cmd.stop()
+				return err
+			}
+			return nil
+		})
+	}
+	// scanTableErr is set before scannedItems is closed, so it can be read after every item is received
+	var scanTableErr error
+	go func() {
+		scanTableErr = scanGroup.Wait().ErrorOrNil()
+		close(scannedItems)
+	}()
+
+	var count int64
+	samples := make([]DDBItem, 0, cmd.PreviewSampleSize)
+	for item := range scannedItems {
+		match, err := cmd.Filters.matches(item)
+		if err != nil {
+			log.Warn(*cmd.logger, "Skipping item that cannot be checked against the purge filters",
+				"grant_id", stringAttr(item, "grant_id"), "error", err)
+		}
+		if !match {
+			continue
+		}
+		count++
+		if cmd.TotalsAfter.Check(count) {
+			log.Info(*cmd.logger, "Updated matched items total", "count", count)
+		}
+		if len(samples) < cmd.PreviewSampleSize {
+			samples = append(samples, item)
+		}
+	}
+	if scanTableErr != nil {
+		return count, scanTableErr
+	}
+	if err := cmd.ctx.Err(); err != nil {
+		return count, err
+	}
+
+	fmt.Fprintf(w, "%d items in table %s match the purge filters.\n", count, cmd.TableName)
+	if len(samples) == 0 {
+		return count, nil
+	}
+	fmt.Fprintf(w, "Sample of %d matching items:\n\n", len(samples))
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "GRANT ID\tAGENCY\tSTAGE\tLAST UPDATED\tREVISED\tTITLE")
+	for _, item := range samples {
+		stage := "posted"
+		if itemIsForecast(item) {
+			stage = "forecast"
+		}
+		revised := ""
+		if id, err := ulid.ParseStrict(stringAttr(item, "revision")); err == nil {
+			revised = ulid.Time(id.Time()).UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", stringAttr(item, "grant_id"),
+			stringAttr(item, "AgencyCode"), stage, stringAttr(item, "LastUpdatedDate"),
+			revised, truncate(stringAttr(item, "OpportunityTitle"), 60))
+	}
+	fmt.Fprintln(tw)
+	return count, tw.Flush()
+}
+
+// confirm asks for confirmation to purge count items, returning true if confirmation is given.
+func confirm(r io.Reader, w io.Writer, count int64) bool {
+	fmt.Fprintf(w, "Proceed with purging %d items? [y/N]: ", count)
+	answer, _ := bufio.NewReader(r).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-3]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,177 @@
+package quality
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/usdigitalresponse/grants-ingest/cli/tableScan"
+	ct "github.com/usdigitalresponse/grants-ingest/cli/types"
+	"github.com/usdigitalresponse/grants-ingest/internal/awsHelpers"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+	"github.com/usdigitalresponse/grants-ingest/internal/quality"
+)
+
+type Cmd struct {
+	// Flags
+	PreparedDataTable string              `required:"" env:"GRANTS_PREPARED_DYNAMODB_NAME" help:"Name of the DynamoDB table containing grants prepared data."`
+	Format            string              `enum:"json,html" default:"json" help:"Report format (json|html)."`
+	Output            string              `short:"o" default:"-" help:"Local file path or S3 URI (s3://bucket/key) to which the report is written, or - for stdout."`
+	SampleSize        int                 `default:"10" help:"Max offending grant IDs to include in the report for each score."`
+	ReadConcurrency   ct.ConcurrencyLimit `default:"1" help:"Max DynamoDB parallel scan workers."`
+	TotalsAfter       ct.TotalsAfter      `default:"1000" help:"Log item totals after this many items are scanned (silent if 0)."`
+	S3UsePathStyle    bool                `name:"s3-use-path-style" help:"Use path-style addressing for S3 bucket."`
+
+	// Internal
+	ctx      context.Context
+	stop     context.CancelFunc
+	ddb      *dynamodb.Client
+	s3       *s3.Client
+	s3Bucket string
+	s3Key    string
+}
+
+func (cmd *Cmd) Help() string {
+	return `
+Scans the prepared-data DynamoDB table and reports its data quality. Each item is mapped to
+grant data in the same manner as PublishGrantEvents, and any malformed item attributes,
+mapping failures, and grant data validation errors are counted. The report includes error
+rates per field, per validation error, and per agency, along with sample offending grant IDs.
+
+The same report is saved to S3 on a schedule by the ReportDataQuality Lambda function,
+when enabled.
+
+The table name may be provided with the GRANTS_PREPARED_DYNAMODB_NAME environment variable.`
+}
+
+func (cmd *Cmd) Validate() error {
+	if cmd.SampleSize < 0 {
+		return fmt.Errorf("--sample-size cannot be negative")
+	}
+	if strings.HasPrefix(cmd.Output, "s3://") {
+		bucket, key, _ := strings.Cut(strings.TrimPrefix(cmd.Output, "s3://"), "/")
+		if bucket == "" || key == "" {
+			return fmt.Errorf("invalid S3 output URI %q: must be of the form s3://bucket/key", cmd.Output)
+		}
+		cmd.s3Bucket, cmd.s3Key = bucket, key
+	}
+	return nil
+}
+
+func (cmd *Cmd) BeforeApply() error {
+	cmd.ctx, cmd.stop = signal.NotifyContext(context.Background(),
+		syscall.SIGHUP, syscall.SIGINT, os.Interrupt)
+	return nil
+}
+
+func (cmd *Cmd) AfterApply() error {
+	cfg, err := awsHelpers.GetConfig(cmd.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to configure AWS SDK: %w", err)
+	}
+	cmd.ddb = dynamodb.NewFromConfig(cfg)
+	cmd.s3 = s3.NewFromConfig(cfg, func(o *s3.Options) { o.UsePathStyle = cmd.S3UsePathStyle })
+	return nil
+}
+
+func (cmd *Cmd) Run(app *kong.Kong, baseLogger *log.Logger) error {
+	defer cmd.stop()
+	logger := log.With(*baseLogger, "table", cmd.PreparedDataTable,
+		"format", cmd.Format, "output", cmd.Output)
+
+	scannedItems := make(chan map[string]types.AttributeValue)
+	var scanTableErr error
+	scanWg := sync.WaitGroup{}
+	for i := 0; i < int(cmd.ReadConcurrency); i++ {
+		scanWg.Add(1)
+		segmentId := i
+		go func() {
+			defer scanWg.Done()
+			err := tableScan.Segment(cmd.ctx, cmd.ddb, logger,
+				dynamodb.ScanInput{TableName: aws.String(cmd.PreparedDataTable)},
+				segmentId, int(cmd.ReadConcurrency), scannedItems)
+			if err != nil && err != context.Canceled {
+				log.Error(logger,
+					"Stopping application due to fatal error encountered while scanning DynamoDB items",
+					err)
+				scanTableErr = err
+				cmd.stop()
+			}
+		}()
+	}
+	go func() {
+		scanWg.Wait()
+		close(scannedItems)
+	}()
+
+	scorecard := quality.NewScorecard(cmd.SampleSize)
+	var totalScanned int64
+	for item := range scannedItems {
+		totalScanned++
+		if cmd.TotalsAfter.Check(totalScanned) {
+			log.Info(logger, "Updated scanned items total", "count", totalScanned)
+		}
+		scorecard.Add(item)
+	}
+	if scanTableErr != nil || cmd.ctx.Err() != nil {
+		// A report of a partial scan would understate error counts
+		log.Info(logger, "Final scanned items total", "count", totalScanned)
+		return fmt.Errorf("the operation completed with errors")
+	}
+
+	report := scorecard.Report(cmd.PreparedDataTable, time.Now().UTC())
+	log.Info(logger, "Final data quality totals", "scanned", totalScanned,
+		"items_with_problems", report.ItemsWithProblems.Count,
+		"unbuildable_items", report.UnbuildableItems.Count,
+		"invalid_grants", report.InvalidGrants.Count)
+
+	if err := cmd.writeReport(report, app.Stdout); err != nil {
+		return log.Errorf(logger, "Error writing report", err)
+	}
+	return nil
+}
+
+// writeReport writes the report to the configured output destination.
+func (cmd *Cmd) writeReport(report quality.Report, stdout io.Writer) error {
+	switch {
+	case cmd.Output == "-":
+		return report.Write(stdout, cmd.Format)
+	case cmd.s3Bucket != "":
+		var buf bytes.Buffer
+		if err := report.Write(&buf, cmd.Format); err != nil {
+			return err
+		}
+		_, err := cmd.s3.PutObject(cmd.ctx, &s3.PutObjectInput{
+			Bucket:               aws.String(cmd.s3Bucket),
+			Key:                  aws.String(cmd.s3Key),
+			Body:                 bytes.NewReader(buf.Bytes()),
+			ContentType:          aws.String(quality.ContentTypes[cmd.Format]),
+			ServerSideEncryption: s3types.ServerSideEncryptionAes256,
+		})
+		return err
+	default:
+		f, err := os.Create(cmd.Output)
+		if err != nil {
+			return err
+		}
+		if err := report.Write(f, cmd.Format); err != nil {
+			f.Close()
+			os.Remove(f.Name())
+			return err
+		}
+		return f.Close()
+	}
+}
@@ -0,0 +1,101 @@
+package restore
+
+import (
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/usdigitalresponse/grants-ingest/cli/backup"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+)
+
+func (cmd *Cmd) restoreObjects(logger log.Logger, archive *backup.Archive, bucket string) error {
+	objects := make(chan backup.Object)
+	restoreCounts := make(chan int)
+	failureCounts := make(chan int)
+
+	reportingDone := make(chan struct{})
+	go cmd.countTotals(logger, "restored objects", restoreCounts, reportingDone)
+	failureReportingDone := make(chan struct{})
+	go cmd.countTotals(logger, "failed restorations", failureCounts, failureReportingDone)
+
+	go func() {
+		defer close(objects)
+		for _, obj := range archive.Objects {
+			select {
+			case objects <- obj:
+			case <-cmd.ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var failedKeys []string
+	var failedKeysMu sync.Mutex
+	workWg := sync.WaitGroup{}
+	for i := 0; i < int(cmd.Concurrency); i++ {
+		workLogger := log.WithSuffix(logger, "worker_id", i)
+		workWg.Add(1)
+		go func() {
+			defer workWg.Done()
+			worker(cmd, workLogger, objects, func(obj backup.Object) error {
+				if err := cmd.putObject(archive, bucket, obj); err != nil {
+					if cmd.ctx.Err() != nil {
+						return cmd.ctx.Err()
+					}
+					log.Debug(workLogger, "Failed to restore S3 object", "key", obj.Key, "error", err)
+					failedKeysMu.Lock()
+					failedKeys = append(failedKeys, obj.Key)
+					failedKeysMu.Unlock()
+					failureCounts <- 1
+					return nil
+				}
+				log.Debug(workLogger, "Restored S3 object", "key", obj.Key)
+				restoreCounts <- 1
+				return nil
+			})
+		}()
+	}
+
+	workWg.Wait()
+	close(restoreCounts)
+	close(failureCounts)
+	<-reportingDone
+	<-failureReportingDone
+
+	if len(failedKeys) > 0 {
+		log.Warn(logger, "Some objects could not be restored",
+			"count", len(failedKeys), "keys", failedKeys)
+	}
+	if cmd.ctx.Err() != nil || len(failedKeys) > 0 {
+		return ErrCompletion
+	}
+	return nil
+}
+
+func (cmd *Cmd) putObject(archive *backup.Archive, bucket string, obj backup.Object) error {
+	body, err := archive.OpenObject(cmd.ctx, obj)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	if cmd.DryRun {
+		return nil
+	}
+
+	input := &s3.PutObjectInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(obj.Key),
+		Body:     body,
+		Metadata: obj.Metadata,
+	}
+	if obj.ContentType != "" {
+		input.ContentType = aws.String(obj.ContentType)
+	}
+	if obj.ContentEncoding != "" {
+		input.ContentEncoding = aws.String(obj.ContentEncoding)
+	}
+	_, err = manager.NewUploader(cmd.s3svc).Upload(cmd.ctx, input)
+	return err
+}
@@ -0,0 +1,152 @@
+package restore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/alecthomas/kong"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/usdigitalresponse/grants-ingest/cli/backup"
+	ct "github.com/usdigitalresponse/grants-ingest/cli/types"
+	"github.com/usdigitalresponse/grants-ingest/internal/awsHelpers"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+)
+
+var ErrCompletion = errors.New("the operation completed with errors")
+
+type Cmd struct {
+	// Positional arguments
+	Archive string `arg:"" name:"archive" help:"Local directory or S3 prefix (s3://bucket/prefix) of the backup archive to restore."`
+
+	// Flags
+	Table          string              `placeholder:"NAME" help:"Name of the DynamoDB table to which items are restored (default: the archived table)."`
+	Bucket         string              `placeholder:"NAME" help:"Name of the S3 bucket to which objects are restored (default: the archived bucket)."`
+	Concurrency    ct.ConcurrencyLimit `default:"10" help:"Max concurrent batch-write (DynamoDB) or upload (S3) operations."`
+	TotalsAfter    ct.TotalsAfter      `default:"1000" help:"Log totals after this many successful/failed restorations (silent if 0)."`
+	S3UsePathStyle bool                `name:"s3-use-path-style" help:"Use path-style addressing for S3 buckets."`
+	DryRun         bool                `help:"Dry run only - no DynamoDB table items or S3 objects will be written."`
+
+	// Internal
+	ctx    context.Context
+	stop   context.CancelFunc
+	ddb    *dynamodb.Client
+	s3svc  *s3.Client
+	logger *log.Logger
+}
+
+func (cmd *Cmd) Help() string {
+	return `
+Restores the data in a backup archive created by a purge command, writing each archived
+DynamoDB item or S3 object back to the table or bucket from which it was archived (or to the
+table or bucket given by --table or --bucket).
+
+Restored DynamoDB items replace any existing items with the same key, and restored S3 objects
+replace any existing objects with the same key. Note that restoring DynamoDB items will produce
+stream records (and therefore published events) for items that differ from their current state.`
+}
+
+func (cmd *Cmd) BeforeApply(app *kong.Kong, logger *log.Logger) error {
+	cmd.ctx, cmd.stop = signal.NotifyContext(context.Background(),
+		syscall.SIGHUP, syscall.SIGINT, os.Interrupt)
+	cmd.logger = logger
+	return nil
+}
+
+func (cmd *Cmd) AfterApply(app *kong.Kong) error {
+	cfg, err := awsHelpers.GetConfig(cmd.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to configure AWS SDK: %w", err)
+	}
+	cmd.ddb = dynamodb.NewFromConfig(cfg)
+	cmd.s3svc = s3.NewFromConfig(cfg, func(o *s3.Options) { o.UsePathStyle = cmd.S3UsePathStyle })
+	return nil
+}
+
+// Destructive reports whether the command writes items or objects, which replace existing ones.
+func (cmd *Cmd) Destructive() bool {
+	return !cmd.DryRun
+}
+
+func (cmd *Cmd) Run(app *kong.Kong) error {
+	defer cmd.stop()
+
+	archive, err := backup.Open(cmd.ctx, cmd.Archive, cmd.s3svc)
+	if err != nil {
+		return log.Errorf(*cmd.logger, "Error opening backup archive", err, "location", cmd.Archive)
+	}
+	logger := log.With(*cmd.logger, "archive", archive.Location(), "kind", archive.Kind)
+	log.Info(logger, "Opened backup archive", "source", archive.Source,
+		"operation", archive.Operation, "created_at", archive.CreatedAt,
+		"items", archive.ItemCount, "objects", len(archive.Objects))
+	if !archive.Complete() {
+		log.Warn(logger, "Backup archive is incomplete because the operation that created it was "+
+			"interrupted; only data that was archived before the interruption will be restored")
+	}
+
+	switch archive.Kind {
+	case backup.KindDynamoDBTable:
+		table := archive.Source
+		if cmd.Table != "" {
+			table = cmd.Table
+		}
+		return cmd.restoreItems(log.With(logger, "table", table), archive, table)
+	case backup.KindS3Bucket:
+		bucket := archive.Source
+		if cmd.Bucket != "" {
+			bucket = cmd.Bucket
+		}
+		return cmd.restoreObjects(log.With(logger, "bucket", bucket), archive, bucket)
+	}
+	return log.Errorf(logger, "Unsupported backup archive",
+		fmt.Errorf("unknown archive kind %q", archive.Kind))
+}
+
+// countTotals logs running and final totals of the counts received from ch, and closes done
+// when ch is closed.
+func (cmd *Cmd) countTotals(logger log.Logger, desc string, ch <-chan int, done chan<- struct{}) {
+	defer close(done)
+	var total int64
+	for n := range ch {
+		for i := 0; i < n; i++ {
+			total++
+			if cmd.TotalsAfter.Check(total) {
+				log.Info(logger, fmt.Sprintf("Updated %s total", desc), "count", total)
+			}
+		}
+	}
+	log.Info(logger, fmt.Sprintf("Final count of %s", desc), "count", total)
+}
+
+// worker calls fn with each unit of work received from work until work is closed or the
+// command is stopped.
+func worker[T any](cmd *Cmd, logger log.Logger, work <-chan T, fn func(T) error) (err error) {
+	defer func() {
+		msg := "Restore worker shutting down"
+		if err == nil {
+			log.Debug(logger, msg, "reason", "no more work")
+		} else if err == context.Canceled {
+			log.Warn(logger, msg, "reason", "shutdown requested")
+		} else {
+			log.Error(logger, msg, err, "reason", "fatal error")
+		}
+	}()
+
+	for {
+		select {
+		case w, ok := <-work:
+			if !ok {
+				return nil
+			}
+			if err := fn(w); err != nil {
+				return err
+			}
+		case <-cmd.ctx.Done():
+			return cmd.ctx.Err()
+		}
+	}
+}
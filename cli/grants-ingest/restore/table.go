@@ -0,0 +1,120 @@
+package restore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/usdigitalresponse/grants-ingest/cli/backup"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+)
+
+func (cmd *Cmd) restoreItems(logger log.Logger, archive *backup.Archive, table string) error {
+	batchedRequests := make(chan []types.WriteRequest)
+	restoreCounts := make(chan int)
+
+	reportingDone := make(chan struct{})
+	go cmd.countTotals(logger, "restored items", restoreCounts, reportingDone)
+
+	var readArchiveErr error
+	go func() {
+		defer close(batchedRequests)
+		currentBatch := make([]types.WriteRequest, 0, 25)
+		send := func() error {
+			select {
+			case batchedRequests <- currentBatch:
+				currentBatch = make([]types.WriteRequest, 0, 25)
+				return nil
+			case <-cmd.ctx.Done():
+				return cmd.ctx.Err()
+			}
+		}
+		err := archive.Items(cmd.ctx, func(item map[string]types.AttributeValue) error {
+			currentBatch = append(currentBatch,
+				types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+			if len(currentBatch) == 25 {
+				return send()
+			}
+			return nil
+		})
+		if err == nil && len(currentBatch) > 0 {
+			err = send()
+		}
+		if err != nil && err != context.Canceled {
+			log.Error(logger,
+				"Stopping application due to fatal error encountered while reading archived items", err)
+			readArchiveErr = err
+			cmd.stop()
+		}
+	}()
+
+	var restoreItemsErr error
+	workWg := sync.WaitGroup{}
+	for i := 0; i < int(cmd.Concurrency); i++ {
+		workLogger := log.WithSuffix(logger, "worker_id", i)
+		workWg.Add(1)
+		go func() {
+			defer workWg.Done()
+			err := worker(cmd, workLogger, batchedRequests, func(batch []types.WriteRequest) error {
+				return cmd.putItems(workLogger, table, batch, restoreCounts)
+			})
+			if err != nil && err != context.Canceled {
+				log.Error(logger,
+					"Stopping application due to fatal error encountered while restoring DynamoDB items",
+					err)
+				restoreItemsErr = err
+				cmd.stop()
+			}
+		}()
+	}
+
+	workWg.Wait()
+	close(restoreCounts)
+	<-reportingDone
+
+	if cmd.ctx.Err() != nil || readArchiveErr != nil || restoreItemsErr != nil {
+		return ErrCompletion
+	}
+	return nil
+}
+
+func (cmd *Cmd) putItems(logger log.Logger, table string, batch []types.WriteRequest, restoreCounts chan<- int) error {
+	input := dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]types.WriteRequest{table: batch},
+	}
+
+	return backoff.RetryNotify(
+		func() error {
+			thisBatchSize := len(input.RequestItems[table])
+			if cmd.DryRun {
+				restoreCounts <- thisBatchSize
+				return nil
+			}
+			resp, err := cmd.ddb.BatchWriteItem(cmd.ctx, &input)
+			if err != nil {
+				return backoff.Permanent(err)
+			}
+			countUnprocessed := len(resp.UnprocessedItems[table])
+			restoreCounts <- (thisBatchSize - countUnprocessed)
+			if countUnprocessed > 0 {
+				input.RequestItems = resp.UnprocessedItems
+				return fmt.Errorf("dynamodb batch write operation returned %d unprocessed items",
+					countUnprocessed)
+			}
+			return nil
+		},
+		func() backoff.BackOff {
+			b := backoff.NewExponentialBackOff()
+			b.MaxElapsedTime = time.Minute * 2
+			return b
+		}(),
+		func(err error, d time.Duration) {
+			log.Debug(logger, "DynamoDB batch write operation throttled",
+				"retry_after", d, "error", err)
+		},
+	)
+}
@@ -0,0 +1,203 @@
+// Package profile provides named environment profiles for CLI commands, so that the names of
+// an environment's resources do not need to be provided for every command.
+package profile
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/kong"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrNotFound     = errors.New("profile not found")
+	ErrNotTerminal  = errors.New("confirmation requires an interactive terminal")
+	ErrNotConfirmed = errors.New("confirmation did not match the profile name")
+)
+
+// Profile defines the resources of an environment.
+type Profile struct {
+	// Name of the profile in the config file
+	Name string `yaml:"-"`
+
+	SourceDataBucket   string `yaml:"source_data_bucket"`
+	PreparedDataBucket string `yaml:"prepared_data_bucket"`
+	PreparedDataTable  string `yaml:"prepared_data_table"`
+	EventBus           string `yaml:"event_bus"`
+	S3UsePathStyle     bool   `yaml:"s3_use_path_style"`
+	// Whether destructive commands require typed confirmation before running
+	Protected bool `yaml:"protected"`
+}
+
+// Config is the content of a CLI config file.
+type Config struct {
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// DefaultConfigPath returns the path of the config file in the user's configuration directory,
+// or an empty string if the directory cannot be determined.
+func DefaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "grants-ingest", "config.yaml")
+}
+
+// Load reads the config file at path.
+func Load(path string) (Config, error) {
+	var cfg Config
+	f, err := os.Open(path)
+	if err != nil {
+		return cfg, err
+	}
+	defer f.Close()
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return cfg, fmt.Errorf("error reading config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Get returns the named profile.
+func (c Config) Get(name string) (Profile, error) {
+	p, ok := c.Profiles[name]
+	if !ok {
+		names := make([]string, 0, len(c.Profiles))
+		for n := range c.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return p, fmt.Errorf("%w: %q (available profiles: %s)", ErrNotFound, name, strings.Join(names, ", "))
+	}
+	p.Name = name
+	return p, nil
+}
+
+// Flags selects a profile. It is intended to be embedded in the CLI's global flags.
+type Flags struct {
+	Profile string `name:"profile" env:"GRANTS_INGEST_PROFILE" placeholder:"NAME" help:"Name of the config file profile that provides resource names and options."`
+	Config  string `name:"config" type:"path" env:"GRANTS_INGEST_CONFIG" placeholder:"PATH" help:"Path of the config file that defines profiles (default: <user config dir>/grants-ingest/config.yaml)."`
+}
+
+// Select loads the profile selected by the flags into selected, which is left empty if no profile
+// is selected. Because flags given on the command line are not applied until after values are
+// resolved, their values are read from the parse context.
+func Select(ctx *kong.Context, selected *Profile) error {
+	var name, path string
+	for _, flag := range ctx.Flags() {
+		switch flag.Name {
+		case "profile":
+			name, _ = ctx.FlagValue(flag).(string)
+		case "config":
+			path, _ = ctx.FlagValue(flag).(string)
+		}
+	}
+	if name == "" {
+		*selected = Profile{}
+		return nil
+	}
+	if path == "" {
+		path = DefaultConfigPath()
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		return err
+	}
+	p, err := cfg.Get(name)
+	if err != nil {
+		return err
+	}
+	*selected = p
+	return nil
+}
+
+// Resolver returns a Kong resolver that provides the values of the following flags from the
+// selected profile: --source-data-bucket, --prepared-data-bucket, --prepared-data-table,
+// --event-bus, and --s3-use-path-style. Flags given on the command line take precedence over
+// profile values, which take precedence over environment variables and defaults.
+func Resolver(selected *Profile) kong.Resolver {
+	return kong.ResolverFunc(func(ctx *kong.Context, parent *kong.Path, flag *kong.Flag) (any, error) {
+		if selected.Name == "" {
+			return nil, nil
+		}
+		var value string
+		switch flag.Name {
+		case "source-data-bucket":
+			value = selected.SourceDataBucket
+		case "prepared-data-bucket":
+			value = selected.PreparedDataBucket
+		case "prepared-data-table":
+			value = selected.PreparedDataTable
+		case "event-bus":
+			value = selected.EventBus
+		case "s3-use-path-style":
+			if selected.S3UsePathStyle {
+				return true, nil
+			}
+		}
+		if value == "" {
+			return nil, nil
+		}
+		return value, nil
+	})
+}
+
+// Default returns value if it is not empty, or else the given profile value. It is an error if
+// both are empty, in which case the error describes the argument (e.g. "<bucket>") and the
+// profile setting (e.g. "prepared_data_bucket") that could provide it.
+func Default(value, profileValue, arg, setting string) (string, error) {
+	if value != "" {
+		return value, nil
+	}
+	if profileValue != "" {
+		return profileValue, nil
+	}
+	return "", fmt.Errorf("missing %s: provide it as an argument or select a --profile that defines %s",
+		arg, setting)
+}
+
+// Destructive is implemented by commands that may modify or delete data, depending on their options.
+type Destructive interface {
+	Destructive() bool
+}
+
+// ConfirmDestructive requires a user to type the name of the selected profile before the command
+// selected by ctx modifies or deletes data, if the profile is protected. Confirmation is read from
+// in, which must be an interactive terminal.
+func ConfirmDestructive(ctx *kong.Context, selected Profile, in *os.File, out io.Writer) error {
+	if !selected.Protected {
+		return nil
+	}
+	node := ctx.Selected()
+	if node == nil || !node.Target.CanAddr() {
+		return nil
+	}
+	cmd, ok := node.Target.Addr().Interface().(Destructive)
+	if !ok || !cmd.Destructive() {
+		return nil
+	}
+
+	if stat, err := in.Stat(); err != nil || stat.Mode()&os.ModeCharDevice == 0 {
+		return fmt.Errorf("%w: profile %q is protected", ErrNotTerminal, selected.Name)
+	}
+	fmt.Fprintf(out, "Profile %q is protected, and %q may modify or delete its data.\n",
+		selected.Name, ctx.Command())
+	fmt.Fprintf(out, "Type the profile name to continue: ")
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if strings.TrimSpace(answer) != selected.Name {
+		return ErrNotConfirmed
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package profile
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alecthomas/kong"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `
+profiles:
+  staging:
+    source_data_bucket: staging-sources
+    prepared_data_table: staging-table
+  production:
+    source_data_bucket: production-sources
+    s3_use_path_style: true
+    protected: true
+`
+
+type testGlobals struct {
+	Flags
+}
+
+func (g testGlobals) BeforeResolve(ctx *kong.Context, selected *Profile) error {
+	return Select(ctx, selected)
+}
+
+type testDeleteCmd struct {
+	SourceDataBucket  string `name:"source-data-bucket" env:"TEST_SOURCE_DATA_BUCKET"`
+	PreparedDataTable string `name:"prepared-data-table" default:"default-table"`
+	S3UsePathStyle    bool   `name:"s3-use-path-style"`
+	DryRun            bool
+}
+
+func (cmd *testDeleteCmd) Destructive() bool { return !cmd.DryRun }
+
+func (cmd *testDeleteCmd) Run() error { return nil }
+
+type testReadCmd struct{}
+
+func (cmd *testReadCmd) Run() error { return nil }
+
+type testCLI struct {
+	testGlobals
+
+	Delete testDeleteCmd `cmd:""`
+	Read   testReadCmd   `cmd:""`
+}
+
+func writeTestConfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testConfig), 0o600))
+	return path
+}
+
+// parse parses args with a CLI that resolves flags like the grants-ingest CLI does.
+func parse(t *testing.T, args ...string) (*testCLI, *kong.Context, Profile, error) {
+	t.Helper()
+	var cli testCLI
+	var selected Profile
+	parser, err := kong.New(&cli,
+		kong.Bind(&selected),
+		kong.Resolvers(Resolver(&selected)),
+		kong.Exit(func(int) { t.Fatal("unexpected exit") }),
+	)
+	require.NoError(t, err)
+	ctx, err := parser.Parse(args)
+	return &cli, ctx, selected, err
+}
+
+func TestSelect(t *testing.T) {
+	path := writeTestConfig(t)
+
+	_, _, selected, err := parse(t, "--config", path, "read")
+	require.NoError(t, err)
+	assert.Equal(t, Profile{}, selected, "No profile should be selected without --profile")
+
+	_, _, selected, err = parse(t, "--config", path, "--profile", "staging", "read")
+	require.NoError(t, err)
+	assert.Equal(t, Profile{
+		Name:              "staging",
+		SourceDataBucket:  "staging-sources",
+		PreparedDataTable: "staging-table",
+	}, selected)
+
+	t.Setenv("GRANTS_INGEST_PROFILE", "production")
+	t.Setenv("GRANTS_INGEST_CONFIG", path)
+	_, _, selected, err = parse(t, "read")
+	require.NoError(t, err)
+	assert.Equal(t, "production", selected.Name, "The profile should be selectable by environment variables")
+
+	_, _, _, err = parse(t, "--profile", "missing", "read")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorContains(t, err, "available profiles: production, staging")
+}
+
+func TestResolver(t *testing.T) {
+	path := writeTestConfig(t)
+	t.Setenv("TEST_SOURCE_DATA_BUCKET", "env-sources")
+
+	for _, tt := range []struct {
+		name             string
+		args             []string
+		sourceDataBucket string
+		table            string
+		pathStyle        bool
+	}{
+		{"no profile", []string{"delete"}, "env-sources", "default-table", false},
+		{"profile", []string{"--profile", "staging", "delete"}, "staging-sources", "staging-table", false},
+		{
+			"flags",
+			[]string{"--profile", "production", "delete", "--source-data-bucket", "flag-sources"},
+			"flag-sources", "default-table", true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cli, _, _, err := parse(t, append([]string{"--config", path}, tt.args...)...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.sourceDataBucket, cli.Delete.SourceDataBucket)
+			assert.Equal(t, tt.table, cli.Delete.PreparedDataTable)
+			assert.Equal(t, tt.pathStyle, cli.Delete.S3UsePathStyle)
+		})
+	}
+}
+
+func TestDefault(t *testing.T) {
+	value, err := Default("arg", "profile", "<bucket>", "source_data_bucket")
+	require.NoError(t, err)
+	assert.Equal(t, "arg", value)
+
+	value, err = Default("", "profile", "<bucket>", "source_data_bucket")
+	require.NoError(t, err)
+	assert.Equal(t, "profile", value)
+
+	_, err = Default("", "", "<bucket>", "source_data_bucket")
+	assert.ErrorContains(t, err, "missing <bucket>")
+	assert.ErrorContains(t, err, "source_data_bucket")
+}
+
+func TestConfirmDestructive(t *testing.T) {
+	path := writeTestConfig(t)
+	// Regular files are not interactive terminals
+	in, err := os.CreateTemp(t.TempDir(), "stdin")
+	require.NoError(t, err)
+	_, err = in.WriteString("production\n")
+	require.NoError(t, err)
+	_, err = in.Seek(0, 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { in.Close() })
+
+	for _, tt := range []struct {
+		name string
+		args []string
+		err  error
+	}{
+		{"unprotected profile", []string{"--profile", "staging", "delete"}, nil},
+		{"non-destructive command", []string{"--profile", "production", "read"}, nil},
+		{"dry run", []string{"--profile", "production", "delete", "--dry-run"}, nil},
+		{"protected profile", []string{"--profile", "production", "delete"}, ErrNotTerminal},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ctx, selected, err := parse(t, append([]string{"--config", path}, tt.args...)...)
+			require.NoError(t, err)
+			var out bytes.Buffer
+			err = ConfirmDestructive(ctx, selected, in, &out)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+			}
+			assert.Empty(t, out.String(), "Nothing should be prompted without a terminal")
+		})
+	}
+}
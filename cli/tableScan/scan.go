@@ -0,0 +1,98 @@
+// Package tableScan provides parallel scanning of DynamoDB tables for CLI commands.
+package tableScan
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+)
+
+type DynamoDBScanAPI interface {
+	Scan(context.Context, *dynamodb.ScanInput, ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+}
+
+// Segment scans the table identified by input, sending each scanned item to ch until the scan
+// is complete or ctx is canceled. When totalSegments is greater than 1, only the given segment
+// of a parallel scan is scanned; callers should run one Segment for each of totalSegments.
+// Consistent reads are always used.
+func Segment(ctx context.Context, c DynamoDBScanAPI, logger log.Logger, input dynamodb.ScanInput,
+	segment, totalSegments int, ch chan<- map[string]types.AttributeValue) error {
+	return scanSegment(ctx, c, logger, input, segment, totalSegments, func(resp *dynamodb.ScanOutput) error {
+		for _, item := range resp.Items {
+			select {
+			case ch <- item:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+}
+
+// Page is a page of items scanned from a table segment.
+type Page struct {
+	Items []map[string]types.AttributeValue
+	// Key at which the scan of the next page begins, or nil if this is the last page.
+	LastEvaluatedKey map[string]types.AttributeValue
+}
+
+// SegmentPages is like Segment, but sends each page of scanned items to ch, so that callers can
+// track the position of the scan. Scanning begins at input.ExclusiveStartKey, if it is set.
+func SegmentPages(ctx context.Context, c DynamoDBScanAPI, logger log.Logger, input dynamodb.ScanInput,
+	segment, totalSegments int, ch chan<- Page) error {
+	return scanSegment(ctx, c, logger, input, segment, totalSegments, func(resp *dynamodb.ScanOutput) error {
+		select {
+		case ch <- Page{resp.Items, resp.LastEvaluatedKey}:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+func scanSegment(ctx context.Context, c DynamoDBScanAPI, logger log.Logger, input dynamodb.ScanInput,
+	segment, totalSegments int, handlePage func(*dynamodb.ScanOutput) error) (err error) {
+	logger = log.WithSuffix(logger, "worker_id", segment)
+	defer func() {
+		msg := "Scan worker shutting down"
+		if err == nil {
+			log.Debug(logger, msg, "reason", "no more work")
+		} else if err == context.Canceled {
+			log.Warn(logger, msg, "reason", "shutdown requested")
+		} else {
+			log.Error(logger, msg, err, "reason", "fatal error")
+		}
+	}()
+
+	input.ConsistentRead = aws.Bool(true)
+	if totalSegments > 1 {
+		input.Segment = aws.Int32(int32(segment))
+		input.TotalSegments = aws.Int32(int32(totalSegments))
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			resp, err := c.Scan(ctx, &input)
+			if err != nil {
+				log.Error(logger, "Error scanning DynamoDB table items", err)
+				return err
+			}
+			for _, item := range resp.Items {
+				log.Debug(logger, "Item found in scan", "item", item)
+			}
+			if err := handlePage(resp); err != nil {
+				return err
+			}
+			if resp.LastEvaluatedKey == nil {
+				return nil
+			}
+			input.ExclusiveStartKey = resp.LastEvaluatedKey
+		}
+	}
+}
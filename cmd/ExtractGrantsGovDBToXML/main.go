@@ -20,24 +20,14 @@ import (
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/usdigitalresponse/grants-ingest/internal/awsHelpers"
-	"github.com/usdigitalresponse/grants-ingest/internal/ddHelpers"
+	"github.com/usdigitalresponse/grants-ingest/internal/lambdas/extractGrantsGovDBToXML"
 	"github.com/usdigitalresponse/grants-ingest/internal/log"
 	awstrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/aws/aws-sdk-go-v2/aws"
 )
 
-type Environment struct {
-	LogLevel          string `env:"LOG_LEVEL,default=INFO"`
-	UsePathStyleS3Opt bool   `env:"S3_USE_PATH_STYLE,default=false"`
-	TmpKeyPrefix      string `env:"TMP_KEY_PATH_PREFIX,default=tmp"`
-	Extras            goenv.EnvSet
-	// Should use zero (default) except during testing or performance tuning
-	DownloadPartSize int64 `env:"DOWNLOAD_PART_SIZE,default=0"`
-}
-
 var (
-	env        Environment
-	logger     log.Logger
-	sendMetric = ddHelpers.NewMetricSender("ExtractGrantsGovDBToXML", "source:grants.gov")
+	env    extractGrantsGovDBToXML.Environment
+	logger log.Logger
 )
 
 func main() {
@@ -47,6 +37,7 @@ func main() {
 	}
 	env.Extras = es
 	log.ConfigureLogger(&logger, env.LogLevel)
+	extractGrantsGovDBToXML.Configure(env, logger)
 
 	log.Debug(logger, "Starting Lambda")
 	lambda.Start(ddlambda.WrapFunction(func(ctx context.Context, s3Event events.S3Event) error {
@@ -59,6 +50,6 @@ func main() {
 			o.UsePathStyle = env.UsePathStyleS3Opt
 		})
 		log.Debug(logger, "Starting Lambda inner")
-		return handleS3Event(ctx, s3svc, s3Event)
+		return extractGrantsGovDBToXML.HandleS3Event(ctx, s3svc, s3Event)
 	}, nil))
 }
@@ -16,22 +16,14 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/usdigitalresponse/grants-ingest/internal/awsHelpers"
-	"github.com/usdigitalresponse/grants-ingest/internal/ddHelpers"
+	"github.com/usdigitalresponse/grants-ingest/internal/lambdas/persistFFISData"
 	"github.com/usdigitalresponse/grants-ingest/internal/log"
 	awstrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/aws/aws-sdk-go-v2/aws"
 )
 
-type Environment struct {
-	LogLevel          string `env:"LOG_LEVEL,default=INFO"`
-	DestinationTable  string `env:"GRANTS_PREPARED_DYNAMODB_NAME,required=true"`
-	UsePathStyleS3Opt bool   `env:"S3_USE_PATH_STYLE,default=false"`
-	Extras            goenv.EnvSet
-}
-
 var (
-	env        Environment
-	logger     log.Logger
-	sendMetric = ddHelpers.NewMetricSender("PersistFFISData", "source:ffis.org")
+	env    persistFFISData.Environment
+	logger log.Logger
 )
 
 func main() {
@@ -41,6 +33,7 @@ func main() {
 	}
 	env.Extras = es
 	log.ConfigureLogger(&logger, env.LogLevel)
+	persistFFISData.Configure(env, logger)
 
 	log.Info(logger, "Starting PersistFFISData")
 
@@ -59,6 +52,6 @@ func main() {
 
 		dynamodbSvc := dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {})
 
-		return handleS3Event(ctx, s3Event, s3Client, dynamodbSvc)
+		return persistFFISData.HandleS3Event(ctx, s3Event, s3Client, dynamodbSvc)
 	}, nil))
 }
@@ -17,22 +17,14 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/usdigitalresponse/grants-ingest/internal/awsHelpers"
-	"github.com/usdigitalresponse/grants-ingest/internal/ddHelpers"
+	"github.com/usdigitalresponse/grants-ingest/internal/lambdas/persistGrantsGovXMLDB"
 	"github.com/usdigitalresponse/grants-ingest/internal/log"
 	awstrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/aws/aws-sdk-go-v2/aws"
 )
 
-type Environment struct {
-	LogLevel          string `env:"LOG_LEVEL,default=INFO"`
-	DestinationTable  string `env:"GRANTS_PREPARED_DYNAMODB_NAME,required=true"`
-	UsePathStyleS3Opt bool   `env:"S3_USE_PATH_STYLE,default=false"`
-	Extras            goenv.EnvSet
-}
-
 var (
-	env        Environment
-	logger     log.Logger
-	sendMetric = ddHelpers.NewMetricSender("PersistGrantsGovXMLDB", "source:grants.gov")
+	env    persistGrantsGovXMLDB.Environment
+	logger log.Logger
 )
 
 func main() {
@@ -42,6 +34,7 @@ func main() {
 	}
 	env.Extras = es
 	log.ConfigureLogger(&logger, env.LogLevel)
+	persistGrantsGovXMLDB.Configure(env, logger)
 
 	log.Debug(logger, "Starting Lambda")
 	lambda.Start(ddlambda.WrapFunction(func(ctx context.Context, s3Event events.S3Event) error {
@@ -58,6 +51,6 @@ func main() {
 		dynamodbSvc := dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {})
 
 		log.Debug(logger, "Starting Lambda")
-		return handleS3EventWithConfig(s3Svc, dynamodbSvc, ctx, s3Event)
+		return persistGrantsGovXMLDB.HandleS3EventWithConfig(s3Svc, dynamodbSvc, ctx, s3Event)
 	}, nil))
 }
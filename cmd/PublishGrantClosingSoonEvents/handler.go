@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+	"github.com/usdigitalresponse/grants-ingest/internal/scheduledLambda"
+	"github.com/usdigitalresponse/grants-ingest/pkg/clock"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/usdr"
+)
+
+// parseWindows parses a comma-separated list of positive numbers of days, which are returned
+// in ascending order without duplicates.
+func parseWindows(s string) ([]int, error) {
+	seen := make(map[int]bool)
+	windows := make([]int, 0)
+	for _, field := range strings.Split(s, ",") {
+		days, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || days <= 0 {
+			return nil, fmt.Errorf("invalid closing soon window %q: must be a positive number of days", field)
+		}
+		if !seen[days] {
+			seen[days] = true
+			windows = append(windows, days)
+		}
+	}
+	sort.Ints(windows)
+	return windows, nil
+}
+
+// windowFor returns the narrowest window that contains the given number of days until close,
+// or false if the number is negative or outside every window.
+func windowFor(daysUntilClose int) (int, bool) {
+	if daysUntilClose < 0 {
+		return 0, false
+	}
+	for _, w := range windows {
+		if daysUntilClose <= w {
+			return w, true
+		}
+	}
+	return 0, false
+}
+
+// handleEvent is a Lambda function handler that is called with the ScheduledEvent invocation
+// event. When invoked, it publishes a GrantClosingSoonEvent for each posted grant in the
+// prepared-data table whose close date falls within a window for which a reminder has not
+// already been published.
+func handleEvent(ctx context.Context, ddbsvc scheduledLambda.DynamoDBScanAPI, remindersvc scheduledLambda.DynamoDBClaimsAPI, pub scheduledLambda.EventBridgePutEventsAPI, event scheduledLambda.ScheduledEvent) error {
+	logger := log.With(logger, "table", env.DynamoDBTableName, "reminders_table", env.RemindersTableName,
+		"event_bus_name", env.EventBusName, "timestamp", event.Timestamp, "windows", fmt.Sprint(windows))
+	now := clock.Fixed(event.Timestamp)
+	reminders := scheduledLambda.ClaimTable{
+		Client:    remindersvc,
+		TableName: env.RemindersTableName,
+		SortKey:   remindersSortKey,
+	}
+
+	publisher := scheduledLambda.NewPublisher(pub, logger, sendMetric)
+	// Reminders that are still pending when returning early (e.g. because scanning failed or ctx
+	// was canceled) were claimed but not published, so they are released to be published by a
+	// later invocation.
+	defer publisher.Discard()
+
+	err := scheduledLambda.ScanTable(ctx, logger, ddbsvc, env.DynamoDBTableName, func(item map[string]ddbtypes.AttributeValue) error {
+		r, entry, ok := closingSoonReminder(logger, item, now)
+		if !ok {
+			return nil
+		}
+		logger := log.With(logger, "grant_id", r.GrantID, "window_days", r.WindowDays)
+		claim := r.claim()
+		claimed, err := reminders.Claim(ctx, claim, event.Timestamp)
+		if err != nil {
+			return log.Errorf(logger, "Error recording reminder in DynamoDB", err)
+		}
+		if !claimed {
+			log.Debug(logger, "Skipped reminder that was already published")
+			sendMetric("reminder.duplicate", 1)
+			return nil
+		}
+		entry.OnFailure = func() { reminders.Release(ctx, logger, claim) }
+		if err := publisher.Add(ctx, entry); err != nil {
+			return log.Errorf(logger, "Error publishing to EventBridge", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := publisher.Flush(ctx); err != nil {
+		return log.Errorf(logger, "Error publishing to EventBridge", err)
+	}
+
+	log.Info(logger, "Published closing soon reminders",
+		"published", publisher.Published, "failed", publisher.Failed)
+	return nil
+}
+
+// closingSoonReminder returns the reminder and EventBridge entry for a GrantClosingSoonEvent if
+// the grant built from item is posted and closes within a window as of now.
+func closingSoonReminder(logger log.Logger, item map[string]ddbtypes.AttributeValue, now clock.Clock) (reminder, scheduledLambda.Entry, bool) {
+	grant, ok := scheduledLambda.BuildGrant(logger, sendMetric, item, now)
+	if !ok {
+		return reminder{}, scheduledLambda.Entry{}, false
+	}
+	opportunity := grant.Opportunity
+	if opportunity.Status != usdr.OpportunityStatusPosted || opportunity.DaysUntilClose == nil {
+		return reminder{}, scheduledLambda.Entry{}, false
+	}
+	window, ok := windowFor(*opportunity.DaysUntilClose)
+	if !ok {
+		return reminder{}, scheduledLambda.Entry{}, false
+	}
+
+	logger = log.With(logger, "grant_id", opportunity.Id)
+	scheduledLambda.ValidateGrant(logger, sendMetric, grant)
+	eventJSON, err := json.Marshal(usdr.NewGrantClosingSoonEvent(grant, window))
+	if err != nil {
+		log.Error(logger, "Error marshaling closing soon event", err)
+		return reminder{}, scheduledLambda.Entry{}, false
+	}
+	r := reminder{
+		GrantID:    opportunity.Id,
+		CloseDate:  time.Time(*opportunity.Milestones.Close.Date),
+		WindowDays: window,
+	}
+	entry := scheduledLambda.Entry{
+		PutEventsRequestEntry: types.PutEventsRequestEntry{
+			Source:       aws.String("org.usdigitalresponse.grants-ingest"),
+			DetailType:   aws.String("GrantClosingSoon"),
+			Detail:       aws.String(string(eventJSON)),
+			Time:         aws.Time(now.Now()),
+			EventBusName: aws.String(env.EventBusName),
+		},
+		GrantID: opportunity.Id,
+	}
+	return r, entry, true
+}
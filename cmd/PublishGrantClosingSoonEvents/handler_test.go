@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	goenv "github.com/Netflix/go-env"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/usdigitalresponse/grants-ingest/internal/scheduledLambda"
+	"github.com/usdigitalresponse/grants-ingest/internal/scheduledLambda/fakes"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/usdr"
+)
+
+func setupLambdaEnvForTesting(t *testing.T) {
+	t.Helper()
+	fakes.SetupLambdaEnv(t, &logger, &env, goenv.EnvSet{
+		"GRANTS_PREPARED_DYNAMODB_NAME": "test-table",
+		"REMINDERS_DYNAMODB_NAME":       "test-reminders-table",
+		"EVENT_BUS_NAME":                "test-event-bus",
+	})
+	windows = []int{3, 14, 30}
+}
+
+// publishedReminders returns the window of each published event, keyed by grant ID.
+func publishedReminders(t *testing.T, pub *fakes.EventBridge) map[string]int {
+	t.Helper()
+	reminders := make(map[string]int)
+	for _, entry := range pub.Entries() {
+		assert.Equal(t, "GrantClosingSoon", aws.ToString(entry.DetailType))
+		assert.Equal(t, env.EventBusName, aws.ToString(entry.EventBusName))
+		assert.NoError(t, usdr.ValidateGrantClosingSoonEventJSON([]byte(aws.ToString(entry.Detail))))
+		var ev usdr.GrantClosingSoonEvent
+		require.NoError(t, json.Unmarshal([]byte(aws.ToString(entry.Detail)), &ev))
+		assert.LessOrEqual(t, ev.DaysUntilClose, ev.WindowDays)
+		reminders[ev.Grant.Opportunity.Id] = ev.WindowDays
+	}
+	return reminders
+}
+
+// testItem returns a prepared-data item for a grant with the given close date, in MMDDYYYY format,
+// which may be empty.
+func testItem(grantID, closeDate string, isForecast bool) map[string]ddbtypes.AttributeValue {
+	return fakes.Item(grantID, isForecast, map[string]string{"CloseDate": closeDate})
+}
+
+// eventOn returns an invocation event shortly after midnight in America/New_York on the given day
+// of March 2024.
+func eventOn(day int) scheduledLambda.ScheduledEvent {
+	return scheduledLambda.ScheduledEvent{Timestamp: time.Date(2024, 3, day, 5, 30, 0, 0, time.UTC)}
+}
+
+func TestParseWindows(t *testing.T) {
+	w, err := parseWindows(" 30,3 ,14,3")
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 14, 30}, w)
+
+	for _, s := range []string{"", "30,,3", "30,0", "-1", "two"} {
+		_, err := parseWindows(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestHandleEvent(t *testing.T) {
+	setupLambdaEnvForTesting(t)
+
+	t.Run("publishes the narrowest window of each grant", func(t *testing.T) {
+		ddb := &fakes.ScanClient{Pages: [][]map[string]ddbtypes.AttributeValue{
+			{
+				testItem("1001", "03042024", false), // 3 days
+				testItem("1002", "03112024", false), // 10 days
+				testItem("1003", "03302024", false), // 29 days
+				testItem("1004", "04302024", false), // 60 days
+			},
+			{
+				testItem("1005", "02292024", false), // closed
+				testItem("1006", "03042024", true),  // forecast
+				testItem("1007", "", false),         // no close date
+				testItem("1008", "03012024", false), // closes today
+			},
+		}}
+		reminders := fakes.NewClaimClient(remindersSortKey)
+		pub := &fakes.EventBridge{}
+		require.NoError(t, handleEvent(context.TODO(), ddb, reminders, pub, eventOn(1)))
+
+		assert.Equal(t, map[string]int{"1001": 3, "1002": 14, "1003": 30, "1008": 3}, publishedReminders(t, pub))
+		assert.Len(t, reminders.Items, 4)
+		assert.Contains(t, reminders.Items, "1002|closing_soon#2024-03-11#14d")
+		assert.Equal(t, strconv.FormatInt(time.Date(2024, 4, 10, 0, 0, 0, 0, time.UTC).Unix(), 10),
+			reminders.Items["1002|closing_soon#2024-03-11#14d"]["expires_at"].(*ddbtypes.AttributeValueMemberN).Value)
+	})
+
+	t.Run("publishes once per window", func(t *testing.T) {
+		ddb := &fakes.ScanClient{Pages: [][]map[string]ddbtypes.AttributeValue{
+			{testItem("1001", "03202024", false)},
+		}}
+		reminders := fakes.NewClaimClient(remindersSortKey)
+		var windowsPublished []int
+		for day := 1; day <= 20; day++ {
+			pub := &fakes.EventBridge{}
+			require.NoError(t, handleEvent(context.TODO(), ddb, reminders, pub, eventOn(day)))
+			if w, ok := publishedReminders(t, pub)["1001"]; ok {
+				windowsPublished = append(windowsPublished, w)
+			}
+		}
+		assert.Equal(t, []int{30, 14, 3}, windowsPublished)
+	})
+
+	t.Run("publishes again when the close date changes", func(t *testing.T) {
+		reminders := fakes.NewClaimClient(remindersSortKey)
+		for _, closeDate := range []string{"03102024", "03122024"} {
+			ddb := &fakes.ScanClient{Pages: [][]map[string]ddbtypes.AttributeValue{
+				{testItem("1001", closeDate, false)},
+			}}
+			pub := &fakes.EventBridge{}
+			require.NoError(t, handleEvent(context.TODO(), ddb, reminders, pub, eventOn(1)))
+			assert.Equal(t, map[string]int{"1001": 14}, publishedReminders(t, pub), closeDate)
+		}
+	})
+
+	t.Run("publishes in batches", func(t *testing.T) {
+		var items []map[string]ddbtypes.AttributeValue
+		for i := 0; i < 25; i++ {
+			items = append(items, testItem(fmt.Sprint(2000+i), "03042024", false))
+		}
+		ddb := &fakes.ScanClient{Pages: [][]map[string]ddbtypes.AttributeValue{items}}
+		pub := &fakes.EventBridge{}
+		require.NoError(t, handleEvent(context.TODO(), ddb, fakes.NewClaimClient(remindersSortKey), pub, eventOn(1)))
+		require.Len(t, pub.Calls, 3)
+		assert.Len(t, pub.Calls[0].Entries, 10)
+		assert.Len(t, pub.Calls[1].Entries, 10)
+		assert.Len(t, pub.Calls[2].Entries, 5)
+	})
+
+	t.Run("releases reminders that fail to publish", func(t *testing.T) {
+		ddb := &fakes.ScanClient{Pages: [][]map[string]ddbtypes.AttributeValue{
+			{testItem("1001", "03042024", false), testItem("1002", "03042024", false)},
+		}}
+		reminders := fakes.NewClaimClient(remindersSortKey)
+		pub := &fakes.EventBridge{FailGrants: map[string]bool{"1002": true}}
+		require.NoError(t, handleEvent(context.TODO(), ddb, reminders, pub, eventOn(1)))
+		assert.Len(t, reminders.Items, 1)
+		assert.Contains(t, reminders.Items, "1001|closing_soon#2024-03-04#3d")
+
+		pub = &fakes.EventBridge{}
+		require.NoError(t, handleEvent(context.TODO(), ddb, reminders, pub, eventOn(1)))
+		assert.Equal(t, map[string]int{"1002": 3}, publishedReminders(t, pub))
+	})
+
+	t.Run("publish error", func(t *testing.T) {
+		ddb := &fakes.ScanClient{Pages: [][]map[string]ddbtypes.AttributeValue{
+			{testItem("1001", "03042024", false)},
+		}}
+		reminders := fakes.NewClaimClient(remindersSortKey)
+		pub := &fakes.EventBridge{Err: errors.New("publish failed")}
+		assert.Error(t, handleEvent(context.TODO(), ddb, reminders, pub, eventOn(1)))
+		assert.Empty(t, reminders.Items, "reminders should be released")
+	})
+
+	t.Run("scan error", func(t *testing.T) {
+		ddb := &fakes.ScanClient{Err: errors.New("scan failed")}
+		pub := &fakes.EventBridge{}
+		assert.Error(t, handleEvent(context.TODO(), ddb, fakes.NewClaimClient(remindersSortKey), pub, eventOn(1)))
+		assert.Empty(t, pub.Calls)
+	})
+
+	t.Run("releases claimed reminders after scan error", func(t *testing.T) {
+		ddb := &fakes.ScanClient{
+			Pages: [][]map[string]ddbtypes.AttributeValue{
+				{testItem("1001", "03042024", false), testItem("1002", "03042024", false)},
+				{testItem("1003", "03042024", false)},
+			},
+			Err:      errors.New("scan failed"),
+			FailPage: 1,
+		}
+		reminders := fakes.NewClaimClient(remindersSortKey)
+		pub := &fakes.EventBridge{}
+		assert.Error(t, handleEvent(context.TODO(), ddb, reminders, pub, eventOn(1)))
+		assert.Empty(t, pub.Calls)
+		assert.Empty(t, reminders.Items, "reminders should be released")
+
+		ddb.Err = nil
+		require.NoError(t, handleEvent(context.TODO(), ddb, reminders, pub, eventOn(1)))
+		assert.Equal(t, map[string]int{"1001": 3, "1002": 3, "1003": 3}, publishedReminders(t, pub))
+	})
+
+	t.Run("releases claimed reminders after claim error", func(t *testing.T) {
+		ddb := &fakes.ScanClient{Pages: [][]map[string]ddbtypes.AttributeValue{
+			{testItem("1001", "03042024", false), testItem("1002", "03042024", false)},
+		}}
+		reminders := fakes.NewClaimClient(remindersSortKey)
+		reminders.FailGrants = map[string]bool{"1002": true}
+		pub := &fakes.EventBridge{}
+		assert.Error(t, handleEvent(context.TODO(), ddb, reminders, pub, eventOn(1)))
+		assert.Empty(t, pub.Calls)
+		assert.Empty(t, reminders.Items, "reminders should be released")
+	})
+
+	t.Run("releases claimed reminders when canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ddb := &fakes.ScanClient{
+			Pages: [][]map[string]ddbtypes.AttributeValue{
+				{testItem("1001", "03042024", false)},
+				{testItem("1002", "03042024", false)},
+			},
+			OnPage: func(int) { cancel() },
+		}
+		reminders := fakes.NewClaimClient(remindersSortKey)
+		pub := &fakes.EventBridge{}
+		assert.ErrorIs(t, handleEvent(ctx, ddb, reminders, pub, eventOn(1)), context.Canceled)
+		assert.Empty(t, pub.Calls)
+		assert.Empty(t, reminders.Items, "reminders should be released")
+	})
+}
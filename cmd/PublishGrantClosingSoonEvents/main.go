@@ -0,0 +1,64 @@
+// Package main compiles to an AWS Lambda handler binary that, when invoked, scans the DynamoDB
+// table named by the GRANTS_PREPARED_DYNAMODB_NAME environment variable for open grants that
+// close within one of the windows of days given by the comma-separated CLOSING_SOON_WINDOWS
+// environment variable (by default, "30,14,3"), as of the "timestamp" field of the invocation
+// event payload. A GrantClosingSoonEvent is published to the EventBridge event bus named by the
+// EVENT_BUS_NAME environment variable for the narrowest window that contains each grant's close
+// date. Published reminders are recorded in the DynamoDB table named by the
+// REMINDERS_DYNAMODB_NAME environment variable, so that each is published at most once for
+// each window and close date of a grant.
+package main
+
+import (
+	"context"
+	goLog "log"
+
+	goenv "github.com/Netflix/go-env"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/usdigitalresponse/grants-ingest/internal/ddHelpers"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+	"github.com/usdigitalresponse/grants-ingest/internal/scheduledLambda"
+)
+
+type Environment struct {
+	LogLevel           string `env:"LOG_LEVEL,default=INFO"`
+	DynamoDBTableName  string `env:"GRANTS_PREPARED_DYNAMODB_NAME,required=true"`
+	RemindersTableName string `env:"REMINDERS_DYNAMODB_NAME,required=true"`
+	EventBusName       string `env:"EVENT_BUS_NAME,required=true"`
+	ClosingSoonWindows string `env:"CLOSING_SOON_WINDOWS"`
+	Extras             goenv.EnvSet
+}
+
+// defaultClosingSoonWindows is used when CLOSING_SOON_WINDOWS is not set.
+const defaultClosingSoonWindows = "30,14,3"
+
+var (
+	env        Environment
+	logger     log.Logger
+	windows    []int
+	sendMetric = ddHelpers.NewMetricSender("PublishGrantClosingSoonEvents")
+)
+
+func main() {
+	es, err := goenv.UnmarshalFromEnviron(&env)
+	if err != nil {
+		goLog.Fatalf("error configuring environment variables: %v", err)
+	}
+	env.Extras = es
+	if env.ClosingSoonWindows == "" {
+		env.ClosingSoonWindows = defaultClosingSoonWindows
+	}
+	windows, err = parseWindows(env.ClosingSoonWindows)
+	if err != nil {
+		goLog.Fatalf("error configuring environment variables: %v", err)
+	}
+	log.ConfigureLogger(&logger, env.LogLevel)
+
+	log.Debug(logger, "Starting Lambda")
+	scheduledLambda.Start(func(ctx context.Context, cfg aws.Config, event scheduledLambda.ScheduledEvent) error {
+		ddbsvc := dynamodb.NewFromConfig(cfg)
+		return handleEvent(ctx, ddbsvc, ddbsvc, eventbridge.NewFromConfig(cfg), event)
+	})
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/usdigitalresponse/grants-ingest/internal/scheduledLambda"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/usdr"
+)
+
+// reminderRetention is how long reminders are recorded after the close date they refer to,
+// after which DynamoDB may delete them (using the expires_at TTL attribute).
+const reminderRetention = 30 * 24 * time.Hour
+
+// remindersSortKey is the name of the sort key of the reminders table.
+const remindersSortKey = "reminder"
+
+// reminder identifies the closing-soon reminder for a window of days before a grant's close date.
+// Reminders are keyed by close date, so that a grant whose close date is extended is reminded again.
+type reminder struct {
+	GrantID    string
+	CloseDate  time.Time
+	WindowDays int
+}
+
+// claim returns the claim that records the reminder as published.
+func (r reminder) claim() scheduledLambda.Claim {
+	return scheduledLambda.Claim{
+		GrantID:   r.GrantID,
+		Event:     fmt.Sprintf("closing_soon#%s#%dd", r.CloseDate.Format(usdr.DateLayout), r.WindowDays),
+		ExpiresAt: r.CloseDate.Add(reminderRetention),
+	}
+}
@@ -1,6 +1,7 @@
 // Package main compiles to an AWS Lambda handler binary that, when invoked, ingests
 // DynamoDB stream events, publishing each record as a new event to the "Grants"
-// EventBridge event bus.
+// EventBridge event bus. When configured with the prepared data bucket, grants are enriched
+// with the Assistance Listings catalog stored in that bucket.
 // On error, sends failing events to the "Publish Grant Events DLQ" dead-letter queue.
 // Keeps track of the SequenceNumber attributes of events that fail to publish to EventBridge,
 // and reports them at the end of each invocation.
@@ -17,23 +18,17 @@ import (
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/usdigitalresponse/grants-ingest/internal/awsHelpers"
-	"github.com/usdigitalresponse/grants-ingest/internal/ddHelpers"
+	"github.com/usdigitalresponse/grants-ingest/internal/lambdas/publishGrantEvents"
 	"github.com/usdigitalresponse/grants-ingest/internal/log"
 	awstrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/aws/aws-sdk-go-v2/aws"
 	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
 )
 
-type Environment struct {
-	LogLevel     string `env:"LOG_LEVEL,default=INFO"`
-	EventBusName string `env:"EVENT_BUS_NAME,required=true"`
-	Extras       goenv.EnvSet
-}
-
 var (
-	env        Environment
-	logger     log.Logger
-	sendMetric = ddHelpers.NewMetricSender("PublishGrantEvents")
+	env    publishGrantEvents.Environment
+	logger log.Logger
 )
 
 func main() {
@@ -43,6 +38,7 @@ func main() {
 	}
 	env.Extras = es
 	log.ConfigureLogger(&logger, env.LogLevel)
+	publishGrantEvents.Configure(env, logger)
 
 	log.Debug(logger, "Starting Lambda")
 	lambda.Start(ddlambda.WrapFunction(
@@ -54,8 +50,11 @@ func main() {
 			}
 			awstrace.AppendMiddleware(&cfg)
 			eventBridgeClient := eventbridge.NewFromConfig(cfg)
+			s3svc := s3.NewFromConfig(cfg, func(o *s3.Options) {
+				o.UsePathStyle = env.UsePathStyleS3Opt
+			})
 			httptrace.WrapClient(http.DefaultClient)
-			return handleEvent(ctx, eventBridgeClient, event)
+			return publishGrantEvents.HandleEvent(ctx, eventBridgeClient, s3svc, event)
 		}, nil),
 	)
 }
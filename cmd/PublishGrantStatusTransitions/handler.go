@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+	"github.com/usdigitalresponse/grants-ingest/internal/scheduledLambda"
+	"github.com/usdigitalresponse/grants-ingest/pkg/clock"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/usdr"
+)
+
+// handleEvent is a Lambda function handler that is called with the ScheduledEvent invocation
+// event. When invoked, it compares the status of every grant in the prepared-data table at the
+// time of the event with its status at the start of the lookback period, and publishes a
+// GrantStatusTransitionEvent for each grant whose status differs, unless that transition was
+// already published by a previous invocation.
+// Since both statuses are computed from the same item, only transitions caused by the passage
+// of time are published; transitions caused by modifications are published as
+// GrantModificationEvents by the PublishGrantEvents Lambda function.
+func handleEvent(ctx context.Context, ddbsvc scheduledLambda.DynamoDBScanAPI, transitionsvc scheduledLambda.DynamoDBClaimsAPI, pub scheduledLambda.EventBridgePutEventsAPI, event scheduledLambda.ScheduledEvent) error {
+	logger := log.With(logger, "table", env.DynamoDBTableName, "transitions_table", env.TransitionsTableName,
+		"event_bus_name", env.EventBusName, "timestamp", event.Timestamp, "lookback", env.Lookback)
+	now := clock.Fixed(event.Timestamp)
+	previousDay := clock.Today(clock.Fixed(event.Timestamp.Add(-env.Lookback)))
+	transitions := scheduledLambda.ClaimTable{
+		Client:    transitionsvc,
+		TableName: env.TransitionsTableName,
+		SortKey:   transitionsSortKey,
+	}
+
+	publisher := scheduledLambda.NewPublisher(pub, logger, sendMetric)
+	// Transitions that are still pending when returning early were claimed but not published,
+	// so they are released to be published by a later invocation.
+	defer publisher.Discard()
+
+	err := scheduledLambda.ScanTable(ctx, logger, ddbsvc, env.DynamoDBTableName, func(item map[string]ddbtypes.AttributeValue) error {
+		claim, entry, ok := statusTransitionEntry(logger, item, now, previousDay)
+		if !ok {
+			return nil
+		}
+		logger := log.With(logger, "grant_id", claim.GrantID, "transition", claim.Event)
+		claimed, err := transitions.Claim(ctx, claim, event.Timestamp)
+		if err != nil {
+			return log.Errorf(logger, "Error recording status transition in DynamoDB", err)
+		}
+		if !claimed {
+			log.Debug(logger, "Skipped status transition that was already published")
+			sendMetric("status.duplicate", 1)
+			return nil
+		}
+		entry.OnFailure = func() { transitions.Release(ctx, logger, claim) }
+		if err := publisher.Add(ctx, entry); err != nil {
+			return log.Errorf(logger, "Error publishing to EventBridge", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := publisher.Flush(ctx); err != nil {
+		return log.Errorf(logger, "Error publishing to EventBridge", err)
+	}
+
+	log.Info(logger, "Published grant status transitions",
+		"published", publisher.Published, "failed", publisher.Failed)
+	return nil
+}
+
+// statusTransitionEntry returns the claim and EventBridge entry for a GrantStatusTransitionEvent
+// if the status of the grant built from item at the time of now differs from its status on
+// previousDay. It returns false if the grant's status did not change or the grant cannot be built.
+func statusTransitionEntry(logger log.Logger, item map[string]ddbtypes.AttributeValue, now clock.Clock, previousDay time.Time) (scheduledLambda.Claim, scheduledLambda.Entry, bool) {
+	grant, ok := scheduledLambda.BuildGrant(logger, sendMetric, item, now)
+	if !ok {
+		return scheduledLambda.Claim{}, scheduledLambda.Entry{}, false
+	}
+	logger = log.With(logger, "grant_id", grant.Opportunity.Id)
+	scheduledLambda.ValidateGrant(logger, sendMetric, grant)
+
+	previousStatus := grant.Opportunity.StatusOn(previousDay)
+	if previousStatus == grant.Opportunity.Status {
+		return scheduledLambda.Claim{}, scheduledLambda.Entry{}, false
+	}
+	claim, ok := transitionClaim(grant)
+	if !ok {
+		log.Warn(logger, "Skipped status transition without a close or archive date",
+			"previous_status", previousStatus, "status", grant.Opportunity.Status)
+		return scheduledLambda.Claim{}, scheduledLambda.Entry{}, false
+	}
+	eventJSON, err := json.Marshal(usdr.NewGrantStatusTransitionEvent(grant, previousStatus))
+	if err != nil {
+		log.Error(logger, "Error marshaling status transition event", err)
+		return scheduledLambda.Claim{}, scheduledLambda.Entry{}, false
+	}
+	log.Debug(logger, "Found grant status transition",
+		"previous_status", previousStatus, "status", grant.Opportunity.Status)
+	sendMetric("status.transitioned", 1,
+		fmt.Sprintf("from:%s", previousStatus), fmt.Sprintf("to:%s", grant.Opportunity.Status))
+	return claim, scheduledLambda.Entry{
+		PutEventsRequestEntry: types.PutEventsRequestEntry{
+			Source:       aws.String("org.usdigitalresponse.grants-ingest"),
+			DetailType:   aws.String("GrantStatusTransitionEvent"),
+			Detail:       aws.String(string(eventJSON)),
+			Time:         aws.Time(now.Now()),
+			EventBusName: aws.String(env.EventBusName),
+		},
+		GrantID: grant.Opportunity.Id,
+	}, true
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	goenv "github.com/Netflix/go-env"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/usdigitalresponse/grants-ingest/internal/scheduledLambda"
+	"github.com/usdigitalresponse/grants-ingest/internal/scheduledLambda/fakes"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/usdr"
+)
+
+func setupLambdaEnvForTesting(t *testing.T) {
+	t.Helper()
+	fakes.SetupLambdaEnv(t, &logger, &env, goenv.EnvSet{
+		"GRANTS_PREPARED_DYNAMODB_NAME":    "test-table",
+		"STATUS_TRANSITIONS_DYNAMODB_NAME": "test-transitions-table",
+		"EVENT_BUS_NAME":                   "test-event-bus",
+		"STATUS_TRANSITION_LOOKBACK":       "24h",
+	})
+}
+
+// publishedTransitions returns the previous and new status of each published event, keyed by
+// grant ID.
+func publishedTransitions(t *testing.T, pub *fakes.EventBridge) map[string][2]usdr.OpportunityStatus {
+	t.Helper()
+	transitions := make(map[string][2]usdr.OpportunityStatus)
+	for _, entry := range pub.Entries() {
+		assert.Equal(t, "GrantStatusTransitionEvent", aws.ToString(entry.DetailType))
+		assert.Equal(t, env.EventBusName, aws.ToString(entry.EventBusName))
+		assert.NoError(t, usdr.ValidateGrantStatusTransitionEventJSON([]byte(aws.ToString(entry.Detail))))
+		var ev usdr.GrantStatusTransitionEvent
+		require.NoError(t, json.Unmarshal([]byte(aws.ToString(entry.Detail)), &ev))
+		assert.Equal(t, usdr.SchemaVersion, ev.SchemaVersion)
+		assert.Equal(t, ev.Status, ev.Grant.Opportunity.Status)
+		transitions[ev.Grant.Opportunity.Id] = [2]usdr.OpportunityStatus{ev.PreviousStatus, ev.Status}
+	}
+	return transitions
+}
+
+// testItem returns a prepared-data item for a grant with the given close and archive dates,
+// in MMDDYYYY format, which may be empty.
+func testItem(grantID, closeDate, archiveDate string, isForecast bool) map[string]ddbtypes.AttributeValue {
+	return fakes.Item(grantID, isForecast, map[string]string{"CloseDate": closeDate, "ArchiveDate": archiveDate})
+}
+
+func TestHandleEvent(t *testing.T) {
+	setupLambdaEnvForTesting(t)
+	// 2024-03-10 at 00:15 in America/New_York
+	event := scheduledLambda.ScheduledEvent{Timestamp: time.Date(2024, 3, 10, 5, 15, 0, 0, time.UTC)}
+
+	t.Run("publishes transitions caused by the passage of time", func(t *testing.T) {
+		ddb := &fakes.ScanClient{Pages: [][]map[string]ddbtypes.AttributeValue{
+			{
+				testItem("1001", "03082024", "", false), // closed yesterday
+				testItem("1002", "03092024", "", false), // closes today
+				testItem("1003", "03102024", "", false), // closes tomorrow
+			},
+			{
+				testItem("1004", "03012024", "03102024", false), // archived today
+				testItem("1005", "", "03102024", true),          // forecast archived today
+				testItem("1006", "", "", true),                  // forecast
+			},
+		}}
+		transitions := fakes.NewClaimClient(transitionsSortKey)
+		pub := &fakes.EventBridge{}
+		require.NoError(t, handleEvent(context.TODO(), ddb, transitions, pub, event))
+
+		assert.Equal(t, map[string][2]usdr.OpportunityStatus{
+			"1002": {usdr.OpportunityStatusPosted, usdr.OpportunityStatusClosed},
+			"1004": {usdr.OpportunityStatusClosed, usdr.OpportunityStatusArchived},
+			"1005": {usdr.OpportunityStatusForecasted, usdr.OpportunityStatusArchived},
+		}, publishedTransitions(t, pub))
+		assert.Len(t, transitions.Items, 3)
+		assert.Contains(t, transitions.Items, "1002|closed#2024-03-09")
+		assert.Contains(t, transitions.Items, "1004|archived#2024-03-10")
+		assert.Equal(t, strconv.FormatInt(time.Date(2024, 4, 8, 0, 0, 0, 0, time.UTC).Unix(), 10),
+			transitions.Items["1002|closed#2024-03-09"]["expires_at"].(*ddbtypes.AttributeValueMemberN).Value)
+	})
+
+	t.Run("publishes each transition once within the lookback period", func(t *testing.T) {
+		env.Lookback = 72 * time.Hour
+		t.Cleanup(func() { env.Lookback = 24 * time.Hour })
+		ddb := &fakes.ScanClient{Pages: [][]map[string]ddbtypes.AttributeValue{{
+			testItem("1001", "03082024", "", false),
+			testItem("1002", "03092024", "03122024", false),
+		}}}
+		transitions := fakes.NewClaimClient(transitionsSortKey)
+
+		// The invocation on 2024-03-09 was missed, so the next invocation publishes its transitions
+		pub := &fakes.EventBridge{}
+		require.NoError(t, handleEvent(context.TODO(), ddb, transitions, pub, event))
+		assert.Equal(t, map[string][2]usdr.OpportunityStatus{
+			"1001": {usdr.OpportunityStatusPosted, usdr.OpportunityStatusClosed},
+			"1002": {usdr.OpportunityStatusPosted, usdr.OpportunityStatusClosed},
+		}, publishedTransitions(t, pub))
+
+		for day, expected := range map[int]map[string][2]usdr.OpportunityStatus{
+			11: {},
+			12: {"1002": {usdr.OpportunityStatusPosted, usdr.OpportunityStatusArchived}},
+			13: {},
+		} {
+			pub := &fakes.EventBridge{}
+			event := scheduledLambda.ScheduledEvent{Timestamp: time.Date(2024, 3, day, 4, 15, 0, 0, time.UTC)}
+			require.NoError(t, handleEvent(context.TODO(), ddb, transitions, pub, event))
+			assert.Equal(t, expected, publishedTransitions(t, pub), "2024-03-%d", day)
+		}
+	})
+
+	t.Run("releases transitions that fail to publish", func(t *testing.T) {
+		ddb := &fakes.ScanClient{Pages: [][]map[string]ddbtypes.AttributeValue{{
+			testItem("1001", "03092024", "", false),
+			testItem("1002", "03092024", "", false),
+		}}}
+		transitions := fakes.NewClaimClient(transitionsSortKey)
+		pub := &fakes.EventBridge{FailGrants: map[string]bool{"1002": true}}
+		require.NoError(t, handleEvent(context.TODO(), ddb, transitions, pub, event))
+		assert.Len(t, transitions.Items, 1)
+		assert.Contains(t, transitions.Items, "1001|closed#2024-03-09")
+
+		pub = &fakes.EventBridge{}
+		require.NoError(t, handleEvent(context.TODO(), ddb, transitions, pub, event))
+		assert.Equal(t, map[string][2]usdr.OpportunityStatus{
+			"1002": {usdr.OpportunityStatusPosted, usdr.OpportunityStatusClosed},
+		}, publishedTransitions(t, pub), "Only the failed transition should be published again")
+	})
+
+	t.Run("publishes in batches", func(t *testing.T) {
+		var items []map[string]ddbtypes.AttributeValue
+		for i := 0; i < 25; i++ {
+			items = append(items, testItem(strconv.Itoa(2000+i), "03092024", "", false))
+		}
+		ddb := &fakes.ScanClient{Pages: [][]map[string]ddbtypes.AttributeValue{items}}
+		transitions := fakes.NewClaimClient(transitionsSortKey)
+		pub := &fakes.EventBridge{FailGrants: map[string]bool{"2000": true}}
+		require.NoError(t, handleEvent(context.TODO(), ddb, transitions, pub, event),
+			"failed entries should not cause an error")
+		require.Len(t, pub.Calls, 3)
+		assert.Len(t, pub.Calls[0].Entries, 10)
+		assert.Len(t, pub.Calls[1].Entries, 10)
+		assert.Len(t, pub.Calls[2].Entries, 5)
+	})
+
+	t.Run("no transitions", func(t *testing.T) {
+		ddb := &fakes.ScanClient{Pages: [][]map[string]ddbtypes.AttributeValue{
+			{testItem("1003", "03102024", "", false)},
+		}}
+		transitions := fakes.NewClaimClient(transitionsSortKey)
+		pub := &fakes.EventBridge{}
+		require.NoError(t, handleEvent(context.TODO(), ddb, transitions, pub, event))
+		assert.Empty(t, pub.Calls)
+	})
+
+	t.Run("scan error", func(t *testing.T) {
+		ddb := &fakes.ScanClient{Err: errors.New("scan failed")}
+		transitions := fakes.NewClaimClient(transitionsSortKey)
+		pub := &fakes.EventBridge{}
+		assert.Error(t, handleEvent(context.TODO(), ddb, transitions, pub, event))
+		assert.Empty(t, pub.Calls)
+	})
+
+	t.Run("publish error", func(t *testing.T) {
+		ddb := &fakes.ScanClient{Pages: [][]map[string]ddbtypes.AttributeValue{
+			{testItem("1002", "03092024", "", false)},
+		}}
+		transitions := fakes.NewClaimClient(transitionsSortKey)
+		pub := &fakes.EventBridge{Err: errors.New("publish failed")}
+		assert.Error(t, handleEvent(context.TODO(), ddb, transitions, pub, event))
+		assert.Empty(t, transitions.Items, "transitions should be released")
+	})
+
+	t.Run("claim error", func(t *testing.T) {
+		ddb := &fakes.ScanClient{Pages: [][]map[string]ddbtypes.AttributeValue{{
+			testItem("1001", "03092024", "", false),
+			testItem("1002", "03092024", "", false),
+		}}}
+		transitions := fakes.NewClaimClient(transitionsSortKey)
+		transitions.FailGrants = map[string]bool{"1002": true}
+		pub := &fakes.EventBridge{}
+		assert.Error(t, handleEvent(context.TODO(), ddb, transitions, pub, event))
+		assert.Empty(t, pub.Calls)
+		assert.Empty(t, transitions.Items, "transitions should be released")
+	})
+}
@@ -0,0 +1,58 @@
+// Package main compiles to an AWS Lambda handler binary that, when invoked, scans the DynamoDB
+// table named by the GRANTS_PREPARED_DYNAMODB_NAME environment variable for grants whose
+// lifecycle status changed only because time passed (i.e. a close date or archive date was
+// reached) since the STATUS_TRANSITION_LOOKBACK duration before the "timestamp" field of the
+// invocation event payload. A GrantStatusTransitionEvent is published for each such grant to the
+// EventBridge event bus named by the EVENT_BUS_NAME environment variable, unless the transition
+// is already recorded as published in the DynamoDB table named by the
+// STATUS_TRANSITIONS_DYNAMODB_NAME environment variable. Since transitions are only published once,
+// the lookback duration may span several invocations, so that transitions are still published
+// after an invocation fails or is missed.
+package main
+
+import (
+	"context"
+	goLog "log"
+	"time"
+
+	goenv "github.com/Netflix/go-env"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/usdigitalresponse/grants-ingest/internal/ddHelpers"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+	"github.com/usdigitalresponse/grants-ingest/internal/scheduledLambda"
+)
+
+type Environment struct {
+	LogLevel             string        `env:"LOG_LEVEL,default=INFO"`
+	DynamoDBTableName    string        `env:"GRANTS_PREPARED_DYNAMODB_NAME,required=true"`
+	TransitionsTableName string        `env:"STATUS_TRANSITIONS_DYNAMODB_NAME,required=true"`
+	EventBusName         string        `env:"EVENT_BUS_NAME,required=true"`
+	Lookback             time.Duration `env:"STATUS_TRANSITION_LOOKBACK,default=72h"`
+	Extras               goenv.EnvSet
+}
+
+var (
+	env        Environment
+	logger     log.Logger
+	sendMetric = ddHelpers.NewMetricSender("PublishGrantStatusTransitions")
+)
+
+func main() {
+	es, err := goenv.UnmarshalFromEnviron(&env)
+	if err != nil {
+		goLog.Fatalf("error configuring environment variables: %v", err)
+	}
+	env.Extras = es
+	if env.Lookback <= 0 || env.Lookback >= transitionRetention {
+		goLog.Fatalf("error configuring environment variables: STATUS_TRANSITION_LOOKBACK must be positive and less than %s", transitionRetention)
+	}
+	log.ConfigureLogger(&logger, env.LogLevel)
+
+	log.Debug(logger, "Starting Lambda")
+	scheduledLambda.Start(func(ctx context.Context, cfg aws.Config, event scheduledLambda.ScheduledEvent) error {
+		ddbsvc := dynamodb.NewFromConfig(cfg)
+		return handleEvent(ctx, ddbsvc, ddbsvc, eventbridge.NewFromConfig(cfg), event)
+	})
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/usdigitalresponse/grants-ingest/internal/scheduledLambda"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/usdr"
+)
+
+// transitionRetention is how long transitions are recorded after the date that caused them,
+// after which DynamoDB may delete them (using the expires_at TTL attribute). It must exceed the
+// lookback duration, so that transitions are not published again by later invocations.
+const transitionRetention = 30 * 24 * time.Hour
+
+// transitionsSortKey is the name of the sort key of the transitions table.
+const transitionsSortKey = "transition"
+
+// transitionClaim returns the claim that records the transition of the grant to its current
+// status as published. Transitions are keyed by the close or archive date that caused them, so
+// that a grant whose date is changed transitions again. It returns false if the grant has no date
+// that could have caused its current status.
+func transitionClaim(grant usdr.Grant) (scheduledLambda.Claim, bool) {
+	opportunity := grant.Opportunity
+	var date *usdr.Date
+	switch opportunity.Status {
+	case usdr.OpportunityStatusClosed:
+		date = opportunity.Milestones.Close.Date
+	case usdr.OpportunityStatusArchived:
+		date = opportunity.Milestones.ArchiveDate
+	}
+	if date == nil {
+		return scheduledLambda.Claim{}, false
+	}
+	return scheduledLambda.Claim{
+		GrantID:   opportunity.Id,
+		Event:     fmt.Sprintf("%s#%s", opportunity.Status, time.Time(*date).Format(usdr.DateLayout)),
+		ExpiresAt: time.Time(*date).Add(transitionRetention),
+	}, true
+}
@@ -7,6 +7,8 @@ import (
 	"net/mail"
 	"strings"
 	"time"
+
+	"github.com/hashicorp/go-multierror"
 )
 
 var (
@@ -42,21 +44,26 @@ func parseEmailContents(r io.Reader) (msg *mail.Message, sender *mail.Address, d
 	return
 }
 
+// verifyEmailIsTrusted runs every trust check against the email and returns a multi-error
+// containing each check that failed, so that callers (and quarantine metadata) can account
+// for all reasons why an email was rejected rather than only the first.
+// Returns nil when all checks pass.
 func verifyEmailIsTrusted(msg *mail.Message, sender *mail.Address) error {
+	var errs *multierror.Error
 	allowedFromDomains := strings.Split(env.AllowedEmailSenders, ",")
 	if !emailAddressAllowed(sender.Address, allowedFromDomains...) {
-		return ErrEmailUnrecognizedSender
+		errs = multierror.Append(errs, ErrEmailUnrecognizedSender)
 	}
 	if err := checkEmailSPF(msg); err != nil {
-		return err
+		errs = multierror.Append(errs, err)
 	}
 	if err := checkEmailSpam(msg); err != nil {
-		return err
+		errs = multierror.Append(errs, err)
 	}
 	if err := checkEmailVirus(msg); err != nil {
-		return err
+		errs = multierror.Append(errs, err)
 	}
-	return nil
+	return errs.ErrorOrNil()
 }
 
 // checkEmailAddress determines whether a given email address matches one or more items
@@ -34,14 +34,17 @@ func handleEvent(ctx context.Context, client S3API, event events.S3Event) error
 
 	msg, sender, sentAt, err := parseEmailContents(resp.Body)
 	if err != nil {
-		return log.Errorf(logger, "failed to parse email from S3 object", err)
+		sendMetric("email.unparseable", 1)
+		err = log.Errorf(logger, "failed to parse email from S3 object", err)
+		return quarantineEmail(ctx, client, event.Records[0], err)
 	}
 	logger = log.With(logger,
 		"email_sender_name", sender.Name, "email_sender_address", sender.Address)
 
 	if err := verifyEmailIsTrusted(msg, sender); err != nil {
 		sendMetric("email.untrusted", 1)
-		return log.Errorf(logger, "email cannot be trusted", err)
+		err = log.Errorf(logger, "email cannot be trusted", err)
+		return quarantineEmail(ctx, client, event.Records[0], err)
 	}
 
 	destKey := fmt.Sprintf("sources/%s/ffis.org/raw.eml", sentAt.Format("2006/01/02"))
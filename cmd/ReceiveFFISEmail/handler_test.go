@@ -91,6 +91,7 @@ func TestHandleEvent(t *testing.T) {
 		uploadFixture     bool
 		shouldError       bool
 		errShouldContain  string
+		expFailedChecks   string
 	}{
 		{
 			name:              "successful invocation",
@@ -106,6 +107,7 @@ func TestHandleEvent(t *testing.T) {
 			uploadFixture:     true,
 			shouldError:       true,
 			errShouldContain:  "failed to parse email from S3 object",
+			expFailedChecks:   "parse",
 		},
 		{
 			name:              "object does not exist",
@@ -122,6 +124,7 @@ func TestHandleEvent(t *testing.T) {
 			uploadFixture:     true,
 			shouldError:       true,
 			errShouldContain:  "email cannot be trusted",
+			expFailedChecks:   "sender",
 		},
 		{
 			name:              "copy object failure",
@@ -163,6 +166,30 @@ func TestHandleEvent(t *testing.T) {
 				assert.Error(t, err)
 				assert.ErrorContains(t, err, tt.errShouldContain)
 			}
+
+			quarantined, err := svc.ListObjectsV2(context.Background(), &s3.ListObjectsV2Input{
+				Bucket: aws.String(env.DestinationBucket),
+				Prefix: aws.String("quarantine/"),
+			})
+			if tt.expFailedChecks == "" {
+				if err == nil {
+					assert.Empty(t, quarantined.Contents, "Email was unexpectedly quarantined")
+				}
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, quarantined.Contents, 1, "Expected a single quarantined email")
+			assert.Regexp(t, `^quarantine/\d{4}/\d{2}/\d{2}/ffis.org/key.eml$`,
+				aws.ToString(quarantined.Contents[0].Key))
+			head, err := svc.HeadObject(context.Background(), &s3.HeadObjectInput{
+				Bucket: aws.String(env.DestinationBucket),
+				Key:    quarantined.Contents[0].Key,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expFailedChecks, head.Metadata[QuarantineMetadataFailedChecks])
+			assert.Equal(t, sourceBucket, head.Metadata[QuarantineMetadataSourceBucket])
+			assert.Equal(t, sourceKey, head.Metadata[QuarantineMetadataSourceKey])
+			assert.NotEmpty(t, head.Metadata[QuarantineMetadataReason])
 		})
 	}
 }
@@ -1,4 +1,7 @@
-// Package main compiles to an AWS Lambda handler binary that, when invoked, TBD
+// Package main compiles to an AWS Lambda handler binary that, when invoked, verifies a newly-received
+// FFIS email and copies it to the source data bucket for further processing. Emails that cannot
+// be parsed or trusted are instead copied to a quarantine location in the same bucket, along with
+// object metadata that records the reasons for rejection.
 package main
 
 import (
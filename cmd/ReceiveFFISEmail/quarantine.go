@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/hashicorp/go-multierror"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+)
+
+const (
+	// Object metadata keys recorded on quarantined emails
+	QuarantineMetadataFailedChecks = "failed-checks"
+	QuarantineMetadataReason       = "quarantine-reason"
+	QuarantineMetadataSourceBucket = "source-bucket"
+	QuarantineMetadataSourceKey    = "source-key"
+
+	// S3 limits user-defined metadata to 2 KB, so the human-readable reason is truncated
+	maxQuarantineReasonLength = 1024
+)
+
+// failedCheckNames maps each error that may cause an email to be rejected to the short
+// identifier recorded in the failed-checks metadata of the quarantined S3 object.
+var failedCheckNames = []struct {
+	err  error
+	name string
+}{
+	{ErrEmailFailedToParse, "parse"},
+	{ErrEmailSenderFailedToParse, "sender_parse"},
+	{ErrEmailDateFailedToParse, "date_parse"},
+	{ErrEmailUnrecognizedSender, "sender"},
+	{ErrEmailSPFCheckFailed, "spf"},
+	{ErrEmailSpamCheckFailed, "spam"},
+	{ErrEmailVirusCheckFailed, "virus"},
+}
+
+// failedChecks returns the identifiers of all known email checks found in err's chain.
+func failedChecks(err error) []string {
+	checks := []string{}
+	for _, fc := range failedCheckNames {
+		if errors.Is(err, fc.err) {
+			checks = append(checks, fc.name)
+		}
+	}
+	return checks
+}
+
+// quarantineObjectKey returns the key where a rejected email received at the given time
+// is quarantined. The key is outside of the "sources/" prefix so that quarantined emails
+// never trigger downstream processing.
+func quarantineObjectKey(receivedAt time.Time, sourceKey string) string {
+	return fmt.Sprintf("quarantine/%s/ffis.org/%s.eml",
+		receivedAt.Format("2006/01/02"), strings.TrimSuffix(path.Base(sourceKey), ".eml"))
+}
+
+// sanitizeMetadataValue makes s safe to store as S3 object metadata, which only permits
+// printable US-ASCII characters.
+func sanitizeMetadataValue(s string, maxLength int) string {
+	s = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return ' '
+		}
+		if r < ' ' || r > '~' {
+			return -1
+		}
+		return r
+	}, s)
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > maxLength {
+		s = s[:maxLength]
+	}
+	return s
+}
+
+// quarantineEmail copies the rejected email identified by record to a quarantine location
+// in the destination bucket. The copied object's metadata records the checks that failed,
+// as determined from rejectErr, so that an operator can review it before it is released.
+// Returns rejectErr when the email was quarantined successfully, or else a multi-error
+// containing both rejectErr and the reason the email could not be quarantined.
+func quarantineEmail(ctx context.Context, client S3API, record events.S3EventRecord, rejectErr error) error {
+	sourceBucket := record.S3.Bucket.Name
+	sourceKey := record.S3.Object.Key
+	receivedAt := record.EventTime
+	if receivedAt.IsZero() {
+		receivedAt = time.Now()
+	}
+	checks := failedChecks(rejectErr)
+	destKey := quarantineObjectKey(receivedAt, sourceKey)
+	logger := log.With(logger, "source_bucket", sourceBucket, "source_key", sourceKey,
+		"destination_bucket", env.DestinationBucket, "destination_key", destKey,
+		"failed_checks", strings.Join(checks, ","))
+
+	if _, err := client.CopyObject(ctx, &s3.CopyObjectInput{
+		CopySource:        aws.String(filepath.Join(sourceBucket, sourceKey)),
+		Bucket:            aws.String(env.DestinationBucket),
+		Key:               aws.String(destKey),
+		MetadataDirective: types.MetadataDirectiveReplace,
+		Metadata: map[string]string{
+			QuarantineMetadataFailedChecks: strings.Join(checks, ","),
+			QuarantineMetadataReason: sanitizeMetadataValue(
+				rejectErr.Error(), maxQuarantineReasonLength),
+			QuarantineMetadataSourceBucket: sourceBucket,
+			QuarantineMetadataSourceKey:    sourceKey,
+		},
+		ServerSideEncryption: types.ServerSideEncryptionAes256,
+	}); err != nil {
+		return multierror.Append(rejectErr,
+			log.Errorf(logger, "failed to quarantine rejected email", err))
+	}
+
+	for _, check := range checks {
+		sendMetric("email.quarantined", 1, fmt.Sprintf("failed_check:%s", check))
+	}
+	log.Warn(logger, "Quarantined rejected email")
+	return rejectErr
+}
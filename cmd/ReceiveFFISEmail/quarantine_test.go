@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailedChecks(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		err       error
+		expChecks []string
+	}{
+		{"unknown error", errors.New("oh no"), []string{}},
+		{"single check", ErrEmailSPFCheckFailed, []string{"spf"}},
+		{"wrapped check", fmt.Errorf("wrapped: %w", ErrEmailDateFailedToParse), []string{"date_parse"}},
+		{
+			"multiple checks",
+			multierror.Append(ErrEmailUnrecognizedSender, ErrEmailSpamCheckFailed, ErrEmailVirusCheckFailed),
+			[]string{"sender", "spam", "virus"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expChecks, failedChecks(tt.err))
+		})
+	}
+}
+
+func TestQuarantineObjectKey(t *testing.T) {
+	receivedAt := time.Date(2023, 4, 22, 14, 55, 26, 0, time.UTC)
+	assert.Equal(t, "quarantine/2023/04/22/ffis.org/abc123.eml",
+		quarantineObjectKey(receivedAt, "ses/inbound/abc123"))
+}
+
+func TestSanitizeMetadataValue(t *testing.T) {
+	assert.Equal(t, "2 errors occurred: * foo * bar",
+		sanitizeMetadataValue("2 errors occurred:\n\t* foo\n\t* bar\n\n", 100))
+	assert.Equal(t, "caf", sanitizeMetadataValue("café", 100))
+	assert.Equal(t, strings.Repeat("x", 10), sanitizeMetadataValue(strings.Repeat("x", 20), 10))
+}
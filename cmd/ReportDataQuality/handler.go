@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+	"github.com/usdigitalresponse/grants-ingest/internal/quality"
+	"github.com/usdigitalresponse/grants-ingest/internal/scheduledLambda"
+)
+
+// reportFormats are the formats in which each report is saved to S3.
+var reportFormats = []string{"json", "html"}
+
+// reportS3Key returns the S3 object key where the report in the given format should be stored
+// for the invocation event.
+func reportS3Key(event scheduledLambda.ScheduledEvent, format string) string {
+	return fmt.Sprintf("%s%s/scorecard.%s", env.ReportKeyPrefix, event.Timestamp.Format("2006/01/02"), format)
+}
+
+type S3PutObjectAPI interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+// handleEvent is a Lambda function handler that is called with the ScheduledEvent invocation
+// event. When invoked, it scores the data quality of every item in the prepared-data table
+// and saves the resulting report to S3.
+func handleEvent(ctx context.Context, ddbsvc scheduledLambda.DynamoDBScanAPI, s3svc S3PutObjectAPI, event scheduledLambda.ScheduledEvent) error {
+	logger := log.With(logger, "table", env.DynamoDBTableName, "report_bucket", env.ReportBucket)
+
+	scorecard := quality.NewScorecard(env.SampleSize)
+	if err := scheduledLambda.ScanTable(ctx, logger, ddbsvc, env.DynamoDBTableName, func(item map[string]ddbtypes.AttributeValue) error {
+		scorecard.Add(item)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	report := scorecard.Report(env.DynamoDBTableName, time.Now().UTC())
+	log.Info(logger, "Scored prepared-data items", "items", report.Items,
+		"items_with_problems", report.ItemsWithProblems.Count,
+		"unbuildable_items", report.UnbuildableItems.Count,
+		"invalid_grants", report.InvalidGrants.Count)
+	sendMetric("items.scored", float64(report.Items))
+	sendMetric("items.with_problems", float64(report.ItemsWithProblems.Count))
+	sendMetric("items.unbuildable", float64(report.UnbuildableItems.Count))
+	sendMetric("grant_data.invalid", float64(report.InvalidGrants.Count))
+
+	for _, format := range reportFormats {
+		key := reportS3Key(event, format)
+		logger := log.With(logger, "report_key", key)
+		var buf bytes.Buffer
+		if err := report.Write(&buf, format); err != nil {
+			return log.Errorf(logger, "Error rendering report", err)
+		}
+		if _, err := s3svc.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:               aws.String(env.ReportBucket),
+			Key:                  aws.String(key),
+			Body:                 bytes.NewReader(buf.Bytes()),
+			ContentType:          aws.String(quality.ContentTypes[format]),
+			ServerSideEncryption: types.ServerSideEncryptionAes256,
+		}); err != nil {
+			return log.Errorf(logger, "Error uploading report to S3", err)
+		}
+		log.Info(logger, "Uploaded report to S3")
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	goenv "github.com/Netflix/go-env"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/usdigitalresponse/grants-ingest/internal/scheduledLambda"
+	"github.com/usdigitalresponse/grants-ingest/internal/scheduledLambda/fakes"
+)
+
+func setupLambdaEnvForTesting(t *testing.T) {
+	t.Helper()
+	fakes.SetupLambdaEnv(t, &logger, &env, goenv.EnvSet{
+		"GRANTS_PREPARED_DYNAMODB_NAME": "test-table",
+		"QUALITY_REPORT_BUCKET_NAME":    "test-report-bucket",
+		"QUALITY_REPORT_SAMPLE_SIZE":    "5",
+		"S3_USE_PATH_STYLE":             "true",
+	})
+}
+
+func setupS3ForTesting(t *testing.T) *s3.Client {
+	t.Helper()
+
+	// Start the S3 mock server and shut it down when the test ends
+	faker := gofakes3.New(s3mem.New())
+	ts := httptest.NewServer(faker.Server())
+	t.Cleanup(ts.Close)
+
+	client := s3.New(s3.Options{
+		Region:       "us-west-2",
+		Credentials:  credentials.NewStaticCredentialsProvider("TEST", "TEST", "TESTING"),
+		BaseEndpoint: aws.String(ts.URL),
+		UsePathStyle: true,
+	})
+	_, err := client.CreateBucket(context.TODO(), &s3.CreateBucketInput{
+		Bucket: aws.String(env.ReportBucket),
+	})
+	require.NoError(t, err, "Error creating mock S3 report bucket")
+	return client
+}
+
+// testItem returns a prepared-data item for a grant with the given title, which may be empty.
+func testItem(grantID, title string) map[string]ddbtypes.AttributeValue {
+	return fakes.Item(grantID, false, map[string]string{"AgencyCode": "HHS", "OpportunityTitle": title})
+}
+
+func TestHandleEvent(t *testing.T) {
+	setupLambdaEnvForTesting(t)
+	event := scheduledLambda.ScheduledEvent{Timestamp: time.Date(2024, 1, 4, 6, 0, 0, 0, time.UTC)}
+
+	t.Run("writes reports", func(t *testing.T) {
+		s3svc := setupS3ForTesting(t)
+		ddb := &fakes.ScanClient{Pages: [][]map[string]ddbtypes.AttributeValue{
+			{testItem("1001", "Test grant"), testItem("1002", "")},
+			{testItem("1003", "Another test grant")},
+		}}
+		require.NoError(t, handleEvent(context.TODO(), ddb, s3svc, event))
+
+		resp, err := s3svc.GetObject(context.TODO(), &s3.GetObjectInput{
+			Bucket: aws.String(env.ReportBucket),
+			Key:    aws.String("reports/quality/2024/01/04/scorecard.json"),
+		})
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, "application/json", aws.ToString(resp.ContentType))
+		var report struct {
+			Source            string `json:"source"`
+			Items             int    `json:"items"`
+			ItemsWithProblems struct {
+				Count          int      `json:"count"`
+				SampleGrantIDs []string `json:"sample_grant_ids"`
+			} `json:"items_with_problems"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+		assert.Equal(t, "test-table", report.Source)
+		assert.Equal(t, 3, report.Items)
+		assert.Equal(t, 1, report.ItemsWithProblems.Count)
+		assert.Equal(t, []string{"1002"}, report.ItemsWithProblems.SampleGrantIDs)
+
+		resp, err = s3svc.GetObject(context.TODO(), &s3.GetObjectInput{
+			Bucket: aws.String(env.ReportBucket),
+			Key:    aws.String("reports/quality/2024/01/04/scorecard.html"),
+		})
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, "text/html; charset=utf-8", aws.ToString(resp.ContentType))
+		b, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(b), "cannot be empty: Title")
+	})
+
+	t.Run("scan error", func(t *testing.T) {
+		s3svc := setupS3ForTesting(t)
+		ddb := &fakes.ScanClient{Err: errors.New("scan failed")}
+		assert.ErrorContains(t, handleEvent(context.TODO(), ddb, s3svc, event), "scan failed")
+	})
+
+	t.Run("upload error", func(t *testing.T) {
+		s3svc := setupS3ForTesting(t)
+		_, err := s3svc.DeleteBucket(context.TODO(), &s3.DeleteBucketInput{Bucket: aws.String(env.ReportBucket)})
+		require.NoError(t, err)
+		ddb := &fakes.ScanClient{Pages: [][]map[string]ddbtypes.AttributeValue{{testItem("1001", "Test grant")}}}
+		assert.Error(t, handleEvent(context.TODO(), ddb, s3svc, event))
+	})
+}
@@ -0,0 +1,53 @@
+// Package main compiles to an AWS Lambda handler binary that, when invoked, scores the data
+// quality of every item in the DynamoDB table named by the GRANTS_PREPARED_DYNAMODB_NAME
+// environment variable. The resulting report is saved in JSON and HTML formats to the S3 bucket
+// named by the QUALITY_REPORT_BUCKET_NAME environment variable, keyed as
+// "<QUALITY_REPORT_KEY_PREFIX>YYYY/mm/dd/scorecard.{json,html}", where the "YYYY/mm/dd" path
+// components represent the date in the "timestamp" field of the invocation event payload.
+package main
+
+import (
+	"context"
+	goLog "log"
+
+	goenv "github.com/Netflix/go-env"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/usdigitalresponse/grants-ingest/internal/ddHelpers"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+	"github.com/usdigitalresponse/grants-ingest/internal/scheduledLambda"
+)
+
+type Environment struct {
+	LogLevel          string `env:"LOG_LEVEL,default=INFO"`
+	DynamoDBTableName string `env:"GRANTS_PREPARED_DYNAMODB_NAME,required=true"`
+	ReportBucket      string `env:"QUALITY_REPORT_BUCKET_NAME,required=true"`
+	ReportKeyPrefix   string `env:"QUALITY_REPORT_KEY_PREFIX,default=reports/quality/"`
+	SampleSize        int    `env:"QUALITY_REPORT_SAMPLE_SIZE,default=10"`
+	UsePathStyleS3Opt bool   `env:"S3_USE_PATH_STYLE,default=false"`
+	Extras            goenv.EnvSet
+}
+
+var (
+	env        Environment
+	logger     log.Logger
+	sendMetric = ddHelpers.NewMetricSender("ReportDataQuality")
+)
+
+func main() {
+	es, err := goenv.UnmarshalFromEnviron(&env)
+	if err != nil {
+		goLog.Fatalf("error configuring environment variables: %v", err)
+	}
+	env.Extras = es
+	log.ConfigureLogger(&logger, env.LogLevel)
+
+	log.Debug(logger, "Starting Lambda")
+	scheduledLambda.Start(func(ctx context.Context, cfg aws.Config, event scheduledLambda.ScheduledEvent) error {
+		s3svc := s3.NewFromConfig(cfg, func(o *s3.Options) {
+			o.UsePathStyle = env.UsePathStyleS3Opt
+		})
+		return handleEvent(ctx, dynamodb.NewFromConfig(cfg), s3svc, event)
+	})
+}
@@ -2,6 +2,16 @@
 // FFIS excel file from the source S3 bucket and uploads the parsed opportunities to
 // as individual JSON files to the destination S3 bucket. If a row of the spreadsheet
 // is not able to be parsed, the error is logged at WARN level and the row is skipped.
+// Opportunities whose JSON representation is unchanged since they were last uploaded are
+// not uploaded again.
+//
+// Each spreadsheet's skipped rows, cell parsing errors, and bills are recorded in a parse report,
+// which is saved as parse-report.json alongside the spreadsheet in the source S3 bucket.
+//
+// A manifest of the grant IDs and bills found in each spreadsheet is also saved to the
+// destination S3 bucket and compared against the manifest of the preceding spreadsheet.
+// Grants that no longer appear in the latest spreadsheet have their DynamoDB Bill attribute
+// flagged as stale (or cleared), according to the STALE_BILL_ACTION environment variable.
 package main
 
 import (
@@ -14,25 +24,17 @@ import (
 	goenv "github.com/Netflix/go-env"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/usdigitalresponse/grants-ingest/internal/awsHelpers"
-	"github.com/usdigitalresponse/grants-ingest/internal/ddHelpers"
+	"github.com/usdigitalresponse/grants-ingest/internal/lambdas/splitFFISSpreadsheet"
 	"github.com/usdigitalresponse/grants-ingest/internal/log"
 	awstrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/aws/aws-sdk-go-v2/aws"
 )
 
-type Environment struct {
-	LogLevel             string `env:"LOG_LEVEL,default=INFO"`
-	DownloadChunkLimit   int64  `env:"DOWNLOAD_CHUNK_LIMIT,default=10"`
-	DestinationBucket    string `env:"GRANTS_PREPARED_DATA_BUCKET_NAME,required=true"`
-	MaxConcurrentUploads int    `env:"MAX_CONCURRENT_UPLOADS,default=1"`
-	UsePathStyleS3Opt    bool   `env:"S3_USE_PATH_STYLE,default=false"`
-	Extras               goenv.EnvSet
-}
-
 var (
-	env        Environment
-	logger     log.Logger
-	sendMetric = ddHelpers.NewMetricSender("SplitFFISSpreadsheet", "source:ffis.org")
+	env    splitFFISSpreadsheet.Environment
+	logger log.Logger
 )
 
 func main() {
@@ -43,6 +45,7 @@ func main() {
 	}
 	env.Extras = es
 	log.ConfigureLogger(&logger, env.LogLevel)
+	splitFFISSpreadsheet.Configure(env, logger)
 
 	log.Debug(logger, "Starting Lambda")
 	lambda.Start(ddlambda.WrapFunction(func(ctx context.Context, s3Event events.S3Event) error {
@@ -51,7 +54,12 @@ func main() {
 			return fmt.Errorf("could not create AWS SDK config: %w", err)
 		}
 		awstrace.AppendMiddleware(&cfg)
+		s3svc := s3.NewFromConfig(cfg, func(o *s3.Options) {
+			o.UsePathStyle = env.UsePathStyleS3Opt
+		})
+		dynamodbSvc := dynamodb.NewFromConfig(cfg)
+
 		log.Debug(logger, "Starting Lambda")
-		return handleS3EventWithConfig(cfg, ctx, s3Event)
+		return splitFFISSpreadsheet.HandleS3Event(ctx, s3svc, dynamodbSvc, s3Event)
 	}, nil))
 }
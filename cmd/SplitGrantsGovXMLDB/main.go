@@ -23,29 +23,14 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/usdigitalresponse/grants-ingest/internal/awsHelpers"
-	"github.com/usdigitalresponse/grants-ingest/internal/ddHelpers"
+	"github.com/usdigitalresponse/grants-ingest/internal/lambdas/splitGrantsGovXMLDB"
 	"github.com/usdigitalresponse/grants-ingest/internal/log"
 	awstrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/aws/aws-sdk-go-v2/aws"
 )
 
-type Environment struct {
-	LogLevel                   string `env:"LOG_LEVEL,default=INFO"`
-	DownloadChunkLimit         int64  `env:"DOWNLOAD_CHUNK_LIMIT,default=10"`
-	DestinationBucket          string `env:"GRANTS_PREPARED_DATA_BUCKET_NAME,required=true"`
-	DynamoDBTableName          string `env:"GRANTS_PREPARED_DATA_TABLE_NAME,required=true"`
-	MaxConcurrentUploads       int    `env:"MAX_CONCURRENT_UPLOADS,default=1"`
-	UsePathStyleS3Opt          bool   `env:"S3_USE_PATH_STYLE,default=false"`
-	IsForecastedGrantsEnabled  bool   `env:"IS_FORECASTED_GRANTS_ENABLED,default=false"`
-	MaxSplitRecords            int    `env:"MAX_SPLIT_RECORDS,default=-1"`             // Hard limit of records to process, regardless of type. -1 for no limit.
-	MaxSplitOpportunityRecords int    `env:"MAX_SPLIT_OPPORTUNITY_RECORDS,default=-1"` // Limit opportunity-type records to process. -1 for no limit.
-	MaxSplitForecastRecords    int    `env:"MAX_SPLIT_FORECAST_RECORDS,default=-1"`    // Limit forecast-type records to process. -1 for no limit.
-	Extras                     goenv.EnvSet
-}
-
 var (
-	env        Environment
-	logger     log.Logger
-	sendMetric = ddHelpers.NewMetricSender("SplitGrantsGovXMLDB", "source:grants.gov")
+	env    splitGrantsGovXMLDB.Environment
+	logger log.Logger
 )
 
 func main() {
@@ -55,6 +40,7 @@ func main() {
 	}
 	env.Extras = es
 	log.ConfigureLogger(&logger, env.LogLevel)
+	splitGrantsGovXMLDB.Configure(env, logger)
 
 	log.Debug(logger, "Starting Lambda")
 	lambda.Start(ddlambda.WrapFunction(func(ctx context.Context, s3Event events.S3Event) error {
@@ -69,6 +55,6 @@ func main() {
 		dynamodbSvc := dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {})
 
 		log.Debug(logger, "Starting Lambda")
-		return handleS3Event(ctx, s3svc, dynamodbSvc, s3Event)
+		return splitGrantsGovXMLDB.HandleS3Event(ctx, s3svc, dynamodbSvc, s3Event)
 	}, nil))
 }
@@ -0,0 +1,58 @@
+package assistanceListings
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+type S3GetObjectAPI interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+// Cache holds the catalog stored at an S3 location, which is reloaded once it is older than
+// the cache's refresh interval.
+type Cache struct {
+	Bucket string
+	Key    string
+	// How long a loaded catalog is used before it is reloaded
+	RefreshInterval time.Duration
+
+	mu       sync.Mutex
+	catalog  *Catalog
+	loadedAt time.Time
+}
+
+// Get returns the cached catalog, reloading it from S3 when it is missing or stale.
+// If reloading a stale catalog fails, the stale catalog is returned along with the error.
+func (c *Cache) Get(ctx context.Context, svc S3GetObjectAPI) (*Catalog, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.catalog != nil && time.Since(c.loadedAt) < c.RefreshInterval {
+		return c.catalog, nil
+	}
+
+	catalog, err := c.load(ctx, svc)
+	if err != nil {
+		return c.catalog, err
+	}
+	c.catalog = catalog
+	c.loadedAt = time.Now()
+	return c.catalog, nil
+}
+
+func (c *Cache) load(ctx context.Context, svc S3GetObjectAPI) (*Catalog, error) {
+	resp, err := svc.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.Bucket),
+		Key:    aws.String(c.Key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting s3://%s/%s: %w", c.Bucket, c.Key, err)
+	}
+	defer resp.Body.Close()
+	return Read(resp.Body)
+}
@@ -0,0 +1,72 @@
+package assistanceListings
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockGetObjectAPI struct {
+	body      []byte
+	err       error
+	callCount int
+}
+
+func (m *mockGetObjectAPI) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	m.callCount++
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(m.body))}, nil
+}
+
+func TestCacheGet(t *testing.T) {
+	c, err := NewCatalog("extract.csv", time.Now(), []Listing{{Number: "10.001", Title: "Research"}})
+	require.NoError(t, err)
+	var b bytes.Buffer
+	require.NoError(t, c.WriteJSON(&b))
+
+	t.Run("loads once per refresh interval", func(t *testing.T) {
+		svc := &mockGetObjectAPI{body: b.Bytes()}
+		cache := &Cache{Bucket: "bucket", Key: "key", RefreshInterval: time.Hour}
+		for i := 0; i < 3; i++ {
+			catalog, err := cache.Get(context.Background(), svc)
+			require.NoError(t, err)
+			assert.Equal(t, 1, catalog.Len())
+		}
+		assert.Equal(t, 1, svc.callCount)
+
+		cache.loadedAt = time.Now().Add(-2 * time.Hour)
+		_, err := cache.Get(context.Background(), svc)
+		require.NoError(t, err)
+		assert.Equal(t, 2, svc.callCount)
+	})
+
+	t.Run("stale catalog is returned when reloading fails", func(t *testing.T) {
+		svc := &mockGetObjectAPI{body: b.Bytes()}
+		cache := &Cache{Bucket: "bucket", Key: "key", RefreshInterval: time.Hour}
+		_, err := cache.Get(context.Background(), svc)
+		require.NoError(t, err)
+
+		cache.loadedAt = time.Now().Add(-2 * time.Hour)
+		svc.err = errors.New("oh no")
+		catalog, err := cache.Get(context.Background(), svc)
+		assert.ErrorContains(t, err, "oh no")
+		require.NotNil(t, catalog)
+		assert.Equal(t, 1, catalog.Len())
+	})
+
+	t.Run("no catalog when loading fails", func(t *testing.T) {
+		cache := &Cache{Bucket: "bucket", Key: "key", RefreshInterval: time.Hour}
+		catalog, err := cache.Get(context.Background(), &mockGetObjectAPI{body: []byte("not json")})
+		assert.Error(t, err)
+		assert.Nil(t, catalog)
+	})
+}
@@ -0,0 +1,167 @@
+// Package assistanceListings provides the catalog of SAM.gov Assistance Listings (formerly the
+// Catalog of Federal Domestic Assistance) that is used to enrich the CFDA numbers of grants.
+//
+// A catalog is imported from an Assistance Listings extract by the grants-ingest CLI and stored
+// as JSON in the prepared-data bucket (see preparedData.AssistanceListingsKey), from which it is
+// loaded by the PublishGrantEvents Lambda function.
+package assistanceListings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/usdr"
+)
+
+var ErrDuplicateListing = errors.New("duplicate assistance listing")
+
+// Listing is a federal assistance program.
+type Listing struct {
+	// CFDA number, e.g. "10.001"
+	Number     string `json:"number"`
+	Title      string `json:"title"`
+	Objectives string `json:"objectives,omitempty"`
+	// Name of the administering agency as given by SAM.gov,
+	// e.g. "AGRICULTURAL RESEARCH SERVICE, AGRICULTURE, DEPARTMENT OF"
+	Agency string `json:"agency,omitempty"`
+}
+
+// Catalog is a set of Assistance Listings, indexed by CFDA number.
+type Catalog struct {
+	// Name of the extract from which the catalog was imported
+	Source string
+	// When the catalog was imported
+	ImportedAt time.Time
+
+	listings map[string]Listing
+}
+
+// catalogFile is the JSON encoding of a Catalog.
+type catalogFile struct {
+	Source     string    `json:"source,omitempty"`
+	ImportedAt time.Time `json:"imported_at"`
+	Listings   []Listing `json:"listings"`
+}
+
+// NewCatalog returns a catalog of the given listings. It is an error if any listing has an
+// invalid CFDA number, or if more than one listing has the same CFDA number.
+func NewCatalog(source string, importedAt time.Time, listings []Listing) (*Catalog, error) {
+	c := &Catalog{
+		Source:     source,
+		ImportedAt: importedAt,
+		listings:   make(map[string]Listing, len(listings)),
+	}
+	var errs *multierror.Error
+	for _, l := range listings {
+		l.Number = strings.TrimSpace(l.Number)
+		if _, err := usdr.NewCFDANumber(l.Number); err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("%w: %q", err, l.Number))
+			continue
+		}
+		if _, exists := c.listings[l.Number]; exists {
+			errs = multierror.Append(errs, fmt.Errorf("%w: %s", ErrDuplicateListing, l.Number))
+			continue
+		}
+		c.listings[l.Number] = l
+	}
+	if err := errs.ErrorOrNil(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Read reads a JSON-encoded catalog, as written by WriteJSON.
+func Read(r io.Reader) (*Catalog, error) {
+	var f catalogFile
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, fmt.Errorf("error reading assistance listings catalog: %w", err)
+	}
+	return NewCatalog(f.Source, f.ImportedAt, f.Listings)
+}
+
+// WriteJSON writes the catalog as JSON, with listings sorted by CFDA number.
+func (c *Catalog) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(catalogFile{
+		Source:     c.Source,
+		ImportedAt: c.ImportedAt,
+		Listings:   c.Listings(),
+	})
+}
+
+// Len returns the number of listings in the catalog.
+func (c *Catalog) Len() int {
+	return len(c.listings)
+}
+
+// Listings returns the listings of the catalog, sorted by CFDA number.
+func (c *Catalog) Listings() []Listing {
+	listings := make([]Listing, 0, len(c.listings))
+	for _, l := range c.listings {
+		listings = append(listings, l)
+	}
+	sort.Slice(listings, func(i, j int) bool { return listings[i].Number < listings[j].Number })
+	return listings
+}
+
+// Lookup returns the listing with the given CFDA number.
+func (c *Catalog) Lookup(number string) (Listing, bool) {
+	l, ok := c.listings[number]
+	return l, ok
+}
+
+// Enrich sets the AssistanceListings of the grant to describe each of its CFDA numbers,
+// and returns the CFDA numbers that are not in the catalog.
+func (c *Catalog) Enrich(g *usdr.Grant) (unknown []string) {
+	g.AssistanceListings = nil
+	for _, number := range g.CFDANumbers {
+		al := usdr.AssistanceListing{Number: number}
+		if l, ok := c.Lookup(string(number)); ok {
+			agency := AdministeringAgency(l.Agency)
+			al.Found = true
+			al.ProgramTitle = l.Title
+			al.Objectives = l.Objectives
+			al.AdministeringAgency = &agency
+		} else {
+			unknown = append(unknown, string(number))
+		}
+		g.AssistanceListings = append(g.AssistanceListings, al)
+	}
+	return unknown
+}
+
+// AdministeringAgency returns the agency with the given SAM.gov name, normalized by the default
+// agency registry. SAM.gov names list an agency's parents after the agency itself, with
+// department names inverted (e.g. "FOREST SERVICE, AGRICULTURE, DEPARTMENT OF"), so the agency
+// is reconciled with the registry by the most specific part of the name that is recognized.
+// Names that are not recognized are preserved.
+func AdministeringAgency(name string) usdr.Agency {
+	registry := usdr.DefaultAgencyRegistry()
+	name = usdr.NormalizeAgencyName(name)
+	parts := strings.Split(name, ",")
+	candidates := []string{name}
+	for i := 0; i < len(parts); i++ {
+		part := strings.TrimSpace(parts[i])
+		if i+1 < len(parts) {
+			// "AGRICULTURE, DEPARTMENT OF" -> "DEPARTMENT OF AGRICULTURE"
+			if next := strings.TrimSpace(parts[i+1]); strings.HasPrefix(strings.ToUpper(next), "DEPARTMENT OF") {
+				part = next + " " + part
+				i++
+			}
+		}
+		candidates = append(candidates, part)
+	}
+	for _, candidate := range candidates {
+		if e, ok := registry.Reconcile(candidate); ok {
+			return registry.Normalize(usdr.Agency{Code: e.Code})
+		}
+	}
+	return registry.Normalize(usdr.Agency{Name: name})
+}
@@ -0,0 +1,109 @@
+package assistanceListings
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/usdr"
+)
+
+func TestNewCatalog(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		c, err := NewCatalog("test.csv", time.Now(), []Listing{
+			{Number: "10.678", Title: "Forest Stewardship"},
+			{Number: " 10.001 ", Title: "Agricultural Research"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, c.Len())
+		assert.Equal(t, []Listing{
+			{Number: "10.001", Title: "Agricultural Research"},
+			{Number: "10.678", Title: "Forest Stewardship"},
+		}, c.Listings())
+	})
+
+	t.Run("invalid and duplicate numbers", func(t *testing.T) {
+		_, err := NewCatalog("test.csv", time.Now(), []Listing{
+			{Number: "10.001"},
+			{Number: "10.001"},
+			{Number: "1234.567"},
+		})
+		assert.ErrorIs(t, err, ErrDuplicateListing)
+		assert.ErrorIs(t, err, usdr.ErrInvalidCFDANumber)
+	})
+}
+
+func TestCatalogReadWriteJSON(t *testing.T) {
+	importedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	c, err := NewCatalog("extract.csv", importedAt, []Listing{
+		{Number: "97.042", Title: "Emergency Management Performance Grants",
+			Objectives: "To prepare for all hazards & threats.", Agency: "FEDERAL EMERGENCY MANAGEMENT AGENCY"},
+	})
+	require.NoError(t, err)
+
+	var b bytes.Buffer
+	require.NoError(t, c.WriteJSON(&b))
+	assert.Contains(t, b.String(), "hazards & threats")
+	read, err := Read(&b)
+	require.NoError(t, err)
+	assert.Equal(t, c.Source, read.Source)
+	assert.True(t, c.ImportedAt.Equal(read.ImportedAt))
+	assert.Equal(t, c.Listings(), read.Listings())
+}
+
+func TestCatalogEnrich(t *testing.T) {
+	c, err := NewCatalog("extract.csv", time.Now(), []Listing{{
+		Number:     "10.678",
+		Title:      "Forest Stewardship",
+		Objectives: "To promote stewardship.",
+		Agency:     "FOREST SERVICE, AGRICULTURE, DEPARTMENT OF",
+	}})
+	require.NoError(t, err)
+
+	known, err := usdr.NewCFDANumber("10.678")
+	require.NoError(t, err)
+	unknown, err := usdr.NewCFDANumber("10.999")
+	require.NoError(t, err)
+	grant := usdr.Grant{AssistanceListings: []usdr.AssistanceListing{{Number: unknown, Found: true}}}
+	grant.CFDANumbers = append(grant.CFDANumbers, known, unknown)
+	assert.Equal(t, []string{"10.999"}, c.Enrich(&grant))
+	assert.Equal(t, []usdr.AssistanceListing{
+		{
+			Number:       known,
+			Found:        true,
+			ProgramTitle: "Forest Stewardship",
+			Objectives:   "To promote stewardship.",
+			AdministeringAgency: &usdr.Agency{
+				Name:           "Forest Service",
+				Code:           "USDA-FS",
+				DepartmentCode: "USDA",
+				DepartmentName: "Department of Agriculture",
+				SubAgencyCode:  "USDA-FS",
+				SubAgencyName:  "Forest Service",
+			},
+		},
+		{Number: unknown},
+	}, grant.AssistanceListings)
+}
+
+func TestAdministeringAgency(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		expectedCode string
+		expectedName string
+	}{
+		{"FOREST SERVICE, AGRICULTURE, DEPARTMENT OF", "USDA-FS", "Forest Service"},
+		{"AGRICULTURAL RESEARCH SERVICE, AGRICULTURE, DEPARTMENT OF", "USDA", "Department of Agriculture"},
+		{"INTERIOR, DEPARTMENT OF THE", "DOI", "Department of the Interior"},
+		{"ENVIRONMENTAL PROTECTION AGENCY", "EPA", "Environmental Protection Agency"},
+		{"OFFICE OF SOMETHING,  UNKNOWN", "", "OFFICE OF SOMETHING, UNKNOWN"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			agency := AdministeringAgency(tc.name)
+			assert.Equal(t, tc.expectedCode, agency.Code)
+			assert.Equal(t, tc.expectedName, agency.Name)
+		})
+	}
+}
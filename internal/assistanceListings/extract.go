@@ -0,0 +1,169 @@
+package assistanceListings
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Supported formats of Assistance Listings extracts
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported extract format")
+	ErrMissingColumn     = errors.New("extract is missing a required column")
+)
+
+// extractColumns maps the normalized names of extract columns (see columnKey) to the Listing
+// field that they provide. SAM.gov extracts name columns like "Program Number" and
+// "Objectives (050)", while the SAM.gov API names fields like "programNumber" and "objective".
+var extractColumns = map[string]string{
+	"programnumber":           "number",
+	"assistancelistingnumber": "number",
+	"cfdanumber":              "number",
+	"programtitle":            "title",
+	"title":                   "title",
+	"objectives":              "objectives",
+	"objective":               "objectives",
+	"federalagency":           "agency",
+	"agency":                  "agency",
+}
+
+// columnSectionSuffix matches the section numbers that SAM.gov appends to column names.
+var columnSectionSuffix = regexp.MustCompile(`\s*\(\d+\)\s*$`)
+
+// columnKey normalizes an extract column name.
+func columnKey(name string) string {
+	name = columnSectionSuffix.ReplaceAllString(strings.TrimPrefix(name, "\ufeff"), "")
+	return strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z', '0' <= r && r <= '9':
+			return r
+		case 'A' <= r && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return -1
+	}, name)
+}
+
+// FormatOf returns the format of an extract file according to its extension.
+func FormatOf(path string) (string, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		return FormatCSV, nil
+	case ".json":
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedFormat, ext)
+	}
+}
+
+// ReadExtract reads the listings of a SAM.gov Assistance Listings extract in the given format.
+// CSV extracts must have a header row. JSON extracts must be an array of objects whose keys are
+// the same as the CSV column names (or the corresponding SAM.gov API field names).
+// Columns other than the program number, title, objectives, and federal agency are ignored.
+func ReadExtract(r io.Reader, format string) ([]Listing, error) {
+	switch format {
+	case FormatCSV:
+		return readCSVExtract(r)
+	case FormatJSON:
+		return readJSONExtract(r)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
+
+func readCSVExtract(r io.Reader) ([]Listing, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV header: %w", err)
+	}
+	var listings []Listing
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("error reading CSV row: %w", err)
+		}
+		record := make(map[string]string, len(header))
+		for i, name := range header {
+			if i < len(row) {
+				record[name] = row[i]
+			}
+		}
+		listings = append(listings, listingFromRecord(record))
+	}
+	return listings, requireNumbers(listings)
+}
+
+func readJSONExtract(r io.Reader) ([]Listing, error) {
+	var records []map[string]any
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, fmt.Errorf("error reading JSON extract: %w", err)
+	}
+	listings := make([]Listing, 0, len(records))
+	for _, rec := range records {
+		record := make(map[string]string, len(rec))
+		for name, v := range rec {
+			if s, ok := v.(string); ok {
+				record[name] = s
+			}
+		}
+		listings = append(listings, listingFromRecord(record))
+	}
+	return listings, requireNumbers(listings)
+}
+
+func listingFromRecord(record map[string]string) Listing {
+	var l Listing
+	for name, value := range record {
+		value = strings.TrimSpace(value)
+		switch extractColumns[columnKey(name)] {
+		case "number":
+			l.Number = value
+		case "title":
+			l.Title = value
+		case "objectives":
+			l.Objectives = value
+		case "agency":
+			l.Agency = value
+		}
+	}
+	return l
+}
+
+// requireNumbers returns an error if the extract has listings but none of them has a program
+// number, which indicates that the extract does not have a recognized program number column.
+func requireNumbers(listings []Listing) error {
+	for _, l := range listings {
+		if l.Number != "" {
+			return nil
+		}
+	}
+	if len(listings) > 0 {
+		return fmt.Errorf("%w: Program Number", ErrMissingColumn)
+	}
+	return nil
+}
+
+// FromExtract returns a catalog of the listings in a SAM.gov Assistance Listings extract.
+func FromExtract(r io.Reader, format, source string, importedAt time.Time) (*Catalog, error) {
+	listings, err := ReadExtract(r, format)
+	if err != nil {
+		return nil, err
+	}
+	return NewCatalog(source, importedAt, listings)
+}
@@ -0,0 +1,87 @@
+package assistanceListings
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatOf(t *testing.T) {
+	for path, expected := range map[string]string{
+		"AssistanceListings_DataGov_PUBLIC_CURRENT.csv": FormatCSV,
+		"extract.JSON": FormatJSON,
+	} {
+		format, err := FormatOf(path)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, format)
+	}
+	_, err := FormatOf("extract.xlsx")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestReadExtract(t *testing.T) {
+	expected := []Listing{
+		{
+			Number:     "10.001",
+			Title:      "Agricultural Research Basic and Applied Research",
+			Objectives: "To make agricultural research discoveries.",
+			Agency:     "AGRICULTURAL RESEARCH SERVICE, AGRICULTURE, DEPARTMENT OF",
+		},
+		{Number: "10.678", Title: "Forest Stewardship"},
+	}
+
+	t.Run("CSV", func(t *testing.T) {
+		extract := "\ufeff" + `"Program Title","Program Number","Popular Name (020)","Federal Agency (030)","Objectives (050)"
+Agricultural Research Basic and Applied Research,10.001,,"AGRICULTURAL RESEARCH SERVICE, AGRICULTURE, DEPARTMENT OF",To make agricultural research discoveries.
+Forest Stewardship, 10.678 ,,
+`
+		listings, err := ReadExtract(strings.NewReader(extract), FormatCSV)
+		require.NoError(t, err)
+		assert.Equal(t, expected, listings)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		extract := `[
+			{
+				"programNumber": "10.001",
+				"title": "Agricultural Research Basic and Applied Research",
+				"objective": "To make agricultural research discoveries.",
+				"Federal Agency (030)": "AGRICULTURAL RESEARCH SERVICE, AGRICULTURE, DEPARTMENT OF",
+				"archived": false
+			},
+			{"Program Number": "10.678", "Program Title": "Forest Stewardship", "Objectives (050)": null}
+		]`
+		listings, err := ReadExtract(strings.NewReader(extract), FormatJSON)
+		require.NoError(t, err)
+		assert.Equal(t, expected, listings)
+	})
+
+	t.Run("missing program number column", func(t *testing.T) {
+		_, err := ReadExtract(strings.NewReader("Title,Agency\nSomething,Someone\n"), FormatCSV)
+		assert.ErrorIs(t, err, ErrMissingColumn)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		_, err := ReadExtract(strings.NewReader(""), "xlsx")
+		assert.ErrorIs(t, err, ErrUnsupportedFormat)
+	})
+}
+
+func TestFromExtract(t *testing.T) {
+	importedAt := time.Now()
+	c, err := FromExtract(strings.NewReader("Program Number,Program Title\n10.001,Research\n"),
+		FormatCSV, "extract.csv", importedAt)
+	require.NoError(t, err)
+	assert.Equal(t, "extract.csv", c.Source)
+	assert.Equal(t, importedAt, c.ImportedAt)
+	l, ok := c.Lookup("10.001")
+	assert.True(t, ok)
+	assert.Equal(t, "Research", l.Title)
+
+	_, err = FromExtract(strings.NewReader("Program Number,Program Title\n1.1,Research\n"),
+		FormatCSV, "extract.csv", importedAt)
+	assert.Error(t, err)
+}
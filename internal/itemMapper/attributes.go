@@ -0,0 +1,119 @@
+package itemMapper
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// FromAttributeValueMap converts a DynamoDB item, as returned by the AWS SDK (e.g. from GetItem),
+// to the representation used by DynamoDB stream events, so that it may be used with ItemMapper.
+func FromAttributeValueMap(item map[string]types.AttributeValue) (map[string]events.DynamoDBAttributeValue, error) {
+	converted := make(map[string]events.DynamoDBAttributeValue, len(item))
+	for k, av := range item {
+		v, err := FromAttributeValue(av)
+		if err != nil {
+			return nil, fmt.Errorf("error converting attribute %q: %w", k, err)
+		}
+		converted[k] = v
+	}
+	return converted, nil
+}
+
+// FromAttributeValue converts a single AWS SDK DynamoDB attribute value to the representation
+// used by DynamoDB stream events.
+func FromAttributeValue(av types.AttributeValue) (events.DynamoDBAttributeValue, error) {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberB:
+		return events.NewBinaryAttribute(v.Value), nil
+	case *types.AttributeValueMemberBOOL:
+		return events.NewBooleanAttribute(v.Value), nil
+	case *types.AttributeValueMemberBS:
+		return events.NewBinarySetAttribute(v.Value), nil
+	case *types.AttributeValueMemberL:
+		list := make([]events.DynamoDBAttributeValue, 0, len(v.Value))
+		for _, item := range v.Value {
+			converted, err := FromAttributeValue(item)
+			if err != nil {
+				return events.DynamoDBAttributeValue{}, err
+			}
+			list = append(list, converted)
+		}
+		return events.NewListAttribute(list), nil
+	case *types.AttributeValueMemberM:
+		m, err := FromAttributeValueMap(v.Value)
+		if err != nil {
+			return events.DynamoDBAttributeValue{}, err
+		}
+		return events.NewMapAttribute(m), nil
+	case *types.AttributeValueMemberN:
+		return events.NewNumberAttribute(v.Value), nil
+	case *types.AttributeValueMemberNS:
+		return events.NewNumberSetAttribute(v.Value), nil
+	case *types.AttributeValueMemberNULL:
+		return events.NewNullAttribute(), nil
+	case *types.AttributeValueMemberS:
+		return events.NewStringAttribute(v.Value), nil
+	case *types.AttributeValueMemberSS:
+		return events.NewStringSetAttribute(v.Value), nil
+	default:
+		return events.DynamoDBAttributeValue{}, fmt.Errorf("unsupported attribute value type %T", av)
+	}
+}
+
+// ToAttributeValueMap converts a DynamoDB item from the representation used by DynamoDB stream
+// events to the representation used by the AWS SDK (e.g. for PutItem).
+// It is the inverse of FromAttributeValueMap.
+func ToAttributeValueMap(item map[string]events.DynamoDBAttributeValue) (map[string]types.AttributeValue, error) {
+	converted := make(map[string]types.AttributeValue, len(item))
+	for k, v := range item {
+		av, err := ToAttributeValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("error converting attribute %q: %w", k, err)
+		}
+		converted[k] = av
+	}
+	return converted, nil
+}
+
+// ToAttributeValue converts a single DynamoDB stream event attribute value to the representation
+// used by the AWS SDK.
+func ToAttributeValue(v events.DynamoDBAttributeValue) (types.AttributeValue, error) {
+	switch v.DataType() {
+	case events.DataTypeBinary:
+		return &types.AttributeValueMemberB{Value: v.Binary()}, nil
+	case events.DataTypeBoolean:
+		return &types.AttributeValueMemberBOOL{Value: v.Boolean()}, nil
+	case events.DataTypeBinarySet:
+		return &types.AttributeValueMemberBS{Value: v.BinarySet()}, nil
+	case events.DataTypeList:
+		list := make([]types.AttributeValue, 0, len(v.List()))
+		for _, item := range v.List() {
+			converted, err := ToAttributeValue(item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, converted)
+		}
+		return &types.AttributeValueMemberL{Value: list}, nil
+	case events.DataTypeMap:
+		m, err := ToAttributeValueMap(v.Map())
+		if err != nil {
+			return nil, err
+		}
+		return &types.AttributeValueMemberM{Value: m}, nil
+	case events.DataTypeNumber:
+		return &types.AttributeValueMemberN{Value: v.Number()}, nil
+	case events.DataTypeNumberSet:
+		return &types.AttributeValueMemberNS{Value: v.NumberSet()}, nil
+	case events.DataTypeNull:
+		return &types.AttributeValueMemberNULL{Value: true}, nil
+	case events.DataTypeString:
+		return &types.AttributeValueMemberS{Value: v.String()}, nil
+	case events.DataTypeStringSet:
+		return &types.AttributeValueMemberSS{Value: v.StringSet()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported attribute data type %d", v.DataType())
+	}
+}
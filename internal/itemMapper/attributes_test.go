@@ -0,0 +1,73 @@
+package itemMapper
+
+import (
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromAttributeValueMap(t *testing.T) {
+	converted, err := FromAttributeValueMap(map[string]types.AttributeValue{
+		"b":    &types.AttributeValueMemberB{Value: []byte("bytes")},
+		"bool": &types.AttributeValueMemberBOOL{Value: true},
+		"bs":   &types.AttributeValueMemberBS{Value: [][]byte{[]byte("a")}},
+		"l": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+			&types.AttributeValueMemberS{Value: "x"},
+		}},
+		"m": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"nested": &types.AttributeValueMemberN{Value: "1"},
+		}},
+		"n":    &types.AttributeValueMemberN{Value: "12.5"},
+		"ns":   &types.AttributeValueMemberNS{Value: []string{"1", "2"}},
+		"null": &types.AttributeValueMemberNULL{Value: true},
+		"s":    &types.AttributeValueMemberS{Value: "string"},
+		"ss":   &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]events.DynamoDBAttributeValue{
+		"b":    events.NewBinaryAttribute([]byte("bytes")),
+		"bool": events.NewBooleanAttribute(true),
+		"bs":   events.NewBinarySetAttribute([][]byte{[]byte("a")}),
+		"l":    events.NewListAttribute([]events.DynamoDBAttributeValue{events.NewStringAttribute("x")}),
+		"m": events.NewMapAttribute(map[string]events.DynamoDBAttributeValue{
+			"nested": events.NewNumberAttribute("1"),
+		}),
+		"n":    events.NewNumberAttribute("12.5"),
+		"ns":   events.NewNumberSetAttribute([]string{"1", "2"}),
+		"null": events.NewNullAttribute(),
+		"s":    events.NewStringAttribute("string"),
+		"ss":   events.NewStringSetAttribute([]string{"a", "b"}),
+	}, converted)
+
+	_, err = FromAttributeValueMap(map[string]types.AttributeValue{
+		"bad": &types.UnknownUnionMember{Tag: "X"},
+	})
+	assert.ErrorContains(t, err, `"bad"`)
+}
+
+func TestToAttributeValueMap(t *testing.T) {
+	item := map[string]types.AttributeValue{
+		"b":    &types.AttributeValueMemberB{Value: []byte("bytes")},
+		"bool": &types.AttributeValueMemberBOOL{Value: true},
+		"bs":   &types.AttributeValueMemberBS{Value: [][]byte{[]byte("a")}},
+		"l": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+			&types.AttributeValueMemberS{Value: "x"},
+		}},
+		"m": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"nested": &types.AttributeValueMemberN{Value: "1"},
+		}},
+		"n":    &types.AttributeValueMemberN{Value: "12.5"},
+		"ns":   &types.AttributeValueMemberNS{Value: []string{"1", "2"}},
+		"null": &types.AttributeValueMemberNULL{Value: true},
+		"s":    &types.AttributeValueMemberS{Value: "string"},
+		"ss":   &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+	}
+	converted, err := FromAttributeValueMap(item)
+	require.NoError(t, err)
+	roundTripped, err := ToAttributeValueMap(converted)
+	require.NoError(t, err)
+	assert.Equal(t, item, roundTripped)
+}
@@ -0,0 +1,236 @@
+// Package itemMapper maps items from the grants prepared data DynamoDB table to usdr.Grant values.
+package itemMapper
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/oklog/ulid/v2"
+	"github.com/usdigitalresponse/grants-ingest/pkg/clock"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/converter"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/ffis"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/usdr"
+)
+
+// MalformedFieldFunc is called with the name of each item attribute that could not be mapped
+// as expected, along with the reason (if known).
+type MalformedFieldFunc = converter.MalformedFieldFunc
+
+const GrantsGovDateLayout = converter.DateLayout
+
+// ItemMapper maps items to usdr.Grant values by reading their attributes into a converter.Record,
+// so that grants are mapped exactly as by converter.Converter.
+type ItemMapper struct {
+	attrs            map[string]events.DynamoDBAttributeValue
+	onMalformedField MalformedFieldFunc
+	converter        *converter.Converter
+	// Read from attrs when first needed (see Record)
+	record *converter.Record
+}
+
+// NewItemMapper returns an ItemMapper for the given DynamoDB item attributes.
+// When onMalformedField is non-nil, it is called for each attribute that is malformed.
+func NewItemMapper(m map[string]events.DynamoDBAttributeValue, onMalformedField MalformedFieldFunc) *ItemMapper {
+	return &ItemMapper{m, onMalformedField, converter.New(onMalformedField), nil}
+}
+
+// WithClock sets the clock used to compute the lifecycle status of mapped opportunities,
+// which is the system clock by default.
+func (im *ItemMapper) WithClock(c clock.Clock) *ItemMapper {
+	im.converter.WithClock(c)
+	return im
+}
+
+func (im *ItemMapper) malformattedField(name string, err error) {
+	if im.onMalformedField != nil {
+		im.onMalformedField(name, err)
+	}
+}
+
+func (im *ItemMapper) stringFor(k string) (s string) {
+	if !im.attrs[k].IsNull() {
+		s = im.attrs[k].String()
+	}
+	return
+}
+
+// stringsFor returns the values of a list attribute, or nil if the attribute is null or missing.
+func (im *ItemMapper) stringsFor(k string) []string {
+	attr := im.attrs[k]
+	if attr.IsNull() {
+		return nil
+	}
+	values := make([]string, 0)
+	for _, av := range attr.List() {
+		values = append(values, av.String())
+	}
+	return values
+}
+
+// Record reads the item attributes, except for the revision (see Revision), as a converter.Record.
+// The attributes are only read by the first call, so that malformed attributes are reported once
+// no matter how many of the Grant, Award, Opportunity, etc. methods are called.
+func (im *ItemMapper) Record() converter.Record {
+	if im.record == nil {
+		im.record = im.readRecord()
+	}
+	return *im.record
+}
+
+func (im *ItemMapper) readRecord() *converter.Record {
+	return &converter.Record{
+		OpportunityID:                      im.stringFor("OpportunityID"),
+		OpportunityTitle:                   im.stringFor("OpportunityTitle"),
+		OpportunityNumber:                  im.stringFor("OpportunityNumber"),
+		OpportunityCategory:                im.stringFor("OpportunityCategory"),
+		OpportunityCategoryExplanation:     im.stringFor("OpportunityCategoryExplanation"),
+		FundingInstrumentType:              im.stringsFor("FundingInstrumentType"),
+		CategoryOfFundingActivity:          im.stringsFor("CategoryOfFundingActivity"),
+		CategoryExplanation:                im.stringFor("CategoryExplanation"),
+		CFDANumbers:                        im.stringsFor("CFDANumbers"),
+		EligibleApplicants:                 im.stringsFor("EligibleApplicants"),
+		AdditionalInformationOnEligibility: im.stringFor("AdditionalInformationOnEligibility"),
+		AgencyCode:                         im.stringFor("AgencyCode"),
+		AgencyName:                         im.stringFor("AgencyName"),
+		PostDate:                           im.stringFor("PostDate"),
+		CloseDate:                          im.stringFor("CloseDate"),
+		CloseDateExplanation:               im.stringFor("CloseDateExplanation"),
+		LastUpdatedDate:                    im.stringFor("LastUpdatedDate"),
+		AwardCeiling:                       im.stringFor("AwardCeiling"),
+		AwardFloor:                         im.stringFor("AwardFloor"),
+		EstimatedTotalProgramFunding:       im.stringFor("EstimatedTotalProgramFunding"),
+		ExpectedNumberOfAwards:             im.stringFor("ExpectedNumberOfAwards"),
+		Description:                        im.stringFor("Description"),
+		Version:                            im.stringFor("Version"),
+		CostSharingOrMatchingRequirement:   im.stringFor("CostSharingOrMatchingRequirement"),
+		ArchiveDate:                        im.stringFor("ArchiveDate"),
+		AdditionalInformationURL:           im.stringFor("AdditionalInformationURL"),
+		AdditionalInformationText:          im.stringFor("AdditionalInformationText"),
+		GrantorContactEmail:                im.stringFor("GrantorContactEmail"),
+		GrantorContactEmailDescription:     im.stringFor("GrantorContactEmailDescription"),
+		GrantorContactText:                 im.stringFor("GrantorContactText"),
+		IsForecast:                         im.IsForecast(),
+		Bill:                               im.stringFor("Bill"),
+		FFISEligibility:                    im.FFISEligibility(),
+	}
+}
+
+func (im *ItemMapper) Grant() usdr.Grant {
+	record := im.Record()
+	record.Revision = im.Revision().Id
+	return im.converter.Grant(record)
+}
+
+func (im *ItemMapper) Revision() usdr.Revision {
+	id, err := ulid.ParseStrict(im.stringFor("revision"))
+	if err != nil {
+		im.malformattedField("revision", err)
+	}
+	return usdr.Revision{Id: id}
+}
+
+// IsForecast returns whether the item was persisted from a Grants.gov opportunity forecast.
+func (im *ItemMapper) IsForecast() bool {
+	attr := im.attrs["is_forecast"]
+	if attr.IsNull() {
+		return false
+	}
+	if attr.DataType() != events.DataTypeBoolean {
+		im.malformattedField("is_forecast", fmt.Errorf("not a boolean"))
+		return false
+	}
+	return attr.Boolean()
+}
+
+// FFISEligibility returns the eligibility flags that were persisted from FFIS.org data,
+// or nil if the item has no FFIS eligibility.
+func (im *ItemMapper) FFISEligibility() *ffis.FFISFundingEligibility {
+	attr := im.attrs["FFISEligibility"]
+	if attr.IsNull() {
+		return nil
+	}
+	if attr.DataType() != events.DataTypeMap {
+		im.malformattedField("FFISEligibility", fmt.Errorf("not a map"))
+		return nil
+	}
+	eligibility := &ffis.FFISFundingEligibility{}
+	fields := map[string]*bool{
+		"higher_education": &eligibility.HigherEducation,
+		"local":            &eligibility.Local,
+		"non_profits":      &eligibility.NonProfits,
+		"other":            &eligibility.Other,
+		"state":            &eligibility.State,
+		"tribal":           &eligibility.Tribal,
+	}
+	for k, av := range attr.Map() {
+		field, ok := fields[k]
+		if !ok || av.DataType() != events.DataTypeBoolean {
+			im.malformattedField("FFISEligibility", fmt.Errorf("unexpected value for %q", k))
+			continue
+		}
+		*field = av.Boolean()
+	}
+	return eligibility
+}
+
+func (im *ItemMapper) Award() usdr.Award {
+	return im.converter.Award(im.Record())
+}
+
+func (im *ItemMapper) EligibleApplicants() []usdr.Applicant {
+	return im.converter.EligibleApplicants(im.Record())
+}
+
+func (im *ItemMapper) FundingActivity() usdr.FundingActivity {
+	return im.converter.FundingActivity(im.Record())
+}
+
+func (im *ItemMapper) AdditionalInformation() usdr.AdditionalInformation {
+	return im.converter.AdditionalInformation(im.Record())
+}
+
+func (im *ItemMapper) Opportunity() usdr.Opportunity {
+	return im.converter.Opportunity(im.Record())
+}
+
+func (im *ItemMapper) OpportunityMilestones() usdr.OpportunityMilestones {
+	return im.converter.OpportunityMilestones(im.Record())
+}
+
+func (im *ItemMapper) FundingInstruments() []usdr.FundingInstrument {
+	return im.converter.FundingInstruments(im.Record())
+}
+
+// GuardPanic wraps any zero-argument function that returns a single value,
+// which may panic when called as part of its normal behavior.
+//
+// This function is provided because the documented behavior of many accessor methods for the
+// events.DynamoDBAttributeValue type is to panic when the stored value of the attribute is
+// of a different type than what is expected by the accessor method (for example, caling .Boolean()
+// on an attribute with a stored type of StringSet ("SS")). Therefore, this function may be used
+// to wrap calls to various functions/methods which make use of DynamoDBAttribute value accessors,
+// such as those provided by ItemMapper.
+//
+// If the wrapped function panics, GuardPanic recovers and returns an error
+// representing the panic value according to the following behavior:
+//   - If the recovered panic value is an error, returns the error as is.
+//   - If the recovered panic value is a string, returns an error created from that string.
+//   - If the recovered panic value is any other type, returns an error prefixed with
+//     "unknown panic:", followed by the verbose string representation of the value and its type.
+func GuardPanic[T any](wrappedFunc func() T) (t T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch v := r.(type) {
+			case error:
+				err = v
+			case string:
+				err = errors.New(v)
+			default:
+				err = fmt.Errorf("unknown panic: %+v of type %T", r, r)
+			}
+		}
+	}()
+	res := wrappedFunc()
+	return res, err
+}
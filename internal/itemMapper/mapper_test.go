@@ -0,0 +1,387 @@
+package itemMapper
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/usdigitalresponse/grants-ingest/pkg/clock"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/converter"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/ffis"
+	grantsgov "github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/grants.gov"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/usdr"
+)
+
+func toPointer[T any](v T) *T {
+	return &v
+}
+
+func TestGuardPanic(t *testing.T) {
+	t.Run("panic with error", func(t *testing.T) {
+		err := errors.New("this is an error")
+		res, caught := GuardPanic(func() (a string) { panic(err) })
+		var zeroValue string
+		assert.Equal(t, zeroValue, res)
+		assert.ErrorIs(t, err, caught)
+	})
+	t.Run("panic with string", func(t *testing.T) {
+		errString := "this is a string"
+		res, err := GuardPanic(func() (a int) { panic(errString) })
+		var zeroValue int
+		assert.Equal(t, zeroValue, res)
+		assert.EqualError(t, err, errString)
+	})
+	t.Run("panic with int64", func(t *testing.T) {
+		res, err := GuardPanic(func() string { panic(int64(1234)) })
+		var zeroValue string
+		assert.Equal(t, zeroValue, res)
+		assert.EqualError(t, err, fmt.Errorf("unknown panic: 1234 of type int64").Error())
+	})
+	t.Run("panic with float64", func(t *testing.T) {
+		res, err := GuardPanic(func() *string { panic(12.34) })
+		assert.Nil(t, res)
+		assert.EqualError(t, err, fmt.Errorf("unknown panic: 12.34 of type float64").Error())
+	})
+	t.Run("no panic", func(t *testing.T) {
+		res, err := GuardPanic(func() string { return "do not panic" })
+		assert.NoError(t, err)
+		assert.Equal(t, "do not panic", res)
+	})
+}
+
+func TestItemMapperAward(t *testing.T) {
+	t.Run("typed amounts", func(t *testing.T) {
+		var malformed []string
+		award := NewItemMapper(map[string]events.DynamoDBAttributeValue{
+			"AwardCeiling":                 events.NewStringAttribute("none"),
+			"AwardFloor":                   events.NewStringAttribute("5000"),
+			"EstimatedTotalProgramFunding": events.NewStringAttribute("1000000"),
+			"ExpectedNumberOfAwards":       events.NewStringAttribute("3"),
+		}, func(name string, err error) { malformed = append(malformed, name) }).Award()
+
+		assert.Empty(t, malformed)
+		assert.Equal(t, usdr.Award{
+			Ceiling:                            "none",
+			Floor:                              "5000",
+			EstimatedTotalProgramFunding:       "1000000",
+			CeilingAmount:                      &usdr.Amount{Unspecified: true},
+			FloorAmount:                        &usdr.Amount{Cents: 500000},
+			EstimatedTotalProgramFundingAmount: &usdr.Amount{Cents: 100000000},
+			ExpectedNumberOfAwards:             3,
+		}, award)
+	})
+
+	t.Run("missing amounts", func(t *testing.T) {
+		var malformed []string
+		award := NewItemMapper(map[string]events.DynamoDBAttributeValue{},
+			func(name string, err error) { malformed = append(malformed, name) }).Award()
+		assert.Empty(t, malformed)
+		assert.Nil(t, award.CeilingAmount)
+		assert.Nil(t, award.FloorAmount)
+		assert.Nil(t, award.EstimatedTotalProgramFundingAmount)
+	})
+
+	t.Run("malformed amounts", func(t *testing.T) {
+		malformed := make(map[string]error)
+		award := NewItemMapper(map[string]events.DynamoDBAttributeValue{
+			"AwardCeiling": events.NewStringAttribute("$1,000"),
+			"AwardFloor":   events.NewStringAttribute("100"),
+		}, func(name string, err error) { malformed[name] = err }).Award()
+
+		assert.Equal(t, "$1,000", award.Ceiling)
+		assert.Nil(t, award.CeilingAmount)
+		assert.Equal(t, &usdr.Amount{Cents: 10000}, award.FloorAmount)
+		assert.Len(t, malformed, 1)
+		assert.ErrorIs(t, malformed["AwardCeiling"], usdr.ErrInvalidAmount)
+	})
+}
+
+func TestItemMapperOpportunityStatus(t *testing.T) {
+	// 2023-05-10 at 10:00 in America/New_York
+	now := clock.Fixed(time.Date(2023, 5, 10, 14, 0, 0, 0, time.UTC))
+	for _, tc := range []struct {
+		name           string
+		attrs          map[string]events.DynamoDBAttributeValue
+		status         usdr.OpportunityStatus
+		daysUntilClose *int
+	}{
+		{
+			"posted",
+			map[string]events.DynamoDBAttributeValue{
+				"CloseDate": events.NewStringAttribute("05122023"),
+			},
+			usdr.OpportunityStatusPosted,
+			toPointer(2),
+		},
+		{
+			"forecasted",
+			map[string]events.DynamoDBAttributeValue{
+				"is_forecast": events.NewBooleanAttribute(true),
+			},
+			usdr.OpportunityStatusForecasted,
+			nil,
+		},
+		{
+			"closed",
+			map[string]events.DynamoDBAttributeValue{
+				"CloseDate":   events.NewStringAttribute("05092023"),
+				"is_forecast": events.NewBooleanAttribute(false),
+			},
+			usdr.OpportunityStatusClosed,
+			toPointer(-1),
+		},
+		{
+			"archived",
+			map[string]events.DynamoDBAttributeValue{
+				"ArchiveDate": events.NewStringAttribute("05102023"),
+				"is_forecast": events.NewBooleanAttribute(true),
+			},
+			usdr.OpportunityStatusArchived,
+			nil,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var malformed []string
+			opportunity := NewItemMapper(tc.attrs, func(name string, err error) {
+				malformed = append(malformed, name)
+			}).WithClock(now).Opportunity()
+			assert.Empty(t, malformed)
+			assert.Equal(t, tc.status, opportunity.Status)
+			assert.Equal(t, tc.daysUntilClose, opportunity.DaysUntilClose)
+		})
+	}
+
+	t.Run("malformed forecast flag", func(t *testing.T) {
+		var malformed []string
+		opportunity := NewItemMapper(map[string]events.DynamoDBAttributeValue{
+			"is_forecast": events.NewStringAttribute("true"),
+		}, func(name string, err error) { malformed = append(malformed, name) }).WithClock(now).Opportunity()
+		assert.Equal(t, []string{"is_forecast"}, malformed)
+		assert.Equal(t, usdr.OpportunityStatusPosted, opportunity.Status)
+	})
+}
+
+func TestItemMapperReadsAttributesOnce(t *testing.T) {
+	var malformed []string
+	im := NewItemMapper(map[string]events.DynamoDBAttributeValue{
+		"revision":        events.NewStringAttribute(ulid.Make().String()),
+		"is_forecast":     events.NewStringAttribute("true"),
+		"FFISEligibility": events.NewStringAttribute("state"),
+		"AwardCeiling":    events.NewStringAttribute("1000"),
+	}, func(name string, err error) { malformed = append(malformed, name) })
+
+	im.Grant()
+	assert.ElementsMatch(t, []string{"is_forecast", "FFISEligibility", "CostSharingOrMatchingRequirement"}, malformed)
+
+	malformed = nil
+	im.Award()
+	im.EligibleApplicants()
+	im.FundingActivity()
+	im.AdditionalInformation()
+	im.Opportunity()
+	im.OpportunityMilestones()
+	im.FundingInstruments()
+	assert.Empty(t, malformed, "Attributes should not be reported again")
+}
+
+func TestItemMapperEligibility(t *testing.T) {
+	grantsGov := func(code string) usdr.EligibilitySource {
+		return usdr.EligibilitySource{Source: usdr.EligibilitySourceGrantsGov, Value: code}
+	}
+	ffis := func(field string) usdr.EligibilitySource {
+		return usdr.EligibilitySource{Source: usdr.EligibilitySourceFFIS, Value: field}
+	}
+	applicants := events.NewListAttribute([]events.DynamoDBAttributeValue{
+		events.NewStringAttribute("00"), events.NewStringAttribute("12"),
+	})
+	ffisEligibility := events.NewMapAttribute(map[string]events.DynamoDBAttributeValue{
+		"state":            events.NewBooleanAttribute(true),
+		"local":            events.NewBooleanAttribute(false),
+		"higher_education": events.NewBooleanAttribute(true),
+	})
+
+	for _, tc := range []struct {
+		name      string
+		attrs     map[string]events.DynamoDBAttributeValue
+		expected  []usdr.Eligibility
+		malformed []string
+	}{
+		{
+			"Grants.gov only",
+			map[string]events.DynamoDBAttributeValue{"EligibleApplicants": applicants},
+			[]usdr.Eligibility{
+				{Category: usdr.EligibilityStateGovernments, Sources: []usdr.EligibilitySource{grantsGov("00")}},
+				{Category: usdr.EligibilityNonprofits, Sources: []usdr.EligibilitySource{grantsGov("12")}},
+			},
+			nil,
+		},
+		{
+			"Grants.gov and FFIS",
+			map[string]events.DynamoDBAttributeValue{
+				"EligibleApplicants": applicants,
+				"FFISEligibility":    ffisEligibility,
+			},
+			[]usdr.Eligibility{
+				{Category: usdr.EligibilityStateGovernments, Sources: []usdr.EligibilitySource{ffis("state"), grantsGov("00")}},
+				{Category: usdr.EligibilityHigherEducation, Sources: []usdr.EligibilitySource{ffis("higher_education")}},
+				{Category: usdr.EligibilityNonprofits, Sources: []usdr.EligibilitySource{grantsGov("12")}},
+			},
+			nil,
+		},
+		{
+			"malformed FFIS eligibility",
+			map[string]events.DynamoDBAttributeValue{
+				"FFISEligibility": events.NewMapAttribute(map[string]events.DynamoDBAttributeValue{
+					"tribal":  events.NewBooleanAttribute(true),
+					"state":   events.NewStringAttribute("yes"),
+					"unknown": events.NewBooleanAttribute(true),
+				}),
+			},
+			[]usdr.Eligibility{
+				{Category: usdr.EligibilityTribalGovernments, Sources: []usdr.EligibilitySource{ffis("tribal")}},
+			},
+			[]string{"FFISEligibility", "FFISEligibility"},
+		},
+		{"none", map[string]events.DynamoDBAttributeValue{}, nil, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var malformed []string
+			grant := NewItemMapper(tc.attrs, func(name string, err error) {
+				if strings.HasPrefix(name, "FFIS") {
+					malformed = append(malformed, name)
+				}
+			}).Grant()
+			assert.Equal(t, tc.expected, grant.Eligibility)
+			assert.Equal(t, tc.malformed, malformed)
+		})
+	}
+}
+
+func TestItemMapperFormattedText(t *testing.T) {
+	grant := NewItemMapper(map[string]events.DynamoDBAttributeValue{
+		"Description": events.NewStringAttribute(
+			`<p>Supports <b>rural</b> housing.<script>alert(1)</script></p><p>See&nbsp;details.</p>`),
+		"AdditionalInformationText":          events.NewStringAttribute("&lt;ul&gt;&lt;li&gt;Item&lt;/ul&gt;"),
+		"AdditionalInformationOnEligibility": events.NewStringAttribute("   "),
+	}, func(string, error) {}).Grant()
+
+	assert.Equal(t, &usdr.FormattedText{
+		HTML:      "<p>Supports <b>rural</b> housing.</p><p>See\u00a0details.</p>",
+		PlainText: "Supports rural housing.\n\nSee details.",
+		Excerpt:   "Supports rural housing. See details.",
+	}, grant.Opportunity.FormattedDescription)
+	assert.Equal(t, &usdr.FormattedText{
+		HTML:      "<ul><li>Item</li></ul>",
+		PlainText: "- Item",
+		Excerpt:   "- Item",
+	}, grant.AdditionalInformation.FormattedText)
+	assert.Nil(t, grant.AdditionalInformation.FormattedEligibility)
+}
+
+func TestItemMapperMatchesConverter(t *testing.T) {
+	now := clock.Fixed(time.Date(2023, 5, 10, 14, 0, 0, 0, time.UTC))
+	revision := ulid.Make()
+	ffisOpportunity := ffis.FFISFundingOpportunity{
+		Bill:        "Inflation Reduction Act",
+		Eligibility: ffis.FFISFundingEligibility{State: true, Other: true},
+	}
+
+	// Items are persisted as in the PersistGrantsGovXMLDB and PersistFFISData Lambda functions
+	item := func(t *testing.T, source any, isForecast bool) map[string]events.DynamoDBAttributeValue {
+		m, err := attributevalue.MarshalMap(source)
+		require.NoError(t, err)
+		m["is_forecast"] = &ddbtypes.AttributeValueMemberBOOL{Value: isForecast}
+		m["revision"] = &ddbtypes.AttributeValueMemberS{Value: revision.String()}
+		m["Bill"] = &ddbtypes.AttributeValueMemberS{Value: ffisOpportunity.Bill}
+		m["FFISEligibility"], err = attributevalue.MarshalWithOptions(ffisOpportunity.Eligibility,
+			func(o *attributevalue.EncoderOptions) { o.TagKey = "json" })
+		require.NoError(t, err)
+		image, err := FromAttributeValueMap(m)
+		require.NoError(t, err)
+		return image
+	}
+
+	for _, tc := range []struct {
+		name string
+		item func(t *testing.T) map[string]events.DynamoDBAttributeValue
+	}{
+		{
+			"opportunity synopsis",
+			func(t *testing.T) map[string]events.DynamoDBAttributeValue {
+				return item(t, grantsgov.OpportunitySynopsisDetail_1_0{
+					OpportunityID:                    "123456",
+					OpportunityTitle:                 "Fun Grant",
+					OpportunityNumber:                "ABCD-1234",
+					OpportunityCategory:              "D",
+					FundingInstrumentType:            []grantsgov.FundingInstrumentTypes{"G"},
+					CFDANumbers:                      []grantsgov.CFDANumberType{"10.001"},
+					EligibleApplicants:               []grantsgov.EligibleApplicantTypes{"00", "25"},
+					AgencyCode:                       "USDA-FS",
+					PostDate:                         "09082022",
+					CloseDate:                        "05122023",
+					LastUpdatedDate:                  "09092022",
+					AwardCeiling:                     "none",
+					Description:                      "<p>Here is a description.",
+					CostSharingOrMatchingRequirement: "No",
+					GrantorContactText:               "Tester Person &lt;br/&gt;",
+				}, false)
+			},
+		},
+		{
+			"opportunity forecast",
+			func(t *testing.T) map[string]events.DynamoDBAttributeValue {
+				return item(t, grantsgov.OpportunityForecastDetail_1_0{
+					OpportunityID:              "654321",
+					OpportunityTitle:           "Future Grant",
+					CategoryOfFundingActivity:  []grantsgov.FundingActivityCategoryTypes{"ENV"},
+					EligibleApplicants:         []grantsgov.EligibleApplicantTypes{"99"},
+					LastUpdatedDate:            "09092022",
+					EstimatedSynopsisCloseDate: "09082030",
+					ArchiveDate:                "09092030",
+					GrantorContactName:         "Tester Person",
+				}, true)
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var mapperMalformed, converterMalformed []string
+			image := tc.item(t)
+			grant := NewItemMapper(image, func(name string, err error) {
+				mapperMalformed = append(mapperMalformed, name)
+			}).WithClock(now).Grant()
+
+			var record converter.Record
+			if image["is_forecast"].Boolean() {
+				var source grantsgov.OpportunityForecastDetail_1_0
+				require.NoError(t, attributevalue.UnmarshalMap(toSDKItem(t, image), &source))
+				record = converter.FromOpportunityForecast(source)
+			} else {
+				var source grantsgov.OpportunitySynopsisDetail_1_0
+				require.NoError(t, attributevalue.UnmarshalMap(toSDKItem(t, image), &source))
+				record = converter.FromOpportunitySynopsis(source)
+			}
+			record.MergeFFIS(ffisOpportunity)
+			record.Revision = revision
+			expected := converter.New(func(name string, err error) {
+				converterMalformed = append(converterMalformed, name)
+			}).WithClock(now).Grant(record)
+
+			assert.Equal(t, expected, grant)
+			assert.ElementsMatch(t, converterMalformed, mapperMalformed)
+		})
+	}
+}
+
+func toSDKItem(t *testing.T, image map[string]events.DynamoDBAttributeValue) map[string]ddbtypes.AttributeValue {
+	item, err := ToAttributeValueMap(image)
+	require.NoError(t, err)
+	return item
+}
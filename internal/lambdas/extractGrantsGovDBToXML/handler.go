@@ -1,4 +1,4 @@
-package main
+package extractGrantsGovDBToXML
 
 import (
 	"context"
@@ -146,7 +146,7 @@ func moveS3Object(ctx context.Context, svc S3MoverAPIClient, bucket, oldKey, new
 //	  --function-name grants-ingest-ExtractGrantsGovDBToXML \
 //	  --payload $(printf '{"Records":[{"s3":{"bucket":{"name":"grantsingest-tsh-grantssourcedata-456635181950-us-west-2"},"object":{"key":"archive.zip"}}}]}' | base64) \
 //	  /dev/stdout
-func handleS3Event(ctx context.Context, s3svc S3UploaderDownloaderMoverAPIClient, s3Event events.S3Event) error {
+func HandleS3Event(ctx context.Context, s3svc S3UploaderDownloaderMoverAPIClient, s3Event events.S3Event) error {
 	record := s3Event.Records[0]
 	bucket := record.S3.Bucket.Name
 	sourceKey := record.S3.Object.Key
@@ -1,4 +1,4 @@
-package main
+package extractGrantsGovDBToXML
 
 import (
 	"archive/zip"
@@ -350,7 +350,7 @@ func TestHandleS3Event(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		err = handleS3Event(context.Background(), s3svc, events.S3Event{
+		err = HandleS3Event(context.Background(), s3svc, events.S3Event{
 			Records: []events.S3EventRecord{{
 				S3: events.S3Entity{
 					Bucket: events.S3Bucket{Name: bucket},
@@ -382,7 +382,7 @@ func TestHandleS3Event(t *testing.T) {
 
 		// Ensure the downloader doesn't download everything at once (simulates a large download)
 		env.DownloadPartSize = 1
-		err = handleS3Event(context.Background(), s3svc, events.S3Event{
+		err = HandleS3Event(context.Background(), s3svc, events.S3Event{
 			Records: []events.S3EventRecord{{
 				S3: events.S3Entity{
 					Bucket: events.S3Bucket{Name: bucket},
@@ -0,0 +1,31 @@
+// Package extractGrantsGovDBToXML provides the handler of the ExtractGrantsGovDBToXML Lambda
+// function, which extracts Grants.gov database XML from zip archives in S3. The Lambda binary is
+// built from cmd/ExtractGrantsGovDBToXML.
+package extractGrantsGovDBToXML
+
+import (
+	goenv "github.com/Netflix/go-env"
+	"github.com/usdigitalresponse/grants-ingest/internal/ddHelpers"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+)
+
+type Environment struct {
+	LogLevel          string `env:"LOG_LEVEL,default=INFO"`
+	UsePathStyleS3Opt bool   `env:"S3_USE_PATH_STYLE,default=false"`
+	TmpKeyPrefix      string `env:"TMP_KEY_PATH_PREFIX,default=tmp"`
+	Extras            goenv.EnvSet
+	// Should use zero (default) except during testing or performance tuning
+	DownloadPartSize int64 `env:"DOWNLOAD_PART_SIZE,default=0"`
+}
+
+var (
+	env        Environment
+	logger     log.Logger
+	sendMetric = ddHelpers.NewMetricSender("ExtractGrantsGovDBToXML", "source:grants.gov")
+)
+
+// Configure sets the environment and logger used when handling invocations.
+func Configure(e Environment, l log.Logger) {
+	env = e
+	logger = l
+}
@@ -1,4 +1,4 @@
-package main
+package persistFFISData
 
 import (
 	"context"
@@ -25,13 +25,18 @@ func UpdateOpportunity(ctx context.Context, c DynamoDBUpdateItemAPI, table strin
 	if err != nil {
 		return err
 	}
-	oppAttr, err := attributevalue.MarshalMap(map[string]interface{}{"Bill": opp.Bill})
+	// FFIS eligibility is stored with the keys of its JSON encoding (e.g. "higher_education")
+	oppAttr, err := attributevalue.MarshalMapWithOptions(map[string]interface{}{
+		"Bill":            opp.Bill,
+		"FFISEligibility": opp.Eligibility,
+	}, func(o *attributevalue.EncoderOptions) { o.TagKey = "json" })
 	if err != nil {
 		return err
 	}
 	condition, _ := awsHelpers.DDBIfAnyValueChangedCondition(oppAttr)
 
-	update := expression.Set(expression.Name("Bill"), expression.Value(oppAttr["Bill"]))
+	update := expression.Set(expression.Name("Bill"), expression.Value(oppAttr["Bill"])).
+		Set(expression.Name("FFISEligibility"), expression.Value(oppAttr["FFISEligibility"]))
 	update = awsHelpers.DDBSetRevisionForUpdate(update)
 
 	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
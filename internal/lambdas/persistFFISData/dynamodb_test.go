@@ -1,13 +1,15 @@
-package main
+package persistFFISData
 
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strconv"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/ffis"
 )
 
 type mockDynamoDBUpdateItemAPI struct {
@@ -34,8 +36,9 @@ func TestUpsertDynamoDB(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			tableName := "test-table"
 			opp := opportunity{
-				GrantID: test.grantId,
-				Bill:    test.bill,
+				GrantID:     test.grantId,
+				Bill:        test.bill,
+				Eligibility: ffis.FFISFundingEligibility{State: true, HigherEducation: true},
 			}
 			mock := mockDynamoDBUpdateItemAPI{expectedError: test.expectedError}
 			result := UpdateOpportunity(context.TODO(), &mock, tableName, opp)
@@ -53,11 +56,23 @@ func TestUpsertDynamoDB(t *testing.T) {
 			if key["grant_id"] != strconv.FormatInt(test.grantId, 10) {
 				t.Errorf("Expected grant_id %v, got %v", test.grantId, key["grant_id"])
 			}
-			values := make(map[string]string)
-			attributevalue.UnmarshalMap(passedParams.ExpressionAttributeValues, &values)
-			if !checkMapContainsValue(t, values, test.bill) {
+			values := make(map[string]any)
+			if err := attributevalue.UnmarshalMap(passedParams.ExpressionAttributeValues, &values); err != nil {
+				t.Fatalf("Error unmarshaling update attribute values: %v", err)
+			}
+			if !checkMapContainsValue[string, any](t, values, test.bill) {
 				t.Error("Missing bill value in update attribute values")
 			}
+			if !checkMapContainsValue(t, passedParams.ExpressionAttributeNames, "FFISEligibility") {
+				t.Errorf("Missing attribute %q in update attribute names", "FFISEligibility")
+			}
+			expectedEligibility := map[string]any{
+				"higher_education": true, "local": false, "non_profits": false,
+				"other": false, "state": true, "tribal": false,
+			}
+			if !containsEqualValue(values, expectedEligibility) {
+				t.Errorf("Missing FFIS eligibility %v in update attribute values", expectedEligibility)
+			}
 			if !checkMapContainsValue(t, passedParams.ExpressionAttributeNames, "revision") {
 				t.Errorf("Missing attribute %q in update attribute names", "revision")
 			}
@@ -65,6 +80,16 @@ func TestUpsertDynamoDB(t *testing.T) {
 	}
 }
 
+// containsEqualValue returns true if a value of m is deeply equal to target
+func containsEqualValue(m map[string]any, target any) bool {
+	for _, v := range m {
+		if reflect.DeepEqual(v, target) {
+			return true
+		}
+	}
+	return false
+}
+
 // checkMapContainsValue is a testing helper function that returns true if target is a value of m
 func checkMapContainsValue[K comparable, V comparable](t *testing.T, m map[K]V, target V) bool {
 	t.Helper()
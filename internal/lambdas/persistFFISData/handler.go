@@ -1,4 +1,4 @@
-package main
+package persistFFISData
 
 import (
 	"context"
@@ -26,7 +26,7 @@ var (
 	ErrMissingGrantID = fmt.Errorf("grant id missing from FFIS data")
 )
 
-func handleS3Event(ctx context.Context, s3Event events.S3Event, s3client S3API, dbapi DynamoDBUpdateItemAPI) error {
+func HandleS3Event(ctx context.Context, s3Event events.S3Event, s3client S3API, dbapi DynamoDBUpdateItemAPI) error {
 	uploadedFile := s3Event.Records[0].S3.Object.Key
 	bucket := s3Event.Records[0].S3.Bucket.Name
 	logger := log.With(logger, "source_key", uploadedFile, "source_bucket", bucket)
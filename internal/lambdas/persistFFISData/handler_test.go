@@ -1,4 +1,4 @@
-package main
+package persistFFISData
 
 import (
 	"bytes"
@@ -59,7 +59,7 @@ func TestInvocationErrorsUpdatingOpportunity(t *testing.T) {
 		{"ignores conditional check error", conditionalCheckErr, nil},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
-			err = handleS3Event(context.Background(), s3Event, mockS3, &mockDynamoDBUpdateItemAPI{
+			err = HandleS3Event(context.Background(), s3Event, mockS3, &mockDynamoDBUpdateItemAPI{
 				expectedError: tt.ddbErr,
 			})
 			if tt.invocationErr == nil {
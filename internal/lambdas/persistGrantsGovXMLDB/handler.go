@@ -1,4 +1,4 @@
-package main
+package persistGrantsGovXMLDB
 
 import (
 	"context"
@@ -15,7 +15,7 @@ import (
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
 
-// handleS3Event handles events representing S3 bucket notifications of type "ObjectCreated:*"
+// HandleS3EventWithConfig handles events representing S3 bucket notifications of type "ObjectCreated:*"
 // for XML DB extracts saved from Grants.gov and split into separate files via the SplitGrantsGovXMLDB Lambda.
 // The XML data from the source S3 object provided represents an individual grant opportunity.
 // Returns an error that represents any and all errors accumulated during the invocation,
@@ -23,7 +23,7 @@ import (
 // a partial or complete invocation failure.
 // Returns nil when all grant opportunities are successfully processed from all source records,
 // indicating complete success.
-func handleS3EventWithConfig(s3svc *s3.Client, dynamodbsvc DynamoDBUpdateItemAPI, ctx context.Context, s3Event events.S3Event) error {
+func HandleS3EventWithConfig(s3svc *s3.Client, dynamodbsvc DynamoDBUpdateItemAPI, ctx context.Context, s3Event events.S3Event) error {
 	wg := multierror.Group{}
 	for _, record := range s3Event.Records {
 		func(record events.S3EventRecord) {
@@ -1,4 +1,4 @@
-package main
+package persistGrantsGovXMLDB
 
 import (
 	"bytes"
@@ -187,7 +187,7 @@ func TestLambdaInvocationScenarios(t *testing.T) {
 				return nil, nil
 			}),
 		}
-		err = handleS3EventWithConfig(s3Client, dynamodbClient, context.TODO(), events.S3Event{
+		err = HandleS3EventWithConfig(s3Client, dynamodbClient, context.TODO(), events.S3Event{
 			Records: []events.S3EventRecord{
 				{S3: events.S3Entity{
 					Bucket: events.S3Bucket{Name: sourceBucketName},
@@ -228,7 +228,7 @@ func TestLambdaInvocationScenarios(t *testing.T) {
 				return nil, nil
 			}),
 		}
-		err = handleS3EventWithConfig(s3Client, dynamodbClient, context.TODO(), events.S3Event{
+		err = HandleS3EventWithConfig(s3Client, dynamodbClient, context.TODO(), events.S3Event{
 			Records: []events.S3EventRecord{
 				{S3: events.S3Entity{
 					Bucket: events.S3Bucket{Name: sourceBucketName},
@@ -254,7 +254,7 @@ func TestLambdaInvocationScenarios(t *testing.T) {
 			}),
 		}
 
-		err = handleS3EventWithConfig(s3Client, dynamodbClient, ctx, events.S3Event{
+		err = HandleS3EventWithConfig(s3Client, dynamodbClient, ctx, events.S3Event{
 			Records: []events.S3EventRecord{
 				{S3: events.S3Entity{
 					Bucket: events.S3Bucket{Name: "source-bucket"},
@@ -328,7 +328,7 @@ func TestLambdaInvocationScenarios(t *testing.T) {
 						return nil, nil
 					}),
 				}
-				err = handleS3EventWithConfig(s3Client, dynamodbClient, context.TODO(), events.S3Event{
+				err = HandleS3EventWithConfig(s3Client, dynamodbClient, context.TODO(), events.S3Event{
 					Records: []events.S3EventRecord{{S3: events.S3Entity{
 						Bucket: events.S3Bucket{Name: sourceBucketName},
 						Object: events.S3Object{Key: "123/123456/grants.gov/v2.xml"},
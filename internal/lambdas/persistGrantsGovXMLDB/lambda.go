@@ -0,0 +1,29 @@
+// Package persistGrantsGovXMLDB provides the handler of the PersistGrantsGovXMLDB Lambda function,
+// which persists prepared Grants.gov data to DynamoDB. The Lambda binary is built from
+// cmd/PersistGrantsGovXMLDB.
+package persistGrantsGovXMLDB
+
+import (
+	goenv "github.com/Netflix/go-env"
+	"github.com/usdigitalresponse/grants-ingest/internal/ddHelpers"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+)
+
+type Environment struct {
+	LogLevel          string `env:"LOG_LEVEL,default=INFO"`
+	DestinationTable  string `env:"GRANTS_PREPARED_DYNAMODB_NAME,required=true"`
+	UsePathStyleS3Opt bool   `env:"S3_USE_PATH_STYLE,default=false"`
+	Extras            goenv.EnvSet
+}
+
+var (
+	env        Environment
+	logger     log.Logger
+	sendMetric = ddHelpers.NewMetricSender("PersistGrantsGovXMLDB", "source:grants.gov")
+)
+
+// Configure sets the environment and logger used when handling invocations.
+func Configure(e Environment, l log.Logger) {
+	env = e
+	logger = l
+}
@@ -1,4 +1,4 @@
-package main
+package persistGrantsGovXMLDB
 
 import (
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
@@ -1,4 +1,4 @@
-package main
+package publishGrantEvents
 
 import (
 	"context"
@@ -9,6 +9,8 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
 	"github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/usdigitalresponse/grants-ingest/internal/assistanceListings"
+	"github.com/usdigitalresponse/grants-ingest/internal/itemMapper"
 	"github.com/usdigitalresponse/grants-ingest/internal/log"
 	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/usdr"
 )
@@ -24,12 +26,13 @@ type EventBridgePutEventsAPI interface {
 		*eventbridge.PutEventsOutput, error)
 }
 
-func handleEvent(ctx context.Context, pub EventBridgePutEventsAPI, event events.DynamoDBEvent) (events.DynamoDBEventResponse, error) {
+func HandleEvent(ctx context.Context, pub EventBridgePutEventsAPI, s3svc assistanceListings.S3GetObjectAPI, event events.DynamoDBEvent) (events.DynamoDBEventResponse, error) {
 	sendMetric("invocation_batch_size", float64(len(event.Records)))
 	failures := make([]events.DynamoDBBatchItemFailure, 0)
+	catalog := loadAssistanceListings(ctx, s3svc)
 
 	for _, record := range event.Records {
-		if err := handleRecord(ctx, pub, record); err != nil {
+		if err := handleRecord(ctx, pub, catalog, record); err != nil {
 			seq := record.Change.SequenceNumber
 			log.Error(logger, "Failed to handle record in batch", err, "sequence_number", seq)
 			sendMetric("record.failed", 1, fmt.Sprintf("event_name:%s", record.EventName))
@@ -41,15 +44,38 @@ func handleEvent(ctx context.Context, pub EventBridgePutEventsAPI, event events.
 	return events.DynamoDBEventResponse{BatchItemFailures: failures}, nil
 }
 
-func handleRecord(ctx context.Context, pub EventBridgePutEventsAPI, rec events.DynamoDBEventRecord) error {
+// loadAssistanceListings returns the catalog used to enrich grants with Assistance Listings,
+// or nil if enrichment is not configured or the catalog cannot be loaded.
+// Grants are published without enrichment rather than failing when the catalog is unavailable.
+func loadAssistanceListings(ctx context.Context, s3svc assistanceListings.S3GetObjectAPI) *assistanceListings.Catalog {
+	if assistanceListingsCache == nil {
+		return nil
+	}
+	catalog, err := assistanceListingsCache.Get(ctx, s3svc)
+	if err != nil {
+		log.Warn(logger, "Error loading Assistance Listings catalog", "error", err,
+			"using_stale_catalog", catalog != nil)
+		sendMetric("assistance_listings.load_failed", 1)
+	}
+	return catalog
+}
+
+func handleRecord(ctx context.Context, pub EventBridgePutEventsAPI, catalog *assistanceListings.Catalog, rec events.DynamoDBEventRecord) error {
 	logger := log.With(logger, "ddb_event_name", rec.EventName,
 		"ddb_keys", rec.Change.Keys, "ddb_sequence_number", rec.Change.SequenceNumber)
 
-	eventJSON, eventType, err := buildGrantModificationEventJSON(rec)
+	eventJSON, eventType, err := buildGrantModificationEventJSON(rec, catalog)
 	if err != nil {
 		return err
 	}
 	logger = log.With(logger, "event_type", eventType)
+	if err := usdr.ValidateGrantModificationEventJSON(eventJSON); err != nil {
+		sendMetric("event.schema_invalid", 1, fmt.Sprintf("type:%s", eventType))
+		if env.EnforceEventSchema {
+			return log.Errorf(logger, "event does not conform to the GrantModificationEvent schema", err)
+		}
+		log.Warn(logger, "event does not conform to the GrantModificationEvent schema", "error", err)
+	}
 
 	eventInput := types.PutEventsRequestEntry{
 		Source:       aws.String("org.usdigitalresponse.grants-ingest"),
@@ -73,7 +99,29 @@ func handleRecord(ctx context.Context, pub EventBridgePutEventsAPI, rec events.D
 	return nil
 }
 
-func buildGrantModificationEventJSON(record events.DynamoDBEventRecord) ([]byte, string, error) {
+// malformattedField logs and reports a metric for an item attribute that could not be mapped.
+func malformattedField(name string, err error) {
+	logger := log.With(logger, "field", name)
+	if err != nil {
+		logger = log.With(logger, "error", err)
+	}
+	log.Warn(logger, "Could not parse field")
+	sendMetric("item_image.malformatted_field", 1, fmt.Sprintf("field:%s", name))
+}
+
+// enrichGrant attaches the Assistance Listing of each of the grant's CFDA numbers, and reports
+// CFDA numbers that are not in the catalog. Grants are not modified when catalog is nil.
+func enrichGrant(logger log.Logger, catalog *assistanceListings.Catalog, grant *usdr.Grant, metricTag string) {
+	if catalog == nil {
+		return
+	}
+	for _, number := range catalog.Enrich(grant) {
+		log.Warn(logger, "CFDA number is not in the Assistance Listings catalog", "cfda_number", number)
+		sendMetric("grant_data.unknown_cfda_number", 1, metricTag)
+	}
+}
+
+func buildGrantModificationEventJSON(record events.DynamoDBEventRecord, catalog *assistanceListings.Catalog) ([]byte, string, error) {
 	logger := log.With(logger, "ddb_change_size_bytes", record.Change.SizeBytes,
 		"ddb_change_approximate_creation_time", record.Change.ApproximateCreationDateTime,
 		"ddb_keys", record.Change.Keys, "ddb_sequence_number", record.Change.SequenceNumber,
@@ -88,13 +136,14 @@ func buildGrantModificationEventJSON(record events.DynamoDBEventRecord) ([]byte,
 		image := record.Change.NewImage
 
 		sendMetric("item_image.build", 1, metricTag)
-		if grant, err := GuardPanic(NewItemMapper(image).Grant); err != nil {
+		if grant, err := itemMapper.GuardPanic(itemMapper.NewItemMapper(image, malformattedField).Grant); err != nil {
 			sendMetric("item_image.unbuildable", 1, metricTag)
 			return nil, "", log.Errorf(logger, "error building grant from change image", err)
 		} else if err := grant.Validate(); err != nil {
 			sendMetric("grant_data.invalid", 1, metricTag)
 			return nil, "", log.Errorf(logger, "grant data from ItemMapper is invalid", err)
 		} else {
+			enrichGrant(logger, catalog, &grant, metricTag)
 			newVersion = &grant
 		}
 	}
@@ -104,10 +153,11 @@ func buildGrantModificationEventJSON(record events.DynamoDBEventRecord) ([]byte,
 		image := record.Change.OldImage
 
 		sendMetric("item_image.build", 1, metricTag)
-		if grant, err := GuardPanic(NewItemMapper(image).Grant); err != nil {
+		if grant, err := itemMapper.GuardPanic(itemMapper.NewItemMapper(image, malformattedField).Grant); err != nil {
 			sendMetric("item_image.unbuildable", 1, metricTag)
 			return nil, "", log.Errorf(logger, "error building grant from change image", err)
 		} else {
+			enrichGrant(logger, catalog, &grant, metricTag)
 			prevVersion = &grant
 		}
 		if err := prevVersion.Validate(); err != nil {
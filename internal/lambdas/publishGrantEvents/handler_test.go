@@ -1,8 +1,10 @@
-package main
+package publishGrantEvents
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"os"
 	"sync"
@@ -12,10 +14,12 @@ import (
 	goenv "github.com/Netflix/go-env"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/go-kit/log"
 	"github.com/oklog/ulid/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/usdigitalresponse/grants-ingest/internal/assistanceListings"
 	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/usdr"
 )
 
@@ -113,7 +117,7 @@ func TestHandleEvent(t *testing.T) {
 	}}
 
 	mockEB := &mockEventBridgePutEventsAPI{}
-	resp, err := handleEvent(context.Background(), mockEB, event)
+	resp, err := HandleEvent(context.Background(), mockEB, nil, event)
 	assert.NoError(t, err)
 	assert.Len(t, resp.BatchItemFailures, 1)
 	assert.Equal(t, "FailAfterInsert", resp.BatchItemFailures[0].ItemIdentifier)
@@ -140,7 +144,7 @@ func TestHandleRecord(t *testing.T) {
 			},
 		}
 		mockEB := &mockEventBridgePutEventsAPI{}
-		err := handleRecord(context.Background(), mockEB, record)
+		err := handleRecord(context.Background(), mockEB, nil, record)
 		assert.NoError(t, err)
 		assert.Equal(t, mockEB.callCount, 1)
 		var modEvent usdr.GrantModificationEvent
@@ -169,7 +173,7 @@ func TestHandleRecord(t *testing.T) {
 					},
 				}
 				mockEB := &mockEventBridgePutEventsAPI{expectedError: tt.ebErr}
-				err := handleRecord(context.Background(), mockEB, record)
+				err := handleRecord(context.Background(), mockEB, nil, record)
 				if tt.ebErr != nil {
 					assert.EqualError(t, err, "error publishing to EventBridge: could not publish")
 				} else {
@@ -192,9 +196,134 @@ func TestHandleRecord(t *testing.T) {
 			},
 		}
 		mockEB := &mockEventBridgePutEventsAPI{}
-		err := handleRecord(context.Background(), mockEB, record)
+		err := handleRecord(context.Background(), mockEB, nil, record)
 		assert.Error(t, err)
 		assert.ErrorContains(t, err, "grant data from ItemMapper is invalid")
 		assert.Equal(t, mockEB.callCount, 0)
 	})
 }
+
+func TestHandleRecordEventSchema(t *testing.T) {
+	setupLambdaEnvForTesting(t)
+
+	// The previous version of a deleted item is published even when it fails validation,
+	// which produces an event that does not conform to the schema.
+	deleteRecord := func(t *testing.T) events.DynamoDBEventRecord {
+		t.Helper()
+		oldImage := getFixtureItem(t, "fixtures/goodItem.json")
+		delete(oldImage, "OpportunityTitle")
+		return events.DynamoDBEventRecord{
+			EventName: DDBStreamEventDelete,
+			Change:    events.DynamoDBStreamRecord{OldImage: oldImage},
+		}
+	}
+
+	t.Run("published events conform to the schema", func(t *testing.T) {
+		record := events.DynamoDBEventRecord{
+			EventName: DDBStreamEventInsert,
+			Change: events.DynamoDBStreamRecord{
+				NewImage: getFixtureItem(t, "fixtures/goodItem.json"),
+			},
+		}
+		mockEB := &mockEventBridgePutEventsAPI{}
+		require.NoError(t, handleRecord(context.Background(), mockEB, nil, record))
+		require.Equal(t, 1, mockEB.callCount)
+		assert.NoError(t, usdr.ValidateGrantModificationEventJSON(
+			[]byte(*mockEB.params.Entries[0].Detail)))
+	})
+
+	t.Run("schema violation is published when not enforced", func(t *testing.T) {
+		mockEB := &mockEventBridgePutEventsAPI{}
+		assert.NoError(t, handleRecord(context.Background(), mockEB, nil, deleteRecord(t)))
+		assert.Equal(t, 1, mockEB.callCount)
+	})
+
+	t.Run("schema violation is not published when enforced", func(t *testing.T) {
+		env.EnforceEventSchema = true
+		defer func() { env.EnforceEventSchema = false }()
+		mockEB := &mockEventBridgePutEventsAPI{}
+		err := handleRecord(context.Background(), mockEB, nil, deleteRecord(t))
+		assert.ErrorIs(t, err, usdr.ErrSchemaViolation)
+		assert.Equal(t, 0, mockEB.callCount)
+	})
+}
+
+type mockS3GetObjectAPI struct {
+	body      []byte
+	callCount int
+}
+
+func (m *mockS3GetObjectAPI) GetObject(ctx context.Context, p *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	m.callCount++
+	if m.body == nil {
+		return nil, errors.New("NoSuchKey")
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(m.body))}, nil
+}
+
+func TestHandleEventAssistanceListings(t *testing.T) {
+	setupLambdaEnvForTesting(t)
+	Configure(Environment{EventBusName: "TestBus", PreparedDataBucket: "TestBucket",
+		AssistanceListingsRefreshInterval: time.Hour}, logger)
+	defer func() { assistanceListingsCache = nil }()
+
+	catalog, err := assistanceListings.NewCatalog("extract.csv", time.Now(), []assistanceListings.Listing{{
+		Number:     "97.062",
+		Title:      "Earthquake Consortium",
+		Objectives: "To reduce earthquake risks.",
+		Agency:     "FEDERAL EMERGENCY MANAGEMENT AGENCY, HOMELAND SECURITY, DEPARTMENT OF",
+	}})
+	require.NoError(t, err)
+	var b bytes.Buffer
+	require.NoError(t, catalog.WriteJSON(&b))
+
+	record := func() events.DynamoDBEventRecord {
+		newImage := getFixtureItem(t, "fixtures/goodItem.json")
+		newImage["CFDANumbers"] = events.NewListAttribute([]events.DynamoDBAttributeValue{
+			events.NewStringAttribute("97.062"), events.NewStringAttribute("10.999"),
+		})
+		return events.DynamoDBEventRecord{
+			EventName: DDBStreamEventInsert,
+			Change:    events.DynamoDBStreamRecord{NewImage: newImage, SequenceNumber: "1"},
+		}
+	}
+
+	t.Run("grants are enriched from the catalog", func(t *testing.T) {
+		svc := &mockS3GetObjectAPI{body: b.Bytes()}
+		mockEB := &mockEventBridgePutEventsAPI{}
+		resp, err := HandleEvent(context.Background(), mockEB, svc,
+			events.DynamoDBEvent{Records: []events.DynamoDBEventRecord{record(), record()}})
+		require.NoError(t, err)
+		assert.Empty(t, resp.BatchItemFailures)
+		assert.Equal(t, 1, svc.callCount, "catalog should be cached")
+		require.Equal(t, 2, mockEB.callCount)
+
+		detail := []byte(*mockEB.params.Entries[0].Detail)
+		assert.NoError(t, usdr.ValidateGrantModificationEventJSON(detail))
+		var modEvent usdr.GrantModificationEvent
+		require.NoError(t, json.Unmarshal(detail, &modEvent))
+		listings := modEvent.Versions.New.AssistanceListings
+		require.Len(t, listings, 2)
+		assert.True(t, listings[0].Found)
+		assert.Equal(t, "Earthquake Consortium", listings[0].ProgramTitle)
+		assert.Equal(t, "To reduce earthquake risks.", listings[0].Objectives)
+		require.NotNil(t, listings[0].AdministeringAgency)
+		assert.Equal(t, "DHS", listings[0].AdministeringAgency.DepartmentCode)
+		assert.Equal(t, "10.999", string(listings[1].Number))
+		assert.False(t, listings[1].Found)
+		assert.Nil(t, listings[1].AdministeringAgency)
+	})
+
+	t.Run("grants are published without enrichment when the catalog is unavailable", func(t *testing.T) {
+		assistanceListingsCache = &assistanceListings.Cache{Bucket: "TestBucket", Key: "missing"}
+		mockEB := &mockEventBridgePutEventsAPI{}
+		resp, err := HandleEvent(context.Background(), mockEB, &mockS3GetObjectAPI{},
+			events.DynamoDBEvent{Records: []events.DynamoDBEventRecord{record()}})
+		require.NoError(t, err)
+		assert.Empty(t, resp.BatchItemFailures)
+		require.Equal(t, 1, mockEB.callCount)
+		var modEvent usdr.GrantModificationEvent
+		require.NoError(t, json.Unmarshal([]byte(*mockEB.params.Entries[0].Detail), &modEvent))
+		assert.Nil(t, modEvent.Versions.New.AssistanceListings)
+	})
+}
@@ -0,0 +1,46 @@
+// Package publishGrantEvents provides the handler of the PublishGrantEvents Lambda function, which
+// publishes grant modification events from the DynamoDB stream. The Lambda binary is built from
+// cmd/PublishGrantEvents.
+package publishGrantEvents
+
+import (
+	"time"
+
+	goenv "github.com/Netflix/go-env"
+	"github.com/usdigitalresponse/grants-ingest/internal/assistanceListings"
+	"github.com/usdigitalresponse/grants-ingest/internal/ddHelpers"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+	"github.com/usdigitalresponse/grants-ingest/internal/preparedData"
+)
+
+type Environment struct {
+	LogLevel           string `env:"LOG_LEVEL,default=INFO"`
+	EventBusName       string `env:"EVENT_BUS_NAME,required=true"`
+	EnforceEventSchema bool   `env:"ENFORCE_EVENT_SCHEMA,default=false"`
+	// Bucket containing the Assistance Listings catalog; grants are not enriched when empty
+	PreparedDataBucket                string        `env:"GRANTS_PREPARED_DATA_BUCKET_NAME"`
+	AssistanceListingsRefreshInterval time.Duration `env:"ASSISTANCE_LISTINGS_REFRESH_INTERVAL,default=1h"`
+	UsePathStyleS3Opt                 bool          `env:"S3_USE_PATH_STYLE,default=false"`
+	Extras                            goenv.EnvSet
+}
+
+var (
+	env                     Environment
+	logger                  log.Logger
+	sendMetric              = ddHelpers.NewMetricSender("PublishGrantEvents")
+	assistanceListingsCache *assistanceListings.Cache
+)
+
+// Configure sets the environment and logger used when handling invocations.
+func Configure(e Environment, l log.Logger) {
+	env = e
+	logger = l
+	assistanceListingsCache = nil
+	if env.PreparedDataBucket != "" {
+		assistanceListingsCache = &assistanceListings.Cache{
+			Bucket:          env.PreparedDataBucket,
+			Key:             preparedData.AssistanceListingsKey,
+			RefreshInterval: env.AssistanceListingsRefreshInterval,
+		}
+	}
+}
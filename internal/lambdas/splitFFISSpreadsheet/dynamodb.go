@@ -0,0 +1,85 @@
+package splitFFISSpreadsheet
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/usdigitalresponse/grants-ingest/internal/awsHelpers"
+)
+
+// StaleBillAttribute is the DynamoDB attribute that records the date of the first FFIS
+// spreadsheet from which a grant was absent, indicating that its Bill attribute may be stale.
+const StaleBillAttribute = "BillStaleSince"
+
+type DynamoDBUpdateItemAPI interface {
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+}
+
+// FlagStaleBill records the date since which the Bill attribute of the identified grant is stale.
+// The update condition fails if the item has no Bill attribute.
+func FlagStaleBill(ctx context.Context, c DynamoDBUpdateItemAPI, table string, grantID int64, since string) error {
+	update := expression.Set(expression.Name(StaleBillAttribute), expression.Value(since))
+	condition := expression.AttributeExists(expression.Name("Bill"))
+	return updateGrant(ctx, c, table, grantID, update, condition)
+}
+
+// UnflagStaleBill removes the stale flag from the identified grant.
+// The update condition fails if the item is not flagged.
+func UnflagStaleBill(ctx context.Context, c DynamoDBUpdateItemAPI, table string, grantID int64) error {
+	update := expression.Remove(expression.Name(StaleBillAttribute))
+	condition := expression.AttributeExists(expression.Name(StaleBillAttribute))
+	return updateGrant(ctx, c, table, grantID, update, condition)
+}
+
+// ClearStaleBill removes the Bill attribute (and any stale flag) from the identified grant
+// and sets a new revision. The update condition fails unless the item's Bill attribute
+// still matches the stale bill, so that newer FFIS data is never cleared.
+func ClearStaleBill(ctx context.Context, c DynamoDBUpdateItemAPI, table string, grantID int64, staleBill string) error {
+	update := expression.Remove(expression.Name("Bill")).Remove(expression.Name(StaleBillAttribute))
+	update = awsHelpers.DDBSetRevisionForUpdate(update)
+	condition := expression.Name("Bill").Equal(expression.Value(staleBill))
+	return updateGrant(ctx, c, table, grantID, update, condition)
+}
+
+// RestoreBill sets the Bill attribute of the identified grant after it was cleared by
+// ClearStaleBill, and sets a new revision. This is necessary because an FFIS opportunity that
+// reappears unchanged is not uploaded again, so PersistFFISData never restores its bill.
+// The update condition fails if the item does not exist or already has a Bill attribute.
+func RestoreBill(ctx context.Context, c DynamoDBUpdateItemAPI, table string, grantID int64, bill string) error {
+	update := awsHelpers.DDBSetRevisionForUpdate(expression.Set(expression.Name("Bill"), expression.Value(bill)))
+	condition := expression.AttributeExists(expression.Name("grant_id")).
+		And(expression.AttributeNotExists(expression.Name("Bill")))
+	return updateGrant(ctx, c, table, grantID, update, condition)
+}
+
+func updateGrant(ctx context.Context, c DynamoDBUpdateItemAPI, table string, grantID int64, update expression.UpdateBuilder, condition expression.ConditionBuilder) error {
+	key, err := buildKey(grantID)
+	if err != nil {
+		return err
+	}
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		return err
+	}
+
+	_, err = c.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(table),
+		Key:                       key,
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ReturnValues:              types.ReturnValueNone,
+	})
+	return err
+}
+
+func buildKey(grantID int64) (map[string]types.AttributeValue, error) {
+	grantIDKey, err := attributevalue.Marshal(strconv.FormatInt(grantID, 10))
+	return map[string]types.AttributeValue{"grant_id": grantIDKey}, err
+}
@@ -1,10 +1,9 @@
-package main
+package splitFFISSpreadsheet
 
 import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
 	"strconv"
 
 	"github.com/aws/aws-lambda-go/events"
@@ -12,6 +11,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/hashicorp/go-multierror"
 	"github.com/usdigitalresponse/grants-ingest/internal/log"
+	"github.com/usdigitalresponse/grants-ingest/internal/preparedData"
 	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/ffis"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
@@ -24,17 +24,11 @@ type opportunity ffis.FFISFundingOpportunity
 
 // S3ObjectKey returns a string to use as the object key when saving the opportunity to an S3 bucket.
 func (o *opportunity) S3ObjectKey() string {
-	firstThree := strconv.FormatInt(o.GrantID, 10)[:3]
-	return fmt.Sprintf("%s/%d/ffis.org/v1.json", firstThree, o.GrantID)
+	return preparedData.FFIS.Key(strconv.FormatInt(o.GrantID, 10))
 }
 
-// handleS3Event handles events representing S3 bucket notifications of type "ObjectCreated:*"
-func handleS3EventWithConfig(cfg aws.Config, ctx context.Context, s3Event events.S3Event) error {
-	// Configure service clients
-	s3svc := s3.NewFromConfig(cfg, func(o *s3.Options) {
-		o.UsePathStyle = env.UsePathStyleS3Opt
-	})
-
+// HandleS3Event handles events representing S3 bucket notifications of type "ObjectCreated:*"
+func HandleS3Event(ctx context.Context, s3svc *s3.Client, ddbsvc DynamoDBUpdateItemAPI, s3Event events.S3Event) error {
 	// Create an opportunities channel to receive opportunities from the source sheet
 	opportunities := make(chan opportunity)
 
@@ -76,22 +70,39 @@ func handleS3EventWithConfig(cfg aws.Config, ctx context.Context, s3Event events
 
 			log.Info(logger, "Parsing excel file")
 
-			parsedOpportunities, err := parseXLSXFile(resp.Body, logger)
-
-			log.Info(logger, "Spreadsheet parsed", "total_opportunties", len(parsedOpportunities))
-
+			// Opportunities are sent down the channel for processing as they are parsed
+			m := newManifest(sourceBucket, sourceKey)
+			report, err := parseXLSXFile(resp.Body, logger, func(opp ffis.FFISFundingOpportunity) error {
+				select {
+				case opportunities <- opportunity(opp):
+					m.add(opportunity(opp))
+					return nil
+				case <-recordCtx.Done():
+					return recordCtx.Err()
+				}
+			})
 			if err != nil {
+				// The manifest is incomplete, so it must not be reconciled
 				log.Error(logger, "Error parsing excel file", err)
 				return err
 			}
 
-			for _, opp := range parsedOpportunities {
-				// Cast opp to opportunity type and send it down the channel
-				// for processing
-				opportunities <- opportunity(opp)
+			log.Info(logger, "Spreadsheet parsed", "total_opportunties", report.OpportunityCount)
+
+			var errs *multierror.Error
+			report.SourceBucket = sourceBucket
+			report.SourceKey = sourceKey
+			if err := saveParseReport(recordCtx, s3svc, report); err != nil {
+				errs = multierror.Append(errs, log.Errorf(logger, "Error saving spreadsheet parse report", err))
+			} else {
+				log.Info(logger, "Saved spreadsheet parse report", "report_key", parseReportKey(sourceKey),
+					"count_skipped_rows", len(report.SkippedRows), "count_cell_errors", len(report.CellErrors))
 			}
 
-			return nil
+			if err := reconcileManifest(recordCtx, s3svc, ddbsvc, m); err != nil {
+				errs = multierror.Append(errs, err)
+			}
+			return errs.ErrorOrNil()
 		}(i, record)
 		if sourcingErr != nil {
 			sourcingErrs = multierror.Append(sourcingErrs, sourcingErr)
@@ -172,7 +183,9 @@ func processOpportunities(ctx context.Context, svc *s3.Client, ch <-chan opportu
 }
 
 // processOpportunity marshals the opportunity to JSON and uploads it to S3.
-func processOpportunity(ctx context.Context, svc S3PutObjectAPI, opp opportunity) error {
+// The upload is skipped when an identical JSON representation of the opportunity
+// already exists in S3, which avoids needlessly triggering downstream processing.
+func processOpportunity(ctx context.Context, svc S3ReadWriteObjectAPI, opp opportunity) error {
 	key := opp.S3ObjectKey()
 
 	logger := log.With(logger,
@@ -187,16 +200,30 @@ func processOpportunity(ctx context.Context, svc S3PutObjectAPI, opp opportunity
 		return log.Errorf(logger, "Error marshaling JSON for opportunity", err)
 	}
 
-	log.Info(logger, "Uploading opportunity")
+	exists, identical, err := CompareS3ObjectContent(ctx, svc, env.DestinationBucket, key, b)
+	if err != nil {
+		return log.Errorf(logger, "Error comparing opportunity with extant S3 object", err)
+	}
+	if identical {
+		log.Debug(logger, "Skipping opportunity upload because the extant object is up-to-date")
+		sendMetric("opportunity.skipped", 1)
+		return nil
+	}
+
+	log.Info(logger, "Uploading opportunity", "is_new", !exists)
 
 	// Upload the object
-	if err := UploadS3Object(ctx, svc, env.DestinationBucket, key, bytes.NewReader(b)); err != nil {
+	if err := UploadS3ObjectWithMetadata(ctx, svc, env.DestinationBucket, key, bytes.NewReader(b),
+		map[string]string{ContentHashMetadataKey: ContentHash(b)}); err != nil {
 		return log.Errorf(logger, "Error uploading prepared opportunity to S3", err)
 	}
 
 	log.Info(logger, "Successfully uploaded opportunity")
-
-	sendMetric("opportunity.created", 1)
+	if exists {
+		sendMetric("opportunity.updated", 1)
+	} else {
+		sendMetric("opportunity.created", 1)
+	}
 
 	return nil
 }
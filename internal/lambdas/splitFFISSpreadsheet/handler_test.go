@@ -1,4 +1,4 @@
-package main
+package splitFFISSpreadsheet
 
 import (
 	"bytes"
@@ -45,6 +45,7 @@ func setupLambdaEnvForTesting(t *testing.T) {
 	// Configure environment variables
 	goenv.Unmarshal(goenv.EnvSet{
 		"GRANTS_PREPARED_DATA_BUCKET_NAME": "test-destination-bucket",
+		"GRANTS_PREPARED_DYNAMODB_NAME":    "test-table",
 		"S3_USE_PATH_STYLE":                "true",
 		"DOWNLOAD_CHUNK_LIMIT":             "10",
 	}, &env)
@@ -94,7 +95,7 @@ func TestLambaInvocation(t *testing.T) {
 
 	sourceBucketName := "test-source-bucket"
 	now := time.Now()
-	s3client, cfg, err := setupS3ForTesting(t, sourceBucketName)
+	s3client, _, err := setupS3ForTesting(t, sourceBucketName)
 	assert.NoError(t, err, "Error configuring test environment")
 
 	excelFixture, err := os.Open("fixtures/example_spreadsheet.xlsx")
@@ -132,7 +133,7 @@ func TestLambaInvocation(t *testing.T) {
 		})
 		require.NoErrorf(t, err, "Error creating test source object %s", objectKey)
 
-		invocationErr := handleS3EventWithConfig(cfg, context.TODO(), events.S3Event{
+		invocationErr := HandleS3Event(context.TODO(), s3client, &mockDynamoDBUpdateItemAPI{}, events.S3Event{
 			Records: []events.S3EventRecord{{
 				S3: events.S3Entity{
 					Bucket: events.S3Bucket{Name: sourceBucketName},
@@ -155,13 +156,23 @@ func TestLambaInvocation(t *testing.T) {
 		var savedOpportunity ffis.FFISFundingOpportunity
 		assert.NoError(t, json.Unmarshal(b, &savedOpportunity))
 		assert.Equal(t, expectedOpp, savedOpportunity)
+
+		reportResp, err := s3client.GetObject(context.TODO(), &s3.GetObjectInput{
+			Bucket: aws.String(sourceBucketName),
+			Key:    aws.String(fmt.Sprintf("sources/%s/ffis.org/parse-report.json", now.Format("2006/01/02"))),
+		})
+		require.NoError(t, err, "Parse report was not saved alongside the source spreadsheet")
+		var savedReport parseReport
+		assert.NoError(t, json.NewDecoder(reportResp.Body).Decode(&savedReport))
+		assert.Equal(t, objectKey, savedReport.SourceKey)
+		assert.Equal(t, 4, savedReport.OpportunityCount)
 	})
 
 	t.Run("invalid excel file", func(t *testing.T) {
 		setupLambdaEnvForTesting(t)
 
 		sourceBucketName := "test-source-bucket"
-		s3client, cfg, err := setupS3ForTesting(t, sourceBucketName)
+		s3client, _, err := setupS3ForTesting(t, sourceBucketName)
 		require.NoError(t, err)
 
 		_, err = s3client.PutObject(context.TODO(), &s3.PutObjectInput{
@@ -171,7 +182,7 @@ func TestLambaInvocation(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		err = handleS3EventWithConfig(cfg, context.TODO(), events.S3Event{
+		err = HandleS3Event(context.TODO(), s3client, &mockDynamoDBUpdateItemAPI{}, events.S3Event{
 			Records: []events.S3EventRecord{
 				{S3: events.S3Entity{
 					Bucket: events.S3Bucket{Name: sourceBucketName},
@@ -190,12 +201,12 @@ func TestLambaInvocation(t *testing.T) {
 
 	t.Run("Context canceled during invocation", func(t *testing.T) {
 		setupLambdaEnvForTesting(t)
-		_, cfg, err := setupS3ForTesting(t, "source-bucket")
+		s3client, _, err := setupS3ForTesting(t, "source-bucket")
 		require.NoError(t, err)
 
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel()
-		err = handleS3EventWithConfig(cfg, ctx, events.S3Event{
+		err = HandleS3Event(ctx, s3client, &mockDynamoDBUpdateItemAPI{}, events.S3Event{
 			Records: []events.S3EventRecord{
 				{S3: events.S3Entity{
 					Bucket: events.S3Bucket{Name: "source-bucket"},
@@ -214,3 +225,37 @@ func TestLambaInvocation(t *testing.T) {
 		}
 	})
 }
+
+type countingPutObjectAPI struct {
+	*s3.Client
+	puts int
+}
+
+func (c *countingPutObjectAPI) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	c.puts++
+	return c.Client.PutObject(ctx, params, optFns...)
+}
+
+func TestProcessOpportunity(t *testing.T) {
+	setupLambdaEnvForTesting(t)
+	s3client, _, err := setupS3ForTesting(t, "source-bucket")
+	require.NoError(t, err)
+	client := &countingPutObjectAPI{Client: s3client}
+	opp := opportunity{GrantID: 123456, OppNumber: "ABC-123", Bill: "Inflation Reduction Act"}
+
+	require.NoError(t, processOpportunity(context.TODO(), client, opp))
+	assert.Equal(t, 1, client.puts, "New opportunity was not uploaded")
+	head, err := s3client.HeadObject(context.TODO(), &s3.HeadObjectInput{
+		Bucket: aws.String(env.DestinationBucket),
+		Key:    aws.String(opp.S3ObjectKey()),
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, head.Metadata[ContentHashMetadataKey])
+
+	require.NoError(t, processOpportunity(context.TODO(), client, opp))
+	assert.Equal(t, 1, client.puts, "Unchanged opportunity was uploaded again")
+
+	opp.Bill = "Infrastructure Investment and Jobs Act"
+	require.NoError(t, processOpportunity(context.TODO(), client, opp))
+	assert.Equal(t, 2, client.puts, "Modified opportunity was not uploaded")
+}
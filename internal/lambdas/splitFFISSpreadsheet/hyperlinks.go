@@ -0,0 +1,224 @@
+package splitFFISSpreadsheet
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// XML namespace of relationship ID attributes in Office Open XML documents
+const officeRelationshipsNamespace = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+
+// sheetHyperlinks is a lookup of hyperlink targets by cell name (e.g. "F12").
+// Hyperlinks of cell ranges are not expanded into their cells, since a single range
+// may span an entire worksheet.
+type sheetHyperlinks struct {
+	// The first hyperlink of each single cell, keyed by cell name
+	cells map[string]sheetHyperlink
+	// Hyperlinks of cell ranges, in the order they were added
+	ranges []sheetHyperlink
+}
+
+// sheetHyperlink is the hyperlink of a cell or of a range of cells.
+type sheetHyperlink struct {
+	target string
+	// Position among all of the hyperlinks added to a sheetHyperlinks
+	order int
+	// Coordinates of the first and last cells of the range (which are equal for a single cell)
+	startCol, startRow, endCol, endRow int
+}
+
+func (l sheetHyperlink) contains(col, row int) bool {
+	return col >= l.startCol && col <= l.endCol && row >= l.startRow && row <= l.endRow
+}
+
+func newSheetHyperlinks() *sheetHyperlinks {
+	return &sheetHyperlinks{cells: map[string]sheetHyperlink{}}
+}
+
+// xlsxRelationships is the content of an Office Open XML relationships (.rels) part.
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// readSheetHyperlinks builds a lookup of every hyperlink in the named worksheet of the
+// xlsx archive. Unlike excelize's GetCellHyperLink, which unmarshals the entire worksheet
+// into memory, the worksheet XML is decoded as a stream of tokens.
+func readSheetHyperlinks(zr *zip.Reader, sheet string) (*sheetHyperlinks, error) {
+	sheetPath, err := worksheetPath(zr, sheet)
+	if err != nil {
+		return nil, err
+	}
+	relTargets, err := readRelationshipTargets(zr,
+		path.Join(path.Dir(sheetPath), "_rels", path.Base(sheetPath)+".rels"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	f, err := zr.Open(sheetPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	links := newSheetHyperlinks()
+	decoder := xml.NewDecoder(f)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		elem, ok := token.(xml.StartElement)
+		if !ok || elem.Name.Local != "hyperlink" {
+			continue
+		}
+
+		var ref, target string
+		for _, attr := range elem.Attr {
+			switch {
+			case attr.Name.Local == "ref":
+				ref = attr.Value
+			case attr.Name.Local == "id" && attr.Name.Space == officeRelationshipsNamespace:
+				target = relTargets[attr.Value]
+			case attr.Name.Local == "location" && target == "":
+				target = attr.Value
+			}
+		}
+		if err := links.add(ref, target); err != nil {
+			return nil, err
+		}
+	}
+	return links, nil
+}
+
+// add records target as the hyperlink of every cell in ref, which is either a single cell
+// name or a range of cells (e.g. "F12:F14").
+func (l *sheetHyperlinks) add(ref, target string) error {
+	start, end, isRange := strings.Cut(ref, ":")
+	if !isRange {
+		end = start
+	}
+	startCol, startRow, err := excelize.CellNameToCoordinates(start)
+	if err != nil {
+		return err
+	}
+	endCol, endRow, err := excelize.CellNameToCoordinates(end)
+	if err != nil {
+		return err
+	}
+	link := sheetHyperlink{
+		target:   target,
+		order:    l.count(),
+		startCol: min(startCol, endCol),
+		startRow: min(startRow, endRow),
+		endCol:   max(startCol, endCol),
+		endRow:   max(startRow, endRow),
+	}
+	if link.startCol != link.endCol || link.startRow != link.endRow {
+		l.ranges = append(l.ranges, link)
+		return nil
+	}
+	cell, err := excelize.CoordinatesToCellName(startCol, startRow)
+	if err != nil {
+		return err
+	}
+	// Consistent with excelize, the first hyperlink that applies to a cell takes precedence
+	if _, exists := l.cells[cell]; !exists {
+		l.cells[cell] = link
+	}
+	return nil
+}
+
+// get returns the hyperlink target of the given cell, if it has one.
+func (l *sheetHyperlinks) get(cell string) (bool, string) {
+	col, row, err := excelize.CellNameToCoordinates(cell)
+	if err != nil {
+		return false, ""
+	}
+	link, ok := l.cells[cell]
+	for _, r := range l.ranges {
+		if ok && r.order > link.order {
+			break
+		}
+		if r.contains(col, row) {
+			link, ok = r, true
+			break
+		}
+	}
+	return ok, link.target
+}
+
+// count returns the number of hyperlinks, counting each range once.
+func (l *sheetHyperlinks) count() int {
+	return len(l.cells) + len(l.ranges)
+}
+
+// worksheetPath returns the location within the xlsx archive of the named worksheet's XML part.
+func worksheetPath(zr *zip.Reader, sheet string) (string, error) {
+	f, err := zr.Open("xl/workbook.xml")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	var workbook struct {
+		Sheets []struct {
+			Name string     `xml:"name,attr"`
+			Attr []xml.Attr `xml:",any,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.NewDecoder(f).Decode(&workbook); err != nil {
+		return "", err
+	}
+
+	relTargets, err := readRelationshipTargets(zr, "xl/_rels/workbook.xml.rels")
+	if err != nil {
+		return "", err
+	}
+	for _, s := range workbook.Sheets {
+		if s.Name != sheet {
+			continue
+		}
+		for _, attr := range s.Attr {
+			if attr.Name.Local == "id" && attr.Name.Space == officeRelationshipsNamespace {
+				if target, ok := relTargets[attr.Value]; ok {
+					if strings.HasPrefix(target, "/") {
+						return strings.TrimPrefix(target, "/"), nil
+					}
+					return path.Join("xl", target), nil
+				}
+			}
+		}
+	}
+	return "", fmt.Errorf("worksheet %q not found in workbook", sheet)
+}
+
+// readRelationshipTargets returns a map of relationship IDs to targets defined in the given
+// relationships part of the xlsx archive.
+func readRelationshipTargets(zr *zip.Reader, name string) (map[string]string, error) {
+	f, err := zr.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var rels xlsxRelationships
+	if err := xml.NewDecoder(f).Decode(&rels); err != nil {
+		return nil, err
+	}
+	targets := make(map[string]string, len(rels.Relationships))
+	for _, rel := range rels.Relationships {
+		targets[rel.ID] = rel.Target
+	}
+	return targets, nil
+}
@@ -0,0 +1,65 @@
+package splitFFISSpreadsheet
+
+import (
+	"archive/zip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadSheetHyperlinks(t *testing.T) {
+	zr, err := zip.OpenReader("fixtures/example_spreadsheet_mixed_hyperlinks.xlsx")
+	require.NoError(t, err, "Error opening spreadsheet fixture")
+	t.Cleanup(func() { zr.Close() })
+
+	links, err := readSheetHyperlinks(&zr.Reader, "Sheet1")
+	require.NoError(t, err)
+	expected := map[string]string{
+		"F10": "https://www.grants.gov/web/grants/view-opportunity.html?oppId=123456",
+		"F13": "https://www.grants.gov/search-results-detail/512512",
+		"F14": "https://not.grants.gov/search-results-detail/215125",
+		"F17": "https://grants.gov/search-results-detail/2152151",
+	}
+	assert.Equal(t, len(expected), links.count())
+	for cell, expectedTarget := range expected {
+		ok, target := links.get(cell)
+		assert.True(t, ok, "Missing hyperlink for cell %s", cell)
+		assert.Equal(t, expectedTarget, target)
+	}
+
+	_, err = readSheetHyperlinks(&zr.Reader, "DoesNotExist")
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestSheetHyperlinksAdd(t *testing.T) {
+	links := newSheetHyperlinks()
+	require.NoError(t, links.add("B2", "first"))
+	require.NoError(t, links.add("A1:B2", "range"))
+	require.NoError(t, links.add("B1", "second"))
+	require.NoError(t, links.add("C3:B2", "reversed"))
+	assert.Equal(t, 4, links.count())
+
+	for cell, expectedTarget := range map[string]string{
+		"A1": "range", "A2": "range", "B1": "range", "B2": "first", "C2": "reversed", "C3": "reversed",
+	} {
+		ok, target := links.get(cell)
+		assert.True(t, ok, "Missing hyperlink for cell %s", cell)
+		assert.Equal(t, expectedTarget, target, "Unexpected hyperlink for cell %s", cell)
+	}
+	ok, _ := links.get("D4")
+	assert.False(t, ok)
+	ok, _ = links.get("not a cell")
+	assert.False(t, ok)
+
+	assert.Error(t, links.add("not a cell", "target"))
+}
+
+func TestSheetHyperlinksAddLargeRange(t *testing.T) {
+	links := newSheetHyperlinks()
+	require.NoError(t, links.add("A1:XFD1048576", "everything"))
+	assert.Equal(t, 1, links.count())
+	ok, target := links.get("XFD1048576")
+	assert.True(t, ok)
+	assert.Equal(t, "everything", target)
+}
@@ -0,0 +1,33 @@
+// Package splitFFISSpreadsheet provides the handler of the SplitFFISSpreadsheet Lambda function,
+// which splits FFIS spreadsheets into individual prepared-data objects. The Lambda binary is built
+// from cmd/SplitFFISSpreadsheet.
+package splitFFISSpreadsheet
+
+import (
+	goenv "github.com/Netflix/go-env"
+	"github.com/usdigitalresponse/grants-ingest/internal/ddHelpers"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+)
+
+type Environment struct {
+	LogLevel             string `env:"LOG_LEVEL,default=INFO"`
+	DownloadChunkLimit   int64  `env:"DOWNLOAD_CHUNK_LIMIT,default=10"`
+	DestinationBucket    string `env:"GRANTS_PREPARED_DATA_BUCKET_NAME,required=true"`
+	DestinationTable     string `env:"GRANTS_PREPARED_DYNAMODB_NAME,required=true"`
+	MaxConcurrentUploads int    `env:"MAX_CONCURRENT_UPLOADS,default=1"`
+	StaleBillAction      string `env:"STALE_BILL_ACTION,default=flag"`
+	UsePathStyleS3Opt    bool   `env:"S3_USE_PATH_STYLE,default=false"`
+	Extras               goenv.EnvSet
+}
+
+var (
+	env        Environment
+	logger     log.Logger
+	sendMetric = ddHelpers.NewMetricSender("SplitFFISSpreadsheet", "source:ffis.org")
+)
+
+// Configure sets the environment and logger used when handling invocations.
+func Configure(e Environment, l log.Logger) {
+	env = e
+	logger = l
+}
@@ -0,0 +1,248 @@
+package splitFFISSpreadsheet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/hashicorp/go-multierror"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+)
+
+const (
+	// manifestKeyPrefix is the destination bucket prefix under which spreadsheet manifests are saved
+	manifestKeyPrefix = "ffis.org/manifests/"
+
+	// Supported values for the STALE_BILL_ACTION environment variable
+	StaleBillActionFlag  = "flag"
+	StaleBillActionClear = "clear"
+	StaleBillActionNone  = "none"
+)
+
+// spreadsheetDatePattern extracts the date path from FFIS spreadsheet source keys
+// like "sources/2023/05/15/ffis.org/download.xlsx".
+var spreadsheetDatePattern = regexp.MustCompile(`^sources/(\d{4}/\d{2}/\d{2})/ffis\.org/`)
+
+// S3ManifestAPI is the interface for listing, reading, and writing spreadsheet manifests in S3
+type S3ManifestAPI interface {
+	s3.ListObjectsV2APIClient
+	S3PutObjectAPI
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+// manifest records the grants that were parsed from a single FFIS spreadsheet.
+type manifest struct {
+	SourceBucket string `json:"source_bucket"`
+	SourceKey    string `json:"source_key"`
+	// Date of the spreadsheet, formatted as YYYY-MM-DD
+	Date string `json:"date"`
+	// Bills maps the grant ID of each parsed opportunity to its bill
+	Bills map[int64]string `json:"bills"`
+}
+
+// newManifest returns an empty manifest for the spreadsheet at the given S3 location.
+// The manifest date is determined from the source key, or else the current date.
+func newManifest(sourceBucket, sourceKey string) *manifest {
+	date := time.Now().UTC().Format("2006/01/02")
+	if match := spreadsheetDatePattern.FindStringSubmatch(sourceKey); match != nil {
+		date = match[1]
+	}
+	return &manifest{
+		SourceBucket: sourceBucket,
+		SourceKey:    sourceKey,
+		Date:         strings.ReplaceAll(date, "/", "-"),
+		Bills:        map[int64]string{},
+	}
+}
+
+// add records the opportunity in the manifest.
+func (m *manifest) add(opp opportunity) {
+	m.Bills[opp.GrantID] = opp.Bill
+}
+
+// S3ObjectKey returns a string to use as the object key when saving the manifest to an S3 bucket.
+// Keys sort in the same order as the spreadsheet dates.
+func (m *manifest) S3ObjectKey() string {
+	return fmt.Sprintf("%s%s/manifest.json", manifestKeyPrefix, strings.ReplaceAll(m.Date, "-", "/"))
+}
+
+// manifestDiff describes how the grants in a spreadsheet changed since the preceding spreadsheet.
+type manifestDiff struct {
+	// Grant IDs that were not in the preceding spreadsheet
+	Added []int64
+	// Grant IDs that are no longer in the spreadsheet
+	Removed []int64
+	// Grant IDs that are associated with a different bill than in the preceding spreadsheet
+	Rebilled []int64
+}
+
+// diffManifests compares the current manifest against the previous one.
+// Each list of grant IDs in the result is sorted in ascending order.
+func diffManifests(previous, current *manifest) manifestDiff {
+	diff := manifestDiff{Added: []int64{}, Removed: []int64{}, Rebilled: []int64{}}
+	for id, bill := range current.Bills {
+		prevBill, ok := previous.Bills[id]
+		if !ok {
+			diff.Added = append(diff.Added, id)
+		} else if prevBill != bill {
+			diff.Rebilled = append(diff.Rebilled, id)
+		}
+	}
+	for id := range previous.Bills {
+		if _, ok := current.Bills[id]; !ok {
+			diff.Removed = append(diff.Removed, id)
+		}
+	}
+	for _, ids := range [][]int64{diff.Added, diff.Removed, diff.Rebilled} {
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	}
+	return diff
+}
+
+// findPreviousManifest returns the manifest in the given bucket with the greatest key that
+// sorts before key, or nil if there is no such manifest.
+func findPreviousManifest(ctx context.Context, svc S3ManifestAPI, bucket, key string) (*manifest, error) {
+	previousKey := ""
+	paginator := s3.NewListObjectsV2Paginator(svc, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(manifestKeyPrefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range page.Contents {
+			if k := aws.ToString(obj.Key); k < key && k > previousKey {
+				previousKey = k
+			}
+		}
+	}
+	if previousKey == "" {
+		return nil, nil
+	}
+
+	resp, err := svc.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(previousKey),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var m manifest
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return nil, fmt.Errorf("error decoding manifest %s: %w", previousKey, err)
+	}
+	return &m, nil
+}
+
+// reconcileManifest saves the current manifest to the destination bucket and compares it
+// against the manifest of the preceding spreadsheet. Grants that were removed since the
+// preceding spreadsheet have their stale Bill attribute handled according to the configured
+// StaleBillAction. Grants that reappear have any stale flag removed or, when stale bills are
+// cleared, their bill restored.
+// Since the preceding manifest is always the one that sorts before the current manifest,
+// reconciling the same spreadsheet more than once produces the same result.
+func reconcileManifest(ctx context.Context, s3svc S3ManifestAPI, ddbsvc DynamoDBUpdateItemAPI, current *manifest) error {
+	key := current.S3ObjectKey()
+	logger := log.With(logger, "manifest_key", key, "manifest_date", current.Date)
+
+	b, err := json.Marshal(current)
+	if err != nil {
+		return log.Errorf(logger, "Error marshaling spreadsheet manifest to JSON", err)
+	}
+	if err := UploadS3Object(ctx, s3svc, env.DestinationBucket, key, bytes.NewReader(b)); err != nil {
+		return log.Errorf(logger, "Error uploading spreadsheet manifest to S3", err)
+	}
+	log.Info(logger, "Saved spreadsheet manifest", "total_opportunities", len(current.Bills))
+
+	previous, err := findPreviousManifest(ctx, s3svc, env.DestinationBucket, key)
+	if err != nil {
+		return log.Errorf(logger, "Error retrieving previous spreadsheet manifest", err)
+	}
+	if previous == nil {
+		log.Info(logger, "No previous spreadsheet manifest exists; skipping comparison")
+		return nil
+	}
+	logger = log.With(logger, "previous_manifest_date", previous.Date)
+
+	diff := diffManifests(previous, current)
+	sendMetric("spreadsheet.opportunities_added", float64(len(diff.Added)))
+	sendMetric("spreadsheet.opportunities_removed", float64(len(diff.Removed)))
+	sendMetric("spreadsheet.opportunities_rebilled", float64(len(diff.Rebilled)))
+	log.Info(logger, "Compared spreadsheet to previous manifest",
+		"added", len(diff.Added), "removed", len(diff.Removed), "rebilled", len(diff.Rebilled))
+	for _, id := range diff.Rebilled {
+		log.Info(logger, "Opportunity bill changed since previous spreadsheet", "opportunity_id", id,
+			"previous_bill", previous.Bills[id], "bill", current.Bills[id])
+	}
+
+	var errs *multierror.Error
+	for _, id := range diff.Removed {
+		logger := log.With(logger, "opportunity_id", id, "bill", previous.Bills[id])
+		var err error
+		var metric string
+		switch env.StaleBillAction {
+		case StaleBillActionFlag:
+			err = FlagStaleBill(ctx, ddbsvc, env.DestinationTable, id, current.Date)
+			metric = "opportunity.stale_bill_flagged"
+		case StaleBillActionClear:
+			err = ClearStaleBill(ctx, ddbsvc, env.DestinationTable, id, previous.Bills[id])
+			metric = "opportunity.stale_bill_cleared"
+		case StaleBillActionNone:
+			log.Info(logger, "Opportunity was removed since previous spreadsheet")
+			continue
+		default:
+			return log.Errorf(logger, "Unsupported stale bill action", fmt.Errorf(
+				"invalid STALE_BILL_ACTION %q", env.StaleBillAction))
+		}
+		if err := handleStaleBillUpdateErr(logger, err); err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+		sendMetric(metric, 1)
+	}
+
+	for _, id := range diff.Added {
+		logger := log.With(logger, "opportunity_id", id, "bill", current.Bills[id])
+		var err error
+		switch env.StaleBillAction {
+		case StaleBillActionFlag:
+			err = UnflagStaleBill(ctx, ddbsvc, env.DestinationTable, id)
+		case StaleBillActionClear:
+			err = RestoreBill(ctx, ddbsvc, env.DestinationTable, id, current.Bills[id])
+		default:
+			continue
+		}
+		if err := handleStaleBillUpdateErr(logger, err); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+
+	return errs.ErrorOrNil()
+}
+
+// handleStaleBillUpdateErr logs the result of a stale bill update. Failed condition checks
+// mean that the DynamoDB item did not need to be updated, so they are not treated as errors.
+func handleStaleBillUpdateErr(logger log.Logger, err error) error {
+	var conditionalCheckErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionalCheckErr) {
+		log.Debug(logger, "Skipped stale bill update because DynamoDB item is already up to date")
+		return nil
+	}
+	if err != nil {
+		return log.Errorf(logger, "Error updating stale bill in DynamoDB", err)
+	}
+	log.Info(logger, "Updated stale bill in DynamoDB", "action", env.StaleBillAction)
+	return nil
+}
@@ -0,0 +1,191 @@
+package splitFFISSpreadsheet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockDynamoDBUpdateItemAPI struct {
+	err    error
+	params []*dynamodb.UpdateItemInput
+}
+
+func (m *mockDynamoDBUpdateItemAPI) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	m.params = append(m.params, params)
+	return &dynamodb.UpdateItemOutput{}, m.err
+}
+
+// updatedGrantIDs returns the grant_id key of each UpdateItem call received by the mock.
+func (m *mockDynamoDBUpdateItemAPI) updatedGrantIDs(t *testing.T) []string {
+	t.Helper()
+	ids := []string{}
+	for _, p := range m.params {
+		key := map[string]string{}
+		require.NoError(t, attributevalue.UnmarshalMap(p.Key, &key))
+		ids = append(ids, key["grant_id"])
+	}
+	return ids
+}
+
+func TestNewManifest(t *testing.T) {
+	m := newManifest("bucket", "sources/2023/05/15/ffis.org/download.xlsx")
+	assert.Equal(t, "2023-05-15", m.Date)
+	assert.Equal(t, "ffis.org/manifests/2023/05/15/manifest.json", m.S3ObjectKey())
+
+	m = newManifest("bucket", "unexpected/key.xlsx")
+	assert.Equal(t, time.Now().UTC().Format("2006-01-02"), m.Date)
+}
+
+func TestDiffManifests(t *testing.T) {
+	previous := &manifest{Bills: map[int64]string{1: "A", 2: "B", 3: "C", 5: "E"}}
+	current := &manifest{Bills: map[int64]string{1: "A", 2: "X", 4: "D", 6: "F"}}
+	diff := diffManifests(previous, current)
+	assert.Equal(t, []int64{4, 6}, diff.Added)
+	assert.Equal(t, []int64{3, 5}, diff.Removed)
+	assert.Equal(t, []int64{2}, diff.Rebilled)
+
+	diff = diffManifests(current, current)
+	assert.Empty(t, diff.Added)
+	assert.Empty(t, diff.Removed)
+	assert.Empty(t, diff.Rebilled)
+}
+
+func TestReconcileManifest(t *testing.T) {
+	putManifest := func(t *testing.T, s3client *s3.Client, m *manifest) {
+		t.Helper()
+		b, err := json.Marshal(m)
+		require.NoError(t, err)
+		_, err = s3client.PutObject(context.TODO(), &s3.PutObjectInput{
+			Bucket: aws.String(env.DestinationBucket),
+			Key:    aws.String(m.S3ObjectKey()),
+			Body:   bytes.NewReader(b),
+		})
+		require.NoError(t, err)
+	}
+	older := &manifest{Date: "2023-05-01", Bills: map[int64]string{1: "A", 9: "Z"}}
+	previous := &manifest{Date: "2023-05-08", Bills: map[int64]string{1: "A", 2: "B", 3: "C"}}
+	later := &manifest{Date: "2023-05-22", Bills: map[int64]string{}}
+	current := &manifest{Date: "2023-05-15", Bills: map[int64]string{1: "A", 2: "X", 4: "D"}}
+
+	for _, tt := range []struct {
+		action            string
+		expUpdatedGrants  []string
+		expUpdateContains string
+	}{
+		{StaleBillActionFlag, []string{"3", "4"}, StaleBillAttribute},
+		{StaleBillActionClear, []string{"3", "4"}, "revision"},
+		{StaleBillActionNone, []string{}, ""},
+	} {
+		t.Run(fmt.Sprintf("stale bill action %s", tt.action), func(t *testing.T) {
+			setupLambdaEnvForTesting(t)
+			env.StaleBillAction = tt.action
+			s3client, _, err := setupS3ForTesting(t, "source-bucket")
+			require.NoError(t, err)
+			for _, m := range []*manifest{older, previous, later} {
+				putManifest(t, s3client, m)
+			}
+			ddb := &mockDynamoDBUpdateItemAPI{}
+
+			require.NoError(t, reconcileManifest(context.TODO(), s3client, ddb, current))
+			assert.Equal(t, tt.expUpdatedGrants, ddb.updatedGrantIDs(t))
+			for _, p := range ddb.params {
+				assert.Equal(t, env.DestinationTable, aws.ToString(p.TableName))
+				assert.NotEmpty(t, aws.ToString(p.ConditionExpression))
+				assert.True(t, checkMapContainsValue(t, p.ExpressionAttributeNames, tt.expUpdateContains),
+					"Missing attribute %q in update attribute names", tt.expUpdateContains)
+			}
+
+			resp, err := s3client.GetObject(context.TODO(), &s3.GetObjectInput{
+				Bucket: aws.String(env.DestinationBucket),
+				Key:    aws.String(current.S3ObjectKey()),
+			})
+			require.NoError(t, err, "Current manifest was not saved")
+			var saved manifest
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&saved))
+			assert.Equal(t, *current, saved)
+		})
+	}
+
+	t.Run("cleared bill is restored when opportunity reappears", func(t *testing.T) {
+		setupLambdaEnvForTesting(t)
+		env.StaleBillAction = StaleBillActionClear
+		s3client, _, err := setupS3ForTesting(t, "source-bucket")
+		require.NoError(t, err)
+		ddb := &mockDynamoDBUpdateItemAPI{}
+		present := &manifest{Date: "2023-05-01", Bills: map[int64]string{1: "A", 2: "B"}}
+		absent := &manifest{Date: "2023-05-08", Bills: map[int64]string{2: "B"}}
+		reappeared := &manifest{Date: "2023-05-15", Bills: map[int64]string{1: "A", 2: "B"}}
+		putManifest(t, s3client, present)
+
+		require.NoError(t, reconcileManifest(context.TODO(), s3client, ddb, absent))
+		require.NoError(t, reconcileManifest(context.TODO(), s3client, ddb, reappeared))
+		require.Equal(t, []string{"1", "1"}, ddb.updatedGrantIDs(t))
+
+		var values map[string]string
+		cleared, restored := ddb.params[0], ddb.params[1]
+		assert.Contains(t, aws.ToString(cleared.UpdateExpression), "REMOVE")
+		assert.True(t, checkMapContainsValue(t, cleared.ExpressionAttributeNames, "Bill"))
+		assert.Contains(t, aws.ToString(restored.UpdateExpression), "SET")
+		assert.True(t, checkMapContainsValue(t, restored.ExpressionAttributeNames, "Bill"))
+		assert.True(t, checkMapContainsValue(t, restored.ExpressionAttributeNames, "revision"))
+		require.NoError(t, attributevalue.UnmarshalMap(restored.ExpressionAttributeValues, &values))
+		assert.True(t, checkMapContainsValue(t, values, "A"), "Restored bill should be the bill of the reappeared opportunity")
+	})
+
+	t.Run("first manifest", func(t *testing.T) {
+		setupLambdaEnvForTesting(t)
+		s3client, _, err := setupS3ForTesting(t, "source-bucket")
+		require.NoError(t, err)
+		putManifest(t, s3client, later)
+		ddb := &mockDynamoDBUpdateItemAPI{}
+
+		require.NoError(t, reconcileManifest(context.TODO(), s3client, ddb, current))
+		assert.Empty(t, ddb.params)
+	})
+
+	t.Run("failed condition checks are not errors", func(t *testing.T) {
+		setupLambdaEnvForTesting(t)
+		s3client, _, err := setupS3ForTesting(t, "source-bucket")
+		require.NoError(t, err)
+		putManifest(t, s3client, previous)
+		ddb := &mockDynamoDBUpdateItemAPI{err: &types.ConditionalCheckFailedException{}}
+
+		require.NoError(t, reconcileManifest(context.TODO(), s3client, ddb, current))
+		assert.Len(t, ddb.params, 2)
+	})
+
+	t.Run("DynamoDB errors are accumulated", func(t *testing.T) {
+		setupLambdaEnvForTesting(t)
+		s3client, _, err := setupS3ForTesting(t, "source-bucket")
+		require.NoError(t, err)
+		putManifest(t, s3client, previous)
+		ddb := &mockDynamoDBUpdateItemAPI{err: fmt.Errorf("oh no")}
+
+		err = reconcileManifest(context.TODO(), s3client, ddb, current)
+		assert.ErrorContains(t, err, "oh no")
+		assert.Len(t, ddb.params, 2)
+	})
+}
+
+// checkMapContainsValue is a testing helper function that returns true if target is a value of m
+func checkMapContainsValue[K comparable, V comparable](t *testing.T, m map[K]V, target V) bool {
+	t.Helper()
+	for _, v := range m {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package splitFFISSpreadsheet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// parseReportFilename is the name of the parse report saved alongside each source spreadsheet
+const parseReportFilename = "parse-report.json"
+
+// Reasons recorded for rows that did not produce an opportunity
+const (
+	SkipReasonBeforeHeaders  = "before_headers"
+	SkipReasonBlank          = "blank"
+	SkipReasonMissingGrantID = "missing_grant_id"
+)
+
+// parseReport describes the outcome of parsing a single FFIS spreadsheet, so that problems
+// with the spreadsheet can be identified without reviewing logs.
+type parseReport struct {
+	SourceBucket string `json:"source_bucket"`
+	SourceKey    string `json:"source_key"`
+	Sheet        string `json:"sheet"`
+	// 1-based number of the column headers row, or 0 if headers were not found
+	HeaderRow        int               `json:"header_row"`
+	TotalRows        int               `json:"total_rows"`
+	OpportunityCount int               `json:"opportunity_count"`
+	CellErrorCounts  map[string]int    `json:"cell_error_counts"`
+	Bills            []parseReportBill `json:"bills"`
+	SkippedRows      []skippedRow      `json:"skipped_rows"`
+	CellErrors       []cellError       `json:"cell_errors"`
+}
+
+// parseReportBill is a bill found in the spreadsheet.
+type parseReportBill struct {
+	Name string `json:"name"`
+	// 1-based number of the row that names the bill
+	Row              int `json:"row"`
+	OpportunityCount int `json:"opportunity_count"`
+}
+
+// skippedRow is a spreadsheet row that did not produce an opportunity.
+type skippedRow struct {
+	// 1-based row number, as displayed in spreadsheet applications
+	Row    int    `json:"row"`
+	Reason string `json:"reason"`
+}
+
+// cellError is a problem encountered when parsing a spreadsheet cell.
+type cellError struct {
+	// Cell name, e.g. "F12"
+	Cell string `json:"cell"`
+	// The opportunity field that could not be parsed from the cell
+	Target string `json:"target"`
+	Value  string `json:"value"`
+	Error  string `json:"error"`
+}
+
+func newParseReport(sheet string) *parseReport {
+	return &parseReport{
+		Sheet:           sheet,
+		CellErrorCounts: map[string]int{},
+		Bills:           []parseReportBill{},
+		SkippedRows:     []skippedRow{},
+		CellErrors:      []cellError{},
+	}
+}
+
+// addBill records a bill named at the given 0-based row index.
+func (r *parseReport) addBill(name string, rowIndex int) {
+	r.Bills = append(r.Bills, parseReportBill{Name: name, Row: rowIndex + 1})
+}
+
+// addOpportunity records an opportunity parsed from the spreadsheet under the current bill.
+func (r *parseReport) addOpportunity() {
+	r.OpportunityCount++
+	if len(r.Bills) > 0 {
+		r.Bills[len(r.Bills)-1].OpportunityCount++
+	}
+}
+
+// skipRow records that the row at the given 0-based index did not produce an opportunity.
+func (r *parseReport) skipRow(rowIndex int, reason string) {
+	r.SkippedRows = append(r.SkippedRows, skippedRow{Row: rowIndex + 1, Reason: reason})
+}
+
+// addCellError records a problem with the cell at the given 0-based row and column indices.
+func (r *parseReport) addCellError(rowIndex, colIndex int, target, value string, err error) {
+	cell, _ := excelize.CoordinatesToCellName(colIndex+1, rowIndex+1)
+	r.CellErrors = append(r.CellErrors, cellError{
+		Cell: cell, Target: target, Value: value, Error: err.Error(),
+	})
+	r.CellErrorCounts[target]++
+}
+
+// parseReportKey returns the S3 object key of the parse report for the given spreadsheet key,
+// which is located in the same "directory" as the spreadsheet.
+func parseReportKey(sourceKey string) string {
+	return path.Join(path.Dir(sourceKey), parseReportFilename)
+}
+
+// saveParseReport uploads the report as JSON to the same bucket as the source spreadsheet.
+func saveParseReport(ctx context.Context, svc S3PutObjectAPI, report *parseReport) error {
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return UploadS3Object(ctx, svc, report.SourceBucket, parseReportKey(report.SourceKey), bytes.NewReader(b))
+}
@@ -0,0 +1,83 @@
+package splitFFISSpreadsheet
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsTransport "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ContentHashMetadataKey is the S3 object metadata key that records the hex-encoded
+// SHA-256 hash of an uploaded object's contents
+const ContentHashMetadataKey = "content-sha256"
+
+// S3PutObjectAPI is the interface for writing new or replacement objects in an S3 bucket
+type S3PutObjectAPI interface {
+	// PutObject uploads an object to S3
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+// S3ReadWriteObjectAPI is the interface for inspecting and writing objects in an S3 bucket
+type S3ReadWriteObjectAPI interface {
+	s3.HeadObjectAPIClient
+	S3PutObjectAPI
+}
+
+// ContentHash returns the hex-encoded SHA-256 hash of b.
+func ContentHash(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// CompareS3ObjectContent determines whether an S3 object exists at the given bucket and key,
+// and if so, whether its contents are identical to b. Objects are compared according to the
+// SHA-256 hash recorded in their metadata when it is available, or else their ETag, which
+// is the MD5 hash of the contents of objects uploaded by UploadS3Object.
+func CompareS3ObjectContent(ctx context.Context, c s3.HeadObjectAPIClient, bucket, key string, b []byte) (exists, identical bool, err error) {
+	headOutput, err := c.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var respError *awsTransport.ResponseError
+		if errors.As(err, &respError) && respError.ResponseError.HTTPStatusCode() == 404 {
+			return false, false, nil
+		}
+		return false, false, err
+	}
+
+	if hash, ok := headOutput.Metadata[ContentHashMetadataKey]; ok {
+		return true, hash == ContentHash(b), nil
+	}
+	md5Sum := md5.Sum(b)
+	etag := strings.Trim(aws.ToString(headOutput.ETag), `"`)
+	return true, etag == hex.EncodeToString(md5Sum[:]), nil
+}
+
+// UploadS3Object uploads bytes read from from r to an S3 object at the given bucket and key.
+// If an error was encountered during upload, returns the error.
+// Returns nil when the upload was successful.
+func UploadS3Object(ctx context.Context, c S3PutObjectAPI, bucket, key string, r io.Reader) error {
+	return UploadS3ObjectWithMetadata(ctx, c, bucket, key, r, nil)
+}
+
+// UploadS3ObjectWithMetadata behaves like UploadS3Object, and additionally
+// sets the given user-defined metadata on the uploaded S3 object.
+func UploadS3ObjectWithMetadata(ctx context.Context, c S3PutObjectAPI, bucket, key string, r io.Reader, metadata map[string]string) error {
+	_, err := c.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:               aws.String(bucket),
+		Key:                  aws.String(key),
+		Body:                 r,
+		Metadata:             metadata,
+		ServerSideEncryption: types.ServerSideEncryptionAes256,
+	})
+	return err
+}
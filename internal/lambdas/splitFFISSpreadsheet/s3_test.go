@@ -1,4 +1,4 @@
-package main
+package splitFFISSpreadsheet
 
 import (
 	"bytes"
@@ -10,6 +10,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type mockPutObjectAPI func(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
@@ -65,3 +66,67 @@ func TestUploadS3Object(t *testing.T) {
 		})
 	}
 }
+
+func TestCompareS3ObjectContent(t *testing.T) {
+	setupLambdaEnvForTesting(t)
+	s3client, _, err := setupS3ForTesting(t, "source-bucket")
+	require.NoError(t, err)
+	content := []byte(`{"grant_id": 123456}`)
+
+	for _, tt := range []struct {
+		name         string
+		key          string
+		upload       func(t *testing.T, key string)
+		expExists    bool
+		expIdentical bool
+	}{
+		{"object does not exist", "missing", func(t *testing.T, key string) {}, false, false},
+		{
+			"identical content hash metadata",
+			"hashed/identical",
+			func(t *testing.T, key string) {
+				require.NoError(t, UploadS3ObjectWithMetadata(context.TODO(), s3client,
+					env.DestinationBucket, key, bytes.NewReader(content),
+					map[string]string{ContentHashMetadataKey: ContentHash(content)}))
+			},
+			true, true,
+		},
+		{
+			"different content hash metadata",
+			"hashed/different",
+			func(t *testing.T, key string) {
+				require.NoError(t, UploadS3ObjectWithMetadata(context.TODO(), s3client,
+					env.DestinationBucket, key, bytes.NewReader(content),
+					map[string]string{ContentHashMetadataKey: ContentHash([]byte("other"))}))
+			},
+			true, false,
+		},
+		{
+			"identical ETag without metadata",
+			"unhashed/identical",
+			func(t *testing.T, key string) {
+				require.NoError(t, UploadS3Object(context.TODO(), s3client,
+					env.DestinationBucket, key, bytes.NewReader(content)))
+			},
+			true, true,
+		},
+		{
+			"different ETag without metadata",
+			"unhashed/different",
+			func(t *testing.T, key string) {
+				require.NoError(t, UploadS3Object(context.TODO(), s3client,
+					env.DestinationBucket, key, bytes.NewReader([]byte("other"))))
+			},
+			true, false,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.upload(t, tt.key)
+			exists, identical, err := CompareS3ObjectContent(context.TODO(), s3client,
+				env.DestinationBucket, tt.key, content)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expExists, exists)
+			assert.Equal(t, tt.expIdentical, identical)
+		})
+	}
+}
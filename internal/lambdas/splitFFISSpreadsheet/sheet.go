@@ -1,9 +1,11 @@
-package main
+package splitFFISSpreadsheet
 
 import (
+	"archive/zip"
 	"fmt"
 	"io"
 	"net/url"
+	"os"
 	"regexp"
 	"strconv"
 	"strings"
@@ -11,6 +13,7 @@ import (
 
 	"github.com/usdigitalresponse/grants-ingest/internal/log"
 	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/ffis"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/usdr"
 	"github.com/xuri/excelize/v2"
 )
 
@@ -23,22 +26,52 @@ func parseEligibility(value string) bool {
 	return value == "X"
 }
 
-// parseXLSXFile is a function that reads and processes an Excel file stream, converting the data into a slice
-// of ffis.FFISFundingOpportunity objects. The file is expected to be provided as an io.Reader.
+// parseXLSXFile is a function that reads and processes an Excel file stream, converting the data into
+// ffis.FFISFundingOpportunity objects. The file is expected to be provided as an io.Reader.
 // The function filters and retains only those funding opportunities that possess a valid grant ID.
+// Rather than collecting every opportunity before returning, each is passed to emit as soon as it is parsed.
+// Rows are read from the sheet as a stream, so memory usage does not grow with the number of rows.
 //
 // Any errors encountered during the parsing of individual cells within the Excel file are not returned as function errors,
 // but are instead logged at the WARN level, accompanied by the associated row and column indices for easy identification.
+// These errors are also recorded in the returned parse report, along with each row that did not produce an opportunity
+// and the bills that were found in the spreadsheet.
 //
 // Parameters:
 // r: The io.Reader providing the Excel file stream to be parsed.
 // logger: The log.Logger used to log any parsing errors at the WARN level.
+// emit: Called with each parsed funding opportunity. Parsing stops if emit returns an error.
 //
 // Returns:
-// A slice of ffis.FFISFundingOpportunity objects representing the parsed funding opportunities from the Excel file.
-// An error is returned if the parsing process fails at a level beyond individual cell parsing.
-func parseXLSXFile(r io.Reader, logger log.Logger) ([]ffis.FFISFundingOpportunity, error) {
-	xlFile, err := excelize.OpenReader(r)
+// A parse report describing the outcome of parsing each row of the Excel file.
+// An error is returned if the parsing process fails at a level beyond individual cell parsing,
+// or if emit returns an error.
+func parseXLSXFile(r io.Reader, logger log.Logger, emit func(ffis.FFISFundingOpportunity) error) (*parseReport, error) {
+	// The file is buffered on disk rather than in memory, since excelize reads it into memory
+	// regardless, and the zip archive is also read directly in order to locate hyperlinks
+	// without loading the whole worksheet into memory
+	tmp, err := os.CreateTemp("", "ffis-*.xlsx")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		tmp.Close()
+		if err := os.Remove(tmp.Name()); err != nil {
+			log.Error(logger, "Error removing temporary excel file", err)
+		}
+	}()
+	size, err := io.Copy(tmp, r)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(tmp, size)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	xlFile, err := excelize.OpenReader(tmp)
 
 	if err != nil {
 		return nil, err
@@ -63,17 +96,21 @@ func parseXLSXFile(r io.Reader, logger log.Logger) ([]ffis.FFISFundingOpportunit
 	// We assume the excel file only has one sheet
 	sheet := "Sheet1"
 
-	// Returns all rows in the sheet. Note this assumes the sheet is somewhat limited in
-	// size, and will not scale to extremely large worksheets (memory overhead)
-	rows, err := xlFile.GetRows(sheet)
+	// Opportunity IDs are only available from cell hyperlinks, so look them all up at once
+	hyperlinks, err := readSheetHyperlinks(zr, sheet)
 	if err != nil {
 		return nil, err
 	}
 
-	sendMetric("spreadsheet.row_count", float64(len(rows)))
-	log.Info(logger, "Parsing spreadsheet", "total_rows", len(rows))
+	// Iterates over the rows in the sheet without loading them all into memory
+	rows, err := xlFile.Rows(sheet)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	var opportunities []ffis.FFISFundingOpportunity
+	report := newParseReport(sheet)
+	log.Info(logger, "Parsing spreadsheet", "total_hyperlinks", hyperlinks.count())
 
 	// Tracks if the iterator has found headers for the sheet. A header
 	// is a column header, like "CFDA", "Opportunity Title", etc.
@@ -85,23 +122,42 @@ func parseXLSXFile(r io.Reader, logger log.Logger) ([]ffis.FFISFundingOpportunit
 	// "Inflation Reduction Act".
 	bill := ""
 
+	rowIndex := -1
+
 rowLoop:
-	for rowIndex, row := range rows {
+	for rows.Next() {
+		rowIndex++
+		row, err := rows.Columns()
+		if err != nil {
+			return report, err
+		}
 		opportunity := ffis.FFISFundingOpportunity{}
 
+		// Rows without any cells are blank
+		if len(row) == 0 {
+			if foundHeaders {
+				report.skipRow(rowIndex, SkipReasonBlank)
+			} else {
+				report.skipRow(rowIndex, SkipReasonBeforeHeaders)
+			}
+			continue
+		}
+
 		for colIndex, cell := range row {
-			logger := log.With(logger, "row_index", row, "column_index", colIndex)
+			logger := log.With(logger, "row_index", rowIndex, "column_index", colIndex)
 
 			// We assume the first column header is "CFDA", if it is,
 			// we're in the headers row, so skip it and set the flag that
 			// the content follows
 			if colIndex == 0 && cell == "CFDA" {
 				foundHeaders = true
+				report.HeaderRow = rowIndex + 1
 				continue rowLoop
 			}
 
 			// If we have not yet found the headers, skip the row
 			if !foundHeaders {
+				report.skipRow(rowIndex, SkipReasonBeforeHeaders)
 				continue rowLoop
 			}
 
@@ -110,6 +166,7 @@ rowLoop:
 			if colIndex == 0 {
 				// If the cell is blank, skip the row
 				if cell == "" {
+					report.skipRow(rowIndex, SkipReasonBlank)
 					continue rowLoop
 				}
 
@@ -117,6 +174,7 @@ rowLoop:
 				// assume it's a bill and continue
 				if !cfdaRegex.MatchString(cell) {
 					bill = cell
+					report.addBill(bill, rowIndex)
 					continue rowLoop
 				}
 			}
@@ -126,8 +184,9 @@ rowLoop:
 			switch colIndex {
 			case 0:
 				if f, err := strconv.ParseFloat(strings.TrimRight(cell, "+"), 64); err != nil {
-					log.Warn(logger, "Error parsing CFDA", err)
+					log.Warn(logger, "Error parsing CFDA", "error", err)
 					sendMetric("spreadsheet.cell_parsing_errors", 1, "target:CFDA")
+					report.addCellError(rowIndex, colIndex, "CFDA", cell, err)
 					continue
 				} else {
 					opportunity.CFDA = fmt.Sprintf("%06.3f", f)
@@ -136,6 +195,12 @@ rowLoop:
 				opportunity.OppTitle = cell
 			case 2:
 				opportunity.Agency = cell
+				if agency, ok := usdr.DefaultAgencyRegistry().Reconcile(cell); ok {
+					opportunity.AgencyCode = agency.Code
+				} else {
+					log.Debug(logger, "Agency is not in the agency registry", "agency", cell)
+					sendMetric("spreadsheet.unrecognized_agency", 1)
+				}
 			case 3:
 				// If estimated funding is N/A, assume 0
 				if cell == "N/A" {
@@ -147,6 +212,7 @@ rowLoop:
 				if err != nil {
 					log.Warn(logger, "Error parsing estimated funding", "error", err)
 					sendMetric("spreadsheet.cell_parsing_errors", 1, "target:EstimatedFunding")
+					report.addCellError(rowIndex, colIndex, "EstimatedFunding", cell, err)
 					continue
 				}
 				opportunity.EstimatedFunding = num
@@ -161,16 +227,11 @@ rowLoop:
 				if err != nil {
 					log.Warn(logger, "Error parsing cell axis for grant ID", "error", err)
 					sendMetric("spreadsheet.cell_parsing_errors", 1, "target:GrantID")
+					report.addCellError(rowIndex, colIndex, "GrantID", cell, err)
 					continue
 				}
 
-				hasLink, target, err := xlFile.GetCellHyperLink(sheet, cellAxis)
-				if err != nil {
-					// log this, it is not worth aborting the whole extraction for
-					log.Warn(logger, "Error getting cell hyperlink for grant ID", "error", err)
-					sendMetric("spreadsheet.cell_parsing_errors", 1, "target:GrantID")
-					continue
-				}
+				hasLink, target := hyperlinks.get(cellAxis)
 
 				// If we have a link, parse the URL for the opportunity ID which
 				// is the only way to get it from the spreadsheet
@@ -180,12 +241,15 @@ rowLoop:
 					if err != nil {
 						log.Warn(logger, "Error parsing link URL for grant ID", "error", err)
 						sendMetric("spreadsheet.cell_parsing_errors", 1, "target:GrantID")
+						report.addCellError(rowIndex, colIndex, "GrantID", target, err)
 						continue
 					}
 
 					if h := linkURL.Hostname(); h != "grants.gov" && h != "www.grants.gov" {
 						log.Warn(logger, "Link URL for grant ID has invalid domain")
 						sendMetric("spreadsheet.cell_parsing_errors", 1, "target:GrantID")
+						report.addCellError(rowIndex, colIndex, "GrantID", target,
+							fmt.Errorf("link URL has invalid domain %q", h))
 						continue
 					}
 
@@ -220,6 +284,7 @@ rowLoop:
 					if err != nil {
 						log.Warn(logger, "Error parsing opportunity ID", "error", err)
 						sendMetric("spreadsheet.cell_parsing_errors", 1, "target:GrantID")
+						report.addCellError(rowIndex, colIndex, "GrantID", target, err)
 						continue
 					}
 
@@ -255,6 +320,8 @@ rowLoop:
 					log.Warn(logger, "Could not parse DueDate according to any attempted layouts",
 						"attempted_layouts", dateLayouts, "raw_value", cell)
 					sendMetric("spreadsheet.cell_parsing_errors", 1, "target:DueDate")
+					report.addCellError(rowIndex, colIndex, "DueDate", cell,
+						fmt.Errorf("does not match any of the layouts %q", dateLayouts))
 					continue
 				}
 			case 13:
@@ -267,9 +334,21 @@ rowLoop:
 
 		// Only add valid opportunities
 		if opportunity.GrantID > 0 {
-			opportunities = append(opportunities, opportunity)
+			report.addOpportunity()
+			if err := emit(opportunity); err != nil {
+				return report, err
+			}
+		} else {
+			report.skipRow(rowIndex, SkipReasonMissingGrantID)
 		}
 	}
+	if err := rows.Error(); err != nil {
+		return report, err
+	}
+
+	report.TotalRows = rowIndex + 1
+	sendMetric("spreadsheet.row_count", float64(report.TotalRows))
+	log.Info(logger, "Parsed spreadsheet", "total_rows", report.TotalRows)
 
-	return opportunities, nil
+	return report, nil
 }
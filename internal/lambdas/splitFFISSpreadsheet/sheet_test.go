@@ -1,6 +1,8 @@
-package main
+package splitFFISSpreadsheet
 
 import (
+	"errors"
+	"io"
 	"os"
 	"testing"
 	"time"
@@ -10,6 +12,16 @@ import (
 	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/ffis"
 )
 
+// collectXLSXFile parses the Excel file stream and collects the emitted opportunities.
+func collectXLSXFile(r io.Reader, logger log.Logger) ([]ffis.FFISFundingOpportunity, *parseReport, error) {
+	var opportunities []ffis.FFISFundingOpportunity
+	report, err := parseXLSXFile(r, logger, func(opp ffis.FFISFundingOpportunity) error {
+		opportunities = append(opportunities, opp)
+		return nil
+	})
+	return opportunities, report, err
+}
+
 func TestParseXLSXFile_good(t *testing.T) {
 	excelFixture, err := os.Open("fixtures/example_spreadsheet.xlsx")
 	assert.NoError(t, err, "Error opening spreadsheet fixture")
@@ -17,7 +29,7 @@ func TestParseXLSXFile_good(t *testing.T) {
 	// Ignore logging in this test
 	logger = log.NewNopLogger()
 
-	opportunities, err := parseXLSXFile(excelFixture, logger)
+	opportunities, _, err := collectXLSXFile(excelFixture, logger)
 	assert.NoError(t, err)
 	assert.NotNil(t, opportunities)
 
@@ -80,7 +92,7 @@ func TestParseXLSXFile_cfda_format_4_digit(t *testing.T) {
 	// Ignore logging in this test
 	logger = log.NewNopLogger()
 
-	opportunities, err := parseXLSXFile(excelFixture, logger)
+	opportunities, _, err := collectXLSXFile(excelFixture, logger)
 	assert.NoError(t, err)
 	assert.NotNil(t, opportunities)
 
@@ -113,7 +125,7 @@ func TestParseXLSXFile_cfda_format_trailing_plus_sign(t *testing.T) {
 	// Ignore logging in this test
 	logger = log.NewNopLogger()
 
-	opportunities, err := parseXLSXFile(excelFixture, logger)
+	opportunities, _, err := collectXLSXFile(excelFixture, logger)
 	assert.NoError(t, err)
 	assert.NotNil(t, opportunities)
 
@@ -158,7 +170,7 @@ func TestParseXLSXFile_hyperlink_parsing_backwards_compatibility(t *testing.T) {
 	// Ignore logging in this test
 	logger = log.NewNopLogger()
 
-	opportunities, err := parseXLSXFile(excelFixture, logger)
+	opportunities, _, err := collectXLSXFile(excelFixture, logger)
 	assert.NoError(t, err)
 	assert.NotNil(t, opportunities)
 
@@ -176,3 +188,54 @@ func TestParseXLSXFile_hyperlink_parsing_backwards_compatibility(t *testing.T) {
 		assert.Equal(t, expectedRow.expectedGrantID, opportunities[idx].GrantID)
 	}
 }
+
+func TestParseXLSXFile_report(t *testing.T) {
+	excelFixture, err := os.Open("fixtures/example_spreadsheet_mixed_hyperlinks.xlsx")
+	assert.NoError(t, err, "Error opening spreadsheet fixture")
+
+	// Ignore logging in this test
+	logger = log.NewNopLogger()
+
+	_, report, err := collectXLSXFile(excelFixture, logger)
+	assert.NoError(t, err)
+	assert.NotNil(t, report)
+
+	assert.Equal(t, "Sheet1", report.Sheet)
+	assert.Equal(t, 7, report.HeaderRow)
+	assert.Equal(t, 18, report.TotalRows)
+	assert.Equal(t, 3, report.OpportunityCount)
+	assert.Equal(t, map[string]int{"GrantID": 1}, report.CellErrorCounts)
+
+	if assert.GreaterOrEqual(t, len(report.Bills), 3) {
+		assert.Equal(t, parseReportBill{"Infrastructure Investment and Jobs Act", 9, 1}, report.Bills[0])
+		assert.Equal(t, parseReportBill{"Inflation Reduction Act", 12, 1}, report.Bills[1])
+		assert.Equal(t, parseReportBill{"Department of Agriculture", 16, 1}, report.Bills[2])
+	}
+
+	// F14 has a hyperlink with an invalid domain, so its row is rejected
+	assert.Contains(t, report.SkippedRows, skippedRow{14, SkipReasonMissingGrantID})
+	assert.Contains(t, report.SkippedRows, skippedRow{8, SkipReasonBlank})
+	assert.Contains(t, report.SkippedRows, skippedRow{1, SkipReasonBeforeHeaders})
+	if assert.Len(t, report.CellErrors, 1) {
+		assert.Equal(t, "F14", report.CellErrors[0].Cell)
+		assert.Equal(t, "GrantID", report.CellErrors[0].Target)
+		assert.Equal(t, "https://not.grants.gov/search-results-detail/215125", report.CellErrors[0].Value)
+	}
+}
+
+func TestParseXLSXFile_emit_error(t *testing.T) {
+	excelFixture, err := os.Open("fixtures/example_spreadsheet.xlsx")
+	assert.NoError(t, err, "Error opening spreadsheet fixture")
+
+	// Ignore logging in this test
+	logger = log.NewNopLogger()
+
+	emitErr := errors.New("stop parsing")
+	emitted := 0
+	_, err = parseXLSXFile(excelFixture, logger, func(opp ffis.FFISFundingOpportunity) error {
+		emitted++
+		return emitErr
+	})
+	assert.ErrorIs(t, err, emitErr)
+	assert.Equal(t, 1, emitted, "Parsing continued after emit returned an error")
+}
@@ -1,10 +1,12 @@
-package main
+package splitGrantsGovXMLDB
 
 import (
 	"bufio"
 	"bytes"
 	"context"
 	"encoding/xml"
+	"errors"
+	"fmt"
 	"io"
 
 	"github.com/aws/aws-lambda-go/events"
@@ -12,6 +14,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/hashicorp/go-multierror"
 	"github.com/usdigitalresponse/grants-ingest/internal/log"
+	grantsgov "github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/grants.gov"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
 
@@ -21,7 +24,7 @@ const (
 	GRANT_FORECAST_XML_NAME    = "OpportunityForecastDetail_1_0"
 )
 
-// handleS3Event handles events representing S3 bucket notifications of type "ObjectCreated:*"
+// HandleS3Event handles events representing S3 bucket notifications of type "ObjectCreated:*"
 // for XML DB extracts saved from Grants.gov. The XML data from the source S3 object provided
 // by each event record is read from S3. Grant opportunity/forecast records are extracted from the XML
 // and uploaded to a "prepared data" destination bucket as individual S3 objects.
@@ -32,7 +35,7 @@ const (
 // a partial or complete invocation failure.
 // Returns nil when all grant records are successfully processed from all source records,
 // indicating complete success.
-func handleS3Event(ctx context.Context, s3svc *s3.Client, ddbsvc DynamoDBGetItemAPI, s3Event events.S3Event) error {
+func HandleS3Event(ctx context.Context, s3svc *s3.Client, ddbsvc DynamoDBGetItemAPI, s3Event events.S3Event) error {
 	// Create a records channel to direct opportunity/forecast values parsed from the source
 	// record to individual S3 object uploads
 	records := make(chan grantRecord)
@@ -239,6 +242,8 @@ func processRecords(ctx context.Context, s3svc *s3.Client, ddbsvc DynamoDBGetIte
 
 // processRecord takes a single record and conditionally uploads an XML representation
 // of the grant forecast/opportunity to its configured S3 destination.
+// The record is first validated against the constraints of the Grants.gov XML schema;
+// when IS_STRICT_VALIDATION_ENABLED is set, records with constraint violations are skipped.
 // Before uploading, the last-modified date of a matching extant DynamoDB item (if any)
 // is compared with the last-modified date the record on-hand.
 // An upload is initiated when the record on-hand has a last-modified date that is more recent
@@ -246,6 +251,12 @@ func processRecords(ctx context.Context, s3svc *s3.Client, ddbsvc DynamoDBGetIte
 func processRecord(ctx context.Context, s3svc S3PutObjectAPI, ddbsvc DynamoDBGetItemAPI, record grantRecord) error {
 	logger := record.logWith(logger)
 
+	if violations := validateRecord(logger, record); len(violations) > 0 && env.IsStrictValidationEnabled {
+		log.Warn(logger, "Skipping record that violates Grants.gov XML schema constraints")
+		sendMetric("record.rejected", 1)
+		return nil
+	}
+
 	lastModified, err := record.lastModified()
 	if err != nil {
 		return log.Errorf(logger, "Error getting last modified time for record", err)
@@ -291,3 +302,25 @@ func processRecord(ctx context.Context, s3svc S3PutObjectAPI, ddbsvc DynamoDBGet
 	}
 	return nil
 }
+
+// validateRecord checks the record against the constraints of the Grants.gov XML schema.
+// Any violations are logged and counted per field, and returned.
+func validateRecord(logger log.Logger, record grantRecord) grantsgov.ValidationError {
+	var violations grantsgov.ValidationError
+	if err := record.validate(); err == nil {
+		return nil
+	} else if !errors.As(err, &violations) {
+		log.Error(logger, "Error validating record", err)
+		return nil
+	}
+
+	fields := make([]string, 0, len(violations))
+	for _, v := range violations {
+		sendMetric("record.constraint_violation", 1, fmt.Sprintf("field:%s", v.Field))
+		fields = append(fields, v.Field)
+	}
+	sendMetric("record.invalid", 1)
+	log.Warn(logger, "Record violates Grants.gov XML schema constraints",
+		"invalid_fields", fields, "violations", violations.Error())
+	return violations
+}
@@ -1,4 +1,4 @@
-package main
+package splitGrantsGovXMLDB
 
 import (
 	"bytes"
@@ -433,7 +433,7 @@ func TestLambdaInvocationScenarios(t *testing.T) {
 			require.NoErrorf(t, err, "Error creating test source object %s", objectKey)
 
 			// Invoke the handler under test with a constructed S3 event
-			invocationErr := handleS3Event(context.TODO(),
+			invocationErr := HandleS3Event(context.TODO(),
 				s3client,
 				ddbGetItemReturnValues.NewGetItemClient(t),
 				events.S3Event{
@@ -518,7 +518,7 @@ func TestLambdaInvocationScenarios(t *testing.T) {
 			Body:   bytes.NewReader(sourceData.Bytes()),
 		})
 		require.NoError(t, err)
-		err = handleS3Event(context.TODO(), s3client, make(mockDDBClientGetItemCollection, 0).NewGetItemClient(t), events.S3Event{
+		err = HandleS3Event(context.TODO(), s3client, make(mockDDBClientGetItemCollection, 0).NewGetItemClient(t), events.S3Event{
 			Records: []events.S3EventRecord{
 				{S3: events.S3Entity{
 					Bucket: events.S3Bucket{Name: sourceBucketName},
@@ -552,7 +552,7 @@ func TestLambdaInvocationScenarios(t *testing.T) {
 
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel()
-		err = handleS3Event(ctx, s3client, make(mockDDBClientGetItemCollection, 0).NewGetItemClient(t), events.S3Event{
+		err = HandleS3Event(ctx, s3client, make(mockDDBClientGetItemCollection, 0).NewGetItemClient(t), events.S3Event{
 			Records: []events.S3EventRecord{
 				{S3: events.S3Entity{
 					Bucket: events.S3Bucket{Name: "source-bucket"},
@@ -781,6 +781,45 @@ func TestProcessRecord(t *testing.T) {
 		assert.True(t, putObjectCalled, "PutObject should have been called")
 	})
 
+	t.Run("invalid records", func(t *testing.T) {
+		invalidOpportunity := testOpportunity
+		invalidOpportunity.CFDANumbers = []grantsgov.CFDANumberType{"10.1", "10.002", "1O.003"}
+		invalidOpportunity.AwardFloor = "$5,000"
+
+		for _, strict := range []bool{false, true} {
+			t.Run(fmt.Sprintf("strict validation %t", strict), func(t *testing.T) {
+				setupLambdaEnvForTesting(t)
+				env.IsStrictValidationEnabled = strict
+				var metrics []string
+				defer func(original func(string, float64, ...string)) { sendMetric = original }(sendMetric)
+				sendMetric = func(metric string, value float64, tags ...string) {
+					metrics = append(metrics, strings.Join(append([]string{metric}, tags...), " "))
+				}
+				putObjectCalled := false
+				s3Client := mockPutObjectAPI(func(context.Context, *s3.PutObjectInput, ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+					putObjectCalled = true
+					return nil, nil
+				})
+
+				err := processRecord(context.TODO(), s3Client, mockDDBClientGetItemCollection{}.NewGetItemClient(t), invalidOpportunity)
+				assert.NoError(t, err)
+				assert.Equal(t, !strict, putObjectCalled, "PutObject called unless validation is strict")
+				expectedMetrics := []string{
+					"record.constraint_violation field:CFDANumbers",
+					"record.constraint_violation field:CFDANumbers",
+					"record.constraint_violation field:AwardFloor",
+					"record.invalid",
+				}
+				if strict {
+					expectedMetrics = append(expectedMetrics, "record.rejected")
+				} else {
+					expectedMetrics = append(expectedMetrics, "record.created")
+				}
+				assert.Equal(t, expectedMetrics, metrics)
+			})
+		}
+	})
+
 	t.Run("uploads to S3 when DDB item is missing", func(t *testing.T) {
 		setupLambdaEnvForTesting(t)
 		putObjectCalled := false
@@ -0,0 +1,37 @@
+// Package splitGrantsGovXMLDB provides the handler of the SplitGrantsGovXMLDB Lambda function,
+// which splits the Grants.gov database XML into individual prepared-data objects. The Lambda
+// binary is built from cmd/SplitGrantsGovXMLDB.
+package splitGrantsGovXMLDB
+
+import (
+	goenv "github.com/Netflix/go-env"
+	"github.com/usdigitalresponse/grants-ingest/internal/ddHelpers"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+)
+
+type Environment struct {
+	LogLevel                   string `env:"LOG_LEVEL,default=INFO"`
+	DownloadChunkLimit         int64  `env:"DOWNLOAD_CHUNK_LIMIT,default=10"`
+	DestinationBucket          string `env:"GRANTS_PREPARED_DATA_BUCKET_NAME,required=true"`
+	DynamoDBTableName          string `env:"GRANTS_PREPARED_DATA_TABLE_NAME,required=true"`
+	MaxConcurrentUploads       int    `env:"MAX_CONCURRENT_UPLOADS,default=1"`
+	UsePathStyleS3Opt          bool   `env:"S3_USE_PATH_STYLE,default=false"`
+	IsForecastedGrantsEnabled  bool   `env:"IS_FORECASTED_GRANTS_ENABLED,default=false"`
+	IsStrictValidationEnabled  bool   `env:"IS_STRICT_VALIDATION_ENABLED,default=false"`
+	MaxSplitRecords            int    `env:"MAX_SPLIT_RECORDS,default=-1"`             // Hard limit of records to process, regardless of type. -1 for no limit.
+	MaxSplitOpportunityRecords int    `env:"MAX_SPLIT_OPPORTUNITY_RECORDS,default=-1"` // Limit opportunity-type records to process. -1 for no limit.
+	MaxSplitForecastRecords    int    `env:"MAX_SPLIT_FORECAST_RECORDS,default=-1"`    // Limit forecast-type records to process. -1 for no limit.
+	Extras                     goenv.EnvSet
+}
+
+var (
+	env        Environment
+	logger     log.Logger
+	sendMetric = ddHelpers.NewMetricSender("SplitGrantsGovXMLDB", "source:grants.gov")
+)
+
+// Configure sets the environment and logger used when handling invocations.
+func Configure(e Environment, l log.Logger) {
+	env = e
+	logger = l
+}
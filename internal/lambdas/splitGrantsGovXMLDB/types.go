@@ -1,12 +1,12 @@
-package main
+package splitGrantsGovXMLDB
 
 import (
 	"encoding/xml"
-	"fmt"
 	"time"
 
 	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/usdigitalresponse/grants-ingest/internal/log"
+	"github.com/usdigitalresponse/grants-ingest/internal/preparedData"
 	grantsgov "github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/grants.gov"
 )
 
@@ -17,6 +17,9 @@ type grantRecord interface {
 	dynamoDBItemKey() map[string]ddbtypes.AttributeValue
 	lastModified() (time.Time, error)
 	toXML() ([]byte, error)
+	// validate returns a grantsgov.ValidationError if the record violates constraints
+	// of the Grants.gov XML schema.
+	validate() error
 }
 
 type opportunity grantsgov.OpportunitySynopsisDetail_1_0
@@ -30,9 +33,7 @@ func (o opportunity) logWith(logger log.Logger) log.Logger {
 }
 
 func (o opportunity) s3ObjectKey() string {
-	return fmt.Sprintf("%s/%s/grants.gov/v2.OpportunitySynopsisDetail_1_0.xml",
-		o.OpportunityID[0:3], o.OpportunityID,
-	)
+	return preparedData.GrantsGovOpportunity.Key(string(o.OpportunityID))
 }
 
 func (o opportunity) dynamoDBItemKey() map[string]ddbtypes.AttributeValue {
@@ -49,6 +50,10 @@ func (o opportunity) toXML() ([]byte, error) {
 	return xml.Marshal(grantsgov.OpportunitySynopsisDetail_1_0(o))
 }
 
+func (o opportunity) validate() error {
+	return grantsgov.OpportunitySynopsisDetail_1_0(o).Validate()
+}
+
 type forecast grantsgov.OpportunityForecastDetail_1_0
 
 func (f forecast) logWith(logger log.Logger) log.Logger {
@@ -60,9 +65,7 @@ func (f forecast) logWith(logger log.Logger) log.Logger {
 }
 
 func (f forecast) s3ObjectKey() string {
-	return fmt.Sprintf("%s/%s/grants.gov/v2.OpportunityForecastDetail_1_0.xml",
-		f.OpportunityID[0:3], f.OpportunityID,
-	)
+	return preparedData.GrantsGovForecast.Key(string(f.OpportunityID))
 }
 
 func (f forecast) lastModified() (time.Time, error) {
@@ -73,6 +76,10 @@ func (f forecast) toXML() ([]byte, error) {
 	return xml.Marshal(grantsgov.OpportunityForecastDetail_1_0(f))
 }
 
+func (f forecast) validate() error {
+	return grantsgov.OpportunityForecastDetail_1_0(f).Validate()
+}
+
 func (f forecast) dynamoDBItemKey() map[string]ddbtypes.AttributeValue {
 	return map[string]ddbtypes.AttributeValue{
 		"grant_id": &ddbtypes.AttributeValueMemberS{Value: string(f.OpportunityID)},
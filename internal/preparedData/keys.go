@@ -0,0 +1,83 @@
+// Package preparedData defines the layout of object keys in the grants prepared-data S3 bucket.
+//
+// Data for each grant is stored under a key prefix formed from the first three digits of the
+// grant ID and the full grant ID (e.g. "123/123456/"), followed by a source-specific file name.
+package preparedData
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AssistanceListingsKey is the key of the SAM.gov Assistance Listings catalog that is used to
+// enrich published grants. It is not specific to any grant.
+const AssistanceListingsKey = "sam.gov/assistance_listings/v1.json"
+
+// Layout describes the keys of one kind of prepared-data object stored for each grant.
+type Layout struct {
+	// Name describes the kind of object
+	Name string
+	// File is the part of the key that follows the grant ID prefix
+	File string
+}
+
+var (
+	// GrantsGovOpportunity objects contain Grants.gov OpportunitySynopsisDetail_1_0 XML.
+	GrantsGovOpportunity = Layout{"Grants.gov opportunity", "grants.gov/v2.OpportunitySynopsisDetail_1_0.xml"}
+	// GrantsGovForecast objects contain Grants.gov OpportunityForecastDetail_1_0 XML.
+	GrantsGovForecast = Layout{"Grants.gov forecast", "grants.gov/v2.OpportunityForecastDetail_1_0.xml"}
+	// GrantsGovLegacy objects contain Grants.gov OpportunitySynopsisDetail_1_0 XML written before
+	// forecasts were ingested. They are no longer written, but may still exist.
+	GrantsGovLegacy = Layout{"Grants.gov opportunity (legacy)", "grants.gov/v2.xml"}
+	// FFIS objects contain FFIS.org opportunity JSON.
+	FFIS = Layout{"FFIS.org opportunity", "ffis.org/v1.json"}
+
+	// GrantsGovLayouts are the layouts of all objects containing Grants.gov data.
+	GrantsGovLayouts = []Layout{GrantsGovOpportunity, GrantsGovForecast, GrantsGovLegacy}
+	// Layouts are the layouts of all per-grant objects in the prepared-data bucket.
+	Layouts = []Layout{GrantsGovOpportunity, GrantsGovForecast, GrantsGovLegacy, FFIS}
+)
+
+// Key returns the object key for the grant with the given ID.
+// Grant IDs must have at least three characters.
+func (l Layout) Key(grantID string) string {
+	return fmt.Sprintf("%s/%s/%s", grantID[:3], grantID, l.File)
+}
+
+// Suffix returns the suffix common to all keys of the layout, suitable for filtering
+// S3 bucket notifications.
+func (l Layout) Suffix() string {
+	return "/" + l.File
+}
+
+// GrantID returns the ID of the grant for a key of this layout, or false if the key
+// does not belong to the layout.
+func (l Layout) GrantID(key string) (string, bool) {
+	rest, ok := strings.CutSuffix(key, l.Suffix())
+	if !ok {
+		return "", false
+	}
+	prefix, grantID, ok := strings.Cut(rest, "/")
+	if !ok || len(prefix) != 3 || !isDigits(grantID) || !strings.HasPrefix(grantID, prefix) {
+		return "", false
+	}
+	return grantID, true
+}
+
+// Match reports whether key belongs to the layout.
+func (l Layout) Match(key string) bool {
+	_, ok := l.GrantID(key)
+	return ok
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
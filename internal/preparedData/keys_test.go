@@ -0,0 +1,44 @@
+package preparedData
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayoutKey(t *testing.T) {
+	assert.Equal(t, "123/123456/grants.gov/v2.OpportunitySynopsisDetail_1_0.xml",
+		GrantsGovOpportunity.Key("123456"))
+	assert.Equal(t, "123/123456/grants.gov/v2.OpportunityForecastDetail_1_0.xml",
+		GrantsGovForecast.Key("123456"))
+	assert.Equal(t, "123/123456/ffis.org/v1.json", FFIS.Key("123456"))
+	assert.Equal(t, "/ffis.org/v1.json", FFIS.Suffix())
+}
+
+func TestLayoutGrantID(t *testing.T) {
+	for _, tt := range []struct {
+		layout  Layout
+		key     string
+		grantID string
+		ok      bool
+	}{
+		{GrantsGovOpportunity, "123/123456/grants.gov/v2.OpportunitySynopsisDetail_1_0.xml", "123456", true},
+		{GrantsGovForecast, "123/123456/grants.gov/v2.OpportunityForecastDetail_1_0.xml", "123456", true},
+		{GrantsGovLegacy, "123/123456/grants.gov/v2.xml", "123456", true},
+		{FFIS, "123/123/ffis.org/v1.json", "123", true},
+		{GrantsGovOpportunity, "123/123456/grants.gov/v2.OpportunityForecastDetail_1_0.xml", "", false},
+		{GrantsGovLegacy, "123/123456/grants.gov/v2.OpportunitySynopsisDetail_1_0.xml", "", false},
+		{FFIS, "124/123456/ffis.org/v1.json", "", false},
+		{FFIS, "12/12/ffis.org/v1.json", "", false},
+		{FFIS, "123/123abc/ffis.org/v1.json", "", false},
+		{FFIS, "prefix/123/123456/ffis.org/v1.json", "", false},
+		{FFIS, "ffis.org/v1.json", "", false},
+	} {
+		t.Run(tt.key, func(t *testing.T) {
+			grantID, ok := tt.layout.GrantID(tt.key)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.grantID, grantID)
+			assert.Equal(t, tt.ok, tt.layout.Match(tt.key))
+		})
+	}
+}
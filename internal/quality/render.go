@@ -0,0 +1,96 @@
+package quality
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// ContentTypes are the content types of each report format.
+var ContentTypes = map[string]string{
+	"json": "application/json",
+	"html": "text/html; charset=utf-8",
+}
+
+// Write writes the report to w in the given format ("json" or "html").
+func (r Report) Write(w io.Writer, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case "html":
+		return htmlTemplate.Execute(w, r)
+	}
+	return fmt.Errorf("unsupported report format %q", format)
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"percent": func(rate float64) string { return fmt.Sprintf("%.2f%%", rate*100) },
+	"join":    strings.Join,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Grants data quality report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.25em 0.75em; text-align: left; vertical-align: top; }
+td.number { text-align: right; }
+td.samples { font-family: monospace; font-size: smaller; }
+</style>
+</head>
+<body>
+<h1>Grants data quality report</h1>
+<p>Generated {{ .GeneratedAt.Format "2006-01-02 15:04:05 MST" }} from {{ .Source }} ({{ .Items }} items).</p>
+
+<h2>Summary</h2>
+<table>
+<tr><th></th><th>Items</th><th>Rate</th><th>Sample grant IDs</th></tr>
+{{- with .ItemsWithProblems }}
+<tr><th>Items with problems</th>{{ template "score" . }}</tr>
+{{- end }}
+{{- with .UnbuildableItems }}
+<tr><th>Unbuildable items</th>{{ template "score" . }}</tr>
+{{- end }}
+{{- with .InvalidGrants }}
+<tr><th>Invalid grant data</th>{{ template "score" . }}</tr>
+{{- end }}
+</table>
+
+<h2>Malformed fields</h2>
+{{ template "namedScores" .MalformedFields }}
+
+<h2>Validation errors</h2>
+{{ template "namedScores" .ValidationErrors }}
+
+<h2>Agencies</h2>
+<table>
+<tr><th>Agency</th><th>Items</th><th>Items with problems</th><th>Rate</th><th>Sample grant IDs</th></tr>
+{{- range .Agencies }}
+<tr><td>{{ .AgencyCode }}</td><td class="number">{{ .Items }}</td>{{ template "score" .Score }}</tr>
+{{- end }}
+</table>
+</body>
+</html>
+
+{{- define "score" -}}
+<td class="number">{{ .Count }}</td><td class="number">{{ percent .Rate }}</td><td class="samples">{{ join .SampleGrantIDs " " }}</td>
+{{- end }}
+
+{{- define "namedScores" }}
+{{- if . }}
+<table>
+<tr><th></th><th>Items</th><th>Rate</th><th>Sample grant IDs</th></tr>
+{{- range . }}
+<tr><th>{{ .Name }}</th>{{ template "score" .Score }}</tr>
+{{- end }}
+</table>
+{{- else }}
+<p>None.</p>
+{{- end }}
+{{- end }}
+`))
@@ -0,0 +1,227 @@
+// Package quality scores the data quality of items in the prepared-data DynamoDB table.
+// Each item is mapped to grant data in the same manner as PublishGrantEvents, and any
+// malformed item attributes, mapping failures, and grant data validation errors are counted
+// per field and per agency.
+package quality
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/hashicorp/go-multierror"
+	"github.com/usdigitalresponse/grants-ingest/internal/itemMapper"
+)
+
+// NoAgency is the agency code under which items without an agency code are counted.
+const NoAgency = "(none)"
+
+// Scorecard accumulates data quality scores for scanned items.
+// A Scorecard is not safe for concurrent use.
+type Scorecard struct {
+	sampleSize  int
+	total       int
+	problems    *tally
+	unbuildable *tally
+	invalid     *tally
+	fields      map[string]*tally
+	validations map[string]*tally
+	agencies    map[string]*agencyTally
+}
+
+type tally struct {
+	count   int
+	samples []string
+}
+
+func (t *tally) add(grantID string, sampleSize int) {
+	t.count++
+	if len(t.samples) < sampleSize {
+		t.samples = append(t.samples, grantID)
+	}
+}
+
+type agencyTally struct {
+	items int
+	tally
+}
+
+// NewScorecard returns an empty Scorecard that keeps up to sampleSize offending grant IDs
+// for each score.
+func NewScorecard(sampleSize int) *Scorecard {
+	return &Scorecard{
+		sampleSize:  sampleSize,
+		problems:    &tally{},
+		unbuildable: &tally{},
+		invalid:     &tally{},
+		fields:      make(map[string]*tally),
+		validations: make(map[string]*tally),
+		agencies:    make(map[string]*agencyTally),
+	}
+}
+
+// Add scores a prepared-data table item.
+func (s *Scorecard) Add(item map[string]types.AttributeValue) {
+	grantID := stringAttr(item, "grant_id")
+	agency := stringAttr(item, "AgencyCode")
+	if agency == "" {
+		agency = NoAgency
+	}
+	s.total++
+	if s.agencies[agency] == nil {
+		s.agencies[agency] = &agencyTally{}
+	}
+	s.agencies[agency].items++
+
+	malformed := map[string]bool{}
+	validationErrs, buildErr := checkItem(item, func(name string, _ error) { malformed[name] = true })
+	for name := range malformed {
+		s.tallyFor(s.fields, name).add(grantID, s.sampleSize)
+	}
+	if buildErr != nil {
+		s.unbuildable.add(grantID, s.sampleSize)
+	}
+	if len(validationErrs) > 0 {
+		s.invalid.add(grantID, s.sampleSize)
+		seen := map[string]bool{}
+		for _, err := range validationErrs {
+			if msg := err.Error(); !seen[msg] {
+				seen[msg] = true
+				s.tallyFor(s.validations, msg).add(grantID, s.sampleSize)
+			}
+		}
+	}
+
+	if len(malformed) > 0 || buildErr != nil || len(validationErrs) > 0 {
+		s.problems.add(grantID, s.sampleSize)
+		s.agencies[agency].add(grantID, s.sampleSize)
+	}
+}
+
+func (s *Scorecard) tallyFor(m map[string]*tally, key string) *tally {
+	if m[key] == nil {
+		m[key] = &tally{}
+	}
+	return m[key]
+}
+
+// checkItem maps the item to grant data, calling onMalformedField for each malformed item
+// attribute. It returns the validation errors of the mapped grant data, or an error if the item
+// could not be mapped.
+func checkItem(item map[string]types.AttributeValue, onMalformedField itemMapper.MalformedFieldFunc) ([]error, error) {
+	image, err := itemMapper.FromAttributeValueMap(item)
+	if err != nil {
+		return nil, err
+	}
+	grant, err := itemMapper.GuardPanic(itemMapper.NewItemMapper(image, onMalformedField).Grant)
+	if err != nil {
+		return nil, err
+	}
+	if err := grant.Validate(); err != nil {
+		var merr *multierror.Error
+		if errors.As(err, &merr) {
+			return merr.Errors, nil
+		}
+		return []error{err}, nil
+	}
+	return nil, nil
+}
+
+// Report is a data quality report produced from a Scorecard.
+type Report struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Source      string    `json:"source"`
+	Items       int       `json:"items"`
+	// Items with at least one malformed field, mapping failure, or validation error
+	ItemsWithProblems Score `json:"items_with_problems"`
+	// Items that could not be mapped to grant data
+	UnbuildableItems Score `json:"unbuildable_items"`
+	// Items mapped to grant data that fails validation
+	InvalidGrants Score `json:"invalid_grants"`
+	// Items with malformed attributes, by attribute name
+	MalformedFields []NamedScore `json:"malformed_fields"`
+	// Items with grant data validation errors, by error message
+	ValidationErrors []NamedScore `json:"validation_errors"`
+	// Items with problems, by agency code
+	Agencies []AgencyScore `json:"agencies"`
+}
+
+// Score counts items with a problem.
+type Score struct {
+	Count int `json:"count"`
+	// Ratio of Count to the number of items scored
+	Rate           float64  `json:"rate"`
+	SampleGrantIDs []string `json:"sample_grant_ids"`
+}
+
+// NamedScore is a Score for a single field or validation error.
+type NamedScore struct {
+	Name string `json:"name"`
+	Score
+}
+
+// AgencyScore is a Score for the items of a single agency.
+type AgencyScore struct {
+	AgencyCode string `json:"agency_code"`
+	Items      int    `json:"items"`
+	// Rate is relative to the number of items of the agency
+	Score
+}
+
+// Report returns a report of the scores accumulated so far. Named scores are ordered by
+// descending count, and agency scores by descending rate.
+func (s *Scorecard) Report(source string, generatedAt time.Time) Report {
+	r := Report{
+		GeneratedAt:       generatedAt,
+		Source:            source,
+		Items:             s.total,
+		ItemsWithProblems: newScore(s.problems, s.total),
+		UnbuildableItems:  newScore(s.unbuildable, s.total),
+		InvalidGrants:     newScore(s.invalid, s.total),
+		MalformedFields:   namedScores(s.fields, s.total),
+		ValidationErrors:  namedScores(s.validations, s.total),
+		Agencies:          make([]AgencyScore, 0, len(s.agencies)),
+	}
+	for code, t := range s.agencies {
+		r.Agencies = append(r.Agencies, AgencyScore{code, t.items, newScore(&t.tally, t.items)})
+	}
+	sort.Slice(r.Agencies, func(i, j int) bool {
+		a, b := r.Agencies[i], r.Agencies[j]
+		if a.Rate != b.Rate {
+			return a.Rate > b.Rate
+		}
+		return a.AgencyCode < b.AgencyCode
+	})
+	return r
+}
+
+func newScore(t *tally, total int) Score {
+	score := Score{Count: t.count, SampleGrantIDs: append([]string{}, t.samples...)}
+	if total > 0 {
+		score.Rate = float64(t.count) / float64(total)
+	}
+	sort.Strings(score.SampleGrantIDs)
+	return score
+}
+
+func namedScores(m map[string]*tally, total int) []NamedScore {
+	scores := make([]NamedScore, 0, len(m))
+	for name, t := range m {
+		scores = append(scores, NamedScore{name, newScore(t, total)})
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Count != scores[j].Count {
+			return scores[i].Count > scores[j].Count
+		}
+		return scores[i].Name < scores[j].Name
+	})
+	return scores
+}
+
+func stringAttr(item map[string]types.AttributeValue, name string) string {
+	if v, ok := item[name].(*types.AttributeValueMemberS); ok {
+		return v.Value
+	}
+	return ""
+}
@@ -0,0 +1,102 @@
+package quality
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validItem(grantID, agencyCode string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"grant_id":                         &types.AttributeValueMemberS{Value: grantID},
+		"revision":                         &types.AttributeValueMemberS{Value: "01H1X3QK8NMJ6G0V5AXN9V3EZZ"},
+		"OpportunityID":                    &types.AttributeValueMemberS{Value: grantID},
+		"OpportunityNumber":                &types.AttributeValueMemberS{Value: "ABC-123"},
+		"OpportunityTitle":                 &types.AttributeValueMemberS{Value: "Test grant"},
+		"OpportunityCategory":              &types.AttributeValueMemberS{Value: "D"},
+		"AgencyCode":                       &types.AttributeValueMemberS{Value: agencyCode},
+		"PostDate":                         &types.AttributeValueMemberS{Value: "01022024"},
+		"LastUpdatedDate":                  &types.AttributeValueMemberS{Value: "01032024"},
+		"CostSharingOrMatchingRequirement": &types.AttributeValueMemberS{Value: "No"},
+	}
+}
+
+func TestScorecard(t *testing.T) {
+	s := NewScorecard(2)
+	s.Add(validItem("1001", "HHS"))
+	s.Add(validItem("1002", "HHS"))
+
+	malformedDate := validItem("1003", "HHS-NIH11")
+	malformedDate["PostDate"] = &types.AttributeValueMemberS{Value: "2024-01-02"}
+	s.Add(malformedDate)
+
+	missingTitle := validItem("1004", "DOE")
+	delete(missingTitle, "OpportunityTitle")
+	missingTitle["CostSharingOrMatchingRequirement"] = &types.AttributeValueMemberS{Value: "maybe"}
+	s.Add(missingTitle)
+
+	unbuildable := validItem("1005", "")
+	unbuildable["EligibleApplicants"] = &types.AttributeValueMemberS{Value: "25"}
+	s.Add(unbuildable)
+
+	generatedAt := time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)
+	r := s.Report("test-table", generatedAt)
+
+	assert.Equal(t, generatedAt, r.GeneratedAt)
+	assert.Equal(t, "test-table", r.Source)
+	assert.Equal(t, 5, r.Items)
+	assert.Equal(t, Score{3, 0.6, []string{"1003", "1004"}}, r.ItemsWithProblems)
+	assert.Equal(t, Score{1, 0.2, []string{"1005"}}, r.UnbuildableItems)
+	assert.Equal(t, Score{2, 0.4, []string{"1003", "1004"}}, r.InvalidGrants)
+	assert.Equal(t, []NamedScore{
+		{"CostSharingOrMatchingRequirement", Score{1, 0.2, []string{"1004"}}},
+		{"PostDate", Score{1, 0.2, []string{"1003"}}},
+	}, r.MalformedFields)
+	assert.Equal(t, []NamedScore{
+		{"cannot be empty: Title", Score{1, 0.2, []string{"1004"}}},
+		{"cannot be nil: PostDate", Score{1, 0.2, []string{"1003"}}},
+	}, r.ValidationErrors)
+	assert.Equal(t, []AgencyScore{
+		{NoAgency, 1, Score{1, 1, []string{"1005"}}},
+		{"DOE", 1, Score{1, 1, []string{"1004"}}},
+		{"HHS-NIH11", 1, Score{1, 1, []string{"1003"}}},
+		{"HHS", 2, Score{0, 0, []string{}}},
+	}, r.Agencies)
+}
+
+func TestReportWrite(t *testing.T) {
+	s := NewScorecard(10)
+	item := validItem("1001", "HHS")
+	delete(item, "OpportunityTitle")
+	s.Add(item)
+	r := s.Report("test-table", time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC))
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, r.Write(&buf, "json"))
+		var decoded map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+		assert.Equal(t, "test-table", decoded["source"])
+		assert.Equal(t, []any{map[string]any{
+			"agency_code": "HHS", "items": float64(1), "count": float64(1), "rate": float64(1),
+			"sample_grant_ids": []any{"1001"},
+		}}, decoded["agencies"])
+	})
+
+	t.Run("html", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, r.Write(&buf, "html"))
+		assert.Contains(t, buf.String(), "<th>cannot be empty: Title</th>")
+		assert.Contains(t, buf.String(), "<td>HHS</td>")
+		assert.Contains(t, buf.String(), "100.00%")
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		assert.Error(t, r.Write(&bytes.Buffer{}, "xml"))
+	})
+}
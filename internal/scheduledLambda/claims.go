@@ -0,0 +1,85 @@
+package scheduledLambda
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+)
+
+type DynamoDBClaimsAPI interface {
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+}
+
+// Claim identifies an event about a grant, which is recorded when it is published so that it is
+// not published again by later invocations.
+type Claim struct {
+	GrantID string
+	// Identifies the event among those published for the grant
+	Event string
+	// Time after which DynamoDB may delete the record (using the expires_at TTL attribute),
+	// which should be later than any invocation that could publish the event again.
+	ExpiresAt time.Time
+}
+
+// ClaimTable records claimed events in a DynamoDB table whose partition key is grant_id and whose
+// sort key, named by SortKey, is the Event of each claim.
+type ClaimTable struct {
+	Client    DynamoDBClaimsAPI
+	TableName string
+	SortKey   string
+}
+
+func (t ClaimTable) key(c Claim) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"grant_id": &types.AttributeValueMemberS{Value: c.GrantID},
+		t.SortKey:  &types.AttributeValueMemberS{Value: c.Event},
+	}
+}
+
+// Claim records the event as published at the given time. It returns false if the event was
+// already recorded, in which case it should not be published again.
+func (t ClaimTable) Claim(ctx context.Context, c Claim, now time.Time) (bool, error) {
+	item := t.key(c)
+	item["published_at"] = &types.AttributeValueMemberS{Value: now.UTC().Format(time.RFC3339)}
+	item["expires_at"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(c.ExpiresAt.Unix(), 10)}
+	expr, err := expression.NewBuilder().WithCondition(
+		expression.AttributeNotExists(expression.Name("grant_id")),
+	).Build()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = t.Client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                 aws.String(t.TableName),
+		Item:                      item,
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ConditionExpression:       expr.Condition(),
+	})
+	var conditionalCheckErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionalCheckErr) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Release deletes the record of an event that could not be published, so that publishing it is
+// attempted again by a later invocation. The record is deleted even when ctx has been canceled,
+// and errors are logged rather than returned, so that Release can be used as Entry.OnFailure.
+func (t ClaimTable) Release(ctx context.Context, logger log.Logger, c Claim) {
+	if _, err := t.Client.DeleteItem(context.WithoutCancel(ctx), &dynamodb.DeleteItemInput{
+		TableName: aws.String(t.TableName),
+		Key:       t.key(c),
+	}); err != nil {
+		log.Error(log.With(logger, "grant_id", c.GrantID, "event", c.Event),
+			"Error releasing claim of unpublished event", err)
+	}
+}
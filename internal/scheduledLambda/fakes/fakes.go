@@ -0,0 +1,166 @@
+// Package fakes provides fake AWS clients and prepared-data items for testing scheduled Lambda
+// functions.
+package fakes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+
+	goenv "github.com/Netflix/go-env"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	kitLog "github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+)
+
+// SetupLambdaEnv suppresses the output of logger and configures env from the given environment
+// variables.
+func SetupLambdaEnv(t *testing.T, logger *log.Logger, env any, vars goenv.EnvSet) {
+	t.Helper()
+
+	// Suppress normal lambda log output
+	*logger = kitLog.NewNopLogger()
+
+	// Configure environment variables
+	err := goenv.Unmarshal(vars, env)
+	require.NoError(t, err, "Error configuring lambda environment for testing")
+}
+
+// ScanClient returns pages of items from Scan requests.
+type ScanClient struct {
+	Pages    [][]map[string]ddbtypes.AttributeValue
+	Err      error
+	FailPage int            // Index of the page for which Err is returned
+	OnPage   func(page int) // Called after each page is scanned
+}
+
+func (c *ScanClient) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	page := 0
+	if params.ExclusiveStartKey != nil {
+		page, _ = strconv.Atoi(params.ExclusiveStartKey["page"].(*ddbtypes.AttributeValueMemberN).Value)
+	}
+	if c.Err != nil && page == c.FailPage {
+		return nil, c.Err
+	}
+	if c.OnPage != nil {
+		defer c.OnPage(page)
+	}
+	out := &dynamodb.ScanOutput{Items: c.Pages[page]}
+	if page+1 < len(c.Pages) {
+		out.LastEvaluatedKey = map[string]ddbtypes.AttributeValue{
+			"page": &ddbtypes.AttributeValueMemberN{Value: strconv.Itoa(page + 1)},
+		}
+	}
+	return out, nil
+}
+
+// ClaimClient stores claim items keyed by "<grant_id>|<sort key>", and fails conditional puts of
+// existing items.
+type ClaimClient struct {
+	SortKey    string
+	Items      map[string]map[string]ddbtypes.AttributeValue
+	FailGrants map[string]bool // IDs of grants whose claims cannot be put
+}
+
+func NewClaimClient(sortKey string) *ClaimClient {
+	return &ClaimClient{SortKey: sortKey, Items: make(map[string]map[string]ddbtypes.AttributeValue)}
+}
+
+func (c *ClaimClient) key(item map[string]ddbtypes.AttributeValue) string {
+	return item["grant_id"].(*ddbtypes.AttributeValueMemberS).Value + "|" +
+		item[c.SortKey].(*ddbtypes.AttributeValueMemberS).Value
+}
+
+func (c *ClaimClient) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	if c.FailGrants[params.Item["grant_id"].(*ddbtypes.AttributeValueMemberS).Value] {
+		return nil, errors.New("put failed")
+	}
+	key := c.key(params.Item)
+	if _, exists := c.Items[key]; exists && params.ConditionExpression != nil {
+		return nil, &ddbtypes.ConditionalCheckFailedException{Message: aws.String("exists")}
+	}
+	c.Items[key] = params.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (c *ClaimClient) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	delete(c.Items, c.key(params.Key))
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+// EventBridge records PutEvents requests.
+type EventBridge struct {
+	Calls      []*eventbridge.PutEventsInput
+	FailGrants map[string]bool // IDs of grants whose entries are reported as failed
+	Err        error
+}
+
+func (m *EventBridge) PutEvents(ctx context.Context, p *eventbridge.PutEventsInput, _ ...func(*eventbridge.Options)) (*eventbridge.PutEventsOutput, error) {
+	m.Calls = append(m.Calls, p)
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	out := &eventbridge.PutEventsOutput{Entries: make([]types.PutEventsResultEntry, len(p.Entries))}
+	for i, entry := range p.Entries {
+		var detail struct {
+			Grant struct {
+				Opportunity struct {
+					ID string `json:"id"`
+				} `json:"opportunity"`
+			} `json:"grant"`
+		}
+		if err := json.Unmarshal([]byte(aws.ToString(entry.Detail)), &detail); err == nil &&
+			m.FailGrants[detail.Grant.Opportunity.ID] {
+			out.Entries[i].ErrorCode = aws.String("InternalFailure")
+			out.FailedEntryCount++
+		}
+	}
+	return out, nil
+}
+
+// Entries returns the entries of every PutEvents request.
+func (m *EventBridge) Entries() []types.PutEventsRequestEntry {
+	var entries []types.PutEventsRequestEntry
+	for _, call := range m.Calls {
+		entries = append(entries, call.Entries...)
+	}
+	return entries
+}
+
+// Item returns a prepared-data item for a grant, with additional string attributes.
+// Attributes with empty values are omitted.
+func Item(grantID string, isForecast bool, attrs map[string]string) map[string]ddbtypes.AttributeValue {
+	item := map[string]ddbtypes.AttributeValue{
+		"grant_id":                         &ddbtypes.AttributeValueMemberS{Value: grantID},
+		"revision":                         &ddbtypes.AttributeValueMemberS{Value: "01H1X3QK8NMJ6G0V5AXN9V3EZZ"},
+		"OpportunityID":                    &ddbtypes.AttributeValueMemberS{Value: grantID},
+		"OpportunityNumber":                &ddbtypes.AttributeValueMemberS{Value: "ABC-123"},
+		"OpportunityTitle":                 &ddbtypes.AttributeValueMemberS{Value: "Test grant"},
+		"OpportunityCategory":              &ddbtypes.AttributeValueMemberS{Value: "D"},
+		"PostDate":                         &ddbtypes.AttributeValueMemberS{Value: "01022024"},
+		"LastUpdatedDate":                  &ddbtypes.AttributeValueMemberS{Value: "01032024"},
+		"CostSharingOrMatchingRequirement": &ddbtypes.AttributeValueMemberS{Value: "No"},
+		"is_forecast":                      &ddbtypes.AttributeValueMemberBOOL{Value: isForecast},
+	}
+	for k, v := range attrs {
+		if v == "" {
+			delete(item, k)
+		} else {
+			item[k] = &ddbtypes.AttributeValueMemberS{Value: v}
+		}
+	}
+	return item
+}
@@ -0,0 +1,51 @@
+package scheduledLambda
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/usdigitalresponse/grants-ingest/internal/itemMapper"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+	"github.com/usdigitalresponse/grants-ingest/pkg/clock"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/usdr"
+)
+
+// MalformattedFieldLogger returns an itemMapper.MalformedFieldFunc that logs and reports a metric
+// for each item attribute that could not be mapped.
+func MalformattedFieldLogger(logger log.Logger, sendMetric MetricSender) itemMapper.MalformedFieldFunc {
+	return func(name string, err error) {
+		logger := log.With(logger, "field", name)
+		if err != nil {
+			logger = log.With(logger, "error", err)
+		}
+		log.Warn(logger, "Could not parse field")
+		sendMetric("item_image.malformatted_field", 1, fmt.Sprintf("field:%s", name))
+	}
+}
+
+// BuildGrant builds the grant for a prepared-data item, with a lifecycle status computed as of now.
+// It returns false if the grant cannot be built.
+func BuildGrant(logger log.Logger, sendMetric MetricSender, item map[string]types.AttributeValue, now clock.Clock) (usdr.Grant, bool) {
+	image, err := itemMapper.FromAttributeValueMap(item)
+	if err != nil {
+		sendMetric("item_image.unbuildable", 1)
+		log.Error(logger, "Error converting prepared-data item", err)
+		return usdr.Grant{}, false
+	}
+	mapper := itemMapper.NewItemMapper(image, MalformattedFieldLogger(logger, sendMetric)).WithClock(now)
+	grant, err := itemMapper.GuardPanic(mapper.Grant)
+	if err != nil {
+		sendMetric("item_image.unbuildable", 1)
+		log.Error(logger, "Error building grant from prepared-data item", err)
+		return usdr.Grant{}, false
+	}
+	return grant, true
+}
+
+// ValidateGrant logs and reports a metric if the grant is invalid.
+func ValidateGrant(logger log.Logger, sendMetric MetricSender, grant usdr.Grant) {
+	if err := grant.Validate(); err != nil {
+		sendMetric("grant_data.invalid", 1)
+		log.Warn(logger, "grant data from ItemMapper is invalid", "error", err)
+	}
+}
@@ -0,0 +1,106 @@
+package scheduledLambda
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+)
+
+// MaxEntriesPerPutEvents is the maximum number of entries accepted by a PutEvents request.
+const MaxEntriesPerPutEvents = 10
+
+// Entry is an event to publish to EventBridge.
+type Entry struct {
+	types.PutEventsRequestEntry
+	// ID of the grant that the event is about, which is logged if the event is not published
+	GrantID string
+	// Called if the event is not published, e.g. to allow a later invocation to publish it
+	OnFailure func()
+}
+
+// Publisher publishes entries to EventBridge in batches of up to MaxEntriesPerPutEvents.
+// Entries that EventBridge fails to publish are logged but do not cause an error, since retrying
+// the invocation would republish the entries that succeeded.
+type Publisher struct {
+	pub        EventBridgePutEventsAPI
+	logger     log.Logger
+	sendMetric MetricSender
+	pending    []Entry
+
+	// Numbers of entries that were published or failed to publish
+	Published, Failed int
+}
+
+func NewPublisher(pub EventBridgePutEventsAPI, logger log.Logger, sendMetric MetricSender) *Publisher {
+	return &Publisher{
+		pub:        pub,
+		logger:     logger,
+		sendMetric: sendMetric,
+		pending:    make([]Entry, 0, MaxEntriesPerPutEvents),
+	}
+}
+
+// Add adds an entry to the current batch, which is published once it is full.
+func (p *Publisher) Add(ctx context.Context, entry Entry) error {
+	p.pending = append(p.pending, entry)
+	if len(p.pending) < MaxEntriesPerPutEvents {
+		return nil
+	}
+	return p.Flush(ctx)
+}
+
+// Flush publishes the current batch. If the PutEvents request fails, every entry in the batch
+// is failed and the error is returned.
+func (p *Publisher) Flush(ctx context.Context) error {
+	if len(p.pending) == 0 {
+		return nil
+	}
+	batch := p.pending
+	p.pending = make([]Entry, 0, MaxEntriesPerPutEvents)
+
+	entries := make([]types.PutEventsRequestEntry, len(batch))
+	for i, e := range batch {
+		entries[i] = e.PutEventsRequestEntry
+	}
+	out, err := p.pub.PutEvents(ctx, &eventbridge.PutEventsInput{Entries: entries})
+	if err != nil {
+		for _, e := range batch {
+			p.fail(e)
+		}
+		p.sendMetric("event.failed", float64(len(batch)))
+		return err
+	}
+
+	published := len(batch)
+	for i, result := range out.Entries {
+		if result.ErrorCode == nil {
+			continue
+		}
+		published--
+		log.Warn(p.logger, "EventBridge failed to publish event", "grant_id", batch[i].GrantID,
+			"error_code", result.ErrorCode, "error_message", result.ErrorMessage)
+		p.fail(batch[i])
+	}
+	p.Published += published
+	p.sendMetric("event.published", float64(published))
+	p.sendMetric("event.failed", float64(len(batch)-published))
+	return nil
+}
+
+// Discard fails the entries of the current batch without publishing them. It should be called
+// when an invocation returns early, e.g. because scanning failed or its context was canceled.
+func (p *Publisher) Discard() {
+	for _, e := range p.pending {
+		p.fail(e)
+	}
+	p.pending = p.pending[:0]
+}
+
+func (p *Publisher) fail(e Entry) {
+	p.Failed++
+	if e.OnFailure != nil {
+		e.OnFailure()
+	}
+}
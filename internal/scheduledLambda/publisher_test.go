@@ -0,0 +1,75 @@
+package scheduledLambda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	kitLog "github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/usdigitalresponse/grants-ingest/internal/scheduledLambda/fakes"
+)
+
+func noopMetric(string, float64, ...string) {}
+
+// testEntry returns an entry for the grant that records its ID in failed if it is not published.
+func testEntry(grantID string, failed *[]string) Entry {
+	return Entry{
+		PutEventsRequestEntry: types.PutEventsRequestEntry{
+			Detail: aws.String(fmt.Sprintf(`{"grant":{"opportunity":{"id":%q}}}`, grantID)),
+		},
+		GrantID:   grantID,
+		OnFailure: func() { *failed = append(*failed, grantID) },
+	}
+}
+
+func TestPublisher(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("publishes in batches", func(t *testing.T) {
+		pub := &fakes.EventBridge{FailGrants: map[string]bool{"3": true, "12": true}}
+		publisher := NewPublisher(pub, kitLog.NewNopLogger(), noopMetric)
+		var failed []string
+		for i := 0; i < 25; i++ {
+			require.NoError(t, publisher.Add(ctx, testEntry(fmt.Sprint(i), &failed)))
+		}
+		assert.Len(t, pub.Calls, 2, "Full batches should be published as entries are added")
+		require.NoError(t, publisher.Flush(ctx))
+		require.Len(t, pub.Calls, 3)
+		assert.Len(t, pub.Calls[2].Entries, 5)
+		require.NoError(t, publisher.Flush(ctx))
+		assert.Len(t, pub.Calls, 3, "Empty batches should not be published")
+
+		assert.Equal(t, 23, publisher.Published)
+		assert.Equal(t, 2, publisher.Failed)
+		assert.Equal(t, []string{"3", "12"}, failed)
+	})
+
+	t.Run("PutEvents error", func(t *testing.T) {
+		pub := &fakes.EventBridge{Err: errors.New("publish failed")}
+		publisher := NewPublisher(pub, kitLog.NewNopLogger(), noopMetric)
+		var failed []string
+		require.NoError(t, publisher.Add(ctx, testEntry("1", &failed)))
+		require.NoError(t, publisher.Add(ctx, testEntry("2", &failed)))
+		assert.ErrorContains(t, publisher.Flush(ctx), "publish failed")
+		assert.Equal(t, 0, publisher.Published)
+		assert.Equal(t, 2, publisher.Failed)
+		assert.Equal(t, []string{"1", "2"}, failed)
+	})
+
+	t.Run("discard", func(t *testing.T) {
+		pub := &fakes.EventBridge{}
+		publisher := NewPublisher(pub, kitLog.NewNopLogger(), noopMetric)
+		var failed []string
+		require.NoError(t, publisher.Add(ctx, testEntry("1", &failed)))
+		publisher.Discard()
+		require.NoError(t, publisher.Flush(ctx))
+		assert.Empty(t, pub.Calls, "Discarded entries should not be published")
+		assert.Equal(t, 1, publisher.Failed)
+		assert.Equal(t, []string{"1"}, failed)
+	})
+}
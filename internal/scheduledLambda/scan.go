@@ -0,0 +1,34 @@
+package scheduledLambda
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/usdigitalresponse/grants-ingest/internal/log"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// ScanTable calls fn with each item in the table, and stops at the first error returned by fn,
+// which is returned as-is. Errors that occur while scanning are logged before they are returned.
+func ScanTable(ctx context.Context, logger log.Logger, ddbsvc DynamoDBScanAPI, table string, fn func(item map[string]types.AttributeValue) error) (err error) {
+	span, scanCtx := tracer.StartSpanFromContext(ctx, "scan")
+	defer func() { span.Finish(tracer.WithError(err)) }()
+
+	paginator := dynamodb.NewScanPaginator(ddbsvc, &dynamodb.ScanInput{
+		TableName: aws.String(table),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(scanCtx)
+		if err != nil {
+			return log.Errorf(logger, "Error scanning prepared-data table", err)
+		}
+		for _, item := range page.Items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
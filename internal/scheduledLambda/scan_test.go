@@ -0,0 +1,50 @@
+package scheduledLambda
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	kitLog "github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/usdigitalresponse/grants-ingest/internal/scheduledLambda/fakes"
+)
+
+func TestScanTable(t *testing.T) {
+	ctx := context.Background()
+	pages := [][]map[string]types.AttributeValue{
+		{fakes.Item("1", false, nil), fakes.Item("2", false, nil)},
+		{fakes.Item("3", true, nil)},
+	}
+	grantID := func(item map[string]types.AttributeValue) string {
+		return item["grant_id"].(*types.AttributeValueMemberS).Value
+	}
+
+	var scanned []string
+	assert.NoError(t, ScanTable(ctx, kitLog.NewNopLogger(), &fakes.ScanClient{Pages: pages}, "test-table",
+		func(item map[string]types.AttributeValue) error {
+			scanned = append(scanned, grantID(item))
+			return nil
+		}))
+	assert.Equal(t, []string{"1", "2", "3"}, scanned)
+
+	scanned = nil
+	errStop := errors.New("stop")
+	err := ScanTable(ctx, kitLog.NewNopLogger(), &fakes.ScanClient{Pages: pages}, "test-table",
+		func(item map[string]types.AttributeValue) error {
+			scanned = append(scanned, grantID(item))
+			return errStop
+		})
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, []string{"1"}, scanned, "Scanning should stop at the first error")
+
+	scanned = nil
+	ddb := &fakes.ScanClient{Pages: pages, Err: errors.New("scan failed"), FailPage: 1}
+	err = ScanTable(ctx, kitLog.NewNopLogger(), ddb, "test-table", func(item map[string]types.AttributeValue) error {
+		scanned = append(scanned, grantID(item))
+		return nil
+	})
+	assert.ErrorContains(t, err, "scan failed")
+	assert.Equal(t, []string{"1", "2"}, scanned)
+}
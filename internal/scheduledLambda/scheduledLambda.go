@@ -0,0 +1,51 @@
+// Package scheduledLambda provides the scaffolding shared by Lambda functions that are invoked on
+// a schedule to scan the prepared-data DynamoDB table, such as building grants from scanned items
+// and publishing events to EventBridge in batches.
+package scheduledLambda
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	ddlambda "github.com/DataDog/datadog-lambda-go"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/usdigitalresponse/grants-ingest/internal/awsHelpers"
+	awstrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/aws/aws-sdk-go-v2/aws"
+)
+
+// ScheduledEvent represents the invocation event for a scheduled Lambda function
+type ScheduledEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type DynamoDBScanAPI interface {
+	dynamodb.ScanAPIClient
+}
+
+type EventBridgePutEventsAPI interface {
+	PutEvents(context.Context, *eventbridge.PutEventsInput, ...func(*eventbridge.Options)) (
+		*eventbridge.PutEventsOutput, error)
+}
+
+// MetricSender sends a metric, as returned by ddHelpers.NewMetricSender.
+type MetricSender func(metric string, value float64, tags ...string)
+
+// Start starts the Lambda handler, which is called with an AWS SDK config for each invocation.
+// The Timestamp of the invocation event defaults to the current time.
+func Start(handler func(ctx context.Context, cfg aws.Config, event ScheduledEvent) error) {
+	lambda.Start(ddlambda.WrapFunction(func(ctx context.Context, event ScheduledEvent) error {
+		cfg, err := awsHelpers.GetConfig(ctx)
+		if err != nil {
+			return fmt.Errorf("could not create AWS SDK config: %w", err)
+		}
+		awstrace.AppendMiddleware(&cfg)
+		if event.Timestamp.IsZero() {
+			event.Timestamp = time.Now().UTC()
+		}
+		return handler(ctx, cfg, event)
+	}, nil))
+}
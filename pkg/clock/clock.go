@@ -0,0 +1,49 @@
+// Package clock provides the current time through an interface, so that behavior that depends
+// on the current date (such as the lifecycle status of a grant) is computed consistently and
+// can be tested with a fixed time.
+package clock
+
+import (
+	"time"
+	_ "time/tzdata"
+)
+
+// Clock reports the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// System is a Clock that reports the current system time.
+var System Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// Fixed returns a Clock that always reports t.
+func Fixed(t time.Time) Clock {
+	return fixedClock(t)
+}
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time { return time.Time(c) }
+
+// Location is the timezone in which Grants.gov dates, which have no time of day, are observed.
+var Location = mustLoadLocation("America/New_York")
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+// Today returns the current date of c in Location, as midnight UTC. This is how dates without
+// a time of day are represented when parsed (e.g. with time.Parse), so the result can be
+// compared to them directly.
+func Today(c Clock) time.Time {
+	y, m, d := c.Now().In(Location).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
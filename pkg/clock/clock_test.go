@@ -0,0 +1,46 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixed(t *testing.T) {
+	now := time.Date(2023, 5, 6, 7, 8, 9, 0, time.UTC)
+	assert.Equal(t, now, Fixed(now).Now())
+}
+
+func TestToday(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{
+			"after midnight UTC but before midnight Eastern",
+			time.Date(2023, 5, 7, 3, 0, 0, 0, time.UTC),
+			time.Date(2023, 5, 6, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			"after midnight Eastern during daylight saving time",
+			time.Date(2023, 5, 7, 4, 0, 0, 0, time.UTC),
+			time.Date(2023, 5, 7, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			"before midnight Eastern during standard time",
+			time.Date(2023, 1, 7, 4, 59, 0, 0, time.UTC),
+			time.Date(2023, 1, 6, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			"time in another zone",
+			time.Date(2023, 1, 7, 1, 0, 0, 0, time.FixedZone("UTC+9", 9*60*60)),
+			time.Date(2023, 1, 6, 0, 0, 0, 0, time.UTC),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Today(Fixed(tc.now)))
+		})
+	}
+}
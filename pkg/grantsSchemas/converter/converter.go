@@ -0,0 +1,241 @@
+// Package converter converts grant opportunity data from Grants.gov and FFIS.org to usdr.Grant
+// values, so that the same representation of a grant can be produced from raw source data
+// (e.g. Grants.gov XML) as from the items of the prepared data table.
+package converter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/usdigitalresponse/grants-ingest/pkg/clock"
+	grantsgov "github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/grants.gov"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/usdr"
+)
+
+// MalformedFieldFunc is called with the name of each Record field that could not be converted
+// as expected, along with the reason (if known).
+type MalformedFieldFunc func(name string, err error)
+
+// DateLayout is the layout of the date fields of a Record.
+const DateLayout = grantsgov.TimeLayoutMMDDYYYYType
+
+type Converter struct {
+	onMalformedField MalformedFieldFunc
+	clock            clock.Clock
+}
+
+// New returns a Converter. When onMalformedField is non-nil, it is called for each field
+// that is malformed.
+func New(onMalformedField MalformedFieldFunc) *Converter {
+	return &Converter{onMalformedField, clock.System}
+}
+
+// WithClock sets the clock used to compute the lifecycle status of converted opportunities,
+// which is the system clock by default.
+func (c *Converter) WithClock(clk clock.Clock) *Converter {
+	c.clock = clk
+	return c
+}
+
+func (c *Converter) malformedField(name string, err error) {
+	if c.onMalformedField != nil {
+		c.onMalformedField(name, err)
+	}
+}
+
+// dateFor parses the value of the named field as a date, or returns nil if the value is empty
+// (or cannot be parsed, in which case the field is reported as malformed).
+func (c *Converter) dateFor(name, value string) *usdr.Date {
+	if value == "" {
+		return nil
+	}
+	t, err := time.Parse(DateLayout, value)
+	if err != nil {
+		c.malformedField(name, err)
+		return nil
+	}
+	return (*usdr.Date)(&t)
+}
+
+// Grant converts the Record to a usdr.Grant.
+func (c *Converter) Grant(r Record) usdr.Grant {
+	grant := usdr.Grant{
+		Bill:                   r.Bill,
+		Revision:               usdr.Revision{Id: r.Revision},
+		Opportunity:            c.Opportunity(r),
+		EligibleApplicants:     c.EligibleApplicants(r),
+		FundingActivity:        c.FundingActivity(r),
+		FundingInstrumentTypes: c.FundingInstruments(r),
+		Award:                  c.Award(r),
+		Metadata: usdr.Metadata{
+			Version: r.Version,
+		},
+		Agency: usdr.DefaultAgencyRegistry().Normalize(usdr.Agency{
+			Name: r.AgencyName,
+			Code: r.AgencyCode,
+		}),
+		AdditionalInformation: c.AdditionalInformation(r),
+		Grantor: usdr.GrantorContact{
+			Email: usdr.Email{
+				Address:     r.GrantorContactEmail,
+				Description: r.GrantorContactEmailDescription,
+			},
+			Text: r.GrantorContactText,
+		},
+	}
+
+	if r.CostSharingOrMatchingRequirement != "" {
+		if normalized := strings.ToLower(r.CostSharingOrMatchingRequirement); normalized == "yes" {
+			grant.CostSharingOrMatchingRequirement = toPointer(true)
+		} else if normalized == "no" {
+			grant.CostSharingOrMatchingRequirement = toPointer(false)
+		} else {
+			c.malformedField(
+				"CostSharingOrMatchingRequirement",
+				fmt.Errorf("not one of yes or no: %s", normalized),
+			)
+		}
+	} else {
+		c.malformedField("CostSharingOrMatchingRequirement", fmt.Errorf("missing or empty"))
+	}
+
+	for _, v := range r.CFDANumbers {
+		cfdaNumber, err := usdr.NewCFDANumber(v)
+		grant.CFDANumbers = append(grant.CFDANumbers, cfdaNumber)
+		if err != nil {
+			c.malformedField("CFDANumbers", err)
+		}
+	}
+
+	grant.Eligibility = usdr.EligibilityFromApplicants(grant.EligibleApplicants)
+	if r.FFISEligibility != nil {
+		grant.Eligibility = usdr.MergeEligibility(grant.Eligibility,
+			usdr.EligibilityFromFFIS(*r.FFISEligibility))
+	}
+
+	return grant
+}
+
+func (c *Converter) Award(r Record) usdr.Award {
+	award := usdr.Award{
+		Ceiling:                      r.AwardCeiling,
+		Floor:                        r.AwardFloor,
+		EstimatedTotalProgramFunding: r.EstimatedTotalProgramFunding,
+	}
+	award.CeilingAmount = c.amountFor("AwardCeiling", award.Ceiling)
+	award.FloorAmount = c.amountFor("AwardFloor", award.Floor)
+	award.EstimatedTotalProgramFundingAmount = c.amountFor(
+		"EstimatedTotalProgramFunding", award.EstimatedTotalProgramFunding)
+	if r.ExpectedNumberOfAwards != "" {
+		val, err := strconv.Atoi(r.ExpectedNumberOfAwards)
+		if err != nil {
+			c.malformedField("ExpectedNumberOfAwards", err)
+		} else {
+			award.ExpectedNumberOfAwards = uint64(val)
+		}
+	}
+	return award
+}
+
+// amountFor parses the value of the named field as an amount, or returns nil (reporting
+// the field as malformed) if it cannot be parsed.
+func (c *Converter) amountFor(name, value string) *usdr.Amount {
+	amount, err := usdr.ParseAmount(value)
+	if err != nil {
+		c.malformedField(name, err)
+	}
+	return amount
+}
+
+func (c *Converter) EligibleApplicants(r Record) []usdr.Applicant {
+	eligibleApplicants := make([]usdr.Applicant, 0)
+	for _, v := range r.EligibleApplicants {
+		applicant, err := usdr.ApplicantFromCode(v)
+		eligibleApplicants = append(eligibleApplicants, applicant)
+		if err != nil {
+			c.malformedField("EligibleApplicants", err)
+		}
+	}
+	return eligibleApplicants
+}
+
+func (c *Converter) FundingActivity(r Record) usdr.FundingActivity {
+	fundingActivity := usdr.FundingActivity{
+		Explanation: r.CategoryExplanation,
+	}
+	if r.CategoryOfFundingActivity != nil {
+		fundingActivity.Categories = make([]usdr.FundingActivityCategory, 0)
+		for _, v := range r.CategoryOfFundingActivity {
+			category, err := usdr.FundingActivityCategoryFromCode(v)
+			fundingActivity.Categories = append(fundingActivity.Categories, category)
+			if err != nil {
+				c.malformedField("CategoryOfFundingActivity", err)
+			}
+		}
+	}
+	return fundingActivity
+}
+
+func (c *Converter) AdditionalInformation(r Record) usdr.AdditionalInformation {
+	return usdr.AdditionalInformation{
+		Eligibility:          r.AdditionalInformationOnEligibility,
+		Text:                 r.AdditionalInformationText,
+		Url:                  r.AdditionalInformationURL,
+		FormattedEligibility: usdr.NewFormattedText(r.AdditionalInformationOnEligibility),
+		FormattedText:        usdr.NewFormattedText(r.AdditionalInformationText),
+	}
+}
+
+func (c *Converter) Opportunity(r Record) usdr.Opportunity {
+	opportunity := usdr.Opportunity{
+		Id:                   r.OpportunityID,
+		Number:               r.OpportunityNumber,
+		Title:                r.OpportunityTitle,
+		Description:          r.Description,
+		Milestones:           c.OpportunityMilestones(r),
+		LastUpdated:          c.dateFor("LastUpdatedDate", r.LastUpdatedDate),
+		IsForecast:           r.IsForecast,
+		FormattedDescription: usdr.NewFormattedText(r.Description),
+	}
+
+	if r.OpportunityCategory != "" {
+		var err error
+		opportunity.Category, err = usdr.OpportunityCategoryFromCode(r.OpportunityCategory)
+		if err != nil {
+			c.malformedField("OpportunityCategory", err)
+		}
+	}
+	opportunity.Category.Explanation = r.OpportunityCategoryExplanation
+	opportunity.UpdateStatus(c.clock)
+
+	return opportunity
+}
+
+func (c *Converter) OpportunityMilestones(r Record) usdr.OpportunityMilestones {
+	return usdr.OpportunityMilestones{
+		PostDate:    c.dateFor("PostDate", r.PostDate),
+		ArchiveDate: c.dateFor("ArchiveDate", r.ArchiveDate),
+		Close: usdr.CloseDate{
+			Date:        c.dateFor("CloseDate", r.CloseDate),
+			Explanation: r.CloseDateExplanation,
+		},
+	}
+}
+
+func (c *Converter) FundingInstruments(r Record) []usdr.FundingInstrument {
+	fundingInstruments := make([]usdr.FundingInstrument, 0)
+	for _, v := range r.FundingInstrumentType {
+		fundingInstrument, err := usdr.FundingInstrumentFromCode(v)
+		fundingInstruments = append(fundingInstruments, fundingInstrument)
+		if err != nil {
+			c.malformedField("FundingInstrumentType", err)
+		}
+	}
+	return fundingInstruments
+}
+
+func toPointer[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,236 @@
+package converter
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/usdigitalresponse/grants-ingest/pkg/clock"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/ffis"
+	grantsgov "github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/grants.gov"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/usdr"
+)
+
+const synopsisXML = `
+<OpportunitySynopsisDetail_1_0>
+	<OpportunityID>123456</OpportunityID>
+	<OpportunityTitle>Fun Grant</OpportunityTitle>
+	<OpportunityNumber>ABCD-1234</OpportunityNumber>
+	<OpportunityCategory>D</OpportunityCategory>
+	<FundingInstrumentType>CA</FundingInstrumentType>
+	<FundingInstrumentType>G</FundingInstrumentType>
+	<CategoryOfFundingActivity>ENV</CategoryOfFundingActivity>
+	<CategoryExplanation>Meow meow meow</CategoryExplanation>
+	<CFDANumbers>10.001</CFDANumbers>
+	<EligibleApplicants>00</EligibleApplicants>
+	<EligibleApplicants>12</EligibleApplicants>
+	<AdditionalInformationOnEligibility>This is some additional information on eligibility.</AdditionalInformationOnEligibility>
+	<AgencyCode>USDA-FS</AgencyCode>
+	<AgencyName>Forest Service</AgencyName>
+	<PostDate>09082022</PostDate>
+	<CloseDate>05122023</CloseDate>
+	<CloseDateExplanation>Applications are due by 5pm ET.</CloseDateExplanation>
+	<LastUpdatedDate>09092022</LastUpdatedDate>
+	<AwardCeiling>600000</AwardCeiling>
+	<AwardFloor>400000</AwardFloor>
+	<EstimatedTotalProgramFunding>6000000</EstimatedTotalProgramFunding>
+	<ExpectedNumberOfAwards>10</ExpectedNumberOfAwards>
+	<Description>&lt;p&gt;Here is a &lt;b&gt;description&lt;/b&gt;.&lt;/p&gt;</Description>
+	<Version>Synopsis 2</Version>
+	<CostSharingOrMatchingRequirement>Yes</CostSharingOrMatchingRequirement>
+	<ArchiveDate>06012023</ArchiveDate>
+	<AdditionalInformationURL>https://www.fs.usda.gov</AdditionalInformationURL>
+	<GrantorContactEmail>test@example.gov</GrantorContactEmail>
+	<GrantorContactEmailDescription>Inquiries</GrantorContactEmailDescription>
+	<GrantorContactText>Tester Person</GrantorContactText>
+</OpportunitySynopsisDetail_1_0>`
+
+const forecastXML = `
+<OpportunityForecastDetail_1_0>
+	<OpportunityID>654321</OpportunityID>
+	<OpportunityTitle>Future Grant</OpportunityTitle>
+	<OpportunityNumber>EFGH-5678</OpportunityNumber>
+	<EligibleApplicants>99</EligibleApplicants>
+	<LastUpdatedDate>09092022</LastUpdatedDate>
+	<EstimatedSynopsisCloseDate>09082030</EstimatedSynopsisCloseDate>
+	<CostSharingOrMatchingRequirement>No</CostSharingOrMatchingRequirement>
+	<GrantorContactName>Tester Person</GrantorContactName>
+</OpportunityForecastDetail_1_0>`
+
+func date(t *testing.T, s string) *usdr.Date {
+	d, err := time.Parse(DateLayout, s)
+	require.NoError(t, err)
+	return (*usdr.Date)(&d)
+}
+
+func TestConvertOpportunitySynopsis(t *testing.T) {
+	var synopsis grantsgov.OpportunitySynopsisDetail_1_0
+	require.NoError(t, xml.Unmarshal([]byte(synopsisXML), &synopsis))
+	// 2023-05-10 at 10:00 in America/New_York
+	now := clock.Fixed(time.Date(2023, 5, 10, 14, 0, 0, 0, time.UTC))
+
+	var malformed []string
+	grant := New(func(name string, err error) { malformed = append(malformed, name) }).
+		WithClock(now).Grant(FromOpportunitySynopsis(synopsis))
+	assert.Empty(t, malformed)
+
+	assert.Equal(t, "123456", grant.Opportunity.Id)
+	assert.Equal(t, "ABCD-1234", grant.Opportunity.Number)
+	assert.Equal(t, "Fun Grant", grant.Opportunity.Title)
+	assert.Equal(t, usdr.OpportunityCategory{Name: "Discretionary", Code: "D"}, grant.Opportunity.Category)
+	assert.Equal(t, "<p>Here is a <b>description</b>.</p>", grant.Opportunity.Description)
+	assert.Equal(t, "Here is a description.", grant.Opportunity.FormattedDescription.PlainText)
+	assert.Equal(t, date(t, "09092022"), grant.Opportunity.LastUpdated)
+	assert.Equal(t, usdr.OpportunityMilestones{
+		PostDate:    date(t, "09082022"),
+		Close:       usdr.CloseDate{Date: date(t, "05122023"), Explanation: "Applications are due by 5pm ET."},
+		ArchiveDate: date(t, "06012023"),
+	}, grant.Opportunity.Milestones)
+	assert.False(t, grant.Opportunity.IsForecast)
+	assert.Equal(t, usdr.OpportunityStatusPosted, grant.Opportunity.Status)
+	assert.Equal(t, toPointer(2), grant.Opportunity.DaysUntilClose)
+
+	assert.Equal(t, []usdr.FundingInstrument{
+		{Name: "Cooperative Agreement", Code: "CA"},
+		{Name: "Grant", Code: "G"},
+	}, grant.FundingInstrumentTypes)
+	assert.Equal(t, "Meow meow meow", grant.FundingActivity.Explanation)
+	assert.Equal(t, []usdr.FundingActivityCategory{{Name: "Environment", Code: "ENV"}},
+		grant.FundingActivity.Categories)
+	require.Len(t, grant.CFDANumbers, 1)
+	assert.EqualValues(t, "10.001", grant.CFDANumbers[0])
+	assert.Equal(t, []usdr.EligibilityCategory{usdr.EligibilityStateGovernments, usdr.EligibilityNonprofits},
+		[]usdr.EligibilityCategory{grant.Eligibility[0].Category, grant.Eligibility[1].Category})
+	assert.Equal(t, "Forest Service", grant.Agency.Name)
+	assert.Equal(t, "USDA", grant.Agency.DepartmentCode)
+	assert.Equal(t, &usdr.Amount{Cents: 60000000}, grant.Award.CeilingAmount)
+	assert.Equal(t, uint64(10), grant.Award.ExpectedNumberOfAwards)
+	assert.Equal(t, toPointer(true), grant.CostSharingOrMatchingRequirement)
+	assert.Equal(t, "https://www.fs.usda.gov", grant.AdditionalInformation.Url)
+	assert.Equal(t, "Tester Person", grant.Grantor.Text)
+	assert.Equal(t, "test@example.gov", grant.Grantor.Email.Address)
+	assert.Equal(t, "Synopsis 2", grant.Metadata.Version)
+	assert.Equal(t, ulid.ULID{}, grant.Revision.Id)
+	assert.Empty(t, grant.Bill)
+}
+
+func TestConvertOpportunityForecast(t *testing.T) {
+	var forecast grantsgov.OpportunityForecastDetail_1_0
+	require.NoError(t, xml.Unmarshal([]byte(forecastXML), &forecast))
+
+	record := FromOpportunityForecast(forecast)
+	assert.True(t, record.IsForecast)
+	assert.Empty(t, record.CloseDate)
+
+	var malformed []string
+	grant := New(func(name string, err error) { malformed = append(malformed, name) }).Grant(record)
+	assert.Empty(t, malformed)
+	assert.Equal(t, "654321", grant.Opportunity.Id)
+	assert.True(t, grant.Opportunity.IsForecast)
+	assert.Equal(t, usdr.OpportunityStatusForecasted, grant.Opportunity.Status)
+	assert.Nil(t, grant.Opportunity.Milestones.Close.Date)
+	assert.Empty(t, grant.Grantor.Text)
+	assert.Len(t, grant.Eligibility, len(usdr.EligibilityCategories)-1)
+	assert.Nil(t, grant.FundingActivity.Categories)
+	assert.Empty(t, grant.FundingInstrumentTypes)
+}
+
+func TestConvertFFIS(t *testing.T) {
+	opportunity := ffis.FFISFundingOpportunity{
+		Agency:           "Forest Service",
+		AgencyCode:       "USDA-FS",
+		Bill:             "Inflation Reduction Act",
+		CFDA:             "10.001",
+		DueDate:          time.Date(2023, 5, 12, 0, 0, 0, 0, time.UTC),
+		Eligibility:      ffis.FFISFundingEligibility{State: true, Tribal: true},
+		EstimatedFunding: 25000000,
+		ExpectedAwards:   "N/A",
+		GrantID:          123456,
+		Match:            true,
+		OppNumber:        "ABCD-1234",
+		OppTitle:         "Fun Grant",
+	}
+
+	t.Run("FFIS only", func(t *testing.T) {
+		var malformed []string
+		grant := New(func(name string, err error) { malformed = append(malformed, name) }).
+			Grant(FromFFIS(opportunity))
+		assert.Empty(t, malformed)
+		assert.Equal(t, "123456", grant.Opportunity.Id)
+		assert.Equal(t, "ABCD-1234", grant.Opportunity.Number)
+		assert.Equal(t, "Fun Grant", grant.Opportunity.Title)
+		assert.Equal(t, date(t, "05122023"), grant.Opportunity.Milestones.Close.Date)
+		assert.Equal(t, "Inflation Reduction Act", grant.Bill)
+		assert.Equal(t, "USDA", grant.Agency.DepartmentCode)
+		assert.Equal(t, &usdr.Amount{Cents: 2500000000}, grant.Award.EstimatedTotalProgramFundingAmount)
+		assert.Zero(t, grant.Award.ExpectedNumberOfAwards)
+		assert.Equal(t, toPointer(true), grant.CostSharingOrMatchingRequirement)
+		require.Len(t, grant.CFDANumbers, 1)
+		assert.EqualValues(t, "10.001", grant.CFDANumbers[0])
+		assert.Equal(t, []usdr.Eligibility{
+			{Category: usdr.EligibilityStateGovernments, Sources: []usdr.EligibilitySource{
+				{Source: usdr.EligibilitySourceFFIS, Value: "state"}}},
+			{Category: usdr.EligibilityTribalGovernments, Sources: []usdr.EligibilitySource{
+				{Source: usdr.EligibilitySourceFFIS, Value: "tribal"}}},
+		}, grant.Eligibility)
+	})
+
+	t.Run("merged with Grants.gov", func(t *testing.T) {
+		var synopsis grantsgov.OpportunitySynopsisDetail_1_0
+		require.NoError(t, xml.Unmarshal([]byte(synopsisXML), &synopsis))
+		record := FromOpportunitySynopsis(synopsis)
+		record.MergeFFIS(opportunity)
+
+		grant := New(nil).Grant(record)
+		assert.Equal(t, "Inflation Reduction Act", grant.Bill)
+		assert.Equal(t, date(t, "05122023"), grant.Opportunity.Milestones.Close.Date)
+		assert.Equal(t, []usdr.Eligibility{
+			{Category: usdr.EligibilityStateGovernments, Sources: []usdr.EligibilitySource{
+				{Source: usdr.EligibilitySourceFFIS, Value: "state"},
+				{Source: usdr.EligibilitySourceGrantsGov, Value: "00"}}},
+			{Category: usdr.EligibilityTribalGovernments, Sources: []usdr.EligibilitySource{
+				{Source: usdr.EligibilitySourceFFIS, Value: "tribal"}}},
+			{Category: usdr.EligibilityNonprofits, Sources: []usdr.EligibilitySource{
+				{Source: usdr.EligibilitySourceGrantsGov, Value: "12"}}},
+		}, grant.Eligibility)
+	})
+}
+
+func TestConvertMalformedFields(t *testing.T) {
+	malformed := make(map[string]error)
+	grant := New(func(name string, err error) { malformed[name] = err }).Grant(Record{
+		OpportunityCategory:              "Z",
+		FundingInstrumentType:            []string{"XX"},
+		CategoryOfFundingActivity:        []string{"XYZ"},
+		CFDANumbers:                      []string{"10.1"},
+		EligibleApplicants:               []string{"42"},
+		PostDate:                         "2023-01-01",
+		AwardCeiling:                     "$1,000",
+		ExpectedNumberOfAwards:           "several",
+		CostSharingOrMatchingRequirement: "maybe",
+	})
+	assert.ElementsMatch(t, []string{
+		"OpportunityCategory", "FundingInstrumentType", "CategoryOfFundingActivity", "CFDANumbers",
+		"EligibleApplicants", "PostDate", "AwardCeiling", "ExpectedNumberOfAwards",
+		"CostSharingOrMatchingRequirement",
+	}, keys(malformed))
+	assert.ErrorIs(t, malformed["AwardCeiling"], usdr.ErrInvalidAmount)
+	assert.Nil(t, grant.Opportunity.Milestones.PostDate)
+
+	t.Run("missing cost sharing requirement", func(t *testing.T) {
+		var names []string
+		New(func(name string, err error) { names = append(names, name) }).Grant(Record{})
+		assert.Equal(t, []string{"CostSharingOrMatchingRequirement"}, names)
+	})
+}
+
+func keys[V any](m map[string]V) []string {
+	var k []string
+	for name := range m {
+		k = append(k, name)
+	}
+	return k
+}
@@ -0,0 +1,175 @@
+package converter
+
+import (
+	"strconv"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/ffis"
+	grantsgov "github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/grants.gov"
+)
+
+// Record is the source data of a grant opportunity that is converted to a usdr.Grant.
+// Its fields are named after the Grants.gov XML elements from which they are read, which are
+// also the names of the attributes of grant items in the prepared data table.
+type Record struct {
+	OpportunityID                      string
+	OpportunityTitle                   string
+	OpportunityNumber                  string
+	OpportunityCategory                string
+	OpportunityCategoryExplanation     string
+	FundingInstrumentType              []string
+	CategoryOfFundingActivity          []string
+	CategoryExplanation                string
+	CFDANumbers                        []string
+	EligibleApplicants                 []string
+	AdditionalInformationOnEligibility string
+	AgencyCode                         string
+	AgencyName                         string
+	PostDate                           string
+	CloseDate                          string
+	CloseDateExplanation               string
+	LastUpdatedDate                    string
+	AwardCeiling                       string
+	AwardFloor                         string
+	EstimatedTotalProgramFunding       string
+	ExpectedNumberOfAwards             string
+	Description                        string
+	Version                            string
+	CostSharingOrMatchingRequirement   string
+	ArchiveDate                        string
+	AdditionalInformationURL           string
+	AdditionalInformationText          string
+	GrantorContactEmail                string
+	GrantorContactEmailDescription     string
+	GrantorContactText                 string
+
+	// Whether the record is an opportunity forecast rather than a synopsis
+	IsForecast bool
+	// Identifies the revision of the record in the prepared data table, if any
+	Revision ulid.ULID
+	// Provided by FFIS.org (see MergeFFIS)
+	Bill            string
+	FFISEligibility *ffis.FFISFundingEligibility
+}
+
+// FromOpportunitySynopsis returns the Record of a Grants.gov opportunity synopsis.
+func FromOpportunitySynopsis(o grantsgov.OpportunitySynopsisDetail_1_0) Record {
+	return Record{
+		OpportunityID:                      string(o.OpportunityID),
+		OpportunityTitle:                   string(o.OpportunityTitle),
+		OpportunityNumber:                  string(o.OpportunityNumber),
+		OpportunityCategory:                string(o.OpportunityCategory),
+		OpportunityCategoryExplanation:     string(o.OpportunityCategoryExplanation),
+		FundingInstrumentType:              toStrings(o.FundingInstrumentType),
+		CategoryOfFundingActivity:          toStrings(o.CategoryOfFundingActivity),
+		CategoryExplanation:                string(o.CategoryExplanation),
+		CFDANumbers:                        toStrings(o.CFDANumbers),
+		EligibleApplicants:                 toStrings(o.EligibleApplicants),
+		AdditionalInformationOnEligibility: string(o.AdditionalInformationOnEligibility),
+		AgencyCode:                         string(o.AgencyCode),
+		AgencyName:                         string(o.AgencyName),
+		PostDate:                           string(o.PostDate),
+		CloseDate:                          string(o.CloseDate),
+		CloseDateExplanation:               string(o.CloseDateExplanation),
+		LastUpdatedDate:                    string(o.LastUpdatedDate),
+		AwardCeiling:                       string(o.AwardCeiling),
+		AwardFloor:                         string(o.AwardFloor),
+		EstimatedTotalProgramFunding:       string(o.EstimatedTotalProgramFunding),
+		ExpectedNumberOfAwards:             string(o.ExpectedNumberOfAwards),
+		Description:                        string(o.Description),
+		Version:                            string(o.Version),
+		CostSharingOrMatchingRequirement:   string(o.CostSharingOrMatchingRequirement),
+		ArchiveDate:                        string(o.ArchiveDate),
+		AdditionalInformationURL:           string(o.AdditionalInformationURL),
+		AdditionalInformationText:          string(o.AdditionalInformationText),
+		GrantorContactEmail:                string(o.GrantorContactEmail),
+		GrantorContactEmailDescription:     string(o.GrantorContactEmailDescription),
+		GrantorContactText:                 string(o.GrantorContactText),
+	}
+}
+
+// FromOpportunityForecast returns the Record of a Grants.gov opportunity forecast.
+// Forecast elements that have no counterpart in opportunity synopses (such as
+// EstimatedSynopsisCloseDate) are not converted.
+func FromOpportunityForecast(f grantsgov.OpportunityForecastDetail_1_0) Record {
+	return Record{
+		OpportunityID:                      string(f.OpportunityID),
+		OpportunityTitle:                   string(f.OpportunityTitle),
+		OpportunityNumber:                  string(f.OpportunityNumber),
+		OpportunityCategory:                string(f.OpportunityCategory),
+		OpportunityCategoryExplanation:     string(f.OpportunityCategoryExplanation),
+		FundingInstrumentType:              toStrings(f.FundingInstrumentType),
+		CategoryOfFundingActivity:          toStrings(f.CategoryOfFundingActivity),
+		CategoryExplanation:                string(f.CategoryExplanation),
+		CFDANumbers:                        toStrings(f.CFDANumbers),
+		EligibleApplicants:                 toStrings(f.EligibleApplicants),
+		AdditionalInformationOnEligibility: string(f.AdditionalInformationOnEligibility),
+		AgencyCode:                         string(f.AgencyCode),
+		AgencyName:                         string(f.AgencyName),
+		PostDate:                           string(f.PostDate),
+		LastUpdatedDate:                    string(f.LastUpdatedDate),
+		AwardCeiling:                       string(f.AwardCeiling),
+		AwardFloor:                         string(f.AwardFloor),
+		EstimatedTotalProgramFunding:       string(f.EstimatedTotalProgramFunding),
+		ExpectedNumberOfAwards:             string(f.ExpectedNumberOfAwards),
+		Description:                        string(f.Description),
+		Version:                            string(f.Version),
+		CostSharingOrMatchingRequirement:   string(f.CostSharingOrMatchingRequirement),
+		ArchiveDate:                        string(f.ArchiveDate),
+		AdditionalInformationURL:           string(f.AdditionalInformationURL),
+		AdditionalInformationText:          string(f.AdditionalInformationText),
+		GrantorContactEmail:                string(f.GrantorContactEmail),
+		GrantorContactEmailDescription:     string(f.GrantorContactEmailDescription),
+		IsForecast:                         true,
+	}
+}
+
+// FromFFIS returns the Record of an FFIS.org funding opportunity that is not (yet) known from
+// Grants.gov data. Its GrantID is the Grants.gov opportunity ID.
+func FromFFIS(o ffis.FFISFundingOpportunity) Record {
+	r := Record{
+		OpportunityID:     strconv.FormatInt(o.GrantID, 10),
+		OpportunityTitle:  o.OppTitle,
+		OpportunityNumber: o.OppNumber,
+		AgencyCode:        o.AgencyCode,
+		AgencyName:        o.Agency,
+	}
+	if o.CFDA != "" {
+		r.CFDANumbers = []string{o.CFDA}
+	}
+	if !o.DueDate.IsZero() {
+		r.CloseDate = o.DueDate.Format(grantsgov.TimeLayoutMMDDYYYYType)
+	}
+	if o.EstimatedFunding > 0 {
+		r.EstimatedTotalProgramFunding = strconv.FormatInt(o.EstimatedFunding, 10)
+	}
+	// FFIS.org uses values like "N/A" when the number of awards is unknown
+	if _, err := strconv.Atoi(o.ExpectedAwards); err == nil {
+		r.ExpectedNumberOfAwards = o.ExpectedAwards
+	}
+	r.CostSharingOrMatchingRequirement = "No"
+	if o.Match {
+		r.CostSharingOrMatchingRequirement = "Yes"
+	}
+	r.MergeFFIS(o)
+	return r
+}
+
+// MergeFFIS adds the data that FFIS.org provides in addition to Grants.gov (i.e. the bill
+// and eligibility) to the Record, as is done for grant items in the prepared data table.
+func (r *Record) MergeFFIS(o ffis.FFISFundingOpportunity) {
+	eligibility := o.Eligibility
+	r.Bill = o.Bill
+	r.FFISEligibility = &eligibility
+}
+
+func toStrings[T ~string](values []T) []string {
+	if values == nil {
+		return nil
+	}
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = string(v)
+	}
+	return s
+}
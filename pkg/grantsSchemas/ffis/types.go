@@ -10,9 +10,10 @@ type FFISMessageDownload struct {
 
 // Represents a funding opportunity sourced from an FFIS spreadsheet
 type FFISFundingOpportunity struct {
-	Agency           string                 `json:"opportunity_agency"` // eg. Forest Service
-	Bill             string                 `json:"bill"`               // eg. Inflation Reduction Act
-	CFDA             string                 `json:"cfda"`               // eg. 11.525
+	Agency           string                 `json:"opportunity_agency"`    // eg. Forest Service
+	AgencyCode       string                 `json:"agency_code,omitempty"` // eg. USDA-FS, if Agency is in the usdr agency registry
+	Bill             string                 `json:"bill"`                  // eg. Inflation Reduction Act
+	CFDA             string                 `json:"cfda"`                  // eg. 11.525
 	DueDate          time.Time              `json:"due_date"`
 	Eligibility      FFISFundingEligibility `json:"eligibility"`
 	EstimatedFunding int64                  `json:"estimated_funding"` // eg. $25,000,000
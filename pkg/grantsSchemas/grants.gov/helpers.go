@@ -19,3 +19,15 @@ func (v FiscalYearType) Time() (time.Time, error) {
 
 type OpportunitySynopsisDetail_1_0 OpportunitySynopsisDetail10
 type OpportunityForecastDetail_1_0 OpportunityForecastDetail10
+
+// Validate returns a ValidationError listing the constraint violations of the fields of the
+// opportunity synopsis, or nil if it is valid.
+func (v OpportunitySynopsisDetail_1_0) Validate() error {
+	return OpportunitySynopsisDetail10(v).Validate()
+}
+
+// Validate returns a ValidationError listing the constraint violations of the fields of the
+// opportunity forecast, or nil if it is valid.
+func (v OpportunityForecastDetail_1_0) Validate() error {
+	return OpportunityForecastDetail10(v).Validate()
+}
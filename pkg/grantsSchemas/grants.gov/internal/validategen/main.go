@@ -0,0 +1,243 @@
+// Command validategen generates Validate methods for the types generated by xsdgen from the
+// Grants.gov XML schema, which document the restrictions of the schema only as comments.
+//
+// Each simple type gets a Validate method that checks the pattern and length restrictions of
+// the schema. Each record element (e.g. OpportunitySynopsisDetail_1_0) gets a Validate method
+// that validates its fields and returns a grantsgov.ValidationError; elements that only
+// reference other elements (i.e. Grants) are not validated.
+//
+// Usage:
+//
+//	go run ./internal/validategen <path to schema.xsd> <path to output .go file>
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"go/format"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+type schema struct {
+	Elements    []element    `xml:"element"`
+	SimpleTypes []simpleType `xml:"simpleType"`
+}
+
+type element struct {
+	Name     string         `xml:"name,attr"`
+	Children []childElement `xml:"complexType>sequence>element"`
+}
+
+type childElement struct {
+	Name      string `xml:"name,attr"`
+	Ref       string `xml:"ref,attr"`
+	MinOccurs string `xml:"minOccurs,attr"`
+	MaxOccurs string `xml:"maxOccurs,attr"`
+}
+
+type facet struct {
+	Value string `xml:"value,attr"`
+}
+
+type simpleType struct {
+	Name        string `xml:"name,attr"`
+	Restriction struct {
+		Base      string  `xml:"base,attr"`
+		Patterns  []facet `xml:"pattern"`
+		MinLength *facet  `xml:"minLength"`
+		MaxLength *facet  `xml:"maxLength"`
+	} `xml:"restriction"`
+}
+
+// Template data
+
+type validatedType struct {
+	Name          string
+	Pattern       string
+	PatternSource string
+	MinLength     int
+	MaxLength     int
+}
+
+type validatedRecord struct {
+	Name   string
+	Fields []validatedField
+}
+
+type validatedField struct {
+	Name     string
+	Required bool
+	Multiple bool
+}
+
+var goNamePattern = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+// goName returns the name that xsdgen gives to the Go type of an XML schema name.
+func goName(name string) string {
+	if i := strings.LastIndexByte(name, ':'); i >= 0 {
+		name = name[i+1:]
+	}
+	return goNamePattern.ReplaceAllString(name, "")
+}
+
+func main() {
+	if len(os.Args) != 3 {
+		fmt.Fprintln(os.Stderr, "usage: validategen <path to schema.xsd> <path to output .go file>")
+		os.Exit(2)
+	}
+	if err := generate(os.Args[1], os.Args[2]); err != nil {
+		fmt.Fprintf(os.Stderr, "validategen: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func generate(schemaPath, outputPath string) error {
+	b, err := os.ReadFile(schemaPath)
+	if err != nil {
+		return err
+	}
+	var s schema
+	if err := xml.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("error parsing %s: %w", schemaPath, err)
+	}
+
+	var data struct {
+		Types   []validatedType
+		Records []validatedRecord
+	}
+	for _, st := range s.SimpleTypes {
+		t, err := newValidatedType(st)
+		if err != nil {
+			return err
+		}
+		data.Types = append(data.Types, t)
+	}
+	sort.Slice(data.Types, func(i, j int) bool { return data.Types[i].Name < data.Types[j].Name })
+
+elements:
+	for _, el := range s.Elements {
+		r := validatedRecord{Name: goName(el.Name)}
+		for _, child := range el.Children {
+			if child.Ref != "" {
+				continue elements
+			}
+			r.Fields = append(r.Fields, validatedField{
+				Name:     goName(child.Name),
+				Required: child.MinOccurs != "0", // minOccurs is 1 by default
+				Multiple: child.MaxOccurs != "" && child.MaxOccurs != "1",
+			})
+		}
+		data.Records = append(data.Records, r)
+	}
+	sort.Slice(data.Records, func(i, j int) bool { return data.Records[i].Name < data.Records[j].Name })
+
+	var buf bytes.Buffer
+	if err := outputTemplate.Execute(&buf, data); err != nil {
+		return err
+	}
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("error formatting generated code: %w", err)
+	}
+	return os.WriteFile(outputPath, formatted, 0644)
+}
+
+func newValidatedType(st simpleType) (validatedType, error) {
+	t := validatedType{Name: goName(st.Name)}
+	if base := goName(st.Restriction.Base); base != "string" {
+		return t, fmt.Errorf("simple type %s: unsupported base type %s", st.Name, st.Restriction.Base)
+	}
+	if len(st.Restriction.Patterns) > 0 {
+		// Multiple patterns of a restriction are alternatives, and each matches the entire value
+		var sources []string
+		for _, p := range st.Restriction.Patterns {
+			sources = append(sources, p.Value)
+		}
+		t.PatternSource = strings.Join(sources, "|")
+		t.Pattern = "^(?:" + t.PatternSource + ")$"
+		if len(sources) > 1 {
+			t.Pattern = "^(?:(?:" + strings.Join(sources, ")|(?:") + "))$"
+		}
+		if _, err := regexp.Compile(t.Pattern); err != nil {
+			return t, fmt.Errorf("simple type %s: unsupported pattern: %w", st.Name, err)
+		}
+	}
+	var err error
+	if st.Restriction.MinLength != nil {
+		if t.MinLength, err = strconv.Atoi(st.Restriction.MinLength.Value); err != nil {
+			return t, fmt.Errorf("simple type %s: invalid minLength: %w", st.Name, err)
+		}
+	}
+	if st.Restriction.MaxLength != nil {
+		if t.MaxLength, err = strconv.Atoi(st.Restriction.MaxLength.Value); err != nil {
+			return t, fmt.Errorf("simple type %s: invalid maxLength: %w", st.Name, err)
+		}
+	}
+	return t, nil
+}
+
+var outputTemplate = template.Must(template.New("validate.go").Funcs(template.FuncMap{
+	"quote": func(s string) string {
+		if !strings.Contains(s, "`") {
+			return "`" + s + "`"
+		}
+		return strconv.Quote(s)
+	},
+}).Parse(`// Code generated by validategen. DO NOT EDIT.
+
+package grantsgov
+
+import "regexp"
+
+var (
+{{- range .Types}}
+	constraints{{.Name}} = stringConstraints{
+		{{- if .Pattern}}
+		pattern:       regexp.MustCompile({{quote .Pattern}}),
+		patternSource: {{quote .PatternSource}},
+		{{- end}}
+		{{- if .MinLength}}
+		minLength: {{.MinLength}},
+		{{- end}}
+		{{- if .MaxLength}}
+		maxLength: {{.MaxLength}},
+		{{- end}}
+	}
+{{- end}}
+)
+{{range .Types}}
+// Validate returns an error if the value does not satisfy the restrictions of {{.Name}}.
+func (v {{.Name}}) Validate() error {
+	return constraints{{.Name}}.validate(string(v))
+}
+{{end}}
+{{- range .Records}}
+// Validate returns a ValidationError listing the constraint violations of the fields of the
+// {{.Name}}, or nil if it is valid.
+func (v {{.Name}}) Validate() error {
+	var errs ValidationError
+	{{- range .Fields}}
+	{{- if .Multiple}}
+	for _, item := range v.{{.Name}} {
+		errs.add("{{.Name}}", item.Validate())
+	}
+	{{- if .Required}}
+	if len(v.{{.Name}}) == 0 {
+		errs.add("{{.Name}}", ErrMissingValue)
+	}
+	{{- end}}
+	{{- else if .Required}}
+	errs.add("{{.Name}}", validateRequired(v.{{.Name}}))
+	{{- else}}
+	errs.add("{{.Name}}", validateOptional(v.{{.Name}}))
+	{{- end}}
+	{{- end}}
+	return errs.errorOrNil()
+}
+{{end}}`))
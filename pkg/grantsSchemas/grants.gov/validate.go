@@ -0,0 +1,324 @@
+// Code generated by validategen. DO NOT EDIT.
+
+package grantsgov
+
+import "regexp"
+
+var (
+	constraintsAwardCeilingType = stringConstraints{
+		pattern:       regexp.MustCompile(`^(?:none|[0-9]{1,15})$`),
+		patternSource: `none|[0-9]{1,15}`,
+	}
+	constraintsAwardFloorType = stringConstraints{
+		pattern:       regexp.MustCompile(`^(?:[0-9]{1,15})$`),
+		patternSource: `[0-9]{1,15}`,
+	}
+	constraintsCFDANumberType = stringConstraints{
+		pattern:       regexp.MustCompile(`^(?:[0-9]{2}[\.][0-9]{3})$`),
+		patternSource: `[0-9]{2}[\.][0-9]{3}`,
+	}
+	constraintsCategoryExplanationType = stringConstraints{
+		maxLength: 255,
+	}
+	constraintsCostSharingOrMatchingRequirementType = stringConstraints{
+		maxLength: 3,
+	}
+	constraintsDescriptionType = stringConstraints{
+		maxLength: 18000,
+	}
+	constraintsEligibleApplicantTypes = stringConstraints{
+		maxLength: 2,
+	}
+	constraintsEstimatedTotalProgramFundingType = stringConstraints{
+		pattern:       regexp.MustCompile(`^(?:[0-9]*)$`),
+		patternSource: `[0-9]*`,
+		maxLength:     15,
+	}
+	constraintsExpectedNumberOfAwardsType = stringConstraints{
+		pattern:       regexp.MustCompile(`^(?:[0-9]*)$`),
+		patternSource: `[0-9]*`,
+		maxLength:     15,
+	}
+	constraintsFiscalYearType = stringConstraints{
+		pattern:       regexp.MustCompile(`^(?:[0-9]*)$`),
+		patternSource: `[0-9]*`,
+		maxLength:     4,
+	}
+	constraintsFundingActivityCategoryTypes = stringConstraints{
+		maxLength: 3,
+	}
+	constraintsFundingInstrumentTypes = stringConstraints{
+		maxLength: 2,
+	}
+	constraintsFundingOpportunityNumberType = stringConstraints{
+		maxLength: 40,
+	}
+	constraintsGrantorContactDescriptionType = stringConstraints{
+		maxLength: 300,
+	}
+	constraintsMMDDYYYYType = stringConstraints{
+		pattern:       regexp.MustCompile(`^(?:(0[1-9]|1[012])(0[1-9]|[12][0-9]|3[01])\d\d\d\d)$`),
+		patternSource: `(0[1-9]|1[012])(0[1-9]|[12][0-9]|3[01])\d\d\d\d`,
+	}
+	constraintsNumber20DigitsType = stringConstraints{
+		pattern:       regexp.MustCompile(`^(?:[0-9]{1,20})$`),
+		patternSource: `[0-9]{1,20}`,
+	}
+	constraintsOpportunityCategoryTypes = stringConstraints{
+		maxLength: 20,
+	}
+	constraintsString100Type = stringConstraints{
+		maxLength: 100,
+	}
+	constraintsString102Type = stringConstraints{
+		maxLength: 102,
+	}
+	constraintsString130Type = stringConstraints{
+		maxLength: 130,
+	}
+	constraintsString20Type = stringConstraints{
+		maxLength: 20,
+	}
+	constraintsString2500Type = stringConstraints{
+		maxLength: 2500,
+	}
+	constraintsString250Type = stringConstraints{
+		maxLength: 250,
+	}
+	constraintsString4000Type = stringConstraints{
+		maxLength: 4000,
+	}
+	constraintsStringMin1Max255Type = stringConstraints{
+		minLength: 1,
+		maxLength: 255,
+	}
+	constraintsStringWithoutNewLine255Type = stringConstraints{
+		pattern:       regexp.MustCompile(`^(?:[^\s].{0,254})$`),
+		patternSource: `[^\s].{0,254}`,
+	}
+)
+
+// Validate returns an error if the value does not satisfy the restrictions of AwardCeilingType.
+func (v AwardCeilingType) Validate() error {
+	return constraintsAwardCeilingType.validate(string(v))
+}
+
+// Validate returns an error if the value does not satisfy the restrictions of AwardFloorType.
+func (v AwardFloorType) Validate() error {
+	return constraintsAwardFloorType.validate(string(v))
+}
+
+// Validate returns an error if the value does not satisfy the restrictions of CFDANumberType.
+func (v CFDANumberType) Validate() error {
+	return constraintsCFDANumberType.validate(string(v))
+}
+
+// Validate returns an error if the value does not satisfy the restrictions of CategoryExplanationType.
+func (v CategoryExplanationType) Validate() error {
+	return constraintsCategoryExplanationType.validate(string(v))
+}
+
+// Validate returns an error if the value does not satisfy the restrictions of CostSharingOrMatchingRequirementType.
+func (v CostSharingOrMatchingRequirementType) Validate() error {
+	return constraintsCostSharingOrMatchingRequirementType.validate(string(v))
+}
+
+// Validate returns an error if the value does not satisfy the restrictions of DescriptionType.
+func (v DescriptionType) Validate() error {
+	return constraintsDescriptionType.validate(string(v))
+}
+
+// Validate returns an error if the value does not satisfy the restrictions of EligibleApplicantTypes.
+func (v EligibleApplicantTypes) Validate() error {
+	return constraintsEligibleApplicantTypes.validate(string(v))
+}
+
+// Validate returns an error if the value does not satisfy the restrictions of EstimatedTotalProgramFundingType.
+func (v EstimatedTotalProgramFundingType) Validate() error {
+	return constraintsEstimatedTotalProgramFundingType.validate(string(v))
+}
+
+// Validate returns an error if the value does not satisfy the restrictions of ExpectedNumberOfAwardsType.
+func (v ExpectedNumberOfAwardsType) Validate() error {
+	return constraintsExpectedNumberOfAwardsType.validate(string(v))
+}
+
+// Validate returns an error if the value does not satisfy the restrictions of FiscalYearType.
+func (v FiscalYearType) Validate() error {
+	return constraintsFiscalYearType.validate(string(v))
+}
+
+// Validate returns an error if the value does not satisfy the restrictions of FundingActivityCategoryTypes.
+func (v FundingActivityCategoryTypes) Validate() error {
+	return constraintsFundingActivityCategoryTypes.validate(string(v))
+}
+
+// Validate returns an error if the value does not satisfy the restrictions of FundingInstrumentTypes.
+func (v FundingInstrumentTypes) Validate() error {
+	return constraintsFundingInstrumentTypes.validate(string(v))
+}
+
+// Validate returns an error if the value does not satisfy the restrictions of FundingOpportunityNumberType.
+func (v FundingOpportunityNumberType) Validate() error {
+	return constraintsFundingOpportunityNumberType.validate(string(v))
+}
+
+// Validate returns an error if the value does not satisfy the restrictions of GrantorContactDescriptionType.
+func (v GrantorContactDescriptionType) Validate() error {
+	return constraintsGrantorContactDescriptionType.validate(string(v))
+}
+
+// Validate returns an error if the value does not satisfy the restrictions of MMDDYYYYType.
+func (v MMDDYYYYType) Validate() error {
+	return constraintsMMDDYYYYType.validate(string(v))
+}
+
+// Validate returns an error if the value does not satisfy the restrictions of Number20DigitsType.
+func (v Number20DigitsType) Validate() error {
+	return constraintsNumber20DigitsType.validate(string(v))
+}
+
+// Validate returns an error if the value does not satisfy the restrictions of OpportunityCategoryTypes.
+func (v OpportunityCategoryTypes) Validate() error {
+	return constraintsOpportunityCategoryTypes.validate(string(v))
+}
+
+// Validate returns an error if the value does not satisfy the restrictions of String100Type.
+func (v String100Type) Validate() error {
+	return constraintsString100Type.validate(string(v))
+}
+
+// Validate returns an error if the value does not satisfy the restrictions of String102Type.
+func (v String102Type) Validate() error {
+	return constraintsString102Type.validate(string(v))
+}
+
+// Validate returns an error if the value does not satisfy the restrictions of String130Type.
+func (v String130Type) Validate() error {
+	return constraintsString130Type.validate(string(v))
+}
+
+// Validate returns an error if the value does not satisfy the restrictions of String20Type.
+func (v String20Type) Validate() error {
+	return constraintsString20Type.validate(string(v))
+}
+
+// Validate returns an error if the value does not satisfy the restrictions of String2500Type.
+func (v String2500Type) Validate() error {
+	return constraintsString2500Type.validate(string(v))
+}
+
+// Validate returns an error if the value does not satisfy the restrictions of String250Type.
+func (v String250Type) Validate() error {
+	return constraintsString250Type.validate(string(v))
+}
+
+// Validate returns an error if the value does not satisfy the restrictions of String4000Type.
+func (v String4000Type) Validate() error {
+	return constraintsString4000Type.validate(string(v))
+}
+
+// Validate returns an error if the value does not satisfy the restrictions of StringMin1Max255Type.
+func (v StringMin1Max255Type) Validate() error {
+	return constraintsStringMin1Max255Type.validate(string(v))
+}
+
+// Validate returns an error if the value does not satisfy the restrictions of StringWithoutNewLine255Type.
+func (v StringWithoutNewLine255Type) Validate() error {
+	return constraintsStringWithoutNewLine255Type.validate(string(v))
+}
+
+// Validate returns a ValidationError listing the constraint violations of the fields of the
+// OpportunityForecastDetail10, or nil if it is valid.
+func (v OpportunityForecastDetail10) Validate() error {
+	var errs ValidationError
+	errs.add("OpportunityID", validateRequired(v.OpportunityID))
+	errs.add("OpportunityTitle", validateOptional(v.OpportunityTitle))
+	errs.add("OpportunityNumber", validateOptional(v.OpportunityNumber))
+	errs.add("OpportunityCategory", validateOptional(v.OpportunityCategory))
+	errs.add("OpportunityCategoryExplanation", validateOptional(v.OpportunityCategoryExplanation))
+	for _, item := range v.FundingInstrumentType {
+		errs.add("FundingInstrumentType", item.Validate())
+	}
+	for _, item := range v.CategoryOfFundingActivity {
+		errs.add("CategoryOfFundingActivity", item.Validate())
+	}
+	errs.add("CategoryExplanation", validateOptional(v.CategoryExplanation))
+	for _, item := range v.CFDANumbers {
+		errs.add("CFDANumbers", item.Validate())
+	}
+	for _, item := range v.EligibleApplicants {
+		errs.add("EligibleApplicants", item.Validate())
+	}
+	errs.add("AdditionalInformationOnEligibility", validateOptional(v.AdditionalInformationOnEligibility))
+	errs.add("AgencyCode", validateOptional(v.AgencyCode))
+	errs.add("AgencyName", validateOptional(v.AgencyName))
+	errs.add("PostDate", validateOptional(v.PostDate))
+	errs.add("LastUpdatedDate", validateOptional(v.LastUpdatedDate))
+	errs.add("EstimatedSynopsisPostDate", validateOptional(v.EstimatedSynopsisPostDate))
+	errs.add("FiscalYear", validateOptional(v.FiscalYear))
+	errs.add("EstimatedSynopsisCloseDate", validateOptional(v.EstimatedSynopsisCloseDate))
+	errs.add("EstimatedSynopsisCloseDateExplanation", validateOptional(v.EstimatedSynopsisCloseDateExplanation))
+	errs.add("EstimatedAwardDate", validateOptional(v.EstimatedAwardDate))
+	errs.add("EstimatedProjectStartDate", validateOptional(v.EstimatedProjectStartDate))
+	errs.add("AwardCeiling", validateOptional(v.AwardCeiling))
+	errs.add("AwardFloor", validateOptional(v.AwardFloor))
+	errs.add("EstimatedTotalProgramFunding", validateOptional(v.EstimatedTotalProgramFunding))
+	errs.add("ExpectedNumberOfAwards", validateOptional(v.ExpectedNumberOfAwards))
+	errs.add("Description", validateOptional(v.Description))
+	errs.add("Version", validateOptional(v.Version))
+	errs.add("CostSharingOrMatchingRequirement", validateOptional(v.CostSharingOrMatchingRequirement))
+	errs.add("ArchiveDate", validateOptional(v.ArchiveDate))
+	errs.add("AdditionalInformationURL", validateOptional(v.AdditionalInformationURL))
+	errs.add("AdditionalInformationText", validateOptional(v.AdditionalInformationText))
+	errs.add("GrantorContactEmail", validateOptional(v.GrantorContactEmail))
+	errs.add("GrantorContactEmailDescription", validateOptional(v.GrantorContactEmailDescription))
+	errs.add("GrantorContactName", validateOptional(v.GrantorContactName))
+	errs.add("GrantorContactPhoneNumber", validateOptional(v.GrantorContactPhoneNumber))
+	return errs.errorOrNil()
+}
+
+// Validate returns a ValidationError listing the constraint violations of the fields of the
+// OpportunitySynopsisDetail10, or nil if it is valid.
+func (v OpportunitySynopsisDetail10) Validate() error {
+	var errs ValidationError
+	errs.add("OpportunityID", validateRequired(v.OpportunityID))
+	errs.add("OpportunityTitle", validateOptional(v.OpportunityTitle))
+	errs.add("OpportunityNumber", validateOptional(v.OpportunityNumber))
+	errs.add("OpportunityCategory", validateOptional(v.OpportunityCategory))
+	errs.add("OpportunityCategoryExplanation", validateOptional(v.OpportunityCategoryExplanation))
+	for _, item := range v.FundingInstrumentType {
+		errs.add("FundingInstrumentType", item.Validate())
+	}
+	for _, item := range v.CategoryOfFundingActivity {
+		errs.add("CategoryOfFundingActivity", item.Validate())
+	}
+	errs.add("CategoryExplanation", validateOptional(v.CategoryExplanation))
+	for _, item := range v.CFDANumbers {
+		errs.add("CFDANumbers", item.Validate())
+	}
+	for _, item := range v.EligibleApplicants {
+		errs.add("EligibleApplicants", item.Validate())
+	}
+	errs.add("AdditionalInformationOnEligibility", validateOptional(v.AdditionalInformationOnEligibility))
+	errs.add("AgencyCode", validateOptional(v.AgencyCode))
+	errs.add("AgencyName", validateOptional(v.AgencyName))
+	errs.add("PostDate", validateOptional(v.PostDate))
+	errs.add("CloseDate", validateOptional(v.CloseDate))
+	errs.add("CloseDateExplanation", validateOptional(v.CloseDateExplanation))
+	errs.add("LastUpdatedDate", validateRequired(v.LastUpdatedDate))
+	errs.add("AwardCeiling", validateOptional(v.AwardCeiling))
+	errs.add("AwardFloor", validateOptional(v.AwardFloor))
+	errs.add("EstimatedTotalProgramFunding", validateOptional(v.EstimatedTotalProgramFunding))
+	errs.add("ExpectedNumberOfAwards", validateOptional(v.ExpectedNumberOfAwards))
+	errs.add("Description", validateOptional(v.Description))
+	errs.add("Version", validateOptional(v.Version))
+	errs.add("CostSharingOrMatchingRequirement", validateOptional(v.CostSharingOrMatchingRequirement))
+	errs.add("ArchiveDate", validateOptional(v.ArchiveDate))
+	errs.add("AdditionalInformationURL", validateOptional(v.AdditionalInformationURL))
+	errs.add("AdditionalInformationText", validateOptional(v.AdditionalInformationText))
+	errs.add("GrantorContactEmail", validateOptional(v.GrantorContactEmail))
+	errs.add("GrantorContactEmailDescription", validateOptional(v.GrantorContactEmailDescription))
+	errs.add("GrantorContactText", validateOptional(v.GrantorContactText))
+	return errs.errorOrNil()
+}
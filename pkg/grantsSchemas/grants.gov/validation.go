@@ -0,0 +1,120 @@
+package grantsgov
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrMissingValue    = errors.New("missing required value")
+	ErrPatternMismatch = errors.New("value does not match the pattern")
+	ErrValueTooShort   = errors.New("value is too short")
+	ErrValueTooLong    = errors.New("value is too long")
+)
+
+// FieldError is a constraint violation of a field of a Grants.gov record.
+type FieldError struct {
+	// Name of the XML element of the field (e.g. "CFDANumbers")
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError is returned by the Validate methods of Grants.gov records, and lists every
+// constraint violation of the record's fields. Fields with multiple values (such as CFDANumbers)
+// may be listed once for each invalid value.
+type ValidationError []*FieldError
+
+func (e ValidationError) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return fmt.Sprintf("%d constraint violation(s): %s", len(e), strings.Join(msgs, "; "))
+}
+
+func (e ValidationError) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, fe := range e {
+		errs[i] = fe
+	}
+	return errs
+}
+
+// add appends a FieldError for the named field if err is non-nil.
+func (e *ValidationError) add(field string, err error) {
+	if err != nil {
+		*e = append(*e, &FieldError{Field: field, Err: err})
+	}
+}
+
+func (e ValidationError) errorOrNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+type validatable interface {
+	~string
+	Validate() error
+}
+
+// validateRequired validates the value of a required field, which must not be empty.
+func validateRequired[T validatable](v T) error {
+	if v == "" {
+		return ErrMissingValue
+	}
+	return v.Validate()
+}
+
+// validateOptional validates the value of an optional field, which is valid when empty
+// (i.e. when the element is omitted).
+func validateOptional[T validatable](v T) error {
+	if v == "" {
+		return nil
+	}
+	return v.Validate()
+}
+
+// stringConstraints are the restrictions of an XML schema simple type derived from xs:string.
+type stringConstraints struct {
+	// Matches the entire value; nil when the type has no pattern
+	pattern *regexp.Regexp
+	// The pattern as written in the XML schema
+	patternSource string
+	// Lengths are in characters; zero when the type has no such restriction
+	minLength int
+	maxLength int
+}
+
+func (c stringConstraints) validate(v string) error {
+	if c.pattern != nil && !c.pattern.MatchString(v) {
+		return fmt.Errorf("%w %s: %q", ErrPatternMismatch, c.patternSource, truncate(v, 50))
+	}
+	length := utf8.RuneCountInString(v)
+	if c.minLength > 0 && length < c.minLength {
+		return fmt.Errorf("%w: %d characters (minimum %d)", ErrValueTooShort, length, c.minLength)
+	}
+	if c.maxLength > 0 && length > c.maxLength {
+		return fmt.Errorf("%w: %d characters (maximum %d)", ErrValueTooLong, length, c.maxLength)
+	}
+	return nil
+}
+
+func truncate(s string, maxLength int) string {
+	if runes := []rune(s); len(runes) > maxLength {
+		return string(runes[:maxLength]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,87 @@
+package grantsgov_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	grantsgov "github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/grants.gov"
+)
+
+func TestSimpleTypeValidate(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		value    interface{ Validate() error }
+		expected error
+	}{
+		{"valid pattern", grantsgov.CFDANumberType("10.001"), nil},
+		{"pattern mismatch", grantsgov.CFDANumberType("10.1"), grantsgov.ErrPatternMismatch},
+		{"pattern matches entire value", grantsgov.CFDANumberType("10.0011"), grantsgov.ErrPatternMismatch},
+		{"pattern alternative", grantsgov.AwardCeilingType("none"), nil},
+		{"valid date", grantsgov.MMDDYYYYType("02292024"), nil},
+		{"invalid date", grantsgov.MMDDYYYYType("2024-02-29"), grantsgov.ErrPatternMismatch},
+		{"valid length", grantsgov.DescriptionType(strings.Repeat("é", 18000)), nil},
+		{"too long", grantsgov.DescriptionType(strings.Repeat("a", 18001)), grantsgov.ErrValueTooLong},
+		{"too short", grantsgov.StringMin1Max255Type(""), grantsgov.ErrValueTooShort},
+		{"pattern and length", grantsgov.FiscalYearType("20245"), grantsgov.ErrValueTooLong},
+		{"title without leading whitespace", grantsgov.StringWithoutNewLine255Type("Fun Grant"), nil},
+		{"title with leading whitespace", grantsgov.StringWithoutNewLine255Type(" Fun Grant"), grantsgov.ErrPatternMismatch},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.value.Validate()
+			if tt.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOpportunitySynopsisValidate(t *testing.T) {
+	valid := grantsgov.OpportunitySynopsisDetail_1_0{
+		OpportunityID:   "123456",
+		LastUpdatedDate: "09092022",
+		CFDANumbers:     []grantsgov.CFDANumberType{"10.001"},
+		AwardCeiling:    "none",
+	}
+	assert.NoError(t, valid.Validate())
+
+	invalid := valid
+	invalid.OpportunityID = ""
+	invalid.CFDANumbers = []grantsgov.CFDANumberType{"10.001", "1.1", "10-002"}
+	invalid.AwardFloor = "$5,000"
+	invalid.Description = grantsgov.DescriptionType(strings.Repeat("a", 18001))
+	err := invalid.Validate()
+
+	var validationErr grantsgov.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	var fields []string
+	for _, fe := range validationErr {
+		fields = append(fields, fe.Field)
+	}
+	assert.Equal(t, []string{"OpportunityID", "CFDANumbers", "CFDANumbers", "AwardFloor", "Description"}, fields)
+	assert.ErrorIs(t, err, grantsgov.ErrMissingValue)
+	assert.ErrorIs(t, err, grantsgov.ErrPatternMismatch)
+	assert.ErrorIs(t, err, grantsgov.ErrValueTooLong)
+	assert.Contains(t, err.Error(), `CFDANumbers: value does not match the pattern [0-9]{2}[\.][0-9]{3}: "1.1"`)
+
+	var fieldErr *grantsgov.FieldError
+	require.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "OpportunityID", fieldErr.Field)
+}
+
+func TestOpportunityForecastValidate(t *testing.T) {
+	forecast := grantsgov.OpportunityForecastDetail_1_0{OpportunityID: "123456"}
+	assert.NoError(t, forecast.Validate(), "LastUpdatedDate is optional for forecasts")
+
+	forecast.FiscalYear = "FY24"
+	forecast.GrantorContactPhoneNumber = grantsgov.String100Type(strings.Repeat("5", 101))
+	var validationErr grantsgov.ValidationError
+	require.ErrorAs(t, forecast.Validate(), &validationErr)
+	require.Len(t, validationErr, 2)
+	assert.Equal(t, "FiscalYear", validationErr[0].Field)
+	assert.Equal(t, "GrantorContactPhoneNumber", validationErr[1].Field)
+}
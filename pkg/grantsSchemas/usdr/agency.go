@@ -0,0 +1,262 @@
+package usdr
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// AgencyCodeSeparator separates the department and sub-agency components of agency codes
+// (e.g. "HHS-NIH11").
+const AgencyCodeSeparator = "-"
+
+var (
+	ErrInvalidAgencyCode  = errors.New("invalid agency code")
+	ErrInvalidAgencyEntry = errors.New("invalid agency registry entry")
+
+	validAgencyCodeRegexp = regexp.MustCompile(`^[A-Z0-9]+(-[A-Z0-9]+)*$`)
+)
+
+//go:embed agencies.json
+var embeddedAgencies []byte
+
+// ParseAgencyCode returns the department code of an agency code, which is its first component,
+// and the sub-agency code, which is the whole code when it has more than one component.
+// For example, "HHS-NIH11" is parsed as department "HHS" and sub-agency "HHS-NIH11", and
+// "EPA" is parsed as department "EPA" with no sub-agency.
+func ParseAgencyCode(code string) (department, subAgency string, err error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if !validAgencyCodeRegexp.MatchString(code) {
+		return "", "", fmt.Errorf("%w: %q", ErrInvalidAgencyCode, code)
+	}
+	department, _, isSubAgency := strings.Cut(code, AgencyCodeSeparator)
+	if isSubAgency {
+		subAgency = code
+	}
+	return department, subAgency, nil
+}
+
+// NormalizeAgencyName trims the name and collapses its runs of whitespace.
+func NormalizeAgencyName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// agencyNameKey returns the key used to match agency names, which ignores case, punctuation,
+// a leading "The" or "U.S.", and whether "and" is spelled out.
+func agencyNameKey(name string) string {
+	name = strings.ReplaceAll(strings.ToLower(name), "&", " and ")
+	fields := strings.FieldsFunc(name, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || '0' <= r && r <= '9')
+	})
+	if len(fields) > 0 && fields[0] == "the" {
+		fields = fields[1:]
+	}
+	if len(fields) > 1 && fields[0] == "u" && fields[1] == "s" {
+		fields = fields[2:]
+	} else if len(fields) > 0 && fields[0] == "us" {
+		fields = fields[1:]
+	}
+	return strings.Join(fields, " ")
+}
+
+// AgencyRegistryEntry describes a department or sub-agency.
+type AgencyRegistryEntry struct {
+	// Grants.gov agency code, e.g. "HHS" or "HHS-NIH11"
+	Code string `json:"code"`
+	// Canonical name, e.g. "National Institutes of Health"
+	Name string `json:"name"`
+	// Other names of the agency, such as acronyms and the names used by FFIS
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// AgencyRegistry is a set of known departments and sub-agencies, which is used to determine the
+// department of agency codes, to normalize agency names, and to reconcile agency names from
+// other sources (such as FFIS) with agency codes.
+type AgencyRegistry struct {
+	entries map[string]AgencyRegistryEntry
+	// codes by agencyNameKey of each name and alias
+	codesByName map[string]string
+}
+
+// agencyRegistryFile is the JSON encoding of an AgencyRegistry.
+type agencyRegistryFile struct {
+	Agencies []AgencyRegistryEntry `json:"agencies"`
+}
+
+// NewAgencyRegistry returns a registry of the given entries.
+func NewAgencyRegistry(entries ...AgencyRegistryEntry) (*AgencyRegistry, error) {
+	r := &AgencyRegistry{
+		entries:     make(map[string]AgencyRegistryEntry, len(entries)),
+		codesByName: make(map[string]string),
+	}
+	for _, e := range entries {
+		if err := r.Set(e); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// ReadAgencyRegistry reads a JSON-encoded registry, as written by WriteJSON.
+func ReadAgencyRegistry(rd io.Reader) (*AgencyRegistry, error) {
+	var f agencyRegistryFile
+	dec := json.NewDecoder(rd)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("error reading agency registry: %w", err)
+	}
+	return NewAgencyRegistry(f.Agencies...)
+}
+
+// DefaultAgencyRegistry returns the registry that is embedded in this package.
+var DefaultAgencyRegistry = sync.OnceValue(func() *AgencyRegistry {
+	r, err := ReadAgencyRegistry(bytes.NewReader(embeddedAgencies))
+	if err != nil {
+		panic(fmt.Errorf("embedded agency registry is invalid: %w", err))
+	}
+	return r
+})
+
+// WriteJSON writes the registry as JSON, with entries sorted by code.
+func (r *AgencyRegistry) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(agencyRegistryFile{Agencies: r.Entries()})
+}
+
+// Entries returns the entries of the registry, sorted by code.
+func (r *AgencyRegistry) Entries() []AgencyRegistryEntry {
+	entries := make([]AgencyRegistryEntry, 0, len(r.entries))
+	for _, e := range r.entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Code < entries[j].Code })
+	return entries
+}
+
+// Lookup returns the entry with the given agency code.
+func (r *AgencyRegistry) Lookup(code string) (AgencyRegistryEntry, bool) {
+	e, ok := r.entries[strings.ToUpper(strings.TrimSpace(code))]
+	return e, ok
+}
+
+// Set adds the entry to the registry, or replaces the existing entry with the same code.
+// It is an error if the entry's name or aliases are already used by another entry.
+func (r *AgencyRegistry) Set(e AgencyRegistryEntry) error {
+	e.Code = strings.ToUpper(strings.TrimSpace(e.Code))
+	if _, _, err := ParseAgencyCode(e.Code); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidAgencyEntry, err)
+	}
+	e.Name = NormalizeAgencyName(e.Name)
+	if e.Name == "" {
+		return fmt.Errorf("%w: %s: name cannot be empty", ErrInvalidAgencyEntry, e.Code)
+	}
+	aliases := make([]string, 0, len(e.Aliases))
+	keys := map[string]bool{agencyNameKey(e.Name): true}
+	for _, alias := range e.Aliases {
+		alias = NormalizeAgencyName(alias)
+		if key := agencyNameKey(alias); key != "" && !keys[key] {
+			keys[key] = true
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	e.Aliases = aliases
+	if len(e.Aliases) == 0 {
+		e.Aliases = nil
+	}
+
+	for key := range keys {
+		if code, ok := r.codesByName[key]; ok && code != e.Code {
+			return fmt.Errorf("%w: %s: name %q is already used by %s",
+				ErrInvalidAgencyEntry, e.Code, key, code)
+		}
+	}
+	r.Remove(e.Code)
+	r.entries[e.Code] = e
+	for key := range keys {
+		r.codesByName[key] = e.Code
+	}
+	return nil
+}
+
+// Remove removes the entry with the given code, and reports whether it existed.
+func (r *AgencyRegistry) Remove(code string) bool {
+	e, ok := r.Lookup(code)
+	if !ok {
+		return false
+	}
+	delete(r.entries, e.Code)
+	for key, c := range r.codesByName {
+		if c == e.Code {
+			delete(r.codesByName, key)
+		}
+	}
+	return true
+}
+
+// Reconcile returns the entry whose name or alias matches the given name, such as the free-text
+// agency of an FFIS opportunity. Names are matched regardless of case and punctuation.
+func (r *AgencyRegistry) Reconcile(name string) (AgencyRegistryEntry, bool) {
+	code, ok := r.codesByName[agencyNameKey(name)]
+	if !ok {
+		return AgencyRegistryEntry{}, false
+	}
+	return r.entries[code], true
+}
+
+// nearest returns the entry for the longest prefix of code (by component) that is in the
+// registry and has at least minComponents components.
+func (r *AgencyRegistry) nearest(code string, minComponents int) (AgencyRegistryEntry, bool) {
+	components := strings.Split(code, AgencyCodeSeparator)
+	for n := len(components); n >= minComponents && n > 0; n-- {
+		if e, ok := r.entries[strings.Join(components[:n], AgencyCodeSeparator)]; ok {
+			return e, true
+		}
+	}
+	return AgencyRegistryEntry{}, false
+}
+
+// Normalize returns the agency with a normalized code and name, and with its department and
+// sub-agency fields set according to its code. When the agency has no code, it is reconciled
+// by name. Names of agencies that are not in the registry are preserved.
+func (r *AgencyRegistry) Normalize(a Agency) Agency {
+	a.Code = strings.ToUpper(strings.TrimSpace(a.Code))
+	a.Name = NormalizeAgencyName(a.Name)
+	if a.Code == "" {
+		if e, ok := r.Reconcile(a.Name); ok {
+			a.Code = e.Code
+		}
+	}
+	department, subAgency, err := ParseAgencyCode(a.Code)
+	if err != nil {
+		return a
+	}
+
+	a.DepartmentCode = department
+	if e, ok := r.entries[department]; ok {
+		a.DepartmentName = e.Name
+	}
+	if subAgency != "" {
+		a.SubAgencyCode = subAgency
+		if e, ok := r.nearest(subAgency, 2); ok {
+			a.SubAgencyName = e.Name
+		} else {
+			a.SubAgencyName = a.Name
+		}
+	}
+	if e, ok := r.entries[a.Code]; ok {
+		a.Name = e.Name
+	} else if a.Name == "" {
+		a.Name = a.DepartmentName
+	}
+	return a
+}
@@ -0,0 +1,196 @@
+package usdr
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAgencyCode(t *testing.T) {
+	for _, tc := range []struct {
+		code, department, subAgency string
+		expectErr                   bool
+	}{
+		{"EPA", "EPA", "", false},
+		{"HHS-NIH11", "HHS", "HHS-NIH11", false},
+		{" usdoj-ojp-bja ", "USDOJ", "USDOJ-OJP-BJA", false},
+		{"", "", "", true},
+		{"HHS-", "", "", true},
+		{"HHS NIH", "", "", true},
+	} {
+		t.Run(tc.code, func(t *testing.T) {
+			department, subAgency, err := ParseAgencyCode(tc.code)
+			if tc.expectErr {
+				assert.ErrorIs(t, err, ErrInvalidAgencyCode)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.department, department)
+			assert.Equal(t, tc.subAgency, subAgency)
+		})
+	}
+}
+
+func TestAgencyRegistryNormalize(t *testing.T) {
+	r := DefaultAgencyRegistry()
+	for _, tc := range []struct {
+		name     string
+		agency   Agency
+		expected Agency
+	}{
+		{
+			"known sub-agency",
+			Agency{Code: "HHS-NIH11", Name: "  national institutes  of health "},
+			Agency{
+				Code:           "HHS-NIH11",
+				Name:           "National Institutes of Health",
+				DepartmentCode: "HHS",
+				DepartmentName: "Department of Health and Human Services",
+				SubAgencyCode:  "HHS-NIH11",
+				SubAgencyName:  "National Institutes of Health",
+			},
+		},
+		{
+			"unknown sub-agency of known sub-agency",
+			Agency{Code: "USDOJ-OJP-XYZ", Name: "Some New Office"},
+			Agency{
+				Code:           "USDOJ-OJP-XYZ",
+				Name:           "Some New Office",
+				DepartmentCode: "USDOJ",
+				DepartmentName: "Department of Justice",
+				SubAgencyCode:  "USDOJ-OJP-XYZ",
+				SubAgencyName:  "Office of Justice Programs",
+			},
+		},
+		{
+			"unknown sub-agency of department",
+			Agency{Code: "usda-xyz", Name: "Some New Service"},
+			Agency{
+				Code:           "USDA-XYZ",
+				Name:           "Some New Service",
+				DepartmentCode: "USDA",
+				DepartmentName: "Department of Agriculture",
+				SubAgencyCode:  "USDA-XYZ",
+				SubAgencyName:  "Some New Service",
+			},
+		},
+		{
+			"department without name",
+			Agency{Code: "EPA"},
+			Agency{
+				Code:           "EPA",
+				Name:           "Environmental Protection Agency",
+				DepartmentCode: "EPA",
+				DepartmentName: "Environmental Protection Agency",
+			},
+		},
+		{
+			"reconciled by name",
+			Agency{Name: "U.S. Fish & Wildlife Service"},
+			Agency{
+				Code:           "DOI-FWS",
+				Name:           "Fish and Wildlife Service",
+				DepartmentCode: "DOI",
+				DepartmentName: "Department of the Interior",
+				SubAgencyCode:  "DOI-FWS",
+				SubAgencyName:  "Fish and Wildlife Service",
+			},
+		},
+		{
+			"invalid code",
+			Agency{Code: "not a code", Name: " Someone "},
+			Agency{Code: "NOT A CODE", Name: "Someone"},
+		},
+		{
+			"unknown name",
+			Agency{Name: "Unknown Agency"},
+			Agency{Name: "Unknown Agency"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, r.Normalize(tc.agency))
+		})
+	}
+}
+
+func TestAgencyRegistryReconcile(t *testing.T) {
+	r := DefaultAgencyRegistry()
+	for name, code := range map[string]string{
+		"U.S. Fish & Wildlife Service": "DOI-FWS",
+		"USFWS":                        "DOI-FWS",
+		"the forest service":           "USDA-FS",
+		"NIH":                          "HHS-NIH11",
+	} {
+		t.Run(name, func(t *testing.T) {
+			e, ok := r.Reconcile(name)
+			require.True(t, ok)
+			assert.Equal(t, code, e.Code)
+		})
+	}
+	_, ok := r.Reconcile("Department of Silly Walks")
+	assert.False(t, ok)
+}
+
+func TestAgencyRegistrySet(t *testing.T) {
+	r, err := NewAgencyRegistry(
+		AgencyRegistryEntry{Code: "ABC", Name: "Agency of Bees and Clouds"},
+		AgencyRegistryEntry{Code: "abc-d", Name: " Division  of Dew ", Aliases: []string{"DOD", "dod", "Dew Division"}},
+	)
+	require.NoError(t, err)
+
+	e, ok := r.Lookup("ABC-D")
+	require.True(t, ok)
+	assert.Equal(t, AgencyRegistryEntry{
+		Code: "ABC-D", Name: "Division of Dew", Aliases: []string{"DOD", "Dew Division"},
+	}, e)
+
+	t.Run("conflicting name", func(t *testing.T) {
+		err := r.Set(AgencyRegistryEntry{Code: "XYZ", Name: "The Agency of Bees & Clouds"})
+		assert.ErrorIs(t, err, ErrInvalidAgencyEntry)
+		_, ok := r.Lookup("XYZ")
+		assert.False(t, ok)
+	})
+	t.Run("conflicting alias", func(t *testing.T) {
+		err := r.Set(AgencyRegistryEntry{Code: "XYZ", Name: "Xyz", Aliases: []string{"dew-division"}})
+		assert.ErrorIs(t, err, ErrInvalidAgencyEntry)
+	})
+	t.Run("empty name", func(t *testing.T) {
+		assert.ErrorIs(t, r.Set(AgencyRegistryEntry{Code: "XYZ"}), ErrInvalidAgencyEntry)
+	})
+	t.Run("invalid code", func(t *testing.T) {
+		err := r.Set(AgencyRegistryEntry{Code: "X Y", Name: "Xy"})
+		assert.ErrorIs(t, err, ErrInvalidAgencyEntry)
+		assert.ErrorIs(t, err, ErrInvalidAgencyCode)
+	})
+	t.Run("replace", func(t *testing.T) {
+		require.NoError(t, r.Set(AgencyRegistryEntry{Code: "ABC-D", Name: "Dew Division"}))
+		e, ok := r.Reconcile("Dew Division")
+		require.True(t, ok)
+		assert.Equal(t, "ABC-D", e.Code)
+		_, ok = r.Reconcile("DOD")
+		assert.False(t, ok, "aliases of the replaced entry should be removed")
+	})
+	t.Run("remove", func(t *testing.T) {
+		assert.True(t, r.Remove("abc-d"))
+		assert.False(t, r.Remove("ABC-D"))
+		_, ok := r.Reconcile("Dew Division")
+		assert.False(t, ok)
+	})
+}
+
+func TestEmbeddedAgencyRegistryIsCanonical(t *testing.T) {
+	r, err := ReadAgencyRegistry(bytes.NewReader(embeddedAgencies))
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteJSON(&buf))
+	assert.Equal(t, string(embeddedAgencies), buf.String(),
+		"agencies.json should be formatted as written by AgencyRegistry.WriteJSON")
+}
+
+func TestReadAgencyRegistryRejectsUnknownFields(t *testing.T) {
+	_, err := ReadAgencyRegistry(bytes.NewReader([]byte(
+		`{"agencies":[{"code":"ABC","name":"Abc","parent":"XYZ"}]}`)))
+	assert.Error(t, err)
+}
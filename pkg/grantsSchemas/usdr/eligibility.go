@@ -0,0 +1,171 @@
+package usdr
+
+import (
+	"sort"
+
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/ffis"
+)
+
+// EligibilityCategory is a kind of eligible applicant in the canonical eligibility vocabulary,
+// to which the eligibility data of every source is mapped.
+type EligibilityCategory string
+
+const (
+	EligibilityStateGovernments       EligibilityCategory = "state_governments"
+	EligibilityLocalGovernments       EligibilityCategory = "local_governments"
+	EligibilityTribalGovernments      EligibilityCategory = "tribal_governments"
+	EligibilityTribalOrganizations    EligibilityCategory = "tribal_organizations"
+	EligibilityHigherEducation        EligibilityCategory = "higher_education"
+	EligibilityNonprofits             EligibilityCategory = "nonprofits"
+	EligibilityForProfitOrganizations EligibilityCategory = "for_profit_organizations"
+	EligibilityIndividuals            EligibilityCategory = "individuals"
+	EligibilityOther                  EligibilityCategory = "other"
+)
+
+// EligibilityCategories are all eligibility categories, in the order used by Eligibility lists.
+var EligibilityCategories = []EligibilityCategory{
+	EligibilityStateGovernments,
+	EligibilityLocalGovernments,
+	EligibilityTribalGovernments,
+	EligibilityTribalOrganizations,
+	EligibilityHigherEducation,
+	EligibilityNonprofits,
+	EligibilityForProfitOrganizations,
+	EligibilityIndividuals,
+	EligibilityOther,
+}
+
+// EligibilitySourceName identifies the source of eligibility data.
+type EligibilitySourceName string
+
+const (
+	EligibilitySourceGrantsGov EligibilitySourceName = "grants.gov"
+	EligibilitySourceFFIS      EligibilitySourceName = "ffis.org"
+)
+
+// EligibilitySource is the provenance of an Eligibility entry.
+type EligibilitySource struct {
+	Source EligibilitySourceName `json:"source"`
+	// The source value that was mapped to the eligibility category: an applicant code (e.g. "00")
+	// for Grants.gov, or an eligibility field name (e.g. "state") for FFIS.org
+	Value string `json:"value"`
+}
+
+// Eligibility is an eligible category of applicants and the source data that made it eligible.
+type Eligibility struct {
+	Category EligibilityCategory `json:"category"`
+	Sources  []EligibilitySource `json:"sources"`
+}
+
+var (
+	// eligibilityCategoriesByApplicantCode maps Grants.gov applicant codes to their category.
+	// Unrestricted eligibility ("99") is mapped to every category except "other".
+	eligibilityCategoriesByApplicantCode = map[applicantCode][]EligibilityCategory{
+		"00": {EligibilityStateGovernments},
+		"01": {EligibilityLocalGovernments},
+		"02": {EligibilityLocalGovernments},
+		"04": {EligibilityLocalGovernments},
+		"05": {EligibilityLocalGovernments},
+		"06": {EligibilityHigherEducation},
+		"07": {EligibilityTribalGovernments},
+		"08": {EligibilityLocalGovernments},
+		"11": {EligibilityTribalOrganizations},
+		"12": {EligibilityNonprofits},
+		"13": {EligibilityNonprofits},
+		"20": {EligibilityHigherEducation},
+		"21": {EligibilityIndividuals},
+		"22": {EligibilityForProfitOrganizations},
+		"23": {EligibilityForProfitOrganizations},
+		"25": {EligibilityOther},
+		"99": EligibilityCategories[:len(EligibilityCategories)-1],
+	}
+
+	eligibilityCategoryOrder = func() map[EligibilityCategory]int {
+		m := make(map[EligibilityCategory]int, len(EligibilityCategories))
+		for i, c := range EligibilityCategories {
+			m[c] = i
+		}
+		return m
+	}()
+)
+
+// EligibilityFromApplicants maps Grants.gov eligible applicants to the canonical vocabulary.
+// Applicants with unknown codes are ignored.
+func EligibilityFromApplicants(applicants []Applicant) []Eligibility {
+	var entries []Eligibility
+	for _, a := range applicants {
+		source := EligibilitySource{Source: EligibilitySourceGrantsGov, Value: string(a.Code)}
+		for _, c := range eligibilityCategoriesByApplicantCode[a.Code] {
+			entries = append(entries, Eligibility{Category: c, Sources: []EligibilitySource{source}})
+		}
+	}
+	return MergeEligibility(entries)
+}
+
+// EligibilityFromFFIS maps FFIS.org eligibility flags to the canonical vocabulary.
+func EligibilityFromFFIS(e ffis.FFISFundingEligibility) []Eligibility {
+	var entries []Eligibility
+	for _, f := range []struct {
+		eligible bool
+		value    string
+		category EligibilityCategory
+	}{
+		{e.State, "state", EligibilityStateGovernments},
+		{e.Local, "local", EligibilityLocalGovernments},
+		{e.Tribal, "tribal", EligibilityTribalGovernments},
+		{e.HigherEducation, "higher_education", EligibilityHigherEducation},
+		{e.NonProfits, "non_profits", EligibilityNonprofits},
+		{e.Other, "other", EligibilityOther},
+	} {
+		if f.eligible {
+			entries = append(entries, Eligibility{
+				Category: f.category,
+				Sources:  []EligibilitySource{{Source: EligibilitySourceFFIS, Value: f.value}},
+			})
+		}
+	}
+	return entries
+}
+
+// MergeEligibility combines eligibility lists into one list with a single entry per category,
+// whose sources are the sources of every merged entry in that category. Entries are ordered
+// as in EligibilityCategories, and their sources are ordered by source and value.
+func MergeEligibility(lists ...[]Eligibility) []Eligibility {
+	byCategory := map[EligibilityCategory][]EligibilitySource{}
+	for _, list := range lists {
+		for _, e := range list {
+			for _, s := range e.Sources {
+				if !containsEligibilitySource(byCategory[e.Category], s) {
+					byCategory[e.Category] = append(byCategory[e.Category], s)
+				}
+			}
+		}
+	}
+
+	merged := make([]Eligibility, 0, len(byCategory))
+	for c, sources := range byCategory {
+		sort.Slice(sources, func(i, j int) bool {
+			if sources[i].Source != sources[j].Source {
+				return sources[i].Source < sources[j].Source
+			}
+			return sources[i].Value < sources[j].Value
+		})
+		merged = append(merged, Eligibility{Category: c, Sources: sources})
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return eligibilityCategoryOrder[merged[i].Category] < eligibilityCategoryOrder[merged[j].Category]
+	})
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+func containsEligibilitySource(sources []EligibilitySource, s EligibilitySource) bool {
+	for _, existing := range sources {
+		if existing == s {
+			return true
+		}
+	}
+	return false
+}
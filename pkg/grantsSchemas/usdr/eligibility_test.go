@@ -0,0 +1,84 @@
+package usdr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/ffis"
+)
+
+func TestEligibilityCategoriesByApplicantCode(t *testing.T) {
+	for code := range applicantNamesByCode {
+		assert.NotEmpty(t, eligibilityCategoriesByApplicantCode[code],
+			"applicant code %q is not mapped to an eligibility category", code)
+	}
+}
+
+func TestEligibilityFromApplicants(t *testing.T) {
+	applicants := func(codes ...string) []Applicant {
+		var apps []Applicant
+		for _, c := range codes {
+			app, err := ApplicantFromCode(c)
+			require.NoError(t, err)
+			apps = append(apps, app)
+		}
+		return apps
+	}
+	source := func(code string) EligibilitySource {
+		return EligibilitySource{Source: EligibilitySourceGrantsGov, Value: code}
+	}
+
+	t.Run("codes of the same category are merged", func(t *testing.T) {
+		assert.Equal(t, []Eligibility{
+			{Category: EligibilityLocalGovernments, Sources: []EligibilitySource{source("01"), source("02")}},
+			{Category: EligibilityTribalOrganizations, Sources: []EligibilitySource{source("11")}},
+			{Category: EligibilityNonprofits, Sources: []EligibilitySource{source("12")}},
+		}, EligibilityFromApplicants(applicants("12", "02", "11", "01")))
+	})
+
+	t.Run("unrestricted", func(t *testing.T) {
+		eligibility := EligibilityFromApplicants(applicants("99"))
+		require.Len(t, eligibility, len(EligibilityCategories)-1)
+		for i, e := range eligibility {
+			assert.Equal(t, EligibilityCategories[i], e.Category)
+			assert.NotEqual(t, EligibilityOther, e.Category)
+			assert.Equal(t, []EligibilitySource{source("99")}, e.Sources)
+		}
+	})
+
+	t.Run("unknown codes are ignored", func(t *testing.T) {
+		assert.Nil(t, EligibilityFromApplicants([]Applicant{{Code: "42"}}))
+		assert.Nil(t, EligibilityFromApplicants(nil))
+	})
+}
+
+func TestEligibilityFromFFIS(t *testing.T) {
+	assert.Equal(t, []Eligibility{
+		{Category: EligibilityStateGovernments, Sources: []EligibilitySource{{EligibilitySourceFFIS, "state"}}},
+		{Category: EligibilityTribalGovernments, Sources: []EligibilitySource{{EligibilitySourceFFIS, "tribal"}}},
+		{Category: EligibilityNonprofits, Sources: []EligibilitySource{{EligibilitySourceFFIS, "non_profits"}}},
+		{Category: EligibilityOther, Sources: []EligibilitySource{{EligibilitySourceFFIS, "other"}}},
+	}, EligibilityFromFFIS(ffis.FFISFundingEligibility{
+		State: true, Tribal: true, NonProfits: true, Other: true,
+	}))
+	assert.Nil(t, EligibilityFromFFIS(ffis.FFISFundingEligibility{}))
+}
+
+func TestMergeEligibility(t *testing.T) {
+	gg := EligibilitySource{Source: EligibilitySourceGrantsGov, Value: "00"}
+	ffisState := EligibilitySource{Source: EligibilitySourceFFIS, Value: "state"}
+	ffisOther := EligibilitySource{Source: EligibilitySourceFFIS, Value: "other"}
+
+	assert.Equal(t, []Eligibility{
+		{Category: EligibilityStateGovernments, Sources: []EligibilitySource{ffisState, gg}},
+		{Category: EligibilityOther, Sources: []EligibilitySource{ffisOther}},
+	}, MergeEligibility(
+		[]Eligibility{{Category: EligibilityStateGovernments, Sources: []EligibilitySource{gg}}},
+		[]Eligibility{
+			{Category: EligibilityOther, Sources: []EligibilitySource{ffisOther}},
+			{Category: EligibilityStateGovernments, Sources: []EligibilitySource{ffisState, gg}},
+		},
+	))
+	assert.Nil(t, MergeEligibility())
+}
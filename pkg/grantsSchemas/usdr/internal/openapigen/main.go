@@ -0,0 +1,87 @@
+// Command openapigen replaces the component schemas and version of an OpenAPI document with the
+// schemas derived from the usdr package types (see usdr.JSONSchemas) and usdr.SchemaVersion,
+// preserving the rest of the document.
+//
+// Usage:
+//
+//	go run ./internal/openapigen <path to openapi.yaml>
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/usdigitalresponse/grants-ingest/pkg/grantsSchemas/usdr"
+	"gopkg.in/yaml.v3"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: openapigen <path to openapi.yaml>")
+		os.Exit(2)
+	}
+	if err := generate(os.Args[1]); err != nil {
+		fmt.Fprintf(os.Stderr, "openapigen: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func generate(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return fmt.Errorf("error parsing %s: %w", path, err)
+	}
+
+	var schemas yaml.Node
+	if err := schemas.Encode(usdr.JSONSchemas()); err != nil {
+		return err
+	}
+	info := mappingValue(doc.Content[0], "info")
+	if version := mappingValue(info, "version"); version != nil {
+		version.Value = usdr.SchemaVersion
+	} else {
+		return fmt.Errorf("%s has no info.version", path)
+	}
+	components := mappingValue(doc.Content[0], "components")
+	if components == nil {
+		return fmt.Errorf("%s has no components", path)
+	}
+	if existing := mappingValue(components, "schemas"); existing != nil {
+		*existing = schemas
+	} else {
+		components.Content = append(components.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: "schemas"}, &schemas)
+	}
+
+	var out bytes.Buffer
+	if bytes.HasPrefix(b, []byte("---\n")) {
+		out.WriteString("---\n")
+	}
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, out.Bytes(), 0o644)
+}
+
+// mappingValue returns the value of key in a mapping node, or nil if there is no such key.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
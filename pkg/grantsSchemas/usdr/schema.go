@@ -0,0 +1,281 @@
+package usdr
+
+//go:generate go run ./internal/openapigen ../../../openapi/openapi.yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Schema is a JSON Schema (as used by OpenAPI 3.1 component schemas) that describes the JSON
+// encoding of a type in this package.
+type Schema struct {
+	Ref        string             `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Type       string             `json:"type,omitempty" yaml:"type,omitempty"`
+	Format     string             `json:"format,omitempty" yaml:"format,omitempty"`
+	Pattern    string             `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Enum       []string           `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Minimum    *int               `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	Items      *Schema            `json:"items,omitempty" yaml:"items,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty" yaml:"required,omitempty"`
+	AnyOf      []*Schema          `json:"anyOf,omitempty" yaml:"anyOf,omitempty"`
+}
+
+// SchemaRefPrefix is the prefix of references to component schemas.
+const SchemaRefPrefix = "#/components/schemas/"
+
+var (
+	schemaPkgPath = reflect.TypeOf(Grant{}).PkgPath()
+	ulidPattern   = regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)
+
+	// schemaEnums are the allowed values of string types, sorted for stable output.
+	schemaEnums = map[reflect.Type][]string{
+		reflect.TypeOf(applicantName("")):               sortedValues(applicantNamesByCode),
+		reflect.TypeOf(applicantCode("")):               sortedKeys(applicantNamesByCode),
+		reflect.TypeOf(fundingActivityCategoryName("")): sortedValues(fundingActivityCategoryNamesByCode),
+		reflect.TypeOf(fundingActivityCategoryCode("")): sortedKeys(fundingActivityCategoryNamesByCode),
+		reflect.TypeOf(fundingInstrumentName("")):       sortedValues(fundingInstrumentNamesByCode),
+		reflect.TypeOf(fundingInstrumentCode("")):       sortedKeys(fundingInstrumentNamesByCode),
+		reflect.TypeOf(opportunityCategoryName("")):     sortedValues(opportunityCategoryNamesByCode),
+		reflect.TypeOf(opportunityCategoryCode("")):     sortedKeys(opportunityCategoryNamesByCode),
+		reflect.TypeOf(grantModificationEventType("")):  {EventTypeCreate, EventTypeDelete, EventTypeUpdate},
+		reflect.TypeOf(EligibilityCategory("")):         eligibilityCategoryValues(),
+		reflect.TypeOf(EligibilitySourceName("")):       {string(EligibilitySourceFFIS), string(EligibilitySourceGrantsGov)},
+		reflect.TypeOf(OpportunityStatus("")): {
+			string(OpportunityStatusArchived), string(OpportunityStatusClosed),
+			string(OpportunityStatusForecasted), string(OpportunityStatusPosted),
+		},
+	}
+
+	// schemaOverrides describe types whose JSON encoding is not derived from their fields.
+	schemaOverrides = map[reflect.Type]func() *Schema{
+		reflect.TypeOf(Date{}):      func() *Schema { return &Schema{Type: "string", Format: "date"} },
+		reflect.TypeOf(time.Time{}): func() *Schema { return &Schema{Type: "string", Format: "date-time"} },
+		reflect.TypeOf(ulid.ULID{}): func() *Schema { return &Schema{Ref: SchemaRefPrefix + "ULIDType"} },
+		reflect.TypeOf(cfdaNumber("")): func() *Schema {
+			return &Schema{Type: "string", Pattern: ValidCFDANumberRegexp.String()}
+		},
+		reflect.TypeOf(Revision{}): func() *Schema {
+			return &Schema{
+				Type: "object",
+				Properties: map[string]*Schema{
+					"id":        {Ref: SchemaRefPrefix + "ULIDType"},
+					"timestamp": {Type: "string", Format: "date-time"},
+				},
+				Required: []string{"id", "timestamp"},
+			}
+		},
+	}
+)
+
+func eligibilityCategoryValues() []string {
+	s := make([]string, 0, len(EligibilityCategories))
+	for _, c := range EligibilityCategories {
+		s = append(s, string(c))
+	}
+	sort.Strings(s)
+	return s
+}
+
+func sortedKeys[K, V ~string](m map[K]V) []string {
+	s := make([]string, 0, len(m))
+	for k := range m {
+		s = append(s, string(k))
+	}
+	sort.Strings(s)
+	return s
+}
+
+func sortedValues[K, V ~string](m map[K]V) []string {
+	s := make([]string, 0, len(m))
+	for _, v := range m {
+		s = append(s, string(v))
+	}
+	sort.Strings(s)
+	return s
+}
+
+// JSONSchemas returns the component schemas of GrantModificationEvent, GrantStatusTransitionEvent,
+// GrantClosingSoonEvent, and every exported type they contain, keyed by type name. The schemas are derived from the Go types, their JSON struct tags,
+// and the following options of their `jsonschema` struct tags:
+//   - required: the property is required even though it is omitted when empty
+//   - format=<format>: the string format of the property (as an annotation)
+//   - minimum=<number>: the minimum value of an integer property
+//
+// Properties that are not omitted when empty are always required.
+func JSONSchemas() map[string]*Schema {
+	g := schemaGenerator{components: map[string]*Schema{
+		"ULIDType": {Type: "string", Format: "ulid", Pattern: ulidPattern.String()},
+	}}
+	g.schemaOf(reflect.TypeOf(GrantModificationEvent{}))
+	g.schemaOf(reflect.TypeOf(GrantStatusTransitionEvent{}))
+	g.schemaOf(reflect.TypeOf(GrantClosingSoonEvent{}))
+	return g.components
+}
+
+type schemaGenerator struct {
+	components map[string]*Schema
+}
+
+// schemaOf returns the schema of t, which is a reference for exported struct types of this package.
+func (g *schemaGenerator) schemaOf(t reflect.Type) *Schema {
+	if override, ok := schemaOverrides[t]; ok {
+		return g.named(t, override())
+	}
+	if enum, ok := schemaEnums[t]; ok {
+		return &Schema{Type: "string", Enum: enum}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schemaOf(t.Elem())
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		minimum := 0
+		return &Schema{Type: "integer", Minimum: &minimum}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Struct:
+		return g.named(t, g.structSchema(t))
+	}
+	panic(fmt.Errorf("no JSON schema for type %s", t))
+}
+
+// named returns s, or a reference to s as a component schema if t is an exported type of this
+// package that is encoded as a JSON object.
+func (g *schemaGenerator) named(t reflect.Type, s *Schema) *Schema {
+	if s.Type != "object" || t.PkgPath() != schemaPkgPath || !token.IsExported(t.Name()) {
+		return s
+	}
+	g.components[t.Name()] = s
+	return &Schema{Ref: SchemaRefPrefix + t.Name()}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		omitEmpty := strings.Contains(","+opts+",", ",omitempty,")
+
+		prop := g.schemaOf(f.Type)
+		required := !omitEmpty
+		for _, opt := range strings.Split(f.Tag.Get("jsonschema"), ",") {
+			switch {
+			case opt == "required":
+				required = true
+			case strings.HasPrefix(opt, "format="):
+				prop.Format = strings.TrimPrefix(opt, "format=")
+			case strings.HasPrefix(opt, "minimum="):
+				minimum, err := strconv.Atoi(strings.TrimPrefix(opt, "minimum="))
+				if err != nil {
+					panic(fmt.Errorf("invalid jsonschema tag on %s.%s: %w", t.Name(), f.Name, err))
+				}
+				prop.Minimum = &minimum
+			}
+		}
+		if f.Type.Kind() == reflect.Pointer && !omitEmpty {
+			prop = &Schema{AnyOf: []*Schema{{Type: "null"}, prop}}
+		}
+		s.Properties[name] = prop
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+	sort.Strings(s.Required)
+	return s
+}
+
+// ErrSchemaViolation is returned when JSON data does not conform to its schema.
+var ErrSchemaViolation = errors.New("data does not conform to JSON schema")
+
+// eventSchemaNames are the components whose schemas are used to validate events.
+var eventSchemaNames = []string{
+	"GrantModificationEvent", "GrantStatusTransitionEvent", "GrantClosingSoonEvent",
+}
+
+var eventSchemas = sync.OnceValues(func() (map[string]*jsonschema.Schema, error) {
+	doc, err := json.Marshal(map[string]any{
+		"components": map[string]any{"schemas": JSONSchemas()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	const url = "usdr.json"
+	c := jsonschema.NewCompiler()
+	c.Draft = jsonschema.Draft2020
+	if err := c.AddResource(url, bytes.NewReader(doc)); err != nil {
+		return nil, err
+	}
+	schemas := make(map[string]*jsonschema.Schema, len(eventSchemaNames))
+	for _, name := range eventSchemaNames {
+		if schemas[name], err = c.Compile(url + SchemaRefPrefix + name); err != nil {
+			return nil, err
+		}
+	}
+	return schemas, nil
+})
+
+// validateEventJSON checks that data conforms to the schema of the named event component.
+func validateEventJSON(name string, data []byte) error {
+	schemas, err := eventSchemas()
+	if err != nil {
+		return fmt.Errorf("error compiling schema: %w", err)
+	}
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	if err := schemas[name].Validate(v); err != nil {
+		return fmt.Errorf("%w: %w", ErrSchemaViolation, err)
+	}
+	return nil
+}
+
+// ValidateGrantModificationEventJSON checks that data is a JSON-encoded GrantModificationEvent
+// that conforms to its schema (see JSONSchemas). String formats are not validated.
+func ValidateGrantModificationEventJSON(data []byte) error {
+	return validateEventJSON("GrantModificationEvent", data)
+}
+
+// ValidateGrantStatusTransitionEventJSON checks that data is a JSON-encoded
+// GrantStatusTransitionEvent that conforms to its schema (see JSONSchemas).
+// String formats are not validated.
+func ValidateGrantStatusTransitionEventJSON(data []byte) error {
+	return validateEventJSON("GrantStatusTransitionEvent", data)
+}
+
+// ValidateGrantClosingSoonEventJSON checks that data is a JSON-encoded GrantClosingSoonEvent
+// that conforms to its schema (see JSONSchemas). String formats are not validated.
+func ValidateGrantClosingSoonEventJSON(data []byte) error {
+	return validateEventJSON("GrantClosingSoonEvent", data)
+}
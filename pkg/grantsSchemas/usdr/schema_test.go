@@ -0,0 +1,168 @@
+package usdr
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// normalizeJSON returns v as decoded from its JSON encoding, so that values of different
+// types with equivalent JSON encodings can be compared.
+func normalizeJSON(t *testing.T, v any) any {
+	t.Helper()
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	var normalized any
+	require.NoError(t, json.Unmarshal(b, &normalized))
+	return normalized
+}
+
+func TestOpenAPISpecMatchesJSONSchemas(t *testing.T) {
+	b, err := os.ReadFile("../../../openapi/openapi.yaml")
+	require.NoError(t, err)
+	var spec struct {
+		Info struct {
+			Version string `yaml:"version"`
+		} `yaml:"info"`
+		Components struct {
+			Schemas map[string]any `yaml:"schemas"`
+		} `yaml:"components"`
+	}
+	require.NoError(t, yaml.Unmarshal(b, &spec))
+
+	assert.Equal(t, SchemaVersion, spec.Info.Version,
+		"openapi/openapi.yaml version does not match SchemaVersion; run `go generate ./pkg/grantsSchemas/usdr` to update it")
+	assert.Equal(t, normalizeJSON(t, JSONSchemas()), normalizeJSON(t, spec.Components.Schemas),
+		"openapi/openapi.yaml does not match the usdr types; run `go generate ./pkg/grantsSchemas/usdr` to update it")
+}
+
+func TestJSONSchemas(t *testing.T) {
+	schemas := JSONSchemas()
+
+	t.Run("enums include every code and name", func(t *testing.T) {
+		for _, tc := range []struct {
+			component string
+			codes     int
+		}{
+			{"Applicant", len(applicantNamesByCode)},
+			{"FundingActivityCategory", len(fundingActivityCategoryNamesByCode)},
+			{"FundingInstrument", len(fundingInstrumentNamesByCode)},
+			{"OpportunityCategory", len(opportunityCategoryNamesByCode)},
+		} {
+			require.Contains(t, schemas, tc.component)
+			props := schemas[tc.component].Properties
+			assert.Len(t, props["code"].Enum, tc.codes, tc.component)
+			assert.Len(t, props["name"].Enum, tc.codes, tc.component)
+		}
+		assert.Contains(t, schemas["Applicant"].Properties["name"].Enum,
+			string(applicantNamesByCode["99"]))
+	})
+
+	t.Run("required properties", func(t *testing.T) {
+		assert.Equal(t, []string{"id", "last_updated", "milestones", "number", "title"},
+			schemas["Opportunity"].Required)
+		assert.Equal(t, []string{"post_date"}, schemas["OpportunityMilestones"].Required)
+		assert.Equal(t, []string{"new", "previous"},
+			schemas["GrantModificationEvent"].Properties["versions"].Required)
+	})
+}
+
+func TestValidateGrantModificationEventJSON(t *testing.T) {
+	validGrant := func() *Grant {
+		postDate := Date(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC))
+		applicant, _ := ApplicantFromCode("99")
+		instrument, _ := FundingInstrumentFromCode("G")
+		category, _ := OpportunityCategoryFromCode("D")
+		return &Grant{
+			CFDANumbers:            []cfdaNumber{"12.345"},
+			EligibleApplicants:     []Applicant{applicant},
+			FundingInstrumentTypes: []FundingInstrument{instrument},
+			Opportunity: Opportunity{
+				Id:          "12345",
+				Number:      "ABC-123",
+				Title:       "Test grant",
+				Category:    category,
+				Milestones:  OpportunityMilestones{PostDate: &postDate},
+				LastUpdated: &postDate,
+			},
+			Revision: Revision{Id: ulid.Make()},
+		}
+	}
+	marshal := func(t *testing.T, v any) []byte {
+		t.Helper()
+		b, err := json.Marshal(v)
+		require.NoError(t, err)
+		return b
+	}
+
+	t.Run("valid events", func(t *testing.T) {
+		for _, versions := range [][2]*Grant{
+			{validGrant(), nil},
+			{validGrant(), validGrant()},
+			{nil, validGrant()},
+		} {
+			ev, err := NewGrantModificationEvent(versions[0], versions[1])
+			require.NoError(t, err)
+			assert.NoError(t, ValidateGrantModificationEventJSON(marshal(t, ev)))
+		}
+	})
+
+	t.Run("invalid events", func(t *testing.T) {
+		for name, mutate := range map[string]func(*Grant){
+			"missing opportunity ID": func(g *Grant) { g.Opportunity.Id = "" },
+			"missing post date":      func(g *Grant) { g.Opportunity.Milestones.PostDate = nil },
+			"unknown applicant code": func(g *Grant) { g.EligibleApplicants[0].Code = "98" },
+			"invalid CFDA number":    func(g *Grant) { g.CFDANumbers[0] = "12345" },
+		} {
+			t.Run(name, func(t *testing.T) {
+				grant := validGrant()
+				mutate(grant)
+				ev, err := NewGrantModificationEvent(grant, nil)
+				require.NoError(t, err)
+				assert.ErrorIs(t, ValidateGrantModificationEventJSON(marshal(t, ev)),
+					ErrSchemaViolation)
+			})
+		}
+	})
+
+	t.Run("unknown event type", func(t *testing.T) {
+		ev, err := NewGrantModificationEvent(validGrant(), nil)
+		require.NoError(t, err)
+		ev.Type = "archive"
+		assert.ErrorIs(t, ValidateGrantModificationEventJSON(marshal(t, ev)), ErrSchemaViolation)
+	})
+
+	t.Run("status transition events", func(t *testing.T) {
+		grant := validGrant()
+		grant.Opportunity.Status = OpportunityStatusClosed
+		ev := NewGrantStatusTransitionEvent(*grant, OpportunityStatusPosted)
+		assert.NoError(t, ValidateGrantStatusTransitionEventJSON(marshal(t, ev)))
+
+		ev.PreviousStatus = "expired"
+		assert.ErrorIs(t, ValidateGrantStatusTransitionEventJSON(marshal(t, ev)), ErrSchemaViolation)
+	})
+
+	t.Run("closing soon events", func(t *testing.T) {
+		grant := validGrant()
+		days := 3
+		grant.Opportunity.DaysUntilClose = &days
+		ev := NewGrantClosingSoonEvent(*grant, 14)
+		assert.Equal(t, 3, ev.DaysUntilClose)
+		assert.NoError(t, ValidateGrantClosingSoonEventJSON(marshal(t, ev)))
+
+		ev.WindowDays = -1
+		assert.ErrorIs(t, ValidateGrantClosingSoonEventJSON(marshal(t, ev)), ErrSchemaViolation)
+	})
+
+	t.Run("malformed JSON", func(t *testing.T) {
+		err := ValidateGrantModificationEventJSON([]byte(`{"type":`))
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrSchemaViolation)
+	})
+}
@@ -0,0 +1,54 @@
+package usdr
+
+import (
+	"time"
+
+	"github.com/usdigitalresponse/grants-ingest/pkg/clock"
+)
+
+// OpportunityStatus is the lifecycle status of an opportunity.
+type OpportunityStatus string
+
+const (
+	OpportunityStatusForecasted OpportunityStatus = "forecasted"
+	OpportunityStatusPosted     OpportunityStatus = "posted"
+	OpportunityStatusClosed     OpportunityStatus = "closed"
+	OpportunityStatusArchived   OpportunityStatus = "archived"
+)
+
+// StatusOn returns the lifecycle status of the opportunity on the given date (see clock.Today):
+//   - archived on and after its archive date
+//   - otherwise, forecasted if it is a forecast
+//   - otherwise, closed after its close date
+//   - otherwise, posted
+func (o *Opportunity) StatusOn(today time.Time) OpportunityStatus {
+	if d := o.Milestones.ArchiveDate; d != nil && !today.Before(time.Time(*d)) {
+		return OpportunityStatusArchived
+	}
+	if o.IsForecast {
+		return OpportunityStatusForecasted
+	}
+	if d := o.Milestones.Close.Date; d != nil && today.After(time.Time(*d)) {
+		return OpportunityStatusClosed
+	}
+	return OpportunityStatusPosted
+}
+
+// DaysUntilCloseOn returns the number of days from the given date (see clock.Today) until the
+// opportunity's close date, which is negative after the close date, or nil if it has no close date.
+func (o *Opportunity) DaysUntilCloseOn(today time.Time) *int {
+	d := o.Milestones.Close.Date
+	if d == nil {
+		return nil
+	}
+	days := int(time.Time(*d).Sub(today).Round(24*time.Hour) / (24 * time.Hour))
+	return &days
+}
+
+// UpdateStatus sets the computed Status and DaysUntilClose of the opportunity for the current
+// date of c.
+func (o *Opportunity) UpdateStatus(c clock.Clock) {
+	today := clock.Today(c)
+	o.Status = o.StatusOn(today)
+	o.DaysUntilClose = o.DaysUntilCloseOn(today)
+}
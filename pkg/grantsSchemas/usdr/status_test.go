@@ -0,0 +1,77 @@
+package usdr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/usdigitalresponse/grants-ingest/pkg/clock"
+)
+
+func TestOpportunityStatusOn(t *testing.T) {
+	date := func(s string) *Date {
+		d, err := time.Parse(DateLayout, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return (*Date)(&d)
+	}
+	opportunity := Opportunity{Milestones: OpportunityMilestones{
+		PostDate:    date("2023-05-01"),
+		Close:       CloseDate{Date: date("2023-06-01")},
+		ArchiveDate: date("2023-07-01"),
+	}}
+	forecast := opportunity
+	forecast.IsForecast = true
+
+	for _, tc := range []struct {
+		today    string
+		posted   OpportunityStatus
+		forecast OpportunityStatus
+	}{
+		{"2023-05-01", OpportunityStatusPosted, OpportunityStatusForecasted},
+		{"2023-06-01", OpportunityStatusPosted, OpportunityStatusForecasted},
+		{"2023-06-02", OpportunityStatusClosed, OpportunityStatusForecasted},
+		{"2023-06-30", OpportunityStatusClosed, OpportunityStatusForecasted},
+		{"2023-07-01", OpportunityStatusArchived, OpportunityStatusArchived},
+	} {
+		t.Run(tc.today, func(t *testing.T) {
+			today := time.Time(*date(tc.today))
+			assert.Equal(t, tc.posted, opportunity.StatusOn(today))
+			assert.Equal(t, tc.forecast, forecast.StatusOn(today))
+		})
+	}
+}
+
+func TestOpportunityUpdateStatus(t *testing.T) {
+	closeDate := Date(time.Date(2023, 11, 6, 0, 0, 0, 0, time.UTC))
+	opportunity := Opportunity{Milestones: OpportunityMilestones{Close: CloseDate{Date: &closeDate}}}
+
+	t.Run("counts days in Eastern time across a DST change", func(t *testing.T) {
+		// 2023-11-04 at 23:30 EDT
+		opportunity.UpdateStatus(clock.Fixed(time.Date(2023, 11, 5, 3, 30, 0, 0, time.UTC)))
+		assert.Equal(t, OpportunityStatusPosted, opportunity.Status)
+		if assert.NotNil(t, opportunity.DaysUntilClose) {
+			assert.Equal(t, 2, *opportunity.DaysUntilClose)
+		}
+	})
+
+	t.Run("closes after the close date ends in Eastern time", func(t *testing.T) {
+		// 2023-11-06 at 23:30 EST
+		opportunity.UpdateStatus(clock.Fixed(time.Date(2023, 11, 7, 4, 30, 0, 0, time.UTC)))
+		assert.Equal(t, OpportunityStatusPosted, opportunity.Status)
+		assert.Equal(t, 0, *opportunity.DaysUntilClose)
+
+		// 2023-11-07 at 00:30 EST
+		opportunity.UpdateStatus(clock.Fixed(time.Date(2023, 11, 7, 5, 30, 0, 0, time.UTC)))
+		assert.Equal(t, OpportunityStatusClosed, opportunity.Status)
+		assert.Equal(t, -1, *opportunity.DaysUntilClose)
+	})
+
+	t.Run("no close date", func(t *testing.T) {
+		o := Opportunity{}
+		o.UpdateStatus(clock.Fixed(time.Now()))
+		assert.Equal(t, OpportunityStatusPosted, o.Status)
+		assert.Nil(t, o.DaysUntilClose)
+	})
+}
@@ -4,12 +4,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/oklog/ulid/v2"
+	"github.com/usdigitalresponse/grants-ingest/pkg/richText"
 )
 
 // Utilities
@@ -49,7 +52,10 @@ func (d *Date) UnmarshalJSON(b []byte) error {
 type AdditionalInformation struct {
 	Eligibility string `json:"eligibility,omitempty"`
 	Text        string `json:"text,omitempty"`
-	Url         string `json:"url,omitempty"`
+	Url         string `json:"url,omitempty" jsonschema:"format=uri"`
+	// Derived from Eligibility and Text (see NewFormattedText)
+	FormattedEligibility *FormattedText `json:"formatted_eligibility,omitempty"`
+	FormattedText        *FormattedText `json:"formatted_text,omitempty"`
 }
 
 // Agency model
@@ -57,6 +63,11 @@ type AdditionalInformation struct {
 type Agency struct {
 	Name string `json:"name,omitempty"`
 	Code string `json:"code,omitempty"`
+	// Derived from Code (see AgencyRegistry.Normalize)
+	DepartmentCode string `json:"department_code,omitempty"`
+	DepartmentName string `json:"department_name,omitempty"`
+	SubAgencyCode  string `json:"sub_agency_code,omitempty"`
+	SubAgencyName  string `json:"sub_agency_name,omitempty"`
 }
 
 // Applicant model
@@ -122,13 +133,75 @@ func ApplicantFromCode(code string) (app Applicant, err error) {
 	return
 }
 
+// Amount model
+
+// Amount is a monetary amount in U.S. cents. An amount that the source data explicitly leaves
+// unspecified (e.g. an award ceiling of "none") has Unspecified set and zero Cents.
+type Amount struct {
+	Cents       int64 `json:"cents" jsonschema:"minimum=0"`
+	Unspecified bool  `json:"unspecified"`
+}
+
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// maxAmountDollars is the largest whole-dollar amount whose value in cents fits in an int64.
+const maxAmountDollars = (1<<63 - 1) / 100
+
+// ParseAmount parses a whole-dollar amount (e.g. "150000") or a dollar amount with cents
+// (e.g. "150000.50"), or "none" for an unspecified amount. Returns nil for an empty string.
+func ParseAmount(s string) (*Amount, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	if strings.EqualFold(s, "none") {
+		return &Amount{Unspecified: true}, nil
+	}
+
+	dollars, cents, hasCents := strings.Cut(s, ".")
+	if dollars == "" || !isDigits(dollars) || (hasCents && (len(cents) == 0 || len(cents) > 2 || !isDigits(cents))) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	d, err := strconv.ParseInt(dollars, 10, 64)
+	if err != nil || d > maxAmountDollars {
+		return nil, fmt.Errorf("%w: %q is out of range", ErrInvalidAmount, s)
+	}
+	amount := &Amount{Cents: d * 100}
+	if hasCents {
+		c, _ := strconv.ParseInt(cents, 10, 64)
+		if len(cents) == 1 {
+			c *= 10
+		}
+		if amount.Cents > math.MaxInt64-c {
+			return nil, fmt.Errorf("%w: %q is out of range", ErrInvalidAmount, s)
+		}
+		amount.Cents += c
+	}
+	return amount, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 // Award Model
 
+// Award describes the funding of a grant. The Ceiling, Floor, and EstimatedTotalProgramFunding
+// strings are the amounts as provided by the source data, and the corresponding *Amount fields
+// are the same amounts parsed into cents (or nil if not provided or malformed).
 type Award struct {
-	Ceiling                      string `json:"ceiling,omitempty"`
-	Floor                        string `json:"floor,omitempty"`
-	EstimatedTotalProgramFunding string `json:"estimated_total_program_funding,omitempty"`
-	ExpectedNumberOfAwards       uint64 `json:"expected_number_of_awards,omitempty"`
+	Ceiling                            string  `json:"ceiling,omitempty"`
+	Floor                              string  `json:"floor,omitempty"`
+	EstimatedTotalProgramFunding       string  `json:"estimated_total_program_funding,omitempty"`
+	CeilingAmount                      *Amount `json:"ceiling_amount,omitempty"`
+	FloorAmount                        *Amount `json:"floor_amount,omitempty"`
+	EstimatedTotalProgramFundingAmount *Amount `json:"estimated_total_program_funding_amount,omitempty"`
+	ExpectedNumberOfAwards             uint64  `json:"expected_number_of_awards,omitempty"`
 }
 
 // CloseDate model
@@ -141,10 +214,37 @@ type CloseDate struct {
 // Email model
 
 type Email struct {
-	Address     string `json:"email,omitempty"`
+	Address     string `json:"email,omitempty" jsonschema:"format=email"`
 	Description string `json:"description,omitempty"`
 }
 
+// FormattedText model
+
+// ExcerptLength is the maximum number of characters in the Excerpt of FormattedText.
+const ExcerptLength = 280
+
+// FormattedText contains variants of source text, which may contain raw, escaped, or malformed HTML,
+// that are safe to display.
+type FormattedText struct {
+	HTML      string `json:"html,omitempty"`
+	PlainText string `json:"plain_text,omitempty"`
+	Excerpt   string `json:"excerpt,omitempty"`
+}
+
+// NewFormattedText returns the sanitized HTML, plain text, and excerpt variants of the text,
+// or nil if the text has no content.
+func NewFormattedText(s string) *FormattedText {
+	plainText := richText.PlainText(s)
+	if plainText == "" {
+		return nil
+	}
+	return &FormattedText{
+		HTML:      richText.SanitizeHTML(s),
+		PlainText: plainText,
+		Excerpt:   richText.Excerpt(plainText, ExcerptLength),
+	}
+}
+
 // FundingActivityCategory model
 
 type (
@@ -350,13 +450,19 @@ func OpportunityCategoryFromCode(code string) (cat OpportunityCategory, err erro
 // Opportunity model
 
 type Opportunity struct {
-	Id          string                `json:"id,omitempty"`
-	Number      string                `json:"number,omitempty"`
-	Title       string                `json:"title,omitempty"`
+	Id          string                `json:"id,omitempty" jsonschema:"required"`
+	Number      string                `json:"number,omitempty" jsonschema:"required"`
+	Title       string                `json:"title,omitempty" jsonschema:"required"`
 	Description string                `json:"description,omitempty"`
 	Category    OpportunityCategory   `json:"category,omitempty"`
-	Milestones  OpportunityMilestones `json:"milestones,omitempty"`
-	LastUpdated *Date                 `json:"last_updated,omitempty"`
+	Milestones  OpportunityMilestones `json:"milestones,omitempty" jsonschema:"required"`
+	LastUpdated *Date                 `json:"last_updated,omitempty" jsonschema:"required"`
+	IsForecast  bool                  `json:"is_forecast,omitempty"`
+	// Computed from Milestones and IsForecast (see UpdateStatus)
+	Status         OpportunityStatus `json:"status,omitempty"`
+	DaysUntilClose *int              `json:"days_until_close,omitempty"`
+	// Derived from Description (see NewFormattedText)
+	FormattedDescription *FormattedText `json:"formatted_description,omitempty"`
 }
 
 func (o *Opportunity) Validate() error {
@@ -379,7 +485,7 @@ func (o *Opportunity) Validate() error {
 // OpportunityMilestones model
 
 type OpportunityMilestones struct {
-	PostDate    *Date     `json:"post_date,omitempty"`
+	PostDate    *Date     `json:"post_date,omitempty" jsonschema:"required"`
 	Close       CloseDate `json:"close,omitempty"`
 	ArchiveDate *Date     `json:"archive_date,omitempty"`
 }
@@ -440,20 +546,33 @@ func (c cfdaNumber) Validate() error {
 	return nil
 }
 
+// AssistanceListing describes the federal assistance program (Assistance Listing) identified by
+// a CFDA number, as published by SAM.gov.
+type AssistanceListing struct {
+	Number cfdaNumber `json:"number"`
+	// Whether Number exists in the Assistance Listings extract used for enrichment
+	Found               bool    `json:"found"`
+	ProgramTitle        string  `json:"program_title,omitempty"`
+	Objectives          string  `json:"objectives,omitempty"`
+	AdministeringAgency *Agency `json:"administering_agency,omitempty"`
+}
+
 type Grant struct {
 	FundingInstrumentTypes           []FundingInstrument   `json:"funding_instrument_types,omitempty"`
 	CostSharingOrMatchingRequirement *bool                 `json:"cost_sharing_or_matching_requirement,omitempty"`
 	CFDANumbers                      []cfdaNumber          `json:"cfda_numbers,omitempty"`
+	AssistanceListings               []AssistanceListing   `json:"assistance_listings,omitempty"`
 	Bill                             string                `json:"bill,omitempty"`
 	EligibleApplicants               []Applicant           `json:"eligible_applicants,omitempty"`
+	Eligibility                      []Eligibility         `json:"eligibility,omitempty"`
 	AdditionalInformation            AdditionalInformation `json:"additional_information,omitempty"`
 	Agency                           Agency                `json:"agency,omitempty"`
 	Award                            Award                 `json:"award,omitempty"`
 	FundingActivity                  FundingActivity       `json:"funding_activity,omitempty"`
 	Grantor                          GrantorContact        `json:"grantor,omitempty"`
 	Metadata                         Metadata              `json:"metadata,omitempty"`
-	Opportunity                      Opportunity           `json:"opportunity,omitempty"`
-	Revision                         Revision              `json:"revision,omitempty"`
+	Opportunity                      Opportunity           `json:"opportunity,omitempty" jsonschema:"required"`
+	Revision                         Revision              `json:"revision,omitempty" jsonschema:"required"`
 }
 
 func (g *Grant) Validate() error {
@@ -509,9 +628,14 @@ func (v *grantModificationEventVersions) Validate() error {
 	return err.ErrorOrNil()
 }
 
+// SchemaVersion is the version of the schema of GrantModificationEvent data (see JSONSchemas).
+// It must be incremented whenever the JSON encoding of events changes.
+const SchemaVersion = "1.7.0"
+
 type GrantModificationEvent struct {
-	Type     grantModificationEventType     `json:"type,omitempty"`
-	Versions grantModificationEventVersions `json:"versions,omitempty"`
+	SchemaVersion string                         `json:"schema_version"`
+	Type          grantModificationEventType     `json:"type,omitempty"`
+	Versions      grantModificationEventVersions `json:"versions,omitempty"`
 }
 
 func (e *GrantModificationEvent) Validate() error {
@@ -528,6 +652,7 @@ func (e *GrantModificationEvent) Validate() error {
 
 func NewGrantModificationEvent(newVersion, previousVersion *Grant) (*GrantModificationEvent, error) {
 	ev := &GrantModificationEvent{
+		SchemaVersion: SchemaVersion,
 		Versions: grantModificationEventVersions{
 			New:      newVersion,
 			Previous: previousVersion,
@@ -546,3 +671,47 @@ func NewGrantModificationEvent(newVersion, previousVersion *Grant) (*GrantModifi
 
 	return ev, nil
 }
+
+// GrantStatusTransitionEvent model
+
+// GrantStatusTransitionEvent describes a change of an opportunity's lifecycle status that occurred
+// because time passed (e.g. its close date or archive date was reached), rather than because
+// the grant was modified.
+type GrantStatusTransitionEvent struct {
+	SchemaVersion  string            `json:"schema_version"`
+	PreviousStatus OpportunityStatus `json:"previous_status"`
+	Status         OpportunityStatus `json:"status"`
+	Grant          Grant             `json:"grant"`
+}
+
+func NewGrantStatusTransitionEvent(grant Grant, previousStatus OpportunityStatus) *GrantStatusTransitionEvent {
+	return &GrantStatusTransitionEvent{
+		SchemaVersion:  SchemaVersion,
+		PreviousStatus: previousStatus,
+		Status:         grant.Opportunity.Status,
+		Grant:          grant,
+	}
+}
+
+// GrantClosingSoonEvent model
+
+// GrantClosingSoonEvent is a reminder that an opportunity closes within a window of days, such
+// as 14 days. It is published at most once per window for each close date of an opportunity.
+type GrantClosingSoonEvent struct {
+	SchemaVersion  string `json:"schema_version"`
+	WindowDays     int    `json:"window_days" jsonschema:"minimum=0"`
+	DaysUntilClose int    `json:"days_until_close" jsonschema:"minimum=0"`
+	Grant          Grant  `json:"grant"`
+}
+
+func NewGrantClosingSoonEvent(grant Grant, windowDays int) *GrantClosingSoonEvent {
+	ev := &GrantClosingSoonEvent{
+		SchemaVersion: SchemaVersion,
+		WindowDays:    windowDays,
+		Grant:         grant,
+	}
+	if grant.Opportunity.DaysUntilClose != nil {
+		ev.DaysUntilClose = *grant.Opportunity.DaysUntilClose
+	}
+	return ev
+}
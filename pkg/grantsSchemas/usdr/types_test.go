@@ -237,6 +237,39 @@ func assertMultierrorOverlap(t *testing.T, allErrs []error, v interface{ Validat
 	}
 }
 
+func TestParseAmount(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected *Amount
+	}{
+		{"", nil},
+		{"  ", nil},
+		{"none", &Amount{Unspecified: true}},
+		{"None", &Amount{Unspecified: true}},
+		{"0", &Amount{Cents: 0}},
+		{"150000", &Amount{Cents: 15000000}},
+		{" 150000 ", &Amount{Cents: 15000000}},
+		{"150000.5", &Amount{Cents: 15000050}},
+		{"150000.05", &Amount{Cents: 15000005}},
+		{"999999999999999", &Amount{Cents: 99999999999999900}},
+	} {
+		t.Run(fmt.Sprintf("valid %q", tt.input), func(t *testing.T) {
+			amount, err := ParseAmount(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, amount)
+		})
+	}
+	for _, input := range []string{
+		"-1", "1,000", "$100", "100.", ".50", "100.505", "1e6", "unknown", "99999999999999999999",
+	} {
+		t.Run(fmt.Sprintf("invalid %q", input), func(t *testing.T) {
+			amount, err := ParseAmount(input)
+			assert.ErrorIs(t, err, ErrInvalidAmount)
+			assert.Nil(t, amount)
+		})
+	}
+}
+
 func TestGrant(t *testing.T) {
 	t.Run("Validate", func(t *testing.T) {
 		g := Grant{
@@ -0,0 +1,264 @@
+// Package richText renders text from grant data sources, which may contain HTML (often escaped
+// or malformed), as sanitized HTML, plain text, and short excerpts.
+package richText
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	xhtml "golang.org/x/net/html"
+)
+
+var (
+	// allowedElements are the elements that are kept in sanitized HTML. Other elements are
+	// removed, but their content is kept (unless they are droppedElements).
+	allowedElements = map[string]bool{
+		"a": true, "b": true, "blockquote": true, "br": true, "div": true, "em": true,
+		"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "hr": true,
+		"i": true, "li": true, "ol": true, "p": true, "strong": true, "u": true, "ul": true,
+		"table": true, "thead": true, "tbody": true, "tr": true, "th": true, "td": true,
+	}
+	// droppedElements are removed along with their content.
+	droppedElements = map[string]bool{
+		"script": true, "style": true, "iframe": true, "object": true, "embed": true,
+		"noscript": true, "template": true, "head": true, "title": true, "svg": true, "math": true,
+	}
+	voidElements = map[string]bool{"br": true, "hr": true}
+	// allowedURLSchemes are the schemes of link URLs that are kept in sanitized HTML.
+	allowedURLSchemes = map[string]bool{"http": true, "https": true, "mailto": true}
+
+	markupPattern        = regexp.MustCompile(`<[a-zA-Z/!]`)
+	escapedMarkupPattern = regexp.MustCompile(
+		`(?i)&lt;/?(a|b|br|div|em|font|h[1-6]|i|li|ol|p|span|strong|table|td|tr|u|ul)\b`)
+	paragraphBreakPattern = regexp.MustCompile(`\n\s*\n`)
+)
+
+// SanitizeHTML returns the text as HTML that only contains basic formatting elements (such as
+// paragraphs, lists, emphasis, and tables) and links with http, https, or mailto URLs. All other
+// attributes are removed, and unclosed elements are closed. Markup that was escaped as entities
+// (e.g. "&lt;p&gt;") is unescaped when the text contains no other markup. Text without any markup
+// is converted to HTML paragraphs and line breaks.
+func SanitizeHTML(s string) string {
+	if !markupPattern.MatchString(s) {
+		if !escapedMarkupPattern.MatchString(s) {
+			return textToHTML(s)
+		}
+		s = html.UnescapeString(s)
+	}
+
+	var b strings.Builder
+	var open []string
+	dropDepth := 0
+	z := xhtml.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		if tt == xhtml.ErrorToken {
+			break
+		}
+		tok := z.Token()
+		switch tt {
+		case xhtml.TextToken:
+			if dropDepth == 0 {
+				b.WriteString(html.EscapeString(tok.Data))
+			}
+		case xhtml.StartTagToken, xhtml.SelfClosingTagToken:
+			if droppedElements[tok.Data] {
+				if tt == xhtml.StartTagToken {
+					dropDepth++
+				}
+				continue
+			}
+			if dropDepth > 0 || !allowedElements[tok.Data] {
+				continue
+			}
+			if voidElements[tok.Data] {
+				b.WriteString("<" + tok.Data + ">")
+			} else if tt == xhtml.StartTagToken {
+				b.WriteString(startTag(tok))
+				open = append(open, tok.Data)
+			}
+		case xhtml.EndTagToken:
+			if droppedElements[tok.Data] {
+				dropDepth = max(dropDepth-1, 0)
+				continue
+			}
+			if dropDepth > 0 {
+				continue
+			}
+			// Close the most recent matching element, along with any elements opened within it.
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] == tok.Data {
+					for _, name := range reversed(open[i:]) {
+						b.WriteString("</" + name + ">")
+					}
+					open = open[:i]
+					break
+				}
+			}
+		}
+	}
+	for _, name := range reversed(open) {
+		b.WriteString("</" + name + ">")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// startTag returns the start tag of an allowed element, without any attributes except the
+// href of links with allowed URLs.
+func startTag(tok xhtml.Token) string {
+	if tok.Data != "a" {
+		return "<" + tok.Data + ">"
+	}
+	for _, attr := range tok.Attr {
+		if attr.Key != "href" {
+			continue
+		}
+		u, err := url.Parse(strings.TrimSpace(attr.Val))
+		if err == nil && allowedURLSchemes[strings.ToLower(u.Scheme)] {
+			return `<a href="` + html.EscapeString(u.String()) + `" rel="nofollow noopener noreferrer">`
+		}
+	}
+	return "<a>"
+}
+
+func reversed(s []string) []string {
+	r := make([]string, len(s))
+	for i, v := range s {
+		r[len(s)-1-i] = v
+	}
+	return r
+}
+
+// textToHTML converts text without markup to HTML, with a paragraph for each block of text
+// separated by blank lines, and line breaks within paragraphs.
+func textToHTML(s string) string {
+	var b strings.Builder
+	for _, paragraph := range paragraphBreakPattern.Split(strings.TrimSpace(s), -1) {
+		lines := strings.Split(strings.TrimSpace(paragraph), "\n")
+		if len(lines) == 1 && lines[0] == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		for i, line := range lines {
+			if i > 0 {
+				b.WriteString("<br>")
+			}
+			b.WriteString(html.EscapeString(strings.TrimSpace(line)))
+		}
+		b.WriteString("</p>")
+	}
+	return b.String()
+}
+
+// PlainText returns the text without markup, with paragraphs separated by blank lines, list items
+// prefixed by "- ", and the URL of each link following its text (unless they are the same).
+// Whitespace within paragraphs is collapsed.
+func PlainText(s string) string {
+	w := &textWriter{}
+	var linkURL string
+	var linkText strings.Builder
+	z := xhtml.NewTokenizer(strings.NewReader(SanitizeHTML(s)))
+	for {
+		tt := z.Next()
+		if tt == xhtml.ErrorToken {
+			break
+		}
+		tok := z.Token()
+		switch tt {
+		case xhtml.TextToken:
+			w.write(tok.Data)
+			linkText.WriteString(tok.Data)
+		case xhtml.StartTagToken, xhtml.SelfClosingTagToken:
+			switch tok.Data {
+			case "b", "em", "i", "strong", "u":
+			case "a":
+				linkURL, linkText = "", strings.Builder{}
+				for _, attr := range tok.Attr {
+					if attr.Key == "href" {
+						linkURL = strings.TrimPrefix(attr.Val, "mailto:")
+					}
+				}
+			case "br":
+				w.breakLine(1)
+			case "li":
+				w.breakLine(1)
+				w.prefix = "- "
+			case "tr":
+				w.breakLine(1)
+			case "td", "th":
+				w.pendingSpace = true
+			default:
+				w.breakLine(2)
+			}
+		case xhtml.EndTagToken:
+			switch tok.Data {
+			case "a":
+				if linkURL != "" && strings.TrimSpace(linkText.String()) != linkURL {
+					w.write(" (" + linkURL + ")")
+				}
+				linkURL = ""
+			case "b", "em", "i", "strong", "u", "td", "th", "li", "tr":
+			default:
+				w.breakLine(2)
+			}
+		}
+	}
+	return w.String()
+}
+
+// textWriter writes text with collapsed whitespace and pending line breaks.
+type textWriter struct {
+	b            strings.Builder
+	pendingBreak int
+	pendingSpace bool
+	// Written before the next text, after any pending line break
+	prefix string
+}
+
+// breakLine ensures that the next text follows n line breaks.
+func (w *textWriter) breakLine(n int) {
+	if w.b.Len() > 0 && n > w.pendingBreak {
+		w.pendingBreak = n
+	}
+}
+
+func (w *textWriter) write(text string) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		w.pendingSpace = w.pendingSpace || text != ""
+		return
+	}
+	leadingSpace := strings.TrimLeftFunc(text, unicode.IsSpace) != text
+	if w.pendingBreak > 0 {
+		w.b.WriteString(strings.Repeat("\n", w.pendingBreak))
+	} else if (w.pendingSpace || leadingSpace) && w.b.Len() > 0 && w.prefix == "" {
+		w.b.WriteByte(' ')
+	}
+	w.b.WriteString(w.prefix)
+	w.b.WriteString(strings.Join(fields, " "))
+	w.pendingBreak, w.prefix = 0, ""
+	w.pendingSpace = strings.TrimRightFunc(text, unicode.IsSpace) != text
+}
+
+func (w *textWriter) String() string {
+	return w.b.String()
+}
+
+// Excerpt returns the beginning of the text as a single line of at most maxLength characters.
+// Text that is longer is shortened at a word boundary (when possible), and ends with "…".
+func Excerpt(text string, maxLength int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= maxLength || maxLength < 1 {
+		return text
+	}
+	runes := []rune(text)
+	cut := string(runes[:maxLength-1])
+	if i := strings.LastIndexByte(cut, ' '); i > len(cut)/2 && runes[maxLength-1] != ' ' {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,;:.-–—") + "…"
+}
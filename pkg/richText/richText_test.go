@@ -0,0 +1,124 @@
+package richText
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeHTML(t *testing.T) {
+	for _, tt := range []struct {
+		name, input, expected string
+	}{
+		{
+			"allowed elements are kept without attributes",
+			`<p class="x" style="color:red">Funding for <strong onclick="alert(1)">rural</strong> areas</p>`,
+			`<p>Funding for <strong>rural</strong> areas</p>`,
+		},
+		{
+			"disallowed elements are unwrapped",
+			`<font face="Arial"><span>Eligible</span> applicants</font>`,
+			`Eligible applicants`,
+		},
+		{
+			"dropped elements are removed with their content",
+			`Before<script>alert("x")</script><style>p{}</style><iframe src="https://example.com">frame</iframe> after`,
+			`Before after`,
+		},
+		{
+			"links keep safe URLs only",
+			`<a href="https://www.grants.gov/?a=1&b=2" target="_blank">Grants.gov</a> <a href="javascript:alert(1)">bad</a>`,
+			`<a href="https://www.grants.gov/?a=1&amp;b=2" rel="nofollow noopener noreferrer">Grants.gov</a> <a>bad</a>`,
+		},
+		{
+			"unclosed and stray elements are balanced",
+			`<ul><li><em>One<li>Two</ul></p>`,
+			`<ul><li><em>One<li>Two</li></em></li></ul>`,
+		},
+		{
+			"void elements",
+			`Line<br/>break<hr>`,
+			`Line<br>break<hr>`,
+		},
+		{
+			"text is escaped",
+			`Costs &lt; $5,000 &amp; "other" <b>R&D</b>`,
+			`Costs &lt; $5,000 &amp; &#34;other&#34; <b>R&amp;D</b>`,
+		},
+		{
+			"escaped markup is unescaped",
+			`&lt;p&gt;The program &amp;amp; its &lt;strong&gt;goals&lt;/strong&gt;&lt;/p&gt;`,
+			`<p>The program &amp; its <strong>goals</strong></p>`,
+		},
+		{
+			"text without markup",
+			"First line\nsecond line & more\n\n  \nNew paragraph\n",
+			`<p>First line<br>second line &amp; more</p><p>New paragraph</p>`,
+		},
+		{"empty", "", ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SanitizeHTML(tt.input))
+		})
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	for _, tt := range []struct {
+		name, input, expected string
+	}{
+		{
+			"paragraphs and inline elements",
+			"<p>The   <b>Rural</b>\n<i>Development</i>&nbsp;program.</p><p>Second paragraph.</p>",
+			"The Rural Development program.\n\nSecond paragraph.",
+		},
+		{
+			"lists",
+			"<p>Eligible:</p><ul>\n<li>States</li>\n<li>Tribes<li>Counties</ul>Other text",
+			"Eligible:\n\n- States\n- Tribes\n- Counties\n\nOther text",
+		},
+		{
+			"line breaks and tables",
+			"Contact:<br>Jane Doe<table><tr><th>Award</th><td>$5,000</td></tr><tr><td>Term</td><td>2 years</td></tr></table>",
+			"Contact:\nJane Doe\n\nAward $5,000\nTerm 2 years",
+		},
+		{
+			"links",
+			`See <a href="https://www.grants.gov">Grants.gov</a> or <a href="https://sam.gov">https://sam.gov</a>, or email <a href="mailto:help@example.gov">us</a>.`,
+			"See Grants.gov (https://www.grants.gov) or https://sam.gov, or email us (help@example.gov).",
+		},
+		{
+			"entities and escaped markup",
+			"&lt;p&gt;Costs &amp;lt; $5,000&lt;/p&gt;&lt;p&gt;R&amp;amp;D&lt;/p&gt;",
+			"Costs < $5,000\n\nR&D",
+		},
+		{
+			"text without markup keeps line breaks",
+			"First line\nsecond   line\n\nNew paragraph",
+			"First line\nsecond line\n\nNew paragraph",
+		},
+		{
+			"dropped content",
+			"<style>.a{color:red}</style><div>Visible</div><script>hidden()</script>",
+			"Visible",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, PlainText(tt.input))
+		})
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	assert.Equal(t, "Short text", Excerpt("Short\n\ntext", 20))
+	assert.Equal(t, "The quick brown…", Excerpt("The quick brown fox jumps over the lazy dog.", 18))
+	assert.Equal(t, "The quick brown fox…", Excerpt("The quick brown fox jumps over the lazy dog.", 20))
+	assert.Equal(t, "The quick brown fox…", Excerpt("The quick brown fox, jumps over the lazy dog.", 21))
+	assert.Equal(t, "Supercalifragilisti…", Excerpt("Supercalifragilisticexpialidocious", 20))
+	assert.Equal(t, "Éééé…", Excerpt("Éééééé", 5))
+	assert.Equal(t, "Unlimited", Excerpt("Unlimited", 0))
+
+	long := strings.Repeat("word ", 100)
+	assert.LessOrEqual(t, len([]rune(Excerpt(long, 50))), 50)
+}
@@ -1860,6 +1860,36 @@ resource "datadog_dashboard" "service_dashboard" {
           request {
             display_type = "bars"
 
+            formula {
+              formula_expression = "records_skipped"
+              alias              = "Skipped"
+              style {
+                palette       = "cool"
+                palette_index = 4
+              }
+            }
+            query {
+              metric_query {
+                name  = "records_skipped"
+                query = "sum:grants_ingest.SplitFFISSpreadsheet.opportunity.skipped{$env,$service,$version}.as_count()"
+              }
+            }
+
+            formula {
+              formula_expression = "records_updated"
+              alias              = "Updated"
+              style {
+                palette       = "purple"
+                palette_index = 4
+              }
+            }
+            query {
+              metric_query {
+                name  = "records_updated"
+                query = "sum:grants_ingest.SplitFFISSpreadsheet.opportunity.updated{$env,$service,$version}.as_count()"
+              }
+            }
+
             formula {
               formula_expression = "records_created"
               alias              = "Created"
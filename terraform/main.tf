@@ -276,6 +276,40 @@ module "grants_prepared_dynamodb_table" {
   enable_encryption             = true
 }
 
+module "grant_reminders_dynamodb_table" {
+  source  = "cloudposse/dynamodb/aws"
+  version = "0.36.0"
+  context = module.this.context
+  enabled = var.grant_closing_soon_events_enabled
+
+  name                          = "grantreminders"
+  hash_key                      = "grant_id"
+  range_key                     = "reminder"
+  table_class                   = "STANDARD"
+  billing_mode                  = "PAY_PER_REQUEST"
+  ttl_enabled                   = true
+  ttl_attribute                 = "expires_at"
+  enable_point_in_time_recovery = false
+  enable_encryption             = true
+}
+
+module "grant_status_transitions_dynamodb_table" {
+  source  = "cloudposse/dynamodb/aws"
+  version = "0.36.0"
+  context = module.this.context
+  enabled = var.grant_status_transition_events_enabled
+
+  name                          = "grantstatustransitions"
+  hash_key                      = "grant_id"
+  range_key                     = "transition"
+  table_class                   = "STANDARD"
+  billing_mode                  = "PAY_PER_REQUEST"
+  ttl_enabled                   = true
+  ttl_attribute                 = "expires_at"
+  enable_point_in_time_recovery = false
+  enable_encryption             = true
+}
+
 resource "aws_dynamodb_contributor_insights" "grants_prepared_dynamodb_main" {
   count = var.dynamodb_contributor_insights_enabled ? 1 : 0
 
@@ -527,6 +561,7 @@ module "SplitGrantsGovXMLDB" {
   grants_prepared_dynamodb_table_name = module.grants_prepared_dynamodb_table.table_name
   grants_prepared_dynamodb_table_arn  = module.grants_prepared_dynamodb_table.table_arn
   is_forecasted_grants_enabled        = var.is_forecasted_grants_enabled
+  is_strict_validation_enabled        = var.grantsgov_strict_validation_enabled
   max_split_records                   = var.max_split_grantsgov_records
   max_split_opportunity_records       = var.max_split_grantsgov_opportunity_records
   max_split_forecast_records          = var.max_split_grantsgov_forecast_records
@@ -647,12 +682,15 @@ module "SplitFFISSpreadsheet" {
   additional_lambda_execution_policy_documents = local.lambda_execution_policies
   lambda_layer_arns                            = local.lambda_layer_arns
 
-  grants_source_data_bucket_name   = module.grants_source_data_bucket.bucket_id
-  grants_prepared_data_bucket_name = module.grants_prepared_data_bucket.bucket_id
+  grants_source_data_bucket_name      = module.grants_source_data_bucket.bucket_id
+  grants_prepared_data_bucket_name    = module.grants_prepared_data_bucket.bucket_id
+  grants_prepared_dynamodb_table_name = module.grants_prepared_dynamodb_table.table_name
+  grants_prepared_dynamodb_table_arn  = module.grants_prepared_dynamodb_table.table_arn
 
   depends_on = [
     module.grants_source_data_bucket,
     module.grants_prepared_data_bucket,
+    module.grants_prepared_dynamodb_table,
     aws_sqs_queue.ffis_downloads,
   ]
 }
@@ -722,9 +760,88 @@ module "PublishGrantEvents" {
   additional_lambda_execution_policy_documents = local.lambda_execution_policies
   lambda_layer_arns                            = local.lambda_layer_arns
 
-  dynamodb_table_name = module.grants_prepared_dynamodb_table.table_name
+  dynamodb_table_name                    = module.grants_prepared_dynamodb_table.table_name
+  enforce_event_schema                   = var.grant_events_schema_enforced
+  grants_prepared_data_bucket_name       = module.grants_prepared_data_bucket.bucket_id
+  assistance_listings_enrichment_enabled = var.assistance_listings_enrichment_enabled
 
   depends_on = [
-    module.grants_prepared_dynamodb_table
+    module.grants_prepared_dynamodb_table,
+    module.grants_prepared_data_bucket,
   ]
 }
+
+module "PublishGrantStatusTransitions" {
+  source = "./modules/PublishGrantStatusTransitions"
+  count  = var.grant_status_transition_events_enabled ? 1 : 0
+
+  namespace                                    = var.namespace
+  function_name                                = "PublishGrantStatusTransitions"
+  permissions_boundary_arn                     = local.permissions_boundary_arn
+  lambda_artifact_bucket                       = module.lambda_artifacts_bucket.bucket_id
+  log_retention_in_days                        = var.lambda_default_log_retention_in_days
+  log_level                                    = var.lambda_default_log_level
+  lambda_autobuild                             = var.lambda_binaries_autobuild
+  lambda_binaries_base_path                    = local.lambda_binaries_base_path
+  lambda_arch                                  = var.lambda_arch
+  additional_environment_variables             = local.lambda_environment_variables
+  additional_lambda_execution_policy_documents = local.lambda_execution_policies
+  lambda_layer_arns                            = local.lambda_layer_arns
+
+  scheduler_group_name                = try(aws_scheduler_schedule_group.default[0].name, "")
+  eventbridge_scheduler_enabled       = var.eventbridge_scheduler_enabled
+  grants_prepared_dynamodb_table_name = module.grants_prepared_dynamodb_table.table_name
+  grants_prepared_dynamodb_table_arn  = module.grants_prepared_dynamodb_table.table_arn
+  transitions_dynamodb_table_name     = module.grant_status_transitions_dynamodb_table.table_name
+  transitions_dynamodb_table_arn      = module.grant_status_transitions_dynamodb_table.table_arn
+}
+
+module "PublishGrantClosingSoonEvents" {
+  source = "./modules/PublishGrantClosingSoonEvents"
+  count  = var.grant_closing_soon_events_enabled ? 1 : 0
+
+  namespace                                    = var.namespace
+  function_name                                = "PublishGrantClosingSoonEvents"
+  permissions_boundary_arn                     = local.permissions_boundary_arn
+  lambda_artifact_bucket                       = module.lambda_artifacts_bucket.bucket_id
+  log_retention_in_days                        = var.lambda_default_log_retention_in_days
+  log_level                                    = var.lambda_default_log_level
+  lambda_autobuild                             = var.lambda_binaries_autobuild
+  lambda_binaries_base_path                    = local.lambda_binaries_base_path
+  lambda_arch                                  = var.lambda_arch
+  additional_environment_variables             = local.lambda_environment_variables
+  additional_lambda_execution_policy_documents = local.lambda_execution_policies
+  lambda_layer_arns                            = local.lambda_layer_arns
+
+  scheduler_group_name                = try(aws_scheduler_schedule_group.default[0].name, "")
+  eventbridge_scheduler_enabled       = var.eventbridge_scheduler_enabled
+  grants_prepared_dynamodb_table_name = module.grants_prepared_dynamodb_table.table_name
+  grants_prepared_dynamodb_table_arn  = module.grants_prepared_dynamodb_table.table_arn
+  reminders_dynamodb_table_name       = module.grant_reminders_dynamodb_table.table_name
+  reminders_dynamodb_table_arn        = module.grant_reminders_dynamodb_table.table_arn
+  closing_soon_windows                = var.grant_closing_soon_windows
+}
+
+module "ReportDataQuality" {
+  source = "./modules/ReportDataQuality"
+  count  = var.data_quality_report_enabled ? 1 : 0
+
+  namespace                                    = var.namespace
+  function_name                                = "ReportDataQuality"
+  permissions_boundary_arn                     = local.permissions_boundary_arn
+  lambda_artifact_bucket                       = module.lambda_artifacts_bucket.bucket_id
+  log_retention_in_days                        = var.lambda_default_log_retention_in_days
+  log_level                                    = var.lambda_default_log_level
+  lambda_autobuild                             = var.lambda_binaries_autobuild
+  lambda_binaries_base_path                    = local.lambda_binaries_base_path
+  lambda_arch                                  = var.lambda_arch
+  additional_environment_variables             = local.lambda_environment_variables
+  additional_lambda_execution_policy_documents = local.lambda_execution_policies
+  lambda_layer_arns                            = local.lambda_layer_arns
+
+  scheduler_group_name                = try(aws_scheduler_schedule_group.default[0].name, "")
+  eventbridge_scheduler_enabled       = var.eventbridge_scheduler_enabled
+  grants_prepared_dynamodb_table_name = module.grants_prepared_dynamodb_table.table_name
+  grants_prepared_dynamodb_table_arn  = module.grants_prepared_dynamodb_table.table_arn
+  report_bucket_name                  = module.grants_source_data_bucket.bucket_id
+}
@@ -0,0 +1,108 @@
+terraform {
+  required_version = "1.5.1"
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.46.0"
+    }
+  }
+}
+
+locals {
+  // Since EventBridge Scheduler is not yet supported by localstack, we conditionally set the below
+  // lambda_trigger local value if var.eventbridge_scheduler_enabled is false.
+  eventbridge_scheduler_trigger = {
+    principal  = "scheduler.amazonaws.com"
+    source_arn = try(aws_scheduler_schedule.default[0].arn, "")
+  }
+  cloudwatch_events_trigger = {
+    principal  = "events.amazonaws.com"
+    source_arn = try(aws_cloudwatch_event_rule.schedule[0].arn, "")
+  }
+  lambda_trigger = var.eventbridge_scheduler_enabled ? local.eventbridge_scheduler_trigger : local.cloudwatch_events_trigger
+  dd_tags = merge(
+    {
+      for item in compact(split(",", try(var.additional_environment_variables.DD_TAGS, ""))) :
+      split(":", trimspace(item))[0] => try(split(":", trimspace(item))[1], "")
+    },
+    var.datadog_custom_tags,
+    { handlername = lower(var.function_name), },
+  )
+}
+
+data "aws_cloudwatch_event_bus" "target" {
+  name = var.event_bus_name
+}
+
+module "lambda_execution_policy" {
+  source  = "cloudposse/iam-policy/aws"
+  version = "1.0.1"
+
+  iam_source_policy_documents = var.additional_lambda_execution_policy_documents
+  iam_policy_statements = {
+    AllowDynamoDBScan = {
+      effect    = "Allow"
+      actions   = ["dynamodb:Scan"]
+      resources = [var.grants_prepared_dynamodb_table_arn]
+    }
+    AllowDynamoDBManageReminders = {
+      effect    = "Allow"
+      actions   = ["dynamodb:PutItem", "dynamodb:DeleteItem"]
+      resources = [var.reminders_dynamodb_table_arn]
+    }
+    PublishToEventBridge = {
+      effect    = "Allow"
+      actions   = ["events:PutEvents"]
+      resources = [data.aws_cloudwatch_event_bus.target.arn]
+    }
+  }
+}
+
+module "lambda_artifact" {
+  source = "../taskfile_lambda_builder"
+
+  autobuild        = var.lambda_autobuild
+  binary_base_path = var.lambda_binaries_base_path
+  function_name    = var.function_name
+  s3_bucket        = var.lambda_artifact_bucket
+}
+
+module "lambda_function" {
+  source  = "terraform-aws-modules/lambda/aws"
+  version = "6.7.1"
+
+  function_name = "${var.namespace}-${var.function_name}"
+  description   = "Publishes reminders for grants that close soon"
+
+  role_permissions_boundary         = var.permissions_boundary_arn
+  attach_cloudwatch_logs_policy     = true
+  cloudwatch_logs_retention_in_days = var.log_retention_in_days
+  attach_policy_json                = true
+  policy_json                       = module.lambda_execution_policy.json
+
+  handler       = "bootstrap"
+  runtime       = "provided.al2"
+  architectures = [var.lambda_arch]
+  publish       = true
+  layers        = var.lambda_layer_arns
+
+  create_package = false
+  s3_existing_package = {
+    bucket = var.lambda_artifact_bucket
+    key    = module.lambda_artifact.s3_object_key
+  }
+
+  timeout = 900 # 15 minutes, in seconds
+  environment_variables = merge(var.additional_environment_variables, {
+    CLOSING_SOON_WINDOWS          = join(",", var.closing_soon_windows)
+    DD_TAGS                       = join(",", sort([for k, v in local.dd_tags : "${k}:${v}"]))
+    EVENT_BUS_NAME                = data.aws_cloudwatch_event_bus.target.name
+    GRANTS_PREPARED_DYNAMODB_NAME = var.grants_prepared_dynamodb_table_name
+    LOG_LEVEL                     = var.log_level
+    REMINDERS_DYNAMODB_NAME       = var.reminders_dynamodb_table_name
+  })
+
+  allowed_triggers = {
+    Schedule = local.lambda_trigger
+  }
+}
@@ -38,6 +38,10 @@ resource "aws_sqs_queue" "dlq" {
   }
 }
 
+data "aws_s3_bucket" "prepared_data" {
+  bucket = var.grants_prepared_data_bucket_name
+}
+
 data "aws_dynamodb_table" "source" {
   name = var.dynamodb_table_name
 }
@@ -90,6 +94,11 @@ module "lambda_function" {
       actions   = ["dynamodb:ListStreams"]
       resources = ["*"]
     }
+    ReadAssistanceListingsCatalog = {
+      effect    = "Allow"
+      actions   = ["s3:GetObject"]
+      resources = ["${data.aws_s3_bucket.prepared_data.arn}/${var.assistance_listings_object_key}"]
+    }
   }
 
   handler       = "bootstrap"
@@ -107,9 +116,14 @@ module "lambda_function" {
   timeout     = 30 # seconds
   memory_size = 128
   environment_variables = merge(var.additional_environment_variables, {
-    DD_TAGS        = join(",", sort([for k, v in local.dd_tags : "${k}:${v}"]))
-    LOG_LEVEL      = var.log_level
-    EVENT_BUS_NAME = data.aws_cloudwatch_event_bus.target.name
+    DD_TAGS              = join(",", sort([for k, v in local.dd_tags : "${k}:${v}"]))
+    LOG_LEVEL            = var.log_level
+    EVENT_BUS_NAME       = data.aws_cloudwatch_event_bus.target.name
+    ENFORCE_EVENT_SCHEMA = var.enforce_event_schema
+    GRANTS_PREPARED_DATA_BUCKET_NAME = (
+      var.assistance_listings_enrichment_enabled ? data.aws_s3_bucket.prepared_data.id : ""
+    )
+    ASSISTANCE_LISTINGS_REFRESH_INTERVAL = var.assistance_listings_refresh_interval
   })
 
   event_source_mapping = {
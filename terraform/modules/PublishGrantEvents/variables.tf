@@ -87,3 +87,32 @@ variable "event_bus_name" {
   type        = string
   default     = "default"
 }
+
+variable "enforce_event_schema" {
+  description = "When true, events that do not conform to the GrantModificationEvent schema are not published (and the stream record fails). Otherwise, schema violations are only logged."
+  type        = bool
+  default     = false
+}
+
+variable "grants_prepared_data_bucket_name" {
+  description = "Name of the S3 bucket used to store grants prepared data, which contains the Assistance Listings catalog."
+  type        = string
+}
+
+variable "assistance_listings_enrichment_enabled" {
+  description = "When true, published grants are enriched with the Assistance Listings catalog imported by the grants-ingest CLI."
+  type        = bool
+  default     = false
+}
+
+variable "assistance_listings_object_key" {
+  description = "S3 key of the Assistance Listings catalog in the prepared data bucket. Must match the key read by the Lambda function."
+  type        = string
+  default     = "sam.gov/assistance_listings/v1.json"
+}
+
+variable "assistance_listings_refresh_interval" {
+  description = "How long the Lambda function uses a loaded Assistance Listings catalog before reloading it (e.g. \"1h\")."
+  type        = string
+  default     = "1h"
+}
@@ -58,6 +58,17 @@ module "lambda_execution_policy" {
         "${data.aws_s3_bucket.grants_source_data.arn}/sources/*/*/*/ffis.org/raw.eml",
       ]
     }
+    AllowS3UploadQuarantinedEmails = {
+      effect = "Allow"
+      actions = [
+        "s3:PutObject",
+        "s3:PutObjectTagging",
+      ]
+      resources = [
+        # Path: quarantine/YYYY/mm/dd/ffis.org/<message>.eml
+        "${data.aws_s3_bucket.grants_source_data.arn}/quarantine/*/*/*/ffis.org/*.eml",
+      ]
+    }
   }
 }
 
@@ -89,7 +89,7 @@ variable "email_delivery_object_key_prefix" {
 }
 
 variable "grants_source_data_bucket_name" {
-  description = "Name of the S3 bucket to which received emails will be copied for further processing upon successful verification."
+  description = "Name of the S3 bucket to which received emails will be copied for further processing upon successful verification, or quarantined upon failed verification."
   type        = string
 }
 
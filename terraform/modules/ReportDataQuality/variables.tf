@@ -0,0 +1,116 @@
+// Common
+variable "namespace" {
+  type        = string
+  description = "Prefix to use for resource names and identifiers."
+}
+
+variable "function_name" {
+  description = "Name of this Lambda function (excluding namespace prefix)."
+  type        = string
+}
+
+variable "permissions_boundary_arn" {
+  description = "ARN of the IAM policy to apply as a permissions boundary when provisioning a new role. Ignored if `role_arn` is null."
+  type        = string
+  default     = null
+}
+
+variable "lambda_layer_arns" {
+  description = "Lambda layer ARNs to attach to the function."
+  type        = list(string)
+  default     = []
+}
+
+variable "lambda_artifact_bucket" {
+  description = "Name of the S3 bucket used to store Lambda source artifacts."
+  type        = string
+}
+
+variable "lambda_binaries_base_path" {
+  description = "Path to the local directory where compiled handlers are outputted to per-Lambda subdirectories."
+  type        = string
+}
+
+variable "lambda_autobuild" {
+  description = "When true, a Lambda handler binary will be compiled when missing or outdated. When false, the compiled Lambda handler binary must already exist under `lambda_binaries_base_path`."
+  type        = bool
+}
+
+variable "lambda_arch" {
+  description = "The target build architecture for Lambda functions (either x86_64 or arm64)."
+  type        = string
+
+  validation {
+    condition     = var.lambda_arch == "x86_64" || var.lambda_arch == "arm64"
+    error_message = "Architecture must be x86_64 or arm64."
+  }
+}
+
+variable "log_level" {
+  description = "Value for the LOG_LEVEL environment variable."
+  type        = string
+  default     = "INFO"
+}
+
+variable "log_retention_in_days" {
+  description = "Number of days to retain logs."
+  type        = number
+  default     = 30
+}
+
+variable "additional_lambda_execution_policy_documents" {
+  description = "JSON policy document(s) containing permissions to configure for the Lambda function, in addition to any defined by this module."
+  type        = list(string)
+  default     = []
+}
+
+variable "additional_environment_variables" {
+  description = "Environment variables to configure for the Lambda function, in addition to any defined by this module."
+  type        = map(string)
+  default     = {}
+}
+
+variable "datadog_custom_tags" {
+  description = "Custom tags to configure on the DD_TAGS environment variable."
+  type        = map(string)
+  default     = {}
+}
+
+// Module-specific
+variable "eventbridge_scheduler_enabled" {
+  description = "If false, uses CloudWatch Events to schedule Lambda execution. This should only be false in development."
+  type        = bool
+  default     = true
+}
+
+variable "scheduler_group_name" {
+  description = "Name of the AWS EventBridge Scheduler group in which schedules should be placed."
+  type        = string
+}
+
+variable "grants_prepared_dynamodb_table_name" {
+  description = "Name of the DynamoDB table used to persist grants prepared data."
+  type        = string
+}
+
+variable "grants_prepared_dynamodb_table_arn" {
+  description = "ARN of the DynamoDB table used to persist grants prepared data."
+  type        = string
+}
+
+variable "report_bucket_name" {
+  description = "Name of the S3 bucket to which data quality reports are saved."
+  type        = string
+}
+
+variable "report_key_prefix" {
+  description = "Prefix of the S3 object keys of data quality reports."
+  type        = string
+  default     = "reports/quality/"
+}
+
+variable "report_sample_size" {
+  description = "Maximum number of offending grant IDs to include in data quality reports for each score."
+  type        = number
+  default     = 10
+}
@@ -41,6 +41,14 @@ module "lambda_execution_policy" {
         "${data.aws_s3_bucket.source_data.arn}/sources/*/*/*/ffis.org/download.xlsx"
       ]
     }
+    AllowS3UploadParseReports = {
+      effect  = "Allow"
+      actions = ["s3:PutObject"]
+      resources = [
+        # Saved alongside the source spreadsheet
+        "${data.aws_s3_bucket.source_data.arn}/sources/*/*/*/ffis.org/parse-report.json"
+      ]
+    }
     AllowInspectS3PreparedData = {
       effect = "Allow"
       actions = [
@@ -59,6 +67,19 @@ module "lambda_execution_policy" {
         "${data.aws_s3_bucket.prepared_data.arn}/*/*/ffis.org/v1.json"
       ]
     }
+    AllowS3ManageSpreadsheetManifests = {
+      effect  = "Allow"
+      actions = ["s3:GetObject", "s3:PutObject"]
+      resources = [
+        # Path: ffis.org/manifests/YYYY/MM/DD/manifest.json
+        "${data.aws_s3_bucket.prepared_data.arn}/ffis.org/manifests/*/*/*/manifest.json"
+      ]
+    }
+    AllowDynamoDBUpdateStaleBills = {
+      effect    = "Allow"
+      actions   = ["dynamodb:UpdateItem"]
+      resources = [var.grants_prepared_dynamodb_table_arn]
+    }
   }
 }
 
@@ -103,8 +124,10 @@ module "lambda_function" {
     DD_TAGS                          = join(",", sort([for k, v in local.dd_tags : "${k}:${v}"]))
     DOWNLOAD_CHUNK_LIMIT             = "20"
     GRANTS_PREPARED_DATA_BUCKET_NAME = data.aws_s3_bucket.prepared_data.id
+    GRANTS_PREPARED_DYNAMODB_NAME    = var.grants_prepared_dynamodb_table_name
     LOG_LEVEL                        = var.log_level
     MAX_CONCURRENT_UPLOADS           = "10"
+    STALE_BILL_ACTION                = var.stale_bill_action
   })
 
   allowed_triggers = {
@@ -86,3 +86,24 @@ variable "grants_prepared_data_bucket_name" {
   description = "Name of the S3 bucket used to store grants prepared data."
   type        = string
 }
+
+variable "grants_prepared_dynamodb_table_name" {
+  description = "Name of the DynamoDB table used to persist grants prepared data."
+  type        = string
+}
+
+variable "grants_prepared_dynamodb_table_arn" {
+  description = "ARN of the DynamoDB table used to persist grants prepared data."
+  type        = string
+}
+
+variable "stale_bill_action" {
+  description = "Action taken on the DynamoDB Bill attribute of grants that no longer appear in the latest FFIS spreadsheet. One of: flag, clear, none."
+  type        = string
+  default     = "flag"
+
+  validation {
+    condition     = contains(["flag", "clear", "none"], var.stale_bill_action)
+    error_message = "Value must be one of: flag, clear, none."
+  }
+}
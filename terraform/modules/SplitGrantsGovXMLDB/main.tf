@@ -110,6 +110,7 @@ module "lambda_function" {
     MAX_SPLIT_OPPORTUNITY_RECORDS    = tostring(var.max_split_opportunity_records)
     MAX_SPLIT_FORECAST_RECORDS       = tostring(var.max_split_forecast_records)
     IS_FORECASTED_GRANTS_ENABLED     = var.is_forecasted_grants_enabled
+    IS_STRICT_VALIDATION_ENABLED     = var.is_strict_validation_enabled
   })
 
   allowed_triggers = {
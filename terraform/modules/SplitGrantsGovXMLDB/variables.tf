@@ -103,6 +103,12 @@ variable "is_forecasted_grants_enabled" {
   default     = false
 }
 
+variable "is_strict_validation_enabled" {
+  description = "When true, records that violate the constraints of the Grants.gov XML schema are skipped instead of being stored in S3."
+  type        = bool
+  default     = false
+}
+
 variable "max_split_records" {
   description = "Optional limit (i.e. for testing) on the number of records that the handler will process during a single invocation. This setting is a hard cap on top of opportunity- and forecast-specific limits."
   type        = number
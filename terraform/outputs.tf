@@ -11,7 +11,7 @@ output "service_dashboard_url" {
 }
 
 output "lambda_functions" {
-  value = [
+  value = concat([
     module.DownloadGrantsGovDB.lambda_function_name,
     module.ReceiveFFISEmail.lambda_function_name,
     module.EnqueueFFISDownload.lambda_function_name,
@@ -22,5 +22,9 @@ output "lambda_functions" {
     module.PersistGrantsGovXMLDB.lambda_function_name,
     module.PersistFFISData.lambda_function_name,
     module.PublishGrantEvents.lambda_function_name,
-  ]
+  ],
+    module.PublishGrantStatusTransitions[*].lambda_function_name,
+    module.PublishGrantClosingSoonEvents[*].lambda_function_name,
+    module.ReportDataQuality[*].lambda_function_name,
+  )
 }
@@ -226,12 +226,54 @@ variable "ses_active_receipt_rule_set_enabled" {
   default     = true
 }
 
+variable "grant_events_schema_enforced" {
+  description = "When true, grant modification events that do not conform to the published schema are not published."
+  type        = bool
+  default     = false
+}
+
+variable "assistance_listings_enrichment_enabled" {
+  description = "When true, published grants are enriched with the Assistance Listings catalog imported by the `grants-ingest assistance-listings-import` command."
+  type        = bool
+  default     = false
+}
+
 variable "is_forecasted_grants_enabled" {
   description = "When true, enables processing of forecasted grant records from Grants.gov."
   type        = bool
   default     = false
 }
 
+variable "grantsgov_strict_validation_enabled" {
+  description = "When true, SplitGrantsGovXMLDB skips Grants.gov records that violate the constraints of the Grants.gov XML schema. Otherwise, violations are only logged and reported as metrics."
+  type        = bool
+  default     = false
+}
+
+variable "grant_status_transition_events_enabled" {
+  description = "When true, enables a scheduled Lambda function that publishes events for grants whose status changed because a close or archive date was reached."
+  type        = bool
+  default     = false
+}
+
+variable "grant_closing_soon_events_enabled" {
+  description = "When true, enables a scheduled Lambda function that publishes reminder events for grants that close soon."
+  type        = bool
+  default     = false
+}
+
+variable "grant_closing_soon_windows" {
+  description = "Numbers of days before grant close dates at which closing soon reminder events are published."
+  type        = list(number)
+  default     = [30, 14, 3]
+}
+
+variable "data_quality_report_enabled" {
+  description = "When true, enables a scheduled Lambda function that saves data quality reports of grants prepared data to the source data bucket."
+  type        = bool
+  default     = false
+}
+
 variable "max_split_grantsgov_records" {
   description = "Optional hard limit (i.e. for testing) on the number of records (of any type) that SplitGrantsGovXMLDB handler will process during a single invocation."
   type        = number